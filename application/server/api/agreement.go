@@ -0,0 +1,128 @@
+package api
+
+import (
+	"application/service"
+	"application/utils"
+	"log"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AgreementHandler struct {
+	agreementService *service.AgreementService
+}
+
+func NewAgreementHandler() *AgreementHandler {
+	return &AgreementHandler{
+		agreementService: &service.AgreementService{},
+	}
+}
+
+// CreateFrameworkAgreement 主机厂发起框架协议
+func (h *AgreementHandler) CreateFrameworkAgreement(c *gin.Context) {
+	var req struct {
+		ID             string                  `json:"id"`
+		ManufacturerID string                  `json:"manufacturerId"`
+		Parts          []service.AgreementPart `json:"parts"`
+		ValidFrom      time.Time               `json:"validFrom"`
+		ValidTo        time.Time               `json:"validTo"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequest(c, "无效的请求参数")
+		return
+	}
+
+	if err := h.agreementService.CreateFrameworkAgreement(req.ID, req.ManufacturerID, req.Parts, req.ValidFrom, req.ValidTo); err != nil {
+		log.Printf("CreateFrameworkAgreement Error: %v", err)
+		utils.ServerError(c, err.Error())
+		return
+	}
+	utils.SuccessWithMessage(c, "框架协议已发起", nil)
+}
+
+// AcceptFrameworkAgreement 零部件厂确认框架协议
+func (h *AgreementHandler) AcceptFrameworkAgreement(c *gin.Context) {
+	id := c.Param("id")
+	if err := h.agreementService.AcceptFrameworkAgreement(id); err != nil {
+		log.Printf("AcceptFrameworkAgreement Error: %v", err)
+		utils.ServerError(c, err.Error())
+		return
+	}
+	utils.SuccessWithMessage(c, "框架协议已生效", nil)
+}
+
+// TerminateFrameworkAgreement 主机厂终止框架协议
+func (h *AgreementHandler) TerminateFrameworkAgreement(c *gin.Context) {
+	id := c.Param("id")
+	if err := h.agreementService.TerminateFrameworkAgreement(id); err != nil {
+		utils.ServerError(c, err.Error())
+		return
+	}
+	utils.SuccessWithMessage(c, "框架协议已终止", nil)
+}
+
+// CallOffOrder 主机厂下达调用订单
+func (h *AgreementHandler) CallOffOrder(c *gin.Context) {
+	agreementId := c.Param("id")
+	var req struct {
+		OrderID string              `json:"orderId"`
+		Items   []service.OrderItem `json:"items"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequest(c, "参数错误")
+		return
+	}
+
+	if err := h.agreementService.CallOffOrder(agreementId, req.OrderID, req.Items); err != nil {
+		log.Printf("CallOffOrder Error: %v", err)
+		utils.ServerError(c, err.Error())
+		return
+	}
+	utils.SuccessWithMessage(c, "调用订单已下达", nil)
+}
+
+// QueryFrameworkAgreement 查询框架协议 (主机厂)
+func (h *AgreementHandler) QueryFrameworkAgreement(c *gin.Context) {
+	h.queryFrameworkAgreement(c, service.OEM_ORG)
+}
+
+// QueryFrameworkAgreementForManufacturer 查询框架协议 (零部件厂商)
+func (h *AgreementHandler) QueryFrameworkAgreementForManufacturer(c *gin.Context) {
+	h.queryFrameworkAgreement(c, service.MANUFACTURER_ORG)
+}
+
+func (h *AgreementHandler) queryFrameworkAgreement(c *gin.Context, orgName string) {
+	id := c.Param("id")
+	agreement, err := h.agreementService.QueryFrameworkAgreement(orgName, id)
+	if err != nil {
+		utils.ServerError(c, err.Error())
+		return
+	}
+	utils.Success(c, agreement)
+}
+
+// QueryAgreementUtilisation 查询框架协议执行情况 (主机厂)
+func (h *AgreementHandler) QueryAgreementUtilisation(c *gin.Context) {
+	h.queryAgreementUtilisation(c, service.OEM_ORG)
+}
+
+// QueryAgreementUtilisationForManufacturer 查询框架协议执行情况 (零部件厂商)
+func (h *AgreementHandler) QueryAgreementUtilisationForManufacturer(c *gin.Context) {
+	h.queryAgreementUtilisation(c, service.MANUFACTURER_ORG)
+}
+
+// QueryAgreementUtilisationForPlatform 查询框架协议执行情况 (平台方)
+func (h *AgreementHandler) QueryAgreementUtilisationForPlatform(c *gin.Context) {
+	h.queryAgreementUtilisation(c, service.PLATFORM_ORG)
+}
+
+func (h *AgreementHandler) queryAgreementUtilisation(c *gin.Context, orgName string) {
+	id := c.Param("id")
+	utilisation, err := h.agreementService.QueryAgreementUtilisation(orgName, id)
+	if err != nil {
+		utils.ServerError(c, err.Error())
+		return
+	}
+	utils.Success(c, utilisation)
+}
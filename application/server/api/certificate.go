@@ -0,0 +1,146 @@
+package api
+
+import (
+	"application/service"
+	"application/utils"
+	"log"
+
+	"github.com/gin-gonic/gin"
+)
+
+type CertificateHandler struct {
+	certificateService *service.CertificateService
+}
+
+func NewCertificateHandler() *CertificateHandler {
+	return &CertificateHandler{
+		certificateService: &service.CertificateService{},
+	}
+}
+
+// RegisterLabKey 平台方登记第三方实验室公钥
+func (h *CertificateHandler) RegisterLabKey(c *gin.Context) {
+	var req struct {
+		LabID     string `json:"labId"`
+		Name      string `json:"name"`
+		PublicKey string `json:"publicKey"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil || req.LabID == "" || req.PublicKey == "" {
+		utils.BadRequest(c, "参数错误")
+		return
+	}
+
+	if err := h.certificateService.RegisterLabKey(req.LabID, req.Name, req.PublicKey); err != nil {
+		log.Printf("RegisterLabKey Error: %v", err)
+		utils.ServerError(c, err.Error())
+		return
+	}
+	utils.SuccessWithMessage(c, "实验室公钥已登记", gin.H{"labId": req.LabID})
+}
+
+// QueryLabKey 查询第三方实验室公钥
+func (h *CertificateHandler) QueryLabKey(c *gin.Context) {
+	labKey, err := h.certificateService.QueryLabKey(service.PLATFORM_ORG, c.Param("id"))
+	if err != nil {
+		utils.ServerError(c, err.Error())
+		return
+	}
+	utils.Success(c, labKey)
+}
+
+// AddCertificate 厂商登记质量证书
+func (h *CertificateHandler) AddCertificate(c *gin.Context) {
+	var req service.Certificate
+	if err := c.ShouldBindJSON(&req); err != nil || req.ID == "" || req.DocHash == "" {
+		utils.BadRequest(c, "参数错误")
+		return
+	}
+
+	if err := h.certificateService.AddCertificate(req); err != nil {
+		log.Printf("AddCertificate Error: %v", err)
+		utils.ServerError(c, err.Error())
+		return
+	}
+	utils.SuccessWithMessage(c, "质量证书已登记", gin.H{"id": req.ID})
+}
+
+// SetRequiredCertificates 主机厂设置签收前必须具备的证书类型
+func (h *CertificateHandler) SetRequiredCertificates(c *gin.Context) {
+	id := c.Param("id")
+	var req struct {
+		Types []string `json:"types"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil || req.Types == nil {
+		utils.BadRequest(c, "参数错误")
+		return
+	}
+
+	if err := h.certificateService.SetRequiredCertificates(id, req.Types); err != nil {
+		log.Printf("SetRequiredCertificates Error: %v", err)
+		utils.ServerError(c, err.Error())
+		return
+	}
+	utils.SuccessWithMessage(c, "证书要求已更新", nil)
+}
+
+// QueryCertificate 查询质量证书 (主机厂)
+func (h *CertificateHandler) QueryCertificate(c *gin.Context) {
+	h.queryCertificate(c, service.OEM_ORG)
+}
+
+// QueryCertificateForManufacturer 查询质量证书 (厂商)
+func (h *CertificateHandler) QueryCertificateForManufacturer(c *gin.Context) {
+	h.queryCertificate(c, service.MANUFACTURER_ORG)
+}
+
+func (h *CertificateHandler) queryCertificate(c *gin.Context, orgName string) {
+	cert, err := h.certificateService.QueryCertificate(orgName, c.Param("id"))
+	if err != nil {
+		utils.ServerError(c, err.Error())
+		return
+	}
+	utils.Success(c, cert)
+}
+
+// QueryOrderCertificates 查询订单的质量证书 (主机厂)
+func (h *CertificateHandler) QueryOrderCertificates(c *gin.Context) {
+	h.queryOrderCertificates(c, service.OEM_ORG)
+}
+
+// QueryOrderCertificatesForManufacturer 查询订单的质量证书 (厂商)
+func (h *CertificateHandler) QueryOrderCertificatesForManufacturer(c *gin.Context) {
+	h.queryOrderCertificates(c, service.MANUFACTURER_ORG)
+}
+
+func (h *CertificateHandler) queryOrderCertificates(c *gin.Context, orgName string) {
+	certs, err := h.certificateService.QueryOrderCertificates(orgName, c.Param("id"))
+	if err != nil {
+		utils.ServerError(c, err.Error())
+		return
+	}
+	utils.Success(c, certs)
+}
+
+// QueryBatchCertificates 查询批次的质量证书 (主机厂)
+func (h *CertificateHandler) QueryBatchCertificates(c *gin.Context) {
+	h.queryBatchCertificates(c, service.OEM_ORG)
+}
+
+// QueryBatchCertificatesForManufacturer 查询批次的质量证书 (厂商)
+func (h *CertificateHandler) QueryBatchCertificatesForManufacturer(c *gin.Context) {
+	h.queryBatchCertificates(c, service.MANUFACTURER_ORG)
+}
+
+// QueryBatchCertificatesForPlatform 查询批次的质量证书 (平台方)
+func (h *CertificateHandler) QueryBatchCertificatesForPlatform(c *gin.Context) {
+	h.queryBatchCertificates(c, service.PLATFORM_ORG)
+}
+
+func (h *CertificateHandler) queryBatchCertificates(c *gin.Context, orgName string) {
+	certs, err := h.certificateService.QueryBatchCertificates(orgName, c.Param("batchNo"))
+	if err != nil {
+		utils.ServerError(c, err.Error())
+		return
+	}
+	utils.Success(c, certs)
+}
@@ -0,0 +1,205 @@
+package api
+
+import (
+	"application/config"
+	"application/service"
+	"application/utils"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 未配置时的单个文件大小上限 (MB)
+const defaultDocumentMaxSizeMB = 32
+
+type DocumentHandler struct {
+	documentService *service.DocumentService
+}
+
+func NewDocumentHandler() *DocumentHandler {
+	return &DocumentHandler{
+		documentService: &service.DocumentService{},
+	}
+}
+
+// UploadDocument 上传文档 (主机厂)
+func (h *DocumentHandler) UploadDocument(c *gin.Context) {
+	h.uploadDocument(c, service.OEM_ORG)
+}
+
+// UploadDocumentForManufacturer 上传文档 (厂商)
+func (h *DocumentHandler) UploadDocumentForManufacturer(c *gin.Context) {
+	h.uploadDocument(c, service.MANUFACTURER_ORG)
+}
+
+// UploadDocumentForCarrier 上传文档 (承运商)
+func (h *DocumentHandler) UploadDocumentForCarrier(c *gin.Context) {
+	h.uploadDocument(c, carrierOrg(c))
+}
+
+// multipart 表单: file 文件, refType 关联对象类型 (ORDER/SHIPMENT), refId 关联对象ID, category 文档类别, id 文档ID (可选)
+func (h *DocumentHandler) uploadDocument(c *gin.Context, orgName string) {
+	fileHeader, err := c.FormFile("file")
+	if err != nil || c.PostForm("refType") == "" || c.PostForm("refId") == "" || c.PostForm("category") == "" {
+		utils.BadRequest(c, "参数错误")
+		return
+	}
+	maxSizeMB := config.GlobalConfig.Documents.MaxSizeMB
+	if maxSizeMB <= 0 {
+		maxSizeMB = defaultDocumentMaxSizeMB
+	}
+	if fileHeader.Size > int64(maxSizeMB)<<20 {
+		utils.BadRequest(c, fmt.Sprintf("文件大小超过上限 %dMB", maxSizeMB))
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		utils.BadRequest(c, "读取上传文件失败")
+		return
+	}
+	defer file.Close()
+	data, err := io.ReadAll(file)
+	if err != nil || len(data) == 0 {
+		utils.BadRequest(c, "读取上传文件失败")
+		return
+	}
+
+	contentType := fileHeader.Header.Get("Content-Type")
+	if contentType == "" {
+		contentType = http.DetectContentType(data)
+	}
+	doc, err := h.documentService.UploadDocument(orgName, service.Document{
+		ID:          c.PostForm("id"),
+		FileName:    fileHeader.Filename,
+		ContentType: contentType,
+		Category:    c.PostForm("category"),
+		RefType:     c.PostForm("refType"),
+		RefID:       c.PostForm("refId"),
+	}, data)
+	if err != nil {
+		log.Printf("UploadDocument Error: %v", err)
+		utils.ServerError(c, err.Error())
+		return
+	}
+	utils.SuccessWithMessage(c, "文档已上传并锚定", doc)
+}
+
+// DownloadDocument 下载文档 (主机厂)
+func (h *DocumentHandler) DownloadDocument(c *gin.Context) {
+	h.downloadDocument(c, service.OEM_ORG)
+}
+
+// DownloadDocumentForManufacturer 下载文档 (厂商)
+func (h *DocumentHandler) DownloadDocumentForManufacturer(c *gin.Context) {
+	h.downloadDocument(c, service.MANUFACTURER_ORG)
+}
+
+// DownloadDocumentForCarrier 下载文档 (承运商)
+func (h *DocumentHandler) DownloadDocumentForCarrier(c *gin.Context) {
+	h.downloadDocument(c, carrierOrg(c))
+}
+
+// DownloadDocumentForPlatform 下载文档 (平台方)
+func (h *DocumentHandler) DownloadDocumentForPlatform(c *gin.Context) {
+	h.downloadDocument(c, service.PLATFORM_ORG)
+}
+
+func (h *DocumentHandler) downloadDocument(c *gin.Context, orgName string) {
+	doc, data, err := h.documentService.DownloadDocument(orgName, c.Param("id"))
+	if errors.Is(err, service.ErrDocumentHashMismatch) {
+		log.Printf("DownloadDocument Error: %v", err)
+		utils.Fail(c, http.StatusConflict, err.Error())
+		return
+	}
+	if err != nil {
+		utils.ServerError(c, err.Error())
+		return
+	}
+
+	contentType := doc.ContentType
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	c.Header("Content-Disposition", "attachment; filename*=UTF-8''"+url.PathEscape(doc.FileName))
+	c.Header("X-Document-Hash", doc.Hash)
+	c.Data(http.StatusOK, contentType, data)
+}
+
+// QueryDocument 查询文档锚定记录 (主机厂)
+func (h *DocumentHandler) QueryDocument(c *gin.Context) {
+	h.queryDocument(c, service.OEM_ORG)
+}
+
+// QueryDocumentForManufacturer 查询文档锚定记录 (厂商)
+func (h *DocumentHandler) QueryDocumentForManufacturer(c *gin.Context) {
+	h.queryDocument(c, service.MANUFACTURER_ORG)
+}
+
+// QueryDocumentForCarrier 查询文档锚定记录 (承运商)
+func (h *DocumentHandler) QueryDocumentForCarrier(c *gin.Context) {
+	h.queryDocument(c, carrierOrg(c))
+}
+
+// QueryDocumentForPlatform 查询文档锚定记录 (平台方)
+func (h *DocumentHandler) QueryDocumentForPlatform(c *gin.Context) {
+	h.queryDocument(c, service.PLATFORM_ORG)
+}
+
+func (h *DocumentHandler) queryDocument(c *gin.Context, orgName string) {
+	doc, err := h.documentService.QueryDocument(orgName, c.Param("id"))
+	if err != nil {
+		utils.ServerError(c, err.Error())
+		return
+	}
+	utils.Success(c, doc)
+}
+
+// QueryOrderDocuments 查询订单文档 (主机厂)
+func (h *DocumentHandler) QueryOrderDocuments(c *gin.Context) {
+	h.queryDocuments(c, service.OEM_ORG, "ORDER")
+}
+
+// QueryOrderDocumentsForManufacturer 查询订单文档 (厂商)
+func (h *DocumentHandler) QueryOrderDocumentsForManufacturer(c *gin.Context) {
+	h.queryDocuments(c, service.MANUFACTURER_ORG, "ORDER")
+}
+
+// QueryOrderDocumentsForPlatform 查询订单文档 (平台方)
+func (h *DocumentHandler) QueryOrderDocumentsForPlatform(c *gin.Context) {
+	h.queryDocuments(c, service.PLATFORM_ORG, "ORDER")
+}
+
+// QueryShipmentDocuments 查询物流单文档 (主机厂)
+func (h *DocumentHandler) QueryShipmentDocuments(c *gin.Context) {
+	h.queryDocuments(c, service.OEM_ORG, "SHIPMENT")
+}
+
+// QueryShipmentDocumentsForManufacturer 查询物流单文档 (厂商)
+func (h *DocumentHandler) QueryShipmentDocumentsForManufacturer(c *gin.Context) {
+	h.queryDocuments(c, service.MANUFACTURER_ORG, "SHIPMENT")
+}
+
+// QueryShipmentDocumentsForCarrier 查询物流单文档 (承运商)
+func (h *DocumentHandler) QueryShipmentDocumentsForCarrier(c *gin.Context) {
+	h.queryDocuments(c, carrierOrg(c), "SHIPMENT")
+}
+
+// QueryShipmentDocumentsForPlatform 查询物流单文档 (平台方)
+func (h *DocumentHandler) QueryShipmentDocumentsForPlatform(c *gin.Context) {
+	h.queryDocuments(c, service.PLATFORM_ORG, "SHIPMENT")
+}
+
+func (h *DocumentHandler) queryDocuments(c *gin.Context, orgName string, refType string) {
+	docs, err := h.documentService.QueryDocuments(orgName, refType, c.Param("id"))
+	if err != nil {
+		utils.ServerError(c, err.Error())
+		return
+	}
+	utils.Success(c, docs)
+}
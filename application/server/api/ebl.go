@@ -0,0 +1,133 @@
+package api
+
+import (
+	"application/service"
+	"application/utils"
+	"log"
+
+	"github.com/gin-gonic/gin"
+)
+
+type EBLHandler struct {
+	eblService *service.EBLService
+}
+
+func NewEBLHandler() *EBLHandler {
+	return &EBLHandler{
+		eblService: &service.EBLService{},
+	}
+}
+
+// IssueBillOfLading 承运商签发电子提单
+func (h *EBLHandler) IssueBillOfLading(c *gin.Context) {
+	var req struct {
+		ID        string `json:"id"`
+		WaybillID string `json:"waybillId"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil || req.ID == "" || req.WaybillID == "" {
+		utils.BadRequest(c, "参数错误")
+		return
+	}
+
+	if err := h.eblService.IssueBillOfLading(carrierOrg(c), req.ID, req.WaybillID); err != nil {
+		log.Printf("IssueBillOfLading Error: %v", err)
+		utils.ServerError(c, err.Error())
+		return
+	}
+	utils.SuccessWithMessage(c, "电子提单已签发", gin.H{"id": req.ID})
+}
+
+// TransferTitle 背书转让提单 (主机厂)
+func (h *EBLHandler) TransferTitle(c *gin.Context) {
+	h.transferTitle(c, service.OEM_ORG)
+}
+
+// TransferTitleForManufacturer 背书转让提单 (厂商)
+func (h *EBLHandler) TransferTitleForManufacturer(c *gin.Context) {
+	h.transferTitle(c, service.MANUFACTURER_ORG)
+}
+
+// TransferTitleForBank 背书转让提单 (银行)
+func (h *EBLHandler) TransferTitleForBank(c *gin.Context) {
+	h.transferTitle(c, bankOrg(c))
+}
+
+func (h *EBLHandler) transferTitle(c *gin.Context, orgName string) {
+	id := c.Param("id")
+	var req struct {
+		NewHolderID string `json:"newHolderId"`
+		Note        string `json:"note"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil || req.NewHolderID == "" {
+		utils.BadRequest(c, "参数错误")
+		return
+	}
+
+	if err := h.eblService.TransferTitle(orgName, id, req.NewHolderID, req.Note); err != nil {
+		log.Printf("TransferTitle Error: %v", err)
+		utils.ServerError(c, err.Error())
+		return
+	}
+	utils.SuccessWithMessage(c, "提单已背书转让", nil)
+}
+
+// SurrenderBillOfLading 交回提单 (主机厂)
+func (h *EBLHandler) SurrenderBillOfLading(c *gin.Context) {
+	h.surrenderBillOfLading(c, service.OEM_ORG)
+}
+
+// SurrenderBillOfLadingForManufacturer 交回提单 (厂商)
+func (h *EBLHandler) SurrenderBillOfLadingForManufacturer(c *gin.Context) {
+	h.surrenderBillOfLading(c, service.MANUFACTURER_ORG)
+}
+
+// SurrenderBillOfLadingForBank 交回提单 (银行)
+func (h *EBLHandler) SurrenderBillOfLadingForBank(c *gin.Context) {
+	h.surrenderBillOfLading(c, bankOrg(c))
+}
+
+func (h *EBLHandler) surrenderBillOfLading(c *gin.Context, orgName string) {
+	id := c.Param("id")
+	if err := h.eblService.SurrenderBillOfLading(orgName, id); err != nil {
+		log.Printf("SurrenderBillOfLading Error: %v", err)
+		utils.ServerError(c, err.Error())
+		return
+	}
+	utils.SuccessWithMessage(c, "提单已交回", nil)
+}
+
+// AcceptBillOfLadingSurrender 承运商确认交回提单
+func (h *EBLHandler) AcceptBillOfLadingSurrender(c *gin.Context) {
+	id := c.Param("id")
+	if err := h.eblService.AcceptBillOfLadingSurrender(carrierOrg(c), id); err != nil {
+		log.Printf("AcceptBillOfLadingSurrender Error: %v", err)
+		utils.ServerError(c, err.Error())
+		return
+	}
+	utils.SuccessWithMessage(c, "提单交回已确认", nil)
+}
+
+// QueryBillOfLading 查询电子提单 (主机厂)
+func (h *EBLHandler) QueryBillOfLading(c *gin.Context) {
+	h.queryBillOfLading(c, service.OEM_ORG)
+}
+
+// QueryBillOfLadingForManufacturer 查询电子提单 (厂商)
+func (h *EBLHandler) QueryBillOfLadingForManufacturer(c *gin.Context) {
+	h.queryBillOfLading(c, service.MANUFACTURER_ORG)
+}
+
+// QueryBillOfLadingForCarrier 查询电子提单 (承运商与银行)
+func (h *EBLHandler) QueryBillOfLadingForCarrier(c *gin.Context) {
+	h.queryBillOfLading(c, service.CARRIER_ORG)
+}
+
+func (h *EBLHandler) queryBillOfLading(c *gin.Context, orgName string) {
+	id := c.Param("id")
+	ebl, err := h.eblService.QueryBillOfLading(orgName, id)
+	if err != nil {
+		utils.ServerError(c, err.Error())
+		return
+	}
+	utils.Success(c, ebl)
+}
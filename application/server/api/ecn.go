@@ -0,0 +1,100 @@
+package api
+
+import (
+	"application/service"
+	"application/utils"
+	"log"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ECNHandler struct {
+	ecnService *service.ECNService
+}
+
+func NewECNHandler() *ECNHandler {
+	return &ECNHandler{
+		ecnService: &service.ECNService{},
+	}
+}
+
+// IssueECN 主机厂发布工程变更通知
+func (h *ECNHandler) IssueECN(c *gin.Context) {
+	var req service.EngineeringChange
+	if err := c.ShouldBindJSON(&req); err != nil || req.ID == "" || len(req.PartNumbers) == 0 || len(req.AffectedOrderIDs) == 0 {
+		utils.BadRequest(c, "参数错误")
+		return
+	}
+
+	if err := h.ecnService.IssueECN(req); err != nil {
+		log.Printf("IssueECN Error: %v", err)
+		utils.ServerError(c, err.Error())
+		return
+	}
+	utils.SuccessWithMessage(c, "工程变更已发布, 待厂商确认", gin.H{"id": req.ID})
+}
+
+// AcknowledgeECN 厂商确认工程变更
+func (h *ECNHandler) AcknowledgeECN(c *gin.Context) {
+	id := c.Param("id")
+	var req struct {
+		ManufacturerID  string    `json:"manufacturerId"`
+		EffectivityDate time.Time `json:"effectivityDate"`
+		Note            string    `json:"note"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil || req.ManufacturerID == "" || req.EffectivityDate.IsZero() {
+		utils.BadRequest(c, "参数错误")
+		return
+	}
+
+	if err := h.ecnService.AcknowledgeECN(id, req.ManufacturerID, req.EffectivityDate, req.Note); err != nil {
+		log.Printf("AcknowledgeECN Error: %v", err)
+		utils.ServerError(c, err.Error())
+		return
+	}
+	utils.SuccessWithMessage(c, "工程变更已确认", nil)
+}
+
+// QueryECN 查询工程变更通知 (主机厂)
+func (h *ECNHandler) QueryECN(c *gin.Context) {
+	h.queryECN(c, service.OEM_ORG)
+}
+
+// QueryECNForManufacturer 查询工程变更通知 (厂商)
+func (h *ECNHandler) QueryECNForManufacturer(c *gin.Context) {
+	h.queryECN(c, service.MANUFACTURER_ORG)
+}
+
+// QueryECNForPlatform 查询工程变更通知 (平台方, 质量审计)
+func (h *ECNHandler) QueryECNForPlatform(c *gin.Context) {
+	h.queryECN(c, service.PLATFORM_ORG)
+}
+
+func (h *ECNHandler) queryECN(c *gin.Context, orgName string) {
+	ecn, err := h.ecnService.QueryECN(orgName, c.Param("id"))
+	if err != nil {
+		utils.ServerError(c, err.Error())
+		return
+	}
+	utils.Success(c, ecn)
+}
+
+// QueryOrderECNs 查询订单的工程变更 (主机厂)
+func (h *ECNHandler) QueryOrderECNs(c *gin.Context) {
+	h.queryOrderECNs(c, service.OEM_ORG)
+}
+
+// QueryOrderECNsForManufacturer 查询订单的工程变更 (厂商)
+func (h *ECNHandler) QueryOrderECNsForManufacturer(c *gin.Context) {
+	h.queryOrderECNs(c, service.MANUFACTURER_ORG)
+}
+
+func (h *ECNHandler) queryOrderECNs(c *gin.Context, orgName string) {
+	ecns, err := h.ecnService.QueryOrderECNs(orgName, c.Param("id"))
+	if err != nil {
+		utils.ServerError(c, err.Error())
+		return
+	}
+	utils.Success(c, ecns)
+}
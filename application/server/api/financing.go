@@ -0,0 +1,195 @@
+package api
+
+import (
+	"application/service"
+	"application/utils"
+	"log"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type FinancingHandler struct {
+	financingService *service.FinancingService
+}
+
+func NewFinancingHandler() *FinancingHandler {
+	return &FinancingHandler{
+		financingService: &service.FinancingService{},
+	}
+}
+
+// CreateReceivable 厂商创建应收账款
+func (h *FinancingHandler) CreateReceivable(c *gin.Context) {
+	var req struct {
+		ID         string `json:"id"`
+		SourceType string `json:"sourceType"`
+		SourceID   string `json:"sourceId"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil || req.ID == "" || req.SourceID == "" {
+		utils.BadRequest(c, "参数错误")
+		return
+	}
+
+	if err := h.financingService.CreateReceivable(req.ID, req.SourceType, req.SourceID); err != nil {
+		log.Printf("CreateReceivable Error: %v", err)
+		utils.ServerError(c, err.Error())
+		return
+	}
+	utils.SuccessWithMessage(c, "应收账款已创建", gin.H{"id": req.ID})
+}
+
+// ApplyFinancing 厂商申请融资
+func (h *FinancingHandler) ApplyFinancing(c *gin.Context) {
+	var req struct {
+		ID            string  `json:"id"`
+		ReceivableID  string  `json:"receivableId"`
+		BankID        string  `json:"bankId"`
+		RequestAmount float64 `json:"requestAmount"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil || req.ID == "" || req.ReceivableID == "" || req.BankID == "" {
+		utils.BadRequest(c, "参数错误")
+		return
+	}
+
+	if err := h.financingService.ApplyFinancing(req.ID, req.ReceivableID, req.BankID, req.RequestAmount); err != nil {
+		log.Printf("ApplyFinancing Error: %v", err)
+		utils.ServerError(c, err.Error())
+		return
+	}
+	utils.SuccessWithMessage(c, "融资申请已提交", gin.H{"id": req.ID})
+}
+
+// ApproveFinancing 银行审批通过
+func (h *FinancingHandler) ApproveFinancing(c *gin.Context) {
+	id := c.Param("id")
+	if err := h.financingService.ApproveFinancing(bankOrg(c), id); err != nil {
+		log.Printf("ApproveFinancing Error: %v", err)
+		utils.ServerError(c, err.Error())
+		return
+	}
+	utils.SuccessWithMessage(c, "融资已审批通过", nil)
+}
+
+// RejectFinancing 银行拒绝融资申请
+func (h *FinancingHandler) RejectFinancing(c *gin.Context) {
+	id := c.Param("id")
+	var req struct {
+		Reason string `json:"reason"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil || req.Reason == "" {
+		utils.BadRequest(c, "参数错误")
+		return
+	}
+
+	if err := h.financingService.RejectFinancing(bankOrg(c), id, req.Reason); err != nil {
+		log.Printf("RejectFinancing Error: %v", err)
+		utils.ServerError(c, err.Error())
+		return
+	}
+	utils.SuccessWithMessage(c, "融资申请已拒绝", nil)
+}
+
+// ConfirmRepayment 银行确认还款
+func (h *FinancingHandler) ConfirmRepayment(c *gin.Context) {
+	id := c.Param("id")
+	if err := h.financingService.ConfirmRepayment(bankOrg(c), id); err != nil {
+		log.Printf("ConfirmRepayment Error: %v", err)
+		utils.ServerError(c, err.Error())
+		return
+	}
+	utils.SuccessWithMessage(c, "已确认还款, 应收账款已解锁", nil)
+}
+
+// QueryReceivable 查询应收账款 (厂商)
+func (h *FinancingHandler) QueryReceivable(c *gin.Context) {
+	h.queryReceivable(c, service.MANUFACTURER_ORG)
+}
+
+// QueryReceivableForOEM 查询应收账款 (主机厂)
+func (h *FinancingHandler) QueryReceivableForOEM(c *gin.Context) {
+	h.queryReceivable(c, service.OEM_ORG)
+}
+
+// QueryReceivableForBank 查询应收账款 (银行)
+func (h *FinancingHandler) QueryReceivableForBank(c *gin.Context) {
+	h.queryReceivable(c, bankOrg(c))
+}
+
+func (h *FinancingHandler) queryReceivable(c *gin.Context, orgName string) {
+	id := c.Param("id")
+	receivable, err := h.financingService.QueryReceivable(orgName, id)
+	if err != nil {
+		utils.ServerError(c, err.Error())
+		return
+	}
+	utils.Success(c, receivable)
+}
+
+// QueryReceivableAmount 查询应收账款票面金额与随机盐 (厂商), 可线下披露给银行核验
+func (h *FinancingHandler) QueryReceivableAmount(c *gin.Context) {
+	h.queryReceivableAmount(c, service.MANUFACTURER_ORG)
+}
+
+// QueryReceivableAmountForOEM 查询应收账款票面金额 (主机厂)
+func (h *FinancingHandler) QueryReceivableAmountForOEM(c *gin.Context) {
+	h.queryReceivableAmount(c, service.OEM_ORG)
+}
+
+func (h *FinancingHandler) queryReceivableAmount(c *gin.Context, orgName string) {
+	id := c.Param("id")
+	amount, err := h.financingService.QueryReceivableAmount(orgName, id)
+	if err != nil {
+		utils.ServerError(c, err.Error())
+		return
+	}
+	utils.Success(c, amount)
+}
+
+// VerifyReceivableFaceValue 银行核验厂商披露的票面金额
+func (h *FinancingHandler) VerifyReceivableFaceValue(c *gin.Context) {
+	id := c.Param("id")
+	faceValue, err := strconv.ParseFloat(c.Query("faceValue"), 64)
+	salt := c.Query("salt")
+	if err != nil || salt == "" {
+		utils.BadRequest(c, "参数错误")
+		return
+	}
+
+	matched, err := h.financingService.VerifyReceivableFaceValue(bankOrg(c), id, faceValue, salt)
+	if err != nil {
+		utils.ServerError(c, err.Error())
+		return
+	}
+	utils.Success(c, gin.H{"id": id, "matched": matched})
+}
+
+// QueryFinancing 查询融资申请 (厂商)
+func (h *FinancingHandler) QueryFinancing(c *gin.Context) {
+	h.queryFinancing(c, service.MANUFACTURER_ORG)
+}
+
+// QueryFinancingForBank 查询融资申请 (银行)
+func (h *FinancingHandler) QueryFinancingForBank(c *gin.Context) {
+	h.queryFinancing(c, bankOrg(c))
+}
+
+func (h *FinancingHandler) queryFinancing(c *gin.Context, orgName string) {
+	id := c.Param("id")
+	application, err := h.financingService.QueryFinancing(orgName, id)
+	if err != nil {
+		utils.ServerError(c, err.Error())
+		return
+	}
+	utils.Success(c, application)
+}
+
+// QueryBankFinancings 银行查询提交给自己的融资申请
+func (h *FinancingHandler) QueryBankFinancings(c *gin.Context) {
+	applications, err := h.financingService.QueryBankFinancings(bankOrg(c))
+	if err != nil {
+		utils.ServerError(c, err.Error())
+		return
+	}
+	utils.Success(c, applications)
+}
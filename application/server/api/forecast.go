@@ -0,0 +1,119 @@
+package api
+
+import (
+	"application/service"
+	"application/utils"
+	"log"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ForecastHandler struct {
+	forecastService *service.ForecastService
+}
+
+func NewForecastHandler() *ForecastHandler {
+	return &ForecastHandler{
+		forecastService: &service.ForecastService{},
+	}
+}
+
+// PublishForecast 主机厂发布零件 12 周滚动需求预测
+func (h *ForecastHandler) PublishForecast(c *gin.Context) {
+	var req struct {
+		StartWeek string `json:"startWeek"`
+		Demand    []int  `json:"demand"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil || req.StartWeek == "" || len(req.Demand) == 0 {
+		utils.BadRequest(c, "参数错误")
+		return
+	}
+
+	if err := h.forecastService.PublishForecast(c.Param("manufacturerId"), c.Param("partNumber"), req.StartWeek, req.Demand); err != nil {
+		log.Printf("PublishForecast Error: %v", err)
+		utils.ServerError(c, err.Error())
+		return
+	}
+	utils.SuccessWithMessage(c, "需求预测已发布", nil)
+}
+
+// CommitCapacity 厂商按周承诺产能
+func (h *ForecastHandler) CommitCapacity(c *gin.Context) {
+	var req struct {
+		ManufacturerID string `json:"manufacturerId"`
+		Version        int    `json:"version"`
+		Committed      []int  `json:"committed"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil || req.Version <= 0 || len(req.Committed) == 0 {
+		utils.BadRequest(c, "参数错误")
+		return
+	}
+
+	if err := h.forecastService.CommitCapacity(req.ManufacturerID, c.Param("partNumber"), req.Version, req.Committed); err != nil {
+		log.Printf("CommitCapacity Error: %v", err)
+		utils.ServerError(c, err.Error())
+		return
+	}
+	utils.SuccessWithMessage(c, "产能承诺已提交", nil)
+}
+
+// CreateOrderFromForecast 主机厂将已承诺的预测周转为订单
+func (h *ForecastHandler) CreateOrderFromForecast(c *gin.Context) {
+	var req struct {
+		ID        string  `json:"id"`
+		WeekStart string  `json:"weekStart"`
+		Quantity  int     `json:"quantity"`
+		Price     float64 `json:"price"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil || req.ID == "" || req.WeekStart == "" || req.Quantity <= 0 {
+		utils.BadRequest(c, "参数错误")
+		return
+	}
+
+	if err := h.forecastService.CreateOrderFromForecast(req.ID, c.Param("manufacturerId"), c.Param("partNumber"), req.WeekStart, req.Quantity, req.Price); err != nil {
+		log.Printf("CreateOrderFromForecast Error: %v", err)
+		utils.ServerError(c, err.Error())
+		return
+	}
+	utils.SuccessWithMessage(c, "订单创建成功", gin.H{"id": req.ID})
+}
+
+// QueryForecast 查询需求预测 (主机厂)
+func (h *ForecastHandler) QueryForecast(c *gin.Context) {
+	forecast, err := h.forecastService.QueryForecast(service.OEM_ORG, c.Param("manufacturerId"), c.Param("partNumber"))
+	if err != nil {
+		utils.ServerError(c, err.Error())
+		return
+	}
+	utils.Success(c, forecast)
+}
+
+// QueryForecastForManufacturer 查询本厂商收到的需求预测
+func (h *ForecastHandler) QueryForecastForManufacturer(c *gin.Context) {
+	forecast, err := h.forecastService.QueryForecast(service.MANUFACTURER_ORG, c.Query("manufacturerId"), c.Param("partNumber"))
+	if err != nil {
+		utils.ServerError(c, err.Error())
+		return
+	}
+	utils.Success(c, forecast)
+}
+
+// CompareForecast 预测与承诺对比 (主机厂)
+func (h *ForecastHandler) CompareForecast(c *gin.Context) {
+	comparison, err := h.forecastService.CompareForecast(service.OEM_ORG, c.Param("manufacturerId"), c.Param("partNumber"))
+	if err != nil {
+		utils.ServerError(c, err.Error())
+		return
+	}
+	utils.Success(c, comparison)
+}
+
+// CompareForecastForManufacturer 预测与承诺对比 (厂商)
+func (h *ForecastHandler) CompareForecastForManufacturer(c *gin.Context) {
+	comparison, err := h.forecastService.CompareForecast(service.MANUFACTURER_ORG, c.Query("manufacturerId"), c.Param("partNumber"))
+	if err != nil {
+		utils.ServerError(c, err.Error())
+		return
+	}
+	utils.Success(c, comparison)
+}
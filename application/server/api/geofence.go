@@ -0,0 +1,121 @@
+package api
+
+import (
+	"application/service"
+	"application/utils"
+	"log"
+
+	"github.com/gin-gonic/gin"
+)
+
+type GeofenceHandler struct {
+	geofenceService *service.GeofenceService
+}
+
+func NewGeofenceHandler() *GeofenceHandler {
+	return &GeofenceHandler{
+		geofenceService: &service.GeofenceService{},
+	}
+}
+
+// CreateGeofence 平台方登记地理围栏
+func (h *GeofenceHandler) CreateGeofence(c *gin.Context) {
+	var req service.Geofence
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequest(c, "参数错误")
+		return
+	}
+
+	if err := h.geofenceService.CreateGeofence(req); err != nil {
+		log.Printf("CreateGeofence Error: %v", err)
+		utils.ServerError(c, err.Error())
+		return
+	}
+	utils.SuccessWithMessage(c, "地理围栏已登记", nil)
+}
+
+// UpdateGeofence 平台方修改地理围栏
+func (h *GeofenceHandler) UpdateGeofence(c *gin.Context) {
+	var req service.Geofence
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequest(c, "参数错误")
+		return
+	}
+	req.ID = c.Param("id")
+
+	if err := h.geofenceService.UpdateGeofence(req); err != nil {
+		log.Printf("UpdateGeofence Error: %v", err)
+		utils.ServerError(c, err.Error())
+		return
+	}
+	utils.SuccessWithMessage(c, "地理围栏已更新", nil)
+}
+
+// DeleteGeofence 平台方删除地理围栏
+func (h *GeofenceHandler) DeleteGeofence(c *gin.Context) {
+	id := c.Param("id")
+	if err := h.geofenceService.DeleteGeofence(id); err != nil {
+		log.Printf("DeleteGeofence Error: %v", err)
+		utils.ServerError(c, err.Error())
+		return
+	}
+	utils.SuccessWithMessage(c, "地理围栏已删除", nil)
+}
+
+// QueryGeofence 查询地理围栏
+func (h *GeofenceHandler) QueryGeofence(c *gin.Context) {
+	id := c.Param("id")
+	fence, err := h.geofenceService.QueryGeofence(service.PLATFORM_ORG, id)
+	if err != nil {
+		utils.ServerError(c, err.Error())
+		return
+	}
+	utils.Success(c, fence)
+}
+
+// QueryGeofenceList 查询地理围栏列表 (平台方)
+func (h *GeofenceHandler) QueryGeofenceList(c *gin.Context) {
+	h.queryGeofenceList(c, service.PLATFORM_ORG)
+}
+
+// QueryGeofenceListForOEM 查询地理围栏列表 (主机厂)
+func (h *GeofenceHandler) QueryGeofenceListForOEM(c *gin.Context) {
+	h.queryGeofenceList(c, service.OEM_ORG)
+}
+
+func (h *GeofenceHandler) queryGeofenceList(c *gin.Context, orgName string) {
+	fences, err := h.geofenceService.QueryGeofenceList(orgName)
+	if err != nil {
+		utils.ServerError(c, err.Error())
+		return
+	}
+	utils.Success(c, fences)
+}
+
+// SetShipmentDestination 设置物流单目的地围栏 (主机厂)
+func (h *GeofenceHandler) SetShipmentDestination(c *gin.Context) {
+	h.setShipmentDestination(c, service.OEM_ORG)
+}
+
+// SetShipmentDestinationForCarrier 设置物流单目的地围栏 (承运商)
+func (h *GeofenceHandler) SetShipmentDestinationForCarrier(c *gin.Context) {
+	h.setShipmentDestination(c, carrierOrg(c))
+}
+
+func (h *GeofenceHandler) setShipmentDestination(c *gin.Context, orgName string) {
+	id := c.Param("id")
+	var req struct {
+		FenceID string `json:"fenceId"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil || req.FenceID == "" {
+		utils.BadRequest(c, "参数错误")
+		return
+	}
+
+	if err := h.geofenceService.SetShipmentDestination(orgName, id, req.FenceID); err != nil {
+		log.Printf("SetShipmentDestination Error: %v", err)
+		utils.ServerError(c, err.Error())
+		return
+	}
+	utils.SuccessWithMessage(c, "目的地已设置", nil)
+}
@@ -0,0 +1,49 @@
+package api
+
+import (
+	"application/pkg/fabric"
+	"application/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 上下文中保存链上身份名称的键
+const identityKey = "fabricIdentity"
+
+// CarrierIdentity 承运商接口中间件: 按请求头 X-Carrier-ID 选择承运商链上身份, 未指定时使用默认承运商
+func CarrierIdentity() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		name, err := fabric.CarrierIdentity(c.GetHeader("X-Carrier-ID"))
+		if err != nil {
+			utils.BadRequest(c, err.Error())
+			c.Abort()
+			return
+		}
+		c.Set(identityKey, name)
+		c.Next()
+	}
+}
+
+// BankIdentity 银行接口中间件: 按请求头 X-Bank-ID 选择银行链上身份, 未指定时使用默认银行
+func BankIdentity() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		name, err := fabric.BankIdentity(c.GetHeader("X-Bank-ID"))
+		if err != nil {
+			utils.BadRequest(c, err.Error())
+			c.Abort()
+			return
+		}
+		c.Set(identityKey, name)
+		c.Next()
+	}
+}
+
+// carrierOrg 返回当前请求的承运商身份, 用于提交需要 carrierId 证书属性的交易
+func carrierOrg(c *gin.Context) string {
+	return c.GetString(identityKey)
+}
+
+// bankOrg 返回当前请求的银行身份, 用于提交或查询需要 bankId 证书属性的交易
+func bankOrg(c *gin.Context) string {
+	return c.GetString(identityKey)
+}
@@ -0,0 +1,114 @@
+package api
+
+import (
+	"application/service"
+	"application/utils"
+	"log"
+
+	"github.com/gin-gonic/gin"
+)
+
+type InvoiceHandler struct {
+	invoiceService *service.InvoiceService
+}
+
+func NewInvoiceHandler() *InvoiceHandler {
+	return &InvoiceHandler{
+		invoiceService: &service.InvoiceService{},
+	}
+}
+
+// IssueInvoice 厂商开具发票
+func (h *InvoiceHandler) IssueInvoice(c *gin.Context) {
+	var req struct {
+		ID         string    `json:"id"`
+		OrderID    string    `json:"orderId"`
+		Quantities []int     `json:"quantities"`
+		UnitPrices []float64 `json:"unitPrices"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil || req.ID == "" || req.OrderID == "" {
+		utils.BadRequest(c, "参数错误")
+		return
+	}
+	if len(req.Quantities) == 0 || len(req.Quantities) != len(req.UnitPrices) {
+		utils.BadRequest(c, "开票数量与单价须一一对应")
+		return
+	}
+
+	if err := h.invoiceService.IssueInvoice(req.ID, req.OrderID, req.Quantities, req.UnitPrices); err != nil {
+		log.Printf("IssueInvoice Error: %v", err)
+		utils.ServerError(c, err.Error())
+		return
+	}
+	utils.SuccessWithMessage(c, "发票已开具", gin.H{"id": req.ID})
+}
+
+// ApproveInvoice 主机厂批准发票付款
+func (h *InvoiceHandler) ApproveInvoice(c *gin.Context) {
+	id := c.Param("id")
+	if err := h.invoiceService.ApproveInvoice(id); err != nil {
+		log.Printf("ApproveInvoice Error: %v", err)
+		utils.ServerError(c, err.Error())
+		return
+	}
+	utils.SuccessWithMessage(c, "发票已批准付款", nil)
+}
+
+// RejectInvoice 主机厂拒绝发票
+func (h *InvoiceHandler) RejectInvoice(c *gin.Context) {
+	id := c.Param("id")
+	var req struct {
+		Reason string `json:"reason"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil || req.Reason == "" {
+		utils.BadRequest(c, "参数错误")
+		return
+	}
+
+	if err := h.invoiceService.RejectInvoice(id, req.Reason); err != nil {
+		log.Printf("RejectInvoice Error: %v", err)
+		utils.ServerError(c, err.Error())
+		return
+	}
+	utils.SuccessWithMessage(c, "发票已拒绝", nil)
+}
+
+// QueryInvoice 查询发票 (主机厂)
+func (h *InvoiceHandler) QueryInvoice(c *gin.Context) {
+	h.queryInvoice(c, service.OEM_ORG)
+}
+
+// QueryInvoiceForManufacturer 查询发票 (厂商)
+func (h *InvoiceHandler) QueryInvoiceForManufacturer(c *gin.Context) {
+	h.queryInvoice(c, service.MANUFACTURER_ORG)
+}
+
+func (h *InvoiceHandler) queryInvoice(c *gin.Context, orgName string) {
+	id := c.Param("id")
+	invoice, err := h.invoiceService.QueryInvoice(orgName, id)
+	if err != nil {
+		utils.ServerError(c, err.Error())
+		return
+	}
+	utils.Success(c, invoice)
+}
+
+// QueryInvoiceAmount 查询发票金额 (主机厂)
+func (h *InvoiceHandler) QueryInvoiceAmount(c *gin.Context) {
+	h.queryInvoiceAmount(c, service.OEM_ORG)
+}
+
+// QueryInvoiceAmountForManufacturer 查询发票金额 (厂商)
+func (h *InvoiceHandler) QueryInvoiceAmountForManufacturer(c *gin.Context) {
+	h.queryInvoiceAmount(c, service.MANUFACTURER_ORG)
+}
+
+func (h *InvoiceHandler) queryInvoiceAmount(c *gin.Context, orgName string) {
+	id := c.Param("id")
+	amount, err := h.invoiceService.QueryInvoiceAmount(orgName, id)
+	if err != nil {
+		utils.ServerError(c, err.Error())
+		return
+	}
+	utils.Success(c, amount)
+}
@@ -0,0 +1,186 @@
+package api
+
+import (
+	"application/service"
+	"application/utils"
+	"log"
+
+	"github.com/gin-gonic/gin"
+)
+
+type OnboardingHandler struct {
+	onboardingService *service.OnboardingService
+}
+
+func NewOnboardingHandler() *OnboardingHandler {
+	return &OnboardingHandler{
+		onboardingService: &service.OnboardingService{},
+	}
+}
+
+// SubmitApplication 主机厂推荐新供应商提交准入申请
+func (h *OnboardingHandler) SubmitApplication(c *gin.Context) {
+	h.submitApplication(c, service.OEM_ORG)
+}
+
+// SubmitApplicationForPlatform 平台方代录准入申请
+func (h *OnboardingHandler) SubmitApplicationForPlatform(c *gin.Context) {
+	h.submitApplication(c, service.PLATFORM_ORG)
+}
+
+func (h *OnboardingHandler) submitApplication(c *gin.Context, orgName string) {
+	var req service.OnboardingApplication
+	if err := c.ShouldBindJSON(&req); err != nil || req.ID == "" || req.ParticipantID == "" || len(req.Documents) == 0 {
+		utils.BadRequest(c, "参数错误")
+		return
+	}
+
+	if err := h.onboardingService.SubmitApplication(orgName, req); err != nil {
+		log.Printf("SubmitOnboardingApplication Error: %v", err)
+		utils.ServerError(c, err.Error())
+		return
+	}
+	utils.SuccessWithMessage(c, "准入申请已提交, 待平台审核", gin.H{"id": req.ID})
+}
+
+// ResubmitApplication 主机厂补充材料后重新提交
+func (h *OnboardingHandler) ResubmitApplication(c *gin.Context) {
+	h.resubmitApplication(c, service.OEM_ORG)
+}
+
+// ResubmitApplicationForPlatform 平台方补充材料后重新提交
+func (h *OnboardingHandler) ResubmitApplicationForPlatform(c *gin.Context) {
+	h.resubmitApplication(c, service.PLATFORM_ORG)
+}
+
+func (h *OnboardingHandler) resubmitApplication(c *gin.Context, orgName string) {
+	id := c.Param("id")
+	var req struct {
+		Documents []service.OnboardingDocument `json:"documents"`
+		Comment   string                       `json:"comment"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil || len(req.Documents) == 0 {
+		utils.BadRequest(c, "参数错误")
+		return
+	}
+
+	if err := h.onboardingService.ResubmitApplication(orgName, id, req.Documents, req.Comment); err != nil {
+		log.Printf("ResubmitOnboardingApplication Error: %v", err)
+		utils.ServerError(c, err.Error())
+		return
+	}
+	utils.SuccessWithMessage(c, "准入申请已重新提交", nil)
+}
+
+// StartReview 平台方开始审核
+func (h *OnboardingHandler) StartReview(c *gin.Context) {
+	if err := h.onboardingService.StartReview(c.Param("id")); err != nil {
+		log.Printf("StartOnboardingReview Error: %v", err)
+		utils.ServerError(c, err.Error())
+		return
+	}
+	utils.SuccessWithMessage(c, "准入申请进入审核", nil)
+}
+
+// RequestChanges 平台方要求补充材料
+func (h *OnboardingHandler) RequestChanges(c *gin.Context) {
+	id := c.Param("id")
+	var req struct {
+		Comment string `json:"comment"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil || req.Comment == "" {
+		utils.BadRequest(c, "参数错误")
+		return
+	}
+
+	if err := h.onboardingService.RequestChanges(id, req.Comment); err != nil {
+		log.Printf("RequestOnboardingChanges Error: %v", err)
+		utils.ServerError(c, err.Error())
+		return
+	}
+	utils.SuccessWithMessage(c, "已要求补充材料", nil)
+}
+
+// ApproveApplication 平台方批准准入申请
+func (h *OnboardingHandler) ApproveApplication(c *gin.Context) {
+	id := c.Param("id")
+	var req struct {
+		Comment string `json:"comment"`
+	}
+	_ = c.ShouldBindJSON(&req)
+
+	if err := h.onboardingService.ApproveApplication(id, req.Comment); err != nil {
+		log.Printf("ApproveOnboardingApplication Error: %v", err)
+		utils.ServerError(c, err.Error())
+		return
+	}
+	utils.SuccessWithMessage(c, "准入申请已批准, 参与方已激活", nil)
+}
+
+// RejectApplication 平台方拒绝准入申请
+func (h *OnboardingHandler) RejectApplication(c *gin.Context) {
+	id := c.Param("id")
+	var req struct {
+		Reason string `json:"reason"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil || req.Reason == "" {
+		utils.BadRequest(c, "参数错误")
+		return
+	}
+
+	if err := h.onboardingService.RejectApplication(id, req.Reason); err != nil {
+		log.Printf("RejectOnboardingApplication Error: %v", err)
+		utils.ServerError(c, err.Error())
+		return
+	}
+	utils.SuccessWithMessage(c, "准入申请已拒绝", nil)
+}
+
+// QueryApplication 查询准入申请 (主机厂)
+func (h *OnboardingHandler) QueryApplication(c *gin.Context) {
+	h.queryApplication(c, service.OEM_ORG)
+}
+
+// QueryApplicationForPlatform 查询准入申请 (平台方)
+func (h *OnboardingHandler) QueryApplicationForPlatform(c *gin.Context) {
+	h.queryApplication(c, service.PLATFORM_ORG)
+}
+
+func (h *OnboardingHandler) queryApplication(c *gin.Context, orgName string) {
+	app, err := h.onboardingService.QueryApplication(orgName, c.Param("id"))
+	if err != nil {
+		utils.ServerError(c, err.Error())
+		return
+	}
+	utils.Success(c, app)
+}
+
+// QueryApplications 平台方按状态查询准入申请 (默认待审核)
+func (h *OnboardingHandler) QueryApplications(c *gin.Context) {
+	status := c.DefaultQuery("status", "SUBMITTED")
+	apps, err := h.onboardingService.QueryApplications(status)
+	if err != nil {
+		utils.ServerError(c, err.Error())
+		return
+	}
+	utils.Success(c, apps)
+}
+
+// QueryParticipant 查询已准入参与方 (主机厂)
+func (h *OnboardingHandler) QueryParticipant(c *gin.Context) {
+	h.queryParticipant(c, service.OEM_ORG)
+}
+
+// QueryParticipantForPlatform 查询已准入参与方 (平台方)
+func (h *OnboardingHandler) QueryParticipantForPlatform(c *gin.Context) {
+	h.queryParticipant(c, service.PLATFORM_ORG)
+}
+
+func (h *OnboardingHandler) queryParticipant(c *gin.Context, orgName string) {
+	participant, err := h.onboardingService.QueryParticipant(orgName, c.Param("id"))
+	if err != nil {
+		utils.ServerError(c, err.Error())
+		return
+	}
+	utils.Success(c, participant)
+}
@@ -0,0 +1,113 @@
+package api
+
+import (
+	"application/service"
+	"application/utils"
+	"log"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type PartHandler struct {
+	partService *service.PartService
+}
+
+func NewPartHandler() *PartHandler {
+	return &PartHandler{
+		partService: &service.PartService{},
+	}
+}
+
+// CreatePart 主机厂新增零件
+func (h *PartHandler) CreatePart(c *gin.Context) {
+	var req service.Part
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequest(c, "无效的请求参数")
+		return
+	}
+
+	if err := h.partService.CreatePart(req); err != nil {
+		log.Printf("CreatePart Error: %v", err)
+		utils.ServerError(c, err.Error())
+		return
+	}
+	utils.SuccessWithMessage(c, "零件已新增", nil)
+}
+
+// UpdatePart 主机厂修改零件
+func (h *PartHandler) UpdatePart(c *gin.Context) {
+	var req service.Part
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequest(c, "参数错误")
+		return
+	}
+	req.PartNumber = c.Param("partNumber")
+
+	if err := h.partService.UpdatePart(req); err != nil {
+		log.Printf("UpdatePart Error: %v", err)
+		utils.ServerError(c, err.Error())
+		return
+	}
+	utils.SuccessWithMessage(c, "零件已更新", nil)
+}
+
+// ObsoletePart 主机厂停用零件
+func (h *PartHandler) ObsoletePart(c *gin.Context) {
+	partNumber := c.Param("partNumber")
+	if err := h.partService.ObsoletePart(partNumber); err != nil {
+		utils.ServerError(c, err.Error())
+		return
+	}
+	utils.SuccessWithMessage(c, "零件已停用", nil)
+}
+
+// QueryPart 查询零件 (主机厂)
+func (h *PartHandler) QueryPart(c *gin.Context) {
+	h.queryPart(c, service.OEM_ORG)
+}
+
+// QueryPartForManufacturer 查询零件 (零部件厂商)
+func (h *PartHandler) QueryPartForManufacturer(c *gin.Context) {
+	h.queryPart(c, service.MANUFACTURER_ORG)
+}
+
+func (h *PartHandler) queryPart(c *gin.Context, orgName string) {
+	partNumber := c.Param("partNumber")
+	part, err := h.partService.QueryPart(orgName, partNumber)
+	if err != nil {
+		utils.ServerError(c, err.Error())
+		return
+	}
+	utils.Success(c, part)
+}
+
+// SearchParts 检索零件 (主机厂)
+func (h *PartHandler) SearchParts(c *gin.Context) {
+	h.searchParts(c, service.OEM_ORG)
+}
+
+// SearchPartsForManufacturer 检索零件 (零部件厂商)
+func (h *PartHandler) SearchPartsForManufacturer(c *gin.Context) {
+	h.searchParts(c, service.MANUFACTURER_ORG)
+}
+
+// SearchPartsForPlatform 检索零件 (平台方)
+func (h *PartHandler) SearchPartsForPlatform(c *gin.Context) {
+	h.searchParts(c, service.PLATFORM_ORG)
+}
+
+func (h *PartHandler) searchParts(c *gin.Context, orgName string) {
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("pageSize", "10"))
+	bookmark := c.DefaultQuery("bookmark", "")
+	keyword := c.DefaultQuery("keyword", "")
+	manufacturerId := c.DefaultQuery("manufacturerId", "")
+
+	result, err := h.partService.SearchParts(orgName, keyword, manufacturerId, int32(pageSize), bookmark)
+	if err != nil {
+		log.Printf("SearchParts Error: %v", err)
+		utils.ServerError(c, err.Error())
+		return
+	}
+	utils.Success(c, result)
+}
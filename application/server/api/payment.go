@@ -0,0 +1,110 @@
+package api
+
+import (
+	"application/service"
+	"application/utils"
+	"log"
+
+	"github.com/gin-gonic/gin"
+)
+
+type PaymentHandler struct {
+	paymentService *service.PaymentService
+}
+
+func NewPaymentHandler() *PaymentHandler {
+	return &PaymentHandler{
+		paymentService: &service.PaymentService{},
+	}
+}
+
+// SetPaymentSchedule 主机厂设置订单付款计划
+func (h *PaymentHandler) SetPaymentSchedule(c *gin.Context) {
+	id := c.Param("id")
+	var req struct {
+		Milestones []service.PaymentMilestone `json:"milestones"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil || len(req.Milestones) == 0 {
+		utils.BadRequest(c, "参数错误")
+		return
+	}
+
+	if err := h.paymentService.SetPaymentSchedule(id, req.Milestones); err != nil {
+		log.Printf("SetPaymentSchedule Error: %v", err)
+		utils.ServerError(c, err.Error())
+		return
+	}
+	utils.SuccessWithMessage(c, "付款计划已设置", nil)
+}
+
+// RecordPayment 主机厂登记付款
+func (h *PaymentHandler) RecordPayment(c *gin.Context) {
+	id := c.Param("id")
+	var req struct {
+		Reference string  `json:"reference"`
+		Amount    float64 `json:"amount"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil || req.Reference == "" || req.Amount <= 0 {
+		utils.BadRequest(c, "参数错误")
+		return
+	}
+
+	if err := h.paymentService.RecordPayment(id, req.Reference, req.Amount); err != nil {
+		log.Printf("RecordPayment Error: %v", err)
+		utils.ServerError(c, err.Error())
+		return
+	}
+	utils.SuccessWithMessage(c, "付款已登记", nil)
+}
+
+// ConfirmPaymentReceipt 厂商确认付款到账
+func (h *PaymentHandler) ConfirmPaymentReceipt(c *gin.Context) {
+	id := c.Param("id")
+	reference := c.Param("reference")
+	if err := h.paymentService.ConfirmPaymentReceipt(id, reference); err != nil {
+		log.Printf("ConfirmPaymentReceipt Error: %v", err)
+		utils.ServerError(c, err.Error())
+		return
+	}
+	utils.SuccessWithMessage(c, "已确认到账", nil)
+}
+
+// QueryOrderBalance 查询订单结算余额 (主机厂)
+func (h *PaymentHandler) QueryOrderBalance(c *gin.Context) {
+	h.queryOrderBalance(c, service.OEM_ORG)
+}
+
+// QueryOrderBalanceForManufacturer 查询订单结算余额 (厂商)
+func (h *PaymentHandler) QueryOrderBalanceForManufacturer(c *gin.Context) {
+	h.queryOrderBalance(c, service.MANUFACTURER_ORG)
+}
+
+func (h *PaymentHandler) queryOrderBalance(c *gin.Context, orgName string) {
+	id := c.Param("id")
+	balance, err := h.paymentService.QueryOrderBalance(orgName, id)
+	if err != nil {
+		utils.ServerError(c, err.Error())
+		return
+	}
+	utils.Success(c, balance)
+}
+
+// QueryCounterpartyBalance 查询与交易对手的结算汇总 (主机厂)
+func (h *PaymentHandler) QueryCounterpartyBalance(c *gin.Context) {
+	h.queryCounterpartyBalance(c, service.OEM_ORG)
+}
+
+// QueryCounterpartyBalanceForManufacturer 查询与交易对手的结算汇总 (厂商)
+func (h *PaymentHandler) QueryCounterpartyBalanceForManufacturer(c *gin.Context) {
+	h.queryCounterpartyBalance(c, service.MANUFACTURER_ORG)
+}
+
+func (h *PaymentHandler) queryCounterpartyBalance(c *gin.Context, orgName string) {
+	counterpartyId := c.Param("counterpartyId")
+	balance, err := h.paymentService.QueryCounterpartyBalance(orgName, counterpartyId)
+	if err != nil {
+		utils.ServerError(c, err.Error())
+		return
+	}
+	utils.Success(c, balance)
+}
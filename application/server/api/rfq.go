@@ -0,0 +1,157 @@
+package api
+
+import (
+	"application/service"
+	"application/utils"
+	"log"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type RFQHandler struct {
+	rfqService *service.RFQService
+}
+
+func NewRFQHandler() *RFQHandler {
+	return &RFQHandler{
+		rfqService: &service.RFQService{},
+	}
+}
+
+// CreateRFQ 主机厂发布询价单
+func (h *RFQHandler) CreateRFQ(c *gin.Context) {
+	var req struct {
+		ID            string            `json:"id"`
+		Title         string            `json:"title"`
+		Items         []service.RFQItem `json:"items"`
+		Manufacturers []string          `json:"manufacturers"`
+		Deadline      time.Time         `json:"deadline"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequest(c, "无效的请求参数")
+		return
+	}
+
+	if err := h.rfqService.CreateRFQ(req.ID, req.Title, req.Items, req.Manufacturers, req.Deadline); err != nil {
+		log.Printf("CreateRFQ Error: %v", err)
+		utils.ServerError(c, err.Error())
+		return
+	}
+	utils.SuccessWithMessage(c, "询价单已发布", nil)
+}
+
+// SubmitBid 零部件厂提交密封标
+func (h *RFQHandler) SubmitBid(c *gin.Context) {
+	rfqId := c.Param("id")
+	var req struct {
+		ManufacturerID string    `json:"manufacturerId"`
+		ItemPrices     []float64 `json:"itemPrices"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequest(c, "参数错误")
+		return
+	}
+
+	result, err := h.rfqService.SubmitBid(rfqId, req.ManufacturerID, req.ItemPrices)
+	if err != nil {
+		log.Printf("SubmitBid Error: %v", err)
+		utils.ServerError(c, err.Error())
+		return
+	}
+	utils.SuccessWithMessage(c, "密封标已提交，请妥善保存随机盐用于揭标", result)
+}
+
+// RevealBid 零部件厂揭标
+func (h *RFQHandler) RevealBid(c *gin.Context) {
+	rfqId := c.Param("id")
+	var req struct {
+		ManufacturerID string    `json:"manufacturerId"`
+		ItemPrices     []float64 `json:"itemPrices"`
+		Salt           string    `json:"salt"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequest(c, "参数错误")
+		return
+	}
+
+	if err := h.rfqService.RevealBid(rfqId, req.ManufacturerID, req.ItemPrices, req.Salt); err != nil {
+		log.Printf("RevealBid Error: %v", err)
+		utils.ServerError(c, err.Error())
+		return
+	}
+	utils.SuccessWithMessage(c, "已揭标", nil)
+}
+
+// AwardRFQ 主机厂定标
+func (h *RFQHandler) AwardRFQ(c *gin.Context) {
+	rfqId := c.Param("id")
+	var req struct {
+		ManufacturerID string `json:"manufacturerId"`
+		OrderID        string `json:"orderId"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequest(c, "参数错误")
+		return
+	}
+
+	if err := h.rfqService.AwardRFQ(rfqId, req.ManufacturerID, req.OrderID); err != nil {
+		log.Printf("AwardRFQ Error: %v", err)
+		utils.ServerError(c, err.Error())
+		return
+	}
+	utils.SuccessWithMessage(c, "已定标并生成订单", nil)
+}
+
+// CancelRFQ 主机厂取消询价
+func (h *RFQHandler) CancelRFQ(c *gin.Context) {
+	rfqId := c.Param("id")
+	if err := h.rfqService.CancelRFQ(rfqId); err != nil {
+		utils.ServerError(c, err.Error())
+		return
+	}
+	utils.SuccessWithMessage(c, "询价已取消", nil)
+}
+
+// QueryRFQ 查询询价单 (主机厂)
+func (h *RFQHandler) QueryRFQ(c *gin.Context) {
+	h.queryRFQ(c, service.OEM_ORG)
+}
+
+// QueryRFQForManufacturer 查询询价单 (零部件厂商)
+func (h *RFQHandler) QueryRFQForManufacturer(c *gin.Context) {
+	h.queryRFQ(c, service.MANUFACTURER_ORG)
+}
+
+func (h *RFQHandler) queryRFQ(c *gin.Context, orgName string) {
+	id := c.Param("id")
+	rfq, err := h.rfqService.QueryRFQ(orgName, id)
+	if err != nil {
+		utils.ServerError(c, err.Error())
+		return
+	}
+	utils.Success(c, rfq)
+}
+
+// QueryBids 查询投标列表
+func (h *RFQHandler) QueryBids(c *gin.Context) {
+	id := c.Param("id")
+	bids, err := h.rfqService.QueryBids(service.OEM_ORG, id)
+	if err != nil {
+		utils.ServerError(c, err.Error())
+		return
+	}
+	utils.Success(c, bids)
+}
+
+// QueryBidContent 查询已揭标的投标内容
+func (h *RFQHandler) QueryBidContent(c *gin.Context) {
+	id := c.Param("id")
+	manufacturerId := c.Param("manufacturerId")
+	content, err := h.rfqService.QueryBidContent(service.OEM_ORG, id, manufacturerId)
+	if err != nil {
+		utils.ServerError(c, err.Error())
+		return
+	}
+	utils.Success(c, content)
+}
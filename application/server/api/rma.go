@@ -0,0 +1,172 @@
+package api
+
+import (
+	"application/service"
+	"application/utils"
+	"log"
+
+	"github.com/gin-gonic/gin"
+)
+
+type RMAHandler struct {
+	rmaService *service.RMAService
+}
+
+func NewRMAHandler() *RMAHandler {
+	return &RMAHandler{
+		rmaService: &service.RMAService{},
+	}
+}
+
+// OpenRMA 主机厂发起退货
+func (h *RMAHandler) OpenRMA(c *gin.Context) {
+	var req struct {
+		ID      string            `json:"id"`
+		OrderID string            `json:"orderId"`
+		Lines   []service.RMALine `json:"lines"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil || req.ID == "" || req.OrderID == "" || len(req.Lines) == 0 {
+		utils.BadRequest(c, "参数错误")
+		return
+	}
+
+	if err := h.rmaService.OpenRMA(req.ID, req.OrderID, req.Lines); err != nil {
+		log.Printf("OpenRMA Error: %v", err)
+		utils.ServerError(c, err.Error())
+		return
+	}
+	utils.SuccessWithMessage(c, "退货单已发起, 待厂商审批", gin.H{"id": req.ID})
+}
+
+// ApproveRMA 厂商批准退货
+func (h *RMAHandler) ApproveRMA(c *gin.Context) {
+	id := c.Param("id")
+	var req struct {
+		Resolution string `json:"resolution"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil || req.Resolution == "" {
+		utils.BadRequest(c, "参数错误")
+		return
+	}
+
+	if err := h.rmaService.ApproveRMA(id, req.Resolution); err != nil {
+		log.Printf("ApproveRMA Error: %v", err)
+		utils.ServerError(c, err.Error())
+		return
+	}
+	utils.SuccessWithMessage(c, "退货已批准", nil)
+}
+
+// RejectRMA 厂商拒绝退货
+func (h *RMAHandler) RejectRMA(c *gin.Context) {
+	id := c.Param("id")
+	var req struct {
+		Reason string `json:"reason"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil || req.Reason == "" {
+		utils.BadRequest(c, "参数错误")
+		return
+	}
+
+	if err := h.rmaService.RejectRMA(id, req.Reason); err != nil {
+		log.Printf("RejectRMA Error: %v", err)
+		utils.ServerError(c, err.Error())
+		return
+	}
+	utils.SuccessWithMessage(c, "退货已拒绝", nil)
+}
+
+// PickupReturn 承运商取件承运退货
+func (h *RMAHandler) PickupReturn(c *gin.Context) {
+	id := c.Param("id")
+	var req struct {
+		ShipmentID string `json:"shipmentId"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil || req.ShipmentID == "" {
+		utils.BadRequest(c, "参数错误")
+		return
+	}
+
+	if err := h.rmaService.PickupReturn(carrierOrg(c), id, req.ShipmentID); err != nil {
+		log.Printf("PickupReturn Error: %v", err)
+		utils.ServerError(c, err.Error())
+		return
+	}
+	utils.SuccessWithMessage(c, "退货已取件", gin.H{"shipmentId": req.ShipmentID})
+}
+
+// ConfirmReturnReceipt 厂商签收退货
+func (h *RMAHandler) ConfirmReturnReceipt(c *gin.Context) {
+	id := c.Param("id")
+	if err := h.rmaService.ConfirmReturnReceipt(id); err != nil {
+		log.Printf("ConfirmReturnReceipt Error: %v", err)
+		utils.ServerError(c, err.Error())
+		return
+	}
+	utils.SuccessWithMessage(c, "退货已签收", nil)
+}
+
+// QueryRMA 查询退货单 (主机厂)
+func (h *RMAHandler) QueryRMA(c *gin.Context) {
+	h.queryRMA(c, service.OEM_ORG)
+}
+
+// QueryRMAForManufacturer 查询退货单 (厂商)
+func (h *RMAHandler) QueryRMAForManufacturer(c *gin.Context) {
+	h.queryRMA(c, service.MANUFACTURER_ORG)
+}
+
+// QueryRMAForCarrier 查询退货单 (承运商)
+func (h *RMAHandler) QueryRMAForCarrier(c *gin.Context) {
+	h.queryRMA(c, service.CARRIER_ORG)
+}
+
+func (h *RMAHandler) queryRMA(c *gin.Context, orgName string) {
+	id := c.Param("id")
+	rma, err := h.rmaService.QueryRMA(orgName, id)
+	if err != nil {
+		utils.ServerError(c, err.Error())
+		return
+	}
+	utils.Success(c, rma)
+}
+
+// QueryOrderRMAs 查询订单的全部退货单 (主机厂)
+func (h *RMAHandler) QueryOrderRMAs(c *gin.Context) {
+	h.queryOrderRMAs(c, service.OEM_ORG)
+}
+
+// QueryOrderRMAsForManufacturer 查询订单的全部退货单 (厂商)
+func (h *RMAHandler) QueryOrderRMAsForManufacturer(c *gin.Context) {
+	h.queryOrderRMAs(c, service.MANUFACTURER_ORG)
+}
+
+func (h *RMAHandler) queryOrderRMAs(c *gin.Context, orgName string) {
+	id := c.Param("id")
+	rmas, err := h.rmaService.QueryOrderRMAs(orgName, id)
+	if err != nil {
+		utils.ServerError(c, err.Error())
+		return
+	}
+	utils.Success(c, rmas)
+}
+
+// QueryCreditNote 查询贷项通知单 (主机厂)
+func (h *RMAHandler) QueryCreditNote(c *gin.Context) {
+	h.queryCreditNote(c, service.OEM_ORG)
+}
+
+// QueryCreditNoteForManufacturer 查询贷项通知单 (厂商)
+func (h *RMAHandler) QueryCreditNoteForManufacturer(c *gin.Context) {
+	h.queryCreditNote(c, service.MANUFACTURER_ORG)
+}
+
+func (h *RMAHandler) queryCreditNote(c *gin.Context, orgName string) {
+	id := c.Param("id")
+	note, err := h.rmaService.QueryCreditNote(orgName, id)
+	if err != nil {
+		utils.ServerError(c, err.Error())
+		return
+	}
+	utils.Success(c, note)
+}
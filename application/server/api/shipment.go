@@ -0,0 +1,244 @@
+package api
+
+import (
+	"application/service"
+	"application/utils"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ShipmentHandler struct {
+	shipmentService *service.ShipmentService
+}
+
+func NewShipmentHandler() *ShipmentHandler {
+	return &ShipmentHandler{
+		shipmentService: &service.ShipmentService{},
+	}
+}
+
+// PlanShipmentLegs 规划多段运输
+func (h *ShipmentHandler) PlanShipmentLegs(c *gin.Context) {
+	id := c.Param("id")
+	var req struct {
+		Legs []service.ShipmentLeg `json:"legs"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil || len(req.Legs) == 0 {
+		utils.BadRequest(c, "参数错误")
+		return
+	}
+
+	if err := h.shipmentService.PlanShipmentLegs(carrierOrg(c), id, req.Legs); err != nil {
+		log.Printf("PlanShipmentLegs Error: %v", err)
+		utils.ServerError(c, err.Error())
+		return
+	}
+	utils.SuccessWithMessage(c, "运输段已规划", nil)
+}
+
+// HandoverCustody 发起保管交接
+func (h *ShipmentHandler) HandoverCustody(c *gin.Context) {
+	id := c.Param("id")
+	var req struct {
+		Location string `json:"location"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequest(c, "参数错误")
+		return
+	}
+
+	if err := h.shipmentService.HandoverCustody(carrierOrg(c), id, req.Location); err != nil {
+		log.Printf("HandoverCustody Error: %v", err)
+		utils.ServerError(c, err.Error())
+		return
+	}
+	utils.SuccessWithMessage(c, "交接已发起, 等待接收承运商确认", nil)
+}
+
+// AcceptCustody 确认保管交接
+func (h *ShipmentHandler) AcceptCustody(c *gin.Context) {
+	id := c.Param("id")
+	if err := h.shipmentService.AcceptCustody(carrierOrg(c), id); err != nil {
+		log.Printf("AcceptCustody Error: %v", err)
+		utils.ServerError(c, err.Error())
+		return
+	}
+	utils.SuccessWithMessage(c, "已确认接收货物", nil)
+}
+
+// RejectCustody 拒绝保管交接
+func (h *ShipmentHandler) RejectCustody(c *gin.Context) {
+	id := c.Param("id")
+	if err := h.shipmentService.RejectCustody(carrierOrg(c), id); err != nil {
+		log.Printf("RejectCustody Error: %v", err)
+		utils.ServerError(c, err.Error())
+		return
+	}
+	utils.SuccessWithMessage(c, "已拒绝交接", nil)
+}
+
+// QueryCustodyAt 查询指定时刻的货物保管方 (承运商)
+func (h *ShipmentHandler) QueryCustodyAt(c *gin.Context) {
+	h.queryCustodyAt(c, service.CARRIER_ORG)
+}
+
+// QueryCustodyAtForOEM 查询指定时刻的货物保管方 (主机厂)
+func (h *ShipmentHandler) QueryCustodyAtForOEM(c *gin.Context) {
+	h.queryCustodyAt(c, service.OEM_ORG)
+}
+
+func (h *ShipmentHandler) queryCustodyAt(c *gin.Context, orgName string) {
+	id := c.Param("id")
+	at, err := time.Parse(time.RFC3339, c.Query("at"))
+	if err != nil {
+		utils.BadRequest(c, "时间格式错误, 应为 RFC3339")
+		return
+	}
+
+	record, err := h.shipmentService.QueryCustodyAt(orgName, id, at)
+	if err != nil {
+		utils.ServerError(c, err.Error())
+		return
+	}
+	utils.Success(c, record)
+}
+
+// RecordCheckpoint 记录轨迹检查点
+func (h *ShipmentHandler) RecordCheckpoint(c *gin.Context) {
+	id := c.Param("id")
+	var req service.Checkpoint
+	if err := c.ShouldBindJSON(&req); err != nil || req.EventType == "" {
+		utils.BadRequest(c, "参数错误")
+		return
+	}
+
+	if err := h.shipmentService.RecordCheckpoint(carrierOrg(c), id, req); err != nil {
+		log.Printf("RecordCheckpoint Error: %v", err)
+		utils.ServerError(c, err.Error())
+		return
+	}
+	utils.SuccessWithMessage(c, "轨迹已记录", nil)
+}
+
+// QueryShipmentTrack 查询物流轨迹 (承运商)
+func (h *ShipmentHandler) QueryShipmentTrack(c *gin.Context) {
+	h.queryShipmentTrack(c, service.CARRIER_ORG)
+}
+
+// QueryShipmentTrackForOEM 查询物流轨迹 (主机厂)
+func (h *ShipmentHandler) QueryShipmentTrackForOEM(c *gin.Context) {
+	h.queryShipmentTrack(c, service.OEM_ORG)
+}
+
+// QueryShipmentTrackForPlatform 查询物流轨迹 (平台方)
+func (h *ShipmentHandler) QueryShipmentTrackForPlatform(c *gin.Context) {
+	h.queryShipmentTrack(c, service.PLATFORM_ORG)
+}
+
+func (h *ShipmentHandler) queryShipmentTrack(c *gin.Context, orgName string) {
+	id := c.Param("id")
+	track, err := h.shipmentService.QueryShipmentTrack(orgName, id)
+	if err != nil {
+		utils.ServerError(c, err.Error())
+		return
+	}
+	utils.Success(c, track)
+}
+
+// ReportException 报告物流异常
+func (h *ShipmentHandler) ReportException(c *gin.Context) {
+	id := c.Param("id")
+	var req service.ShipmentException
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequest(c, "参数错误")
+		return
+	}
+
+	if err := h.shipmentService.ReportException(carrierOrg(c), id, req); err != nil {
+		log.Printf("ReportException Error: %v", err)
+		utils.ServerError(c, err.Error())
+		return
+	}
+	utils.SuccessWithMessage(c, "异常已报告", nil)
+}
+
+// ResolveException 处理物流异常 (主机厂)
+func (h *ShipmentHandler) ResolveException(c *gin.Context) {
+	h.resolveException(c, service.OEM_ORG)
+}
+
+// ResolveExceptionForPlatform 处理物流异常 (平台方)
+func (h *ShipmentHandler) ResolveExceptionForPlatform(c *gin.Context) {
+	h.resolveException(c, service.PLATFORM_ORG)
+}
+
+func (h *ShipmentHandler) resolveException(c *gin.Context, orgName string) {
+	id := c.Param("id")
+	seq, err := strconv.Atoi(c.Param("seq"))
+	if err != nil {
+		utils.BadRequest(c, "异常序号错误")
+		return
+	}
+	var req struct {
+		Resolution string `json:"resolution"`
+		Note       string `json:"note"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequest(c, "参数错误")
+		return
+	}
+
+	if err := h.shipmentService.ResolveException(orgName, id, seq, req.Resolution, req.Note); err != nil {
+		log.Printf("ResolveException Error: %v", err)
+		utils.ServerError(c, err.Error())
+		return
+	}
+	utils.SuccessWithMessage(c, "异常已处理", nil)
+}
+
+// QueryShipmentExceptions 查询物流单异常 (承运商)
+func (h *ShipmentHandler) QueryShipmentExceptions(c *gin.Context) {
+	h.queryShipmentExceptions(c, service.CARRIER_ORG)
+}
+
+// QueryShipmentExceptionsForOEM 查询物流单异常 (主机厂)
+func (h *ShipmentHandler) QueryShipmentExceptionsForOEM(c *gin.Context) {
+	h.queryShipmentExceptions(c, service.OEM_ORG)
+}
+
+// QueryShipmentExceptionsForPlatform 查询物流单异常 (平台方)
+func (h *ShipmentHandler) QueryShipmentExceptionsForPlatform(c *gin.Context) {
+	h.queryShipmentExceptions(c, service.PLATFORM_ORG)
+}
+
+func (h *ShipmentHandler) queryShipmentExceptions(c *gin.Context, orgName string) {
+	id := c.Param("id")
+	exceptions, err := h.shipmentService.QueryShipmentExceptions(orgName, id)
+	if err != nil {
+		utils.ServerError(c, err.Error())
+		return
+	}
+	utils.Success(c, exceptions)
+}
+
+// QueryOpenExceptions 查询待处理异常 (主机厂)
+func (h *ShipmentHandler) QueryOpenExceptions(c *gin.Context) {
+	h.queryOpenExceptions(c, service.OEM_ORG)
+}
+
+// QueryOpenExceptionsForPlatform 查询待处理异常 (平台方)
+func (h *ShipmentHandler) QueryOpenExceptionsForPlatform(c *gin.Context) {
+	h.queryOpenExceptions(c, service.PLATFORM_ORG)
+}
+
+func (h *ShipmentHandler) queryOpenExceptions(c *gin.Context, orgName string) {
+	exceptions, err := h.shipmentService.QueryOpenExceptions(orgName)
+	if err != nil {
+		utils.ServerError(c, err.Error())
+		return
+	}
+	utils.Success(c, exceptions)
+}
@@ -21,9 +21,9 @@ func NewSupplyChainHandler() *SupplyChainHandler {
 // CreateOrder 主机厂发布订单
 func (h *SupplyChainHandler) CreateOrder(c *gin.Context) {
 	var req struct {
-		ID             string      `json:"id"`
-		ManufacturerID string      `json:"manufacturerId"`
-		Items          interface{} `json:"items"`
+		ID             string              `json:"id"`
+		ManufacturerID string              `json:"manufacturerId"`
+		Items          []service.OrderItem `json:"items"`
 	}
 	if err := c.ShouldBindJSON(&req); err != nil {
 		utils.BadRequest(c, "无效的请求参数")
@@ -82,7 +82,7 @@ func (h *SupplyChainHandler) PickupGoods(c *gin.Context) {
 		return
 	}
 
-	if err := h.scService.PickupGoods(req.OrderID, req.ShipmentID); err != nil {
+	if err := h.scService.PickupGoods(carrierOrg(c), req.OrderID, req.ShipmentID); err != nil {
 		utils.ServerError(c, err.Error())
 		return
 	}
@@ -93,24 +93,35 @@ func (h *SupplyChainHandler) PickupGoods(c *gin.Context) {
 func (h *SupplyChainHandler) UpdateLocation(c *gin.Context) {
 	id := c.Param("id") // shipmentId
 	var req struct {
-		Location string `json:"location"`
+		Location  string   `json:"location"`
+		Latitude  *float64 `json:"latitude"`
+		Longitude *float64 `json:"longitude"`
 	}
 	if err := c.ShouldBindJSON(&req); err != nil {
 		utils.BadRequest(c, "参数错误")
 		return
 	}
 
-	if err := h.scService.UpdateLocation(id, req.Location); err != nil {
+	if err := h.scService.UpdateLocation(carrierOrg(c), id, req.Location, req.Latitude, req.Longitude); err != nil {
 		utils.ServerError(c, err.Error())
 		return
 	}
 	utils.SuccessWithMessage(c, "位置已更新", nil)
 }
 
-// ConfirmReceipt 主机厂签收
+// ConfirmReceipt 主机厂签收, 请求体可选携带每个零件的实收数量
 func (h *SupplyChainHandler) ConfirmReceipt(c *gin.Context) {
 	id := c.Param("id")
-	if err := h.scService.ConfirmReceipt(id); err != nil {
+	var req struct {
+		ReceivedQuantities []int `json:"receivedQuantities"`
+	}
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			utils.BadRequest(c, "参数错误")
+			return
+		}
+	}
+	if err := h.scService.ConfirmReceipt(id, req.ReceivedQuantities); err != nil {
 		utils.ServerError(c, err.Error())
 		return
 	}
@@ -139,6 +150,94 @@ func (h *SupplyChainHandler) QueryOrder(c *gin.Context) {
 	utils.Success(c, order)
 }
 
+// QueryOrderPrice 查询订单价格 (主机厂)
+func (h *SupplyChainHandler) QueryOrderPrice(c *gin.Context) {
+	h.queryOrderPrice(c, service.OEM_ORG)
+}
+
+// QueryOrderPriceForManufacturer 查询订单价格 (零部件厂商)
+func (h *SupplyChainHandler) QueryOrderPriceForManufacturer(c *gin.Context) {
+	h.queryOrderPrice(c, service.MANUFACTURER_ORG)
+}
+
+func (h *SupplyChainHandler) queryOrderPrice(c *gin.Context, orgName string) {
+	id := c.Param("id")
+	price, err := h.scService.QueryOrderPrice(orgName, id)
+	if err != nil {
+		utils.ServerError(c, err.Error())
+		return
+	}
+	utils.Success(c, price)
+}
+
+// VerifyOrderPrice 平台方校验订单价格哈希
+func (h *SupplyChainHandler) VerifyOrderPrice(c *gin.Context) {
+	id := c.Param("id")
+	matched, err := h.scService.VerifyOrderPrice(id)
+	if err != nil {
+		utils.ServerError(c, err.Error())
+		return
+	}
+	utils.Success(c, gin.H{"orderId": id, "matched": matched})
+}
+
+// CreateSubOrder 一级供应商下达子订单
+func (h *SupplyChainHandler) CreateSubOrder(c *gin.Context) {
+	parentId := c.Param("id")
+	var req struct {
+		ID          string              `json:"id"`
+		SupplierID  string              `json:"supplierId"`
+		Items       []service.OrderItem `json:"items"`
+		BlockParent bool                `json:"blockParent"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequest(c, "无效的请求参数")
+		return
+	}
+
+	if err := h.scService.CreateSubOrder(parentId, req.ID, req.SupplierID, req.Items, req.BlockParent); err != nil {
+		log.Printf("CreateSubOrder Error: %v", err)
+		utils.ServerError(c, err.Error())
+		return
+	}
+	utils.SuccessWithMessage(c, "子订单已下达", nil)
+}
+
+// ConfirmSubOrderReceipt 一级供应商签收子订单
+func (h *SupplyChainHandler) ConfirmSubOrderReceipt(c *gin.Context) {
+	id := c.Param("id")
+	if err := h.scService.ConfirmSubOrderReceipt(id); err != nil {
+		utils.ServerError(c, err.Error())
+		return
+	}
+	utils.SuccessWithMessage(c, "子订单已签收", nil)
+}
+
+// QueryOrderTree 查询订单履约树 (主机厂)
+func (h *SupplyChainHandler) QueryOrderTree(c *gin.Context) {
+	h.queryOrderTree(c, service.OEM_ORG)
+}
+
+// QueryOrderTreeForManufacturer 查询订单履约树 (零部件厂商)
+func (h *SupplyChainHandler) QueryOrderTreeForManufacturer(c *gin.Context) {
+	h.queryOrderTree(c, service.MANUFACTURER_ORG)
+}
+
+// QueryOrderTreeForPlatform 查询订单履约树 (平台方)
+func (h *SupplyChainHandler) QueryOrderTreeForPlatform(c *gin.Context) {
+	h.queryOrderTree(c, service.PLATFORM_ORG)
+}
+
+func (h *SupplyChainHandler) queryOrderTree(c *gin.Context, orgName string) {
+	id := c.Param("id")
+	tree, err := h.scService.QueryOrderTree(orgName, id)
+	if err != nil {
+		utils.ServerError(c, err.Error())
+		return
+	}
+	utils.Success(c, tree)
+}
+
 // QueryOrderList 分页列表
 func (h *SupplyChainHandler) QueryOrderList(c *gin.Context) {
 	pageSize, _ := strconv.Atoi(c.DefaultQuery("pageSize", "10"))
@@ -0,0 +1,135 @@
+package api
+
+import (
+	"application/pkg/telemetry"
+	"application/service"
+	"application/utils"
+	"log"
+
+	"github.com/gin-gonic/gin"
+)
+
+type TelemetryHandler struct {
+	telemetryService *service.TelemetryService
+}
+
+func NewTelemetryHandler() *TelemetryHandler {
+	return &TelemetryHandler{
+		telemetryService: &service.TelemetryService{},
+	}
+}
+
+// SetSensorThresholds 设置传感器阈值 (主机厂)
+func (h *TelemetryHandler) SetSensorThresholds(c *gin.Context) {
+	h.setSensorThresholds(c, service.OEM_ORG)
+}
+
+// SetSensorThresholdsForManufacturer 设置传感器阈值 (零部件厂商)
+func (h *TelemetryHandler) SetSensorThresholdsForManufacturer(c *gin.Context) {
+	h.setSensorThresholds(c, service.MANUFACTURER_ORG)
+}
+
+func (h *TelemetryHandler) setSensorThresholds(c *gin.Context, orgName string) {
+	id := c.Param("id")
+	var req service.SensorThresholds
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequest(c, "参数错误")
+		return
+	}
+
+	if err := h.telemetryService.SetSensorThresholds(orgName, id, req); err != nil {
+		log.Printf("SetSensorThresholds Error: %v", err)
+		utils.ServerError(c, err.Error())
+		return
+	}
+	utils.SuccessWithMessage(c, "传感器阈值已设置", nil)
+}
+
+// IngestReadings 批量接收传感器读数
+func (h *TelemetryHandler) IngestReadings(c *gin.Context) {
+	id := c.Param("id")
+	var req struct {
+		Readings []telemetry.Reading `json:"readings"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil || len(req.Readings) == 0 {
+		utils.BadRequest(c, "参数错误")
+		return
+	}
+
+	accepted, duplicates, err := h.telemetryService.IngestReadings(id, req.Readings)
+	if err != nil {
+		log.Printf("IngestReadings Error: %v", err)
+		utils.ServerError(c, err.Error())
+		return
+	}
+	utils.Success(c, gin.H{
+		"accepted":   accepted,
+		"duplicates": duplicates,
+	})
+}
+
+// AnchorTelemetry 立即锚定未上链的读数 (平台方)
+func (h *TelemetryHandler) AnchorTelemetry(c *gin.Context) {
+	if err := h.telemetryService.AnchorPending(); err != nil {
+		log.Printf("AnchorTelemetry Error: %v", err)
+		utils.ServerError(c, err.Error())
+		return
+	}
+	utils.SuccessWithMessage(c, "遥测数据已锚定", nil)
+}
+
+// QueryReadings 查询链下读数
+func (h *TelemetryHandler) QueryReadings(c *gin.Context) {
+	id := c.Param("id")
+	readings, err := h.telemetryService.QueryReadings(id)
+	if err != nil {
+		utils.ServerError(c, err.Error())
+		return
+	}
+	utils.Success(c, readings)
+}
+
+// QueryExcursions 查询超限记录 (主机厂)
+func (h *TelemetryHandler) QueryExcursions(c *gin.Context) {
+	h.queryExcursions(c, service.OEM_ORG)
+}
+
+// QueryExcursionsForCarrier 查询超限记录 (承运商)
+func (h *TelemetryHandler) QueryExcursionsForCarrier(c *gin.Context) {
+	h.queryExcursions(c, service.CARRIER_ORG)
+}
+
+// QueryExcursionsForPlatform 查询超限记录 (平台方)
+func (h *TelemetryHandler) QueryExcursionsForPlatform(c *gin.Context) {
+	h.queryExcursions(c, service.PLATFORM_ORG)
+}
+
+func (h *TelemetryHandler) queryExcursions(c *gin.Context, orgName string) {
+	id := c.Param("id")
+	excursions, err := h.telemetryService.QueryExcursions(orgName, id)
+	if err != nil {
+		utils.ServerError(c, err.Error())
+		return
+	}
+	utils.Success(c, excursions)
+}
+
+// VerifyTelemetry 校验链下读数与链上默克尔根 (主机厂)
+func (h *TelemetryHandler) VerifyTelemetry(c *gin.Context) {
+	h.verifyTelemetry(c, service.OEM_ORG)
+}
+
+// VerifyTelemetryForPlatform 校验链下读数与链上默克尔根 (平台方)
+func (h *TelemetryHandler) VerifyTelemetryForPlatform(c *gin.Context) {
+	h.verifyTelemetry(c, service.PLATFORM_ORG)
+}
+
+func (h *TelemetryHandler) verifyTelemetry(c *gin.Context, orgName string) {
+	id := c.Param("id")
+	result, err := h.telemetryService.VerifyTelemetry(orgName, id)
+	if err != nil {
+		utils.ServerError(c, err.Error())
+		return
+	}
+	utils.Success(c, result)
+}
@@ -0,0 +1,119 @@
+package api
+
+import (
+	"application/service"
+	"application/utils"
+	"log"
+
+	"github.com/gin-gonic/gin"
+)
+
+type TraceHandler struct {
+	traceService *service.TraceService
+}
+
+func NewTraceHandler() *TraceHandler {
+	return &TraceHandler{
+		traceService: &service.TraceService{},
+	}
+}
+
+// CompleteProduction 零部件厂登记生产批次并完成生产
+func (h *TraceHandler) CompleteProduction(c *gin.Context) {
+	id := c.Param("id")
+	var req struct {
+		Batches [][]service.ItemBatch `json:"batches"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequest(c, "参数错误")
+		return
+	}
+
+	if err := h.traceService.CompleteProduction(id, req.Batches); err != nil {
+		log.Printf("CompleteProduction Error: %v", err)
+		utils.ServerError(c, err.Error())
+		return
+	}
+	utils.SuccessWithMessage(c, "生产批次已登记", nil)
+}
+
+// TraceBatch 批次追溯 (主机厂)
+func (h *TraceHandler) TraceBatch(c *gin.Context) {
+	h.traceBatch(c, service.OEM_ORG)
+}
+
+// TraceBatchForPlatform 批次追溯 (平台方)
+func (h *TraceHandler) TraceBatchForPlatform(c *gin.Context) {
+	h.traceBatch(c, service.PLATFORM_ORG)
+}
+
+func (h *TraceHandler) traceBatch(c *gin.Context, orgName string) {
+	batchNo := c.Param("batchNo")
+	trace, err := h.traceService.TraceBatch(orgName, batchNo)
+	if err != nil {
+		utils.ServerError(c, err.Error())
+		return
+	}
+	utils.Success(c, trace)
+}
+
+// TraceSerial 序列号追溯
+func (h *TraceHandler) TraceSerial(c *gin.Context) {
+	serialNo := c.Param("serialNo")
+	orders, err := h.traceService.TraceSerial(service.PLATFORM_ORG, serialNo)
+	if err != nil {
+		utils.ServerError(c, err.Error())
+		return
+	}
+	utils.Success(c, orders)
+}
+
+// IssueRecall 平台方发布召回
+func (h *TraceHandler) IssueRecall(c *gin.Context) {
+	var req struct {
+		ID       string   `json:"id"`
+		BatchNos []string `json:"batchNos"`
+		Reason   string   `json:"reason"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequest(c, "无效的请求参数")
+		return
+	}
+
+	if err := h.traceService.IssueRecall(req.ID, req.BatchNos, req.Reason); err != nil {
+		log.Printf("IssueRecall Error: %v", err)
+		utils.ServerError(c, err.Error())
+		return
+	}
+	utils.SuccessWithMessage(c, "召回已发布", nil)
+}
+
+// CloseRecall 平台方关闭召回
+func (h *TraceHandler) CloseRecall(c *gin.Context) {
+	id := c.Param("id")
+	if err := h.traceService.CloseRecall(id); err != nil {
+		utils.ServerError(c, err.Error())
+		return
+	}
+	utils.SuccessWithMessage(c, "召回已关闭", nil)
+}
+
+// QueryRecall 查询召回单 (主机厂)
+func (h *TraceHandler) QueryRecall(c *gin.Context) {
+	h.queryRecall(c, service.OEM_ORG)
+}
+
+// QueryRecallForPlatform 查询召回单 (平台方)
+func (h *TraceHandler) QueryRecallForPlatform(c *gin.Context) {
+	h.queryRecall(c, service.PLATFORM_ORG)
+}
+
+func (h *TraceHandler) queryRecall(c *gin.Context, orgName string) {
+	id := c.Param("id")
+	recall, err := h.traceService.QueryRecall(orgName, id)
+	if err != nil {
+		utils.ServerError(c, err.Error())
+		return
+	}
+	utils.Success(c, recall)
+}
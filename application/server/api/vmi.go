@@ -0,0 +1,193 @@
+package api
+
+import (
+	"application/service"
+	"application/utils"
+	"log"
+
+	"github.com/gin-gonic/gin"
+)
+
+type VMIHandler struct {
+	vmiService *service.VMIService
+}
+
+func NewVMIHandler() *VMIHandler {
+	return &VMIHandler{
+		vmiService: &service.VMIService{},
+	}
+}
+
+// CreateInventory 厂商在主机厂工厂开设寄售库存
+func (h *VMIHandler) CreateInventory(c *gin.Context) {
+	var req struct {
+		ManufacturerID string `json:"manufacturerId"`
+		PartNumber     string `json:"partNumber"`
+		Location       string `json:"location"`
+		MinLevel       int    `json:"minLevel"`
+		MaxLevel       int    `json:"maxLevel"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil || req.PartNumber == "" || req.Location == "" {
+		utils.BadRequest(c, "参数错误")
+		return
+	}
+
+	if err := h.vmiService.CreateInventory(req.ManufacturerID, req.PartNumber, req.Location, req.MinLevel, req.MaxLevel); err != nil {
+		log.Printf("CreateInventory Error: %v", err)
+		utils.ServerError(c, err.Error())
+		return
+	}
+	utils.SuccessWithMessage(c, "寄售库存已开设", nil)
+}
+
+// SetInventoryLevels 调整寄售库存水位 (主机厂)
+func (h *VMIHandler) SetInventoryLevels(c *gin.Context) {
+	h.setInventoryLevels(c, service.OEM_ORG)
+}
+
+// SetInventoryLevelsForManufacturer 调整本厂商寄售库存水位
+func (h *VMIHandler) SetInventoryLevelsForManufacturer(c *gin.Context) {
+	h.setInventoryLevels(c, service.MANUFACTURER_ORG)
+}
+
+func (h *VMIHandler) setInventoryLevels(c *gin.Context, orgName string) {
+	var req struct {
+		MinLevel int `json:"minLevel"`
+		MaxLevel int `json:"maxLevel"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequest(c, "参数错误")
+		return
+	}
+
+	if err := h.vmiService.SetInventoryLevels(orgName, c.Param("partNumber"), c.Param("location"), inventoryOwnerID(c), req.MinLevel, req.MaxLevel); err != nil {
+		log.Printf("SetInventoryLevels Error: %v", err)
+		utils.ServerError(c, err.Error())
+		return
+	}
+	utils.SuccessWithMessage(c, "库存水位已更新", nil)
+}
+
+// SetOrderConsignment 主机厂指定订单签收后入寄售库存
+func (h *VMIHandler) SetOrderConsignment(c *gin.Context) {
+	var req struct {
+		Location string `json:"location"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil || req.Location == "" {
+		utils.BadRequest(c, "参数错误")
+		return
+	}
+
+	if err := h.vmiService.SetOrderConsignment(c.Param("id"), req.Location); err != nil {
+		log.Printf("SetOrderConsignment Error: %v", err)
+		utils.ServerError(c, err.Error())
+		return
+	}
+	utils.SuccessWithMessage(c, "订单已指定寄售入库", nil)
+}
+
+// ReportConsumption 主机厂报告寄售库存消耗
+func (h *VMIHandler) ReportConsumption(c *gin.Context) {
+	var req struct {
+		ID         string `json:"id"`
+		PartNumber string `json:"partNumber"`
+		Location   string `json:"location"`
+		OwnerID    string `json:"ownerId"`
+		Quantity   int    `json:"quantity"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil || req.ID == "" || req.PartNumber == "" || req.Location == "" || req.OwnerID == "" || req.Quantity <= 0 {
+		utils.BadRequest(c, "参数错误")
+		return
+	}
+
+	if err := h.vmiService.ReportConsumption(req.ID, req.PartNumber, req.Location, req.OwnerID, req.Quantity); err != nil {
+		log.Printf("ReportConsumption Error: %v", err)
+		utils.ServerError(c, err.Error())
+		return
+	}
+	utils.SuccessWithMessage(c, "消耗已报告", gin.H{"id": req.ID})
+}
+
+// QueryInventory 查询寄售库存 (主机厂)
+func (h *VMIHandler) QueryInventory(c *gin.Context) {
+	h.queryInventory(c, service.OEM_ORG)
+}
+
+// QueryInventoryForManufacturer 查询本厂商寄售库存
+func (h *VMIHandler) QueryInventoryForManufacturer(c *gin.Context) {
+	h.queryInventory(c, service.MANUFACTURER_ORG)
+}
+
+func (h *VMIHandler) queryInventory(c *gin.Context, orgName string) {
+	inventory, err := h.vmiService.QueryInventory(orgName, c.Param("partNumber"), c.Param("location"), inventoryOwnerID(c))
+	if err != nil {
+		utils.ServerError(c, err.Error())
+		return
+	}
+	utils.Success(c, inventory)
+}
+
+// QueryLocationInventory 查询某地点的全部寄售库存 (主机厂)
+func (h *VMIHandler) QueryLocationInventory(c *gin.Context) {
+	h.queryLocationInventory(c, service.OEM_ORG)
+}
+
+// QueryLocationInventoryForManufacturer 查询某地点的全部寄售库存 (厂商)
+func (h *VMIHandler) QueryLocationInventoryForManufacturer(c *gin.Context) {
+	h.queryLocationInventory(c, service.MANUFACTURER_ORG)
+}
+
+func (h *VMIHandler) queryLocationInventory(c *gin.Context, orgName string) {
+	inventories, err := h.vmiService.QueryLocationInventory(orgName, c.Param("location"))
+	if err != nil {
+		utils.ServerError(c, err.Error())
+		return
+	}
+	utils.Success(c, inventories)
+}
+
+// QueryReplenishmentSuggestions 查询某地点的补货建议 (主机厂)
+func (h *VMIHandler) QueryReplenishmentSuggestions(c *gin.Context) {
+	h.queryReplenishmentSuggestions(c, service.OEM_ORG)
+}
+
+// QueryReplenishmentSuggestionsForManufacturer 查询某地点的补货建议 (厂商)
+func (h *VMIHandler) QueryReplenishmentSuggestionsForManufacturer(c *gin.Context) {
+	h.queryReplenishmentSuggestions(c, service.MANUFACTURER_ORG)
+}
+
+func (h *VMIHandler) queryReplenishmentSuggestions(c *gin.Context, orgName string) {
+	suggestions, err := h.vmiService.QueryReplenishmentSuggestions(orgName, c.Param("location"))
+	if err != nil {
+		utils.ServerError(c, err.Error())
+		return
+	}
+	utils.Success(c, suggestions)
+}
+
+// QueryConsumption 查询消耗报告 (主机厂)
+func (h *VMIHandler) QueryConsumption(c *gin.Context) {
+	h.queryConsumption(c, service.OEM_ORG)
+}
+
+// QueryConsumptionForManufacturer 查询消耗报告 (厂商)
+func (h *VMIHandler) QueryConsumptionForManufacturer(c *gin.Context) {
+	h.queryConsumption(c, service.MANUFACTURER_ORG)
+}
+
+func (h *VMIHandler) queryConsumption(c *gin.Context, orgName string) {
+	consumption, err := h.vmiService.QueryConsumption(orgName, c.Param("id"))
+	if err != nil {
+		utils.ServerError(c, err.Error())
+		return
+	}
+	utils.Success(c, consumption)
+}
+
+// inventoryOwnerID 库存货权方: 主机厂一侧取路径参数, 厂商一侧可通过 ?manufacturerId= 指定
+func inventoryOwnerID(c *gin.Context) string {
+	if ownerId := c.Param("ownerId"); ownerId != "" {
+		return ownerId
+	}
+	return c.Query("manufacturerId")
+}
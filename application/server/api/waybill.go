@@ -0,0 +1,153 @@
+package api
+
+import (
+	"application/service"
+	"application/utils"
+	"log"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type WaybillHandler struct {
+	waybillService *service.WaybillService
+}
+
+func NewWaybillHandler() *WaybillHandler {
+	return &WaybillHandler{
+		waybillService: &service.WaybillService{},
+	}
+}
+
+// CreateWaybill 核心企业签发数字运单
+func (h *WaybillHandler) CreateWaybill(c *gin.Context) {
+	var req service.Waybill
+	if err := c.ShouldBindJSON(&req); err != nil || req.ID == "" || req.OrderID == "" || req.CarrierID == "" {
+		utils.BadRequest(c, "参数错误")
+		return
+	}
+
+	if err := h.waybillService.CreateWaybill(req); err != nil {
+		log.Printf("CreateWaybill Error: %v", err)
+		utils.ServerError(c, err.Error())
+		return
+	}
+	utils.SuccessWithMessage(c, "运单已签发, 待承运方确认", gin.H{"id": req.ID})
+}
+
+// ConfirmWaybill 承运方确认运单
+func (h *WaybillHandler) ConfirmWaybill(c *gin.Context) {
+	var req struct {
+		ID string `json:"id"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil || req.ID == "" {
+		utils.BadRequest(c, "参数错误")
+		return
+	}
+
+	if err := h.waybillService.ConfirmWaybill(carrierOrg(c), req.ID); err != nil {
+		log.Printf("ConfirmWaybill Error: %v", err)
+		utils.ServerError(c, err.Error())
+		return
+	}
+	utils.SuccessWithMessage(c, "运单已生效", nil)
+}
+
+// TransferWaybill 转让运单 (核心企业)
+func (h *WaybillHandler) TransferWaybill(c *gin.Context) {
+	h.transferWaybill(c, service.OEM_ORG)
+}
+
+// TransferWaybillForManufacturer 转让运单 (厂商)
+func (h *WaybillHandler) TransferWaybillForManufacturer(c *gin.Context) {
+	h.transferWaybill(c, service.MANUFACTURER_ORG)
+}
+
+// TransferWaybillForBank 转让运单 (银行)
+func (h *WaybillHandler) TransferWaybillForBank(c *gin.Context) {
+	h.transferWaybill(c, bankOrg(c))
+}
+
+func (h *WaybillHandler) transferWaybill(c *gin.Context, orgName string) {
+	id := c.Param("id")
+	var req struct {
+		NewHolderID string `json:"newHolderId"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil || req.NewHolderID == "" {
+		utils.BadRequest(c, "参数错误")
+		return
+	}
+
+	if err := h.waybillService.TransferWaybill(orgName, id, req.NewHolderID); err != nil {
+		log.Printf("TransferWaybill Error: %v", err)
+		utils.ServerError(c, err.Error())
+		return
+	}
+	utils.SuccessWithMessage(c, "运单已转让", nil)
+}
+
+// QueryWaybill 查询运单 (核心企业)
+func (h *WaybillHandler) QueryWaybill(c *gin.Context) {
+	h.queryWaybill(c, service.OEM_ORG)
+}
+
+// QueryWaybillForCarrier 查询运单 (承运方)
+func (h *WaybillHandler) QueryWaybillForCarrier(c *gin.Context) {
+	h.queryWaybill(c, service.CARRIER_ORG)
+}
+
+func (h *WaybillHandler) queryWaybill(c *gin.Context, orgName string) {
+	id := c.Param("id")
+	waybill, err := h.waybillService.QueryWaybill(orgName, id)
+	if err != nil {
+		utils.ServerError(c, err.Error())
+		return
+	}
+	utils.Success(c, waybill)
+}
+
+// QueryWaybillList 分页查询运单列表 (核心企业)
+func (h *WaybillHandler) QueryWaybillList(c *gin.Context) {
+	h.queryWaybillList(c, service.OEM_ORG)
+}
+
+// QueryWaybillListForCarrier 分页查询运单列表 (承运方, 可按 status=PENDING 查看待确认运单)
+func (h *WaybillHandler) QueryWaybillListForCarrier(c *gin.Context) {
+	h.queryWaybillList(c, service.CARRIER_ORG)
+}
+
+func (h *WaybillHandler) queryWaybillList(c *gin.Context, orgName string) {
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("pageSize", "10"))
+	bookmark := c.DefaultQuery("bookmark", "")
+	status := c.DefaultQuery("status", "")
+
+	result, err := h.waybillService.QueryWaybillList(orgName, int32(pageSize), bookmark, status)
+	if err != nil {
+		log.Printf("QueryWaybillList Error: %v", err)
+		utils.ServerError(c, err.Error())
+		return
+	}
+	utils.Success(c, result)
+}
+
+// QueryOrderWaybills 查询订单关联的运单 (核心企业)
+func (h *WaybillHandler) QueryOrderWaybills(c *gin.Context) {
+	id := c.Param("id")
+	waybills, err := h.waybillService.QueryOrderWaybills(service.OEM_ORG, id)
+	if err != nil {
+		utils.ServerError(c, err.Error())
+		return
+	}
+	utils.Success(c, waybills)
+}
+
+// VerifyWaybill 平台方校验运单内容哈希
+func (h *WaybillHandler) VerifyWaybill(c *gin.Context) {
+	id := c.Param("id")
+	matched, err := h.waybillService.VerifyWaybill(id)
+	if err != nil {
+		utils.ServerError(c, err.Error())
+		return
+	}
+	utils.Success(c, gin.H{"waybillId": id, "matched": matched})
+}
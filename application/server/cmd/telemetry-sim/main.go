@@ -0,0 +1,78 @@
+// telemetry-sim 模拟运输途中的温度与冲击传感器, 按批次向服务端推送读数, 用于联调遥测接入与超限告警
+//
+//	go run ./cmd/telemetry-sim -shipment SHIP001 -count 120 -batch 10
+package main
+
+import (
+	"application/pkg/telemetry"
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"math/rand"
+	"net/http"
+	"time"
+)
+
+func main() {
+	server := flag.String("server", "http://localhost:8888", "服务端地址")
+	shipmentId := flag.String("shipment", "", "物流单ID")
+	deviceId := flag.String("device", "SIM-001", "设备ID")
+	count := flag.Int("count", 60, "读数总数")
+	batch := flag.Int("batch", 10, "每批推送的读数数量")
+	interval := flag.Duration("interval", time.Second, "批次间隔")
+	baseTemp := flag.Float64("temp", 20, "基准温度 (摄氏度)")
+	spikeRate := flag.Float64("spike", 0.05, "异常读数 (高温或冲击) 的概率")
+	flag.Parse()
+
+	if *shipmentId == "" {
+		log.Fatal("请通过 -shipment 指定物流单ID")
+	}
+
+	url := fmt.Sprintf("%s/api/carrier/shipment/%s/telemetry", *server, *shipmentId)
+	readings := make([]telemetry.Reading, 0, *batch)
+	for i := 0; i < *count; i++ {
+		temperature := *baseTemp + rand.NormFloat64()
+		shock := rand.Float64() * 0.5
+		if rand.Float64() < *spikeRate {
+			if rand.Intn(2) == 0 {
+				temperature += 15
+			} else {
+				shock += 5
+			}
+		}
+		readings = append(readings, telemetry.Reading{
+			DeviceID:    *deviceId,
+			Timestamp:   time.Now().UTC().Truncate(time.Millisecond),
+			Temperature: &temperature,
+			Shock:       &shock,
+		})
+
+		if len(readings) == *batch || i == *count-1 {
+			if err := push(url, readings); err != nil {
+				log.Printf("推送读数失败：%v", err)
+			}
+			readings = readings[:0]
+			time.Sleep(*interval)
+		}
+	}
+}
+
+func push(url string, readings []telemetry.Reading) error {
+	body, err := json.Marshal(map[string]interface{}{"readings": readings})
+	if err != nil {
+		return err
+	}
+
+	resp, err := http.Post(url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	respBody, _ := io.ReadAll(resp.Body)
+	log.Printf("推送 %d 条读数: %s", len(readings), respBody)
+	return nil
+}
@@ -9,8 +9,10 @@ import (
 
 // Config 配置
 type Config struct {
-	Server ServerConfig `yaml:"server"`
-	Fabric FabricConfig `yaml:"fabric"`
+	Server    ServerConfig    `yaml:"server"`
+	Fabric    FabricConfig    `yaml:"fabric"`
+	Telemetry TelemetryConfig `yaml:"telemetry"`
+	Documents DocumentConfig  `yaml:"documents"`
 }
 
 // ServerConfig 服务器配置
@@ -18,11 +20,37 @@ type ServerConfig struct {
 	Port int `yaml:"port"`
 }
 
+// TelemetryConfig 遥测配置
+type TelemetryConfig struct {
+	AnchorInterval    int `yaml:"anchorInterval"`    // 默克尔根锚定间隔 (秒), 0 表示仅手动锚定
+	ExcursionInterval int `yaml:"excursionInterval"` // 超限检测重试间隔 (秒), 未配置时为 10 秒; 新读数入库时立即检测
+}
+
+// DocumentConfig 链下文档存储配置
+type DocumentConfig struct {
+	Backend   string   `yaml:"backend"`   // 存储后端: local (默认) 或 s3
+	Dir       string   `yaml:"dir"`       // 本地存储目录
+	MaxSizeMB int      `yaml:"maxSizeMB"` // 单个文件大小上限 (MB)
+	S3        S3Config `yaml:"s3"`        // S3 兼容对象存储
+}
+
+// S3Config S3 兼容对象存储配置
+type S3Config struct {
+	Endpoint  string `yaml:"endpoint"`
+	Region    string `yaml:"region"`
+	Bucket    string `yaml:"bucket"`
+	Prefix    string `yaml:"prefix"`
+	AccessKey string `yaml:"accessKey"`
+	SecretKey string `yaml:"secretKey"`
+}
+
 // FabricConfig Fabric配置
 type FabricConfig struct {
 	ChannelName   string                        `yaml:"channelName"`
 	ChaincodeName string                        `yaml:"chaincodeName"`
 	Organizations map[string]OrganizationConfig `yaml:"organizations"`
+	Carriers      map[string]OrganizationConfig `yaml:"carriers"` // 承运商身份, 键为证书属性 carrierId
+	Banks         map[string]OrganizationConfig `yaml:"banks"`    // 银行身份, 键为证书属性 bankId
 }
 
 // OrganizationConfig 组织配置
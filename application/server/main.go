@@ -3,13 +3,21 @@ package main
 import (
 	"application/api"
 	"application/config"
+	"application/pkg/docstore"
 	"application/pkg/fabric"
+	"application/pkg/telemetry"
+	"application/service"
 	"fmt"
 	"log"
+	"path/filepath"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
 
+// 未配置时的超限检测重试间隔 (秒)
+const defaultExcursionInterval = 10
+
 func main() {
 	// 初始化配置
 	if err := config.InitConfig(); err != nil {
@@ -21,6 +29,36 @@ func main() {
 		log.Fatalf("初始化Fabric客户端失败：%v", err)
 	}
 
+	// 初始化遥测本地存储, 启动超限检测与定期锚定
+	if err := telemetry.InitStore(filepath.Join("data", "telemetry")); err != nil {
+		log.Fatalf("初始化遥测存储失败：%v", err)
+	}
+	excursionInterval := config.GlobalConfig.Telemetry.ExcursionInterval
+	if excursionInterval <= 0 {
+		excursionInterval = defaultExcursionInterval
+	}
+	service.StartExcursionDetection(time.Duration(excursionInterval) * time.Second)
+	if interval := config.GlobalConfig.Telemetry.AnchorInterval; interval > 0 {
+		service.StartTelemetryAnchoring(time.Duration(interval) * time.Second)
+	}
+
+	// 初始化链下文档存储
+	documents := config.GlobalConfig.Documents
+	if err := docstore.InitStorage(docstore.Options{
+		Backend: documents.Backend,
+		Dir:     documents.Dir,
+		S3: docstore.S3Options{
+			Endpoint:  documents.S3.Endpoint,
+			Region:    documents.S3.Region,
+			Bucket:    documents.S3.Bucket,
+			Prefix:    documents.S3.Prefix,
+			AccessKey: documents.S3.AccessKey,
+			SecretKey: documents.S3.SecretKey,
+		},
+	}); err != nil {
+		log.Fatalf("初始化文档存储失败：%v", err)
+	}
+
 	// 创建 Gin 路由
 	gin.SetMode(gin.ReleaseMode)
 	r := gin.Default()
@@ -29,6 +67,25 @@ func main() {
 
 	// 注册路由
 	scHandler := api.NewSupplyChainHandler()
+	rfqHandler := api.NewRFQHandler()
+	agreementHandler := api.NewAgreementHandler()
+	traceHandler := api.NewTraceHandler()
+	partHandler := api.NewPartHandler()
+	shipmentHandler := api.NewShipmentHandler()
+	geofenceHandler := api.NewGeofenceHandler()
+	telemetryHandler := api.NewTelemetryHandler()
+	invoiceHandler := api.NewInvoiceHandler()
+	financingHandler := api.NewFinancingHandler()
+	waybillHandler := api.NewWaybillHandler()
+	eblHandler := api.NewEBLHandler()
+	paymentHandler := api.NewPaymentHandler()
+	rmaHandler := api.NewRMAHandler()
+	certificateHandler := api.NewCertificateHandler()
+	documentHandler := api.NewDocumentHandler()
+	ecnHandler := api.NewECNHandler()
+	onboardingHandler := api.NewOnboardingHandler()
+	forecastHandler := api.NewForecastHandler()
+	vmiHandler := api.NewVMIHandler()
 
 	// 主机厂接口 (Org1)
 	oemGroup := apiGroup.Group("/oem")
@@ -36,7 +93,106 @@ func main() {
 		oemGroup.POST("/order/create", scHandler.CreateOrder)
 		oemGroup.PUT("/order/:id/receive", scHandler.ConfirmReceipt)
 		oemGroup.GET("/order/:id", scHandler.QueryOrder)
+		oemGroup.GET("/order/:id/price", scHandler.QueryOrderPrice)
+		oemGroup.GET("/order/:id/tree", scHandler.QueryOrderTree)
 		oemGroup.GET("/order/list", scHandler.QueryOrderList)
+
+		oemGroup.POST("/rfq/create", rfqHandler.CreateRFQ)
+		oemGroup.POST("/rfq/:id/award", rfqHandler.AwardRFQ)
+		oemGroup.PUT("/rfq/:id/cancel", rfqHandler.CancelRFQ)
+		oemGroup.GET("/rfq/:id", rfqHandler.QueryRFQ)
+		oemGroup.GET("/rfq/:id/bids", rfqHandler.QueryBids)
+		oemGroup.GET("/rfq/:id/bids/:manufacturerId", rfqHandler.QueryBidContent)
+
+		oemGroup.POST("/agreement/create", agreementHandler.CreateFrameworkAgreement)
+		oemGroup.PUT("/agreement/:id/terminate", agreementHandler.TerminateFrameworkAgreement)
+		oemGroup.POST("/agreement/:id/calloff", agreementHandler.CallOffOrder)
+		oemGroup.GET("/agreement/:id", agreementHandler.QueryFrameworkAgreement)
+		oemGroup.GET("/agreement/:id/utilisation", agreementHandler.QueryAgreementUtilisation)
+
+		oemGroup.GET("/batch/:batchNo/trace", traceHandler.TraceBatch)
+		oemGroup.GET("/recall/:id", traceHandler.QueryRecall)
+
+		oemGroup.POST("/part/create", partHandler.CreatePart)
+		oemGroup.PUT("/part/:partNumber", partHandler.UpdatePart)
+		oemGroup.DELETE("/part/:partNumber", partHandler.ObsoletePart)
+		oemGroup.GET("/part/search", partHandler.SearchParts)
+		oemGroup.GET("/part/:partNumber", partHandler.QueryPart)
+
+		oemGroup.GET("/shipment/:id/custody", shipmentHandler.QueryCustodyAtForOEM)
+		oemGroup.GET("/shipment/:id/track", shipmentHandler.QueryShipmentTrackForOEM)
+		oemGroup.PUT("/shipment/:id/destination", geofenceHandler.SetShipmentDestination)
+		oemGroup.GET("/geofence/list", geofenceHandler.QueryGeofenceListForOEM)
+		oemGroup.PUT("/shipment/:id/thresholds", telemetryHandler.SetSensorThresholds)
+		oemGroup.GET("/shipment/:id/excursions", telemetryHandler.QueryExcursions)
+		oemGroup.GET("/shipment/:id/telemetry/verify", telemetryHandler.VerifyTelemetry)
+		oemGroup.GET("/shipment/:id/exceptions", shipmentHandler.QueryShipmentExceptionsForOEM)
+		oemGroup.PUT("/shipment/:id/exception/:seq/resolve", shipmentHandler.ResolveException)
+		oemGroup.GET("/exception/open", shipmentHandler.QueryOpenExceptions)
+
+		oemGroup.PUT("/invoice/:id/approve", invoiceHandler.ApproveInvoice)
+		oemGroup.PUT("/invoice/:id/reject", invoiceHandler.RejectInvoice)
+		oemGroup.GET("/invoice/:id", invoiceHandler.QueryInvoice)
+		oemGroup.GET("/invoice/:id/amount", invoiceHandler.QueryInvoiceAmount)
+		oemGroup.GET("/receivable/:id", financingHandler.QueryReceivableForOEM)
+		oemGroup.GET("/receivable/:id/amount", financingHandler.QueryReceivableAmountForOEM)
+
+		oemGroup.PUT("/ebl/:id/transfer", eblHandler.TransferTitle)
+		oemGroup.PUT("/ebl/:id/surrender", eblHandler.SurrenderBillOfLading)
+		oemGroup.GET("/ebl/:id", eblHandler.QueryBillOfLading)
+
+		oemGroup.PUT("/order/:id/payment-schedule", paymentHandler.SetPaymentSchedule)
+		oemGroup.POST("/order/:id/payment", paymentHandler.RecordPayment)
+		oemGroup.GET("/order/:id/balance", paymentHandler.QueryOrderBalance)
+		oemGroup.GET("/balance/:counterpartyId", paymentHandler.QueryCounterpartyBalance)
+
+		oemGroup.POST("/rma/create", rmaHandler.OpenRMA)
+		oemGroup.GET("/rma/:id", rmaHandler.QueryRMA)
+		oemGroup.GET("/rma/:id/credit-note", rmaHandler.QueryCreditNote)
+		oemGroup.GET("/order/:id/rmas", rmaHandler.QueryOrderRMAs)
+
+		oemGroup.PUT("/order/:id/required-certificates", certificateHandler.SetRequiredCertificates)
+		oemGroup.GET("/order/:id/certificates", certificateHandler.QueryOrderCertificates)
+		oemGroup.GET("/batch/:batchNo/certificates", certificateHandler.QueryBatchCertificates)
+		oemGroup.GET("/certificate/:id", certificateHandler.QueryCertificate)
+
+		oemGroup.POST("/document/upload", documentHandler.UploadDocument)
+		oemGroup.GET("/document/:id", documentHandler.QueryDocument)
+		oemGroup.GET("/document/:id/download", documentHandler.DownloadDocument)
+		oemGroup.GET("/order/:id/documents", documentHandler.QueryOrderDocuments)
+		oemGroup.GET("/shipment/:id/documents", documentHandler.QueryShipmentDocuments)
+
+		oemGroup.POST("/ecn/create", ecnHandler.IssueECN)
+		oemGroup.GET("/ecn/:id", ecnHandler.QueryECN)
+		oemGroup.GET("/order/:id/ecns", ecnHandler.QueryOrderECNs)
+
+		oemGroup.POST("/onboarding/apply", onboardingHandler.SubmitApplication)
+		oemGroup.PUT("/onboarding/:id/resubmit", onboardingHandler.ResubmitApplication)
+		oemGroup.GET("/onboarding/:id", onboardingHandler.QueryApplication)
+		oemGroup.GET("/participant/:id", onboardingHandler.QueryParticipant)
+
+		oemGroup.PUT("/forecast/:manufacturerId/:partNumber", forecastHandler.PublishForecast)
+		oemGroup.GET("/forecast/:manufacturerId/:partNumber", forecastHandler.QueryForecast)
+		oemGroup.GET("/forecast/:manufacturerId/:partNumber/compare", forecastHandler.CompareForecast)
+		oemGroup.POST("/forecast/:manufacturerId/:partNumber/order", forecastHandler.CreateOrderFromForecast)
+
+		oemGroup.PUT("/order/:id/consignment", vmiHandler.SetOrderConsignment)
+		oemGroup.POST("/consumption/create", vmiHandler.ReportConsumption)
+		oemGroup.GET("/consumption/:id", vmiHandler.QueryConsumption)
+		oemGroup.PUT("/inventory/:location/:partNumber/:ownerId/levels", vmiHandler.SetInventoryLevels)
+		oemGroup.GET("/inventory/:location/:partNumber/:ownerId", vmiHandler.QueryInventory)
+		oemGroup.GET("/location/:location/inventory", vmiHandler.QueryLocationInventory)
+		oemGroup.GET("/location/:location/replenishment", vmiHandler.QueryReplenishmentSuggestions)
+	}
+
+	// 核心企业接口 (Org1, 沿用 MVP 规划中的数字运单路径)
+	coreEnterpriseGroup := apiGroup.Group("/core-enterprise")
+	{
+		coreEnterpriseGroup.POST("/waybill/create", waybillHandler.CreateWaybill)
+		coreEnterpriseGroup.GET("/waybill/list", waybillHandler.QueryWaybillList)
+		coreEnterpriseGroup.PUT("/waybill/:id/transfer", waybillHandler.TransferWaybill)
+		coreEnterpriseGroup.GET("/waybill/:id", waybillHandler.QueryWaybill)
+		coreEnterpriseGroup.GET("/order/:id/waybills", waybillHandler.QueryOrderWaybills)
 	}
 
 	// 零部件厂商接口 (Org2)
@@ -44,22 +200,186 @@ func main() {
 	{
 		manufacturerGroup.PUT("/order/:id/accept", scHandler.AcceptOrder)
 		manufacturerGroup.PUT("/order/:id/status", scHandler.UpdateStatus)
+		manufacturerGroup.PUT("/order/:id/produced", traceHandler.CompleteProduction)
+		manufacturerGroup.POST("/order/:id/suborder", scHandler.CreateSubOrder)
+		manufacturerGroup.PUT("/order/:id/receive", scHandler.ConfirmSubOrderReceipt)
+		manufacturerGroup.GET("/order/:id/tree", scHandler.QueryOrderTreeForManufacturer)
+		manufacturerGroup.GET("/order/:id/price", scHandler.QueryOrderPriceForManufacturer)
 		manufacturerGroup.GET("/order/list", scHandler.QueryOrderList)
+
+		manufacturerGroup.GET("/rfq/:id", rfqHandler.QueryRFQForManufacturer)
+		manufacturerGroup.POST("/rfq/:id/bid", rfqHandler.SubmitBid)
+		manufacturerGroup.POST("/rfq/:id/reveal", rfqHandler.RevealBid)
+
+		manufacturerGroup.PUT("/agreement/:id/accept", agreementHandler.AcceptFrameworkAgreement)
+		manufacturerGroup.GET("/agreement/:id", agreementHandler.QueryFrameworkAgreementForManufacturer)
+		manufacturerGroup.GET("/agreement/:id/utilisation", agreementHandler.QueryAgreementUtilisationForManufacturer)
+
+		manufacturerGroup.GET("/part/search", partHandler.SearchPartsForManufacturer)
+		manufacturerGroup.GET("/part/:partNumber", partHandler.QueryPartForManufacturer)
+
+		manufacturerGroup.PUT("/shipment/:id/thresholds", telemetryHandler.SetSensorThresholdsForManufacturer)
+
+		manufacturerGroup.POST("/invoice/create", invoiceHandler.IssueInvoice)
+		manufacturerGroup.GET("/invoice/:id", invoiceHandler.QueryInvoiceForManufacturer)
+		manufacturerGroup.GET("/invoice/:id/amount", invoiceHandler.QueryInvoiceAmountForManufacturer)
+
+		manufacturerGroup.POST("/receivable/create", financingHandler.CreateReceivable)
+		manufacturerGroup.GET("/receivable/:id", financingHandler.QueryReceivable)
+		manufacturerGroup.GET("/receivable/:id/amount", financingHandler.QueryReceivableAmount)
+		manufacturerGroup.POST("/financing/apply", financingHandler.ApplyFinancing)
+		manufacturerGroup.GET("/financing/:id", financingHandler.QueryFinancing)
+
+		manufacturerGroup.PUT("/waybill/:id/transfer", waybillHandler.TransferWaybillForManufacturer)
+		manufacturerGroup.PUT("/ebl/:id/transfer", eblHandler.TransferTitleForManufacturer)
+		manufacturerGroup.PUT("/ebl/:id/surrender", eblHandler.SurrenderBillOfLadingForManufacturer)
+		manufacturerGroup.GET("/ebl/:id", eblHandler.QueryBillOfLadingForManufacturer)
+
+		manufacturerGroup.PUT("/order/:id/payment/:reference/confirm", paymentHandler.ConfirmPaymentReceipt)
+		manufacturerGroup.GET("/order/:id/balance", paymentHandler.QueryOrderBalanceForManufacturer)
+		manufacturerGroup.GET("/balance/:counterpartyId", paymentHandler.QueryCounterpartyBalanceForManufacturer)
+
+		manufacturerGroup.PUT("/rma/:id/approve", rmaHandler.ApproveRMA)
+		manufacturerGroup.PUT("/rma/:id/reject", rmaHandler.RejectRMA)
+		manufacturerGroup.PUT("/rma/:id/receive", rmaHandler.ConfirmReturnReceipt)
+		manufacturerGroup.GET("/rma/:id", rmaHandler.QueryRMAForManufacturer)
+		manufacturerGroup.GET("/rma/:id/credit-note", rmaHandler.QueryCreditNoteForManufacturer)
+		manufacturerGroup.GET("/order/:id/rmas", rmaHandler.QueryOrderRMAsForManufacturer)
+
+		manufacturerGroup.POST("/certificate/create", certificateHandler.AddCertificate)
+		manufacturerGroup.GET("/certificate/:id", certificateHandler.QueryCertificateForManufacturer)
+		manufacturerGroup.GET("/order/:id/certificates", certificateHandler.QueryOrderCertificatesForManufacturer)
+		manufacturerGroup.GET("/batch/:batchNo/certificates", certificateHandler.QueryBatchCertificatesForManufacturer)
+
+		manufacturerGroup.POST("/document/upload", documentHandler.UploadDocumentForManufacturer)
+		manufacturerGroup.GET("/document/:id", documentHandler.QueryDocumentForManufacturer)
+		manufacturerGroup.GET("/document/:id/download", documentHandler.DownloadDocumentForManufacturer)
+		manufacturerGroup.GET("/order/:id/documents", documentHandler.QueryOrderDocumentsForManufacturer)
+		manufacturerGroup.GET("/shipment/:id/documents", documentHandler.QueryShipmentDocumentsForManufacturer)
+
+		manufacturerGroup.PUT("/ecn/:id/acknowledge", ecnHandler.AcknowledgeECN)
+		manufacturerGroup.GET("/ecn/:id", ecnHandler.QueryECNForManufacturer)
+		manufacturerGroup.GET("/order/:id/ecns", ecnHandler.QueryOrderECNsForManufacturer)
+
+		manufacturerGroup.PUT("/forecast/:partNumber/commitment", forecastHandler.CommitCapacity)
+		manufacturerGroup.GET("/forecast/:partNumber", forecastHandler.QueryForecastForManufacturer)
+		manufacturerGroup.GET("/forecast/:partNumber/compare", forecastHandler.CompareForecastForManufacturer)
+
+		manufacturerGroup.POST("/inventory/create", vmiHandler.CreateInventory)
+		manufacturerGroup.PUT("/inventory/:location/:partNumber/levels", vmiHandler.SetInventoryLevelsForManufacturer)
+		manufacturerGroup.GET("/inventory/:location/:partNumber", vmiHandler.QueryInventoryForManufacturer)
+		manufacturerGroup.GET("/location/:location/inventory", vmiHandler.QueryLocationInventoryForManufacturer)
+		manufacturerGroup.GET("/location/:location/replenishment", vmiHandler.QueryReplenishmentSuggestionsForManufacturer)
+		manufacturerGroup.GET("/consumption/:id", vmiHandler.QueryConsumptionForManufacturer)
 	}
 
 	// 承运商接口 (Org3)
-	carrierGroup := apiGroup.Group("/carrier")
+	carrierGroup := apiGroup.Group("/carrier", api.CarrierIdentity())
 	{
 		carrierGroup.POST("/shipment/pickup", scHandler.PickupGoods)
 		carrierGroup.PUT("/shipment/:id/location", scHandler.UpdateLocation)
 		carrierGroup.GET("/shipment/:id", scHandler.QueryShipment)
 		carrierGroup.GET("/order/list", scHandler.QueryOrderList)
+
+		carrierGroup.POST("/shipment/:id/legs", shipmentHandler.PlanShipmentLegs)
+		carrierGroup.POST("/shipment/:id/handover", shipmentHandler.HandoverCustody)
+		carrierGroup.PUT("/shipment/:id/handover/accept", shipmentHandler.AcceptCustody)
+		carrierGroup.PUT("/shipment/:id/handover/reject", shipmentHandler.RejectCustody)
+		carrierGroup.GET("/shipment/:id/custody", shipmentHandler.QueryCustodyAt)
+		carrierGroup.POST("/shipment/:id/checkpoint", shipmentHandler.RecordCheckpoint)
+		carrierGroup.GET("/shipment/:id/track", shipmentHandler.QueryShipmentTrack)
+		carrierGroup.PUT("/shipment/:id/destination", geofenceHandler.SetShipmentDestinationForCarrier)
+		carrierGroup.POST("/shipment/:id/telemetry", telemetryHandler.IngestReadings)
+		carrierGroup.GET("/shipment/:id/telemetry", telemetryHandler.QueryReadings)
+		carrierGroup.GET("/shipment/:id/excursions", telemetryHandler.QueryExcursionsForCarrier)
+		carrierGroup.POST("/shipment/:id/exception", shipmentHandler.ReportException)
+		carrierGroup.GET("/shipment/:id/exceptions", shipmentHandler.QueryShipmentExceptions)
+
+		carrierGroup.POST("/waybill/confirm", waybillHandler.ConfirmWaybill)
+		carrierGroup.GET("/waybill/list", waybillHandler.QueryWaybillListForCarrier)
+		carrierGroup.GET("/waybill/:id", waybillHandler.QueryWaybillForCarrier)
+		carrierGroup.POST("/ebl/issue", eblHandler.IssueBillOfLading)
+		carrierGroup.PUT("/ebl/:id/surrender/accept", eblHandler.AcceptBillOfLadingSurrender)
+		carrierGroup.GET("/ebl/:id", eblHandler.QueryBillOfLadingForCarrier)
+
+		carrierGroup.POST("/rma/:id/pickup", rmaHandler.PickupReturn)
+		carrierGroup.GET("/rma/:id", rmaHandler.QueryRMAForCarrier)
+
+		carrierGroup.POST("/document/upload", documentHandler.UploadDocumentForCarrier)
+		carrierGroup.GET("/document/:id", documentHandler.QueryDocumentForCarrier)
+		carrierGroup.GET("/document/:id/download", documentHandler.DownloadDocumentForCarrier)
+		carrierGroup.GET("/shipment/:id/documents", documentHandler.QueryShipmentDocumentsForCarrier)
 	}
 
 	// 平台方接口 (Org3 - 监管)
 	platformGroup := apiGroup.Group("/platform")
 	{
 		platformGroup.GET("/order/list", scHandler.QueryOrderList)
+		platformGroup.GET("/order/:id/verify-price", scHandler.VerifyOrderPrice)
+		platformGroup.GET("/order/:id/tree", scHandler.QueryOrderTreeForPlatform)
+		platformGroup.GET("/agreement/:id/utilisation", agreementHandler.QueryAgreementUtilisationForPlatform)
+
+		platformGroup.GET("/batch/:batchNo/trace", traceHandler.TraceBatchForPlatform)
+		platformGroup.GET("/serial/:serialNo/trace", traceHandler.TraceSerial)
+		platformGroup.POST("/recall/create", traceHandler.IssueRecall)
+		platformGroup.PUT("/recall/:id/close", traceHandler.CloseRecall)
+		platformGroup.GET("/recall/:id", traceHandler.QueryRecallForPlatform)
+
+		platformGroup.GET("/part/search", partHandler.SearchPartsForPlatform)
+
+		platformGroup.GET("/shipment/:id/track", shipmentHandler.QueryShipmentTrackForPlatform)
+		platformGroup.GET("/shipment/:id/telemetry", telemetryHandler.QueryReadings)
+		platformGroup.GET("/shipment/:id/telemetry/verify", telemetryHandler.VerifyTelemetryForPlatform)
+		platformGroup.GET("/shipment/:id/excursions", telemetryHandler.QueryExcursionsForPlatform)
+		platformGroup.POST("/telemetry/anchor", telemetryHandler.AnchorTelemetry)
+		platformGroup.GET("/shipment/:id/exceptions", shipmentHandler.QueryShipmentExceptionsForPlatform)
+		platformGroup.PUT("/shipment/:id/exception/:seq/resolve", shipmentHandler.ResolveExceptionForPlatform)
+		platformGroup.GET("/exception/open", shipmentHandler.QueryOpenExceptionsForPlatform)
+
+		platformGroup.POST("/geofence/create", geofenceHandler.CreateGeofence)
+		platformGroup.PUT("/geofence/:id", geofenceHandler.UpdateGeofence)
+		platformGroup.DELETE("/geofence/:id", geofenceHandler.DeleteGeofence)
+		platformGroup.GET("/geofence/list", geofenceHandler.QueryGeofenceList)
+		platformGroup.GET("/geofence/:id", geofenceHandler.QueryGeofence)
+
+		platformGroup.GET("/waybill/:id/verify", waybillHandler.VerifyWaybill)
+
+		platformGroup.POST("/lab-key", certificateHandler.RegisterLabKey)
+		platformGroup.GET("/lab-key/:id", certificateHandler.QueryLabKey)
+		platformGroup.GET("/batch/:batchNo/certificates", certificateHandler.QueryBatchCertificatesForPlatform)
+
+		platformGroup.GET("/document/:id", documentHandler.QueryDocumentForPlatform)
+		platformGroup.GET("/document/:id/download", documentHandler.DownloadDocumentForPlatform)
+		platformGroup.GET("/order/:id/documents", documentHandler.QueryOrderDocumentsForPlatform)
+		platformGroup.GET("/shipment/:id/documents", documentHandler.QueryShipmentDocumentsForPlatform)
+
+		platformGroup.GET("/ecn/:id", ecnHandler.QueryECNForPlatform)
+
+		platformGroup.POST("/onboarding/apply", onboardingHandler.SubmitApplicationForPlatform)
+		platformGroup.PUT("/onboarding/:id/resubmit", onboardingHandler.ResubmitApplicationForPlatform)
+		platformGroup.GET("/onboarding/list", onboardingHandler.QueryApplications)
+		platformGroup.GET("/onboarding/:id", onboardingHandler.QueryApplicationForPlatform)
+		platformGroup.PUT("/onboarding/:id/review", onboardingHandler.StartReview)
+		platformGroup.PUT("/onboarding/:id/request-changes", onboardingHandler.RequestChanges)
+		platformGroup.PUT("/onboarding/:id/approve", onboardingHandler.ApproveApplication)
+		platformGroup.PUT("/onboarding/:id/reject", onboardingHandler.RejectApplication)
+		platformGroup.GET("/participant/:id", onboardingHandler.QueryParticipantForPlatform)
+	}
+
+	// 银行接口 (Org3 下携带 bankId 属性的身份)
+	bankGroup := apiGroup.Group("/bank", api.BankIdentity())
+	{
+		bankGroup.GET("/financing/list", financingHandler.QueryBankFinancings)
+		bankGroup.GET("/financing/:id", financingHandler.QueryFinancingForBank)
+		bankGroup.PUT("/financing/:id/approve", financingHandler.ApproveFinancing)
+		bankGroup.PUT("/financing/:id/reject", financingHandler.RejectFinancing)
+		bankGroup.PUT("/financing/:id/repay", financingHandler.ConfirmRepayment)
+		bankGroup.GET("/receivable/:id", financingHandler.QueryReceivableForBank)
+		bankGroup.GET("/receivable/:id/verify", financingHandler.VerifyReceivableFaceValue)
+		bankGroup.PUT("/waybill/:id/transfer", waybillHandler.TransferWaybillForBank)
+		bankGroup.PUT("/ebl/:id/transfer", eblHandler.TransferTitleForBank)
+		bankGroup.PUT("/ebl/:id/surrender", eblHandler.SurrenderBillOfLadingForBank)
+		bankGroup.GET("/ebl/:id", eblHandler.QueryBillOfLadingForCarrier)
 	}
 
 	// 启动服务器
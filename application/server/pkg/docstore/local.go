@@ -0,0 +1,89 @@
+package docstore
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// LocalStorage 本地磁盘存储, 文件按哈希前两级目录分散存放
+type LocalStorage struct {
+	dir string
+}
+
+// NewLocalStorage 创建本地磁盘存储
+func NewLocalStorage(dir string) (*LocalStorage, error) {
+	if dir == "" {
+		dir = filepath.Join("data", "documents")
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("创建文档目录失败：%w", err)
+	}
+	return &LocalStorage{dir: dir}, nil
+}
+
+// Put 写入内容, 同一内容重复写入直接跳过
+func (s *LocalStorage) Put(hash string, data []byte) error {
+	if err := validHash(hash); err != nil {
+		return err
+	}
+	if HashContent(data) != hash {
+		return fmt.Errorf("内容与地址 %s 不符", hash)
+	}
+	path := s.path(hash)
+	if _, err := os.Stat(path); err == nil {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("创建文档目录失败：%w", err)
+	}
+
+	// 先写临时文件再重命名, 避免并发读取到写了一半的文件
+	tmp, err := os.CreateTemp(filepath.Dir(path), hash+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("创建临时文件失败：%w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("写入文档失败：%w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("写入文档失败：%w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("保存文档失败：%w", err)
+	}
+	return nil
+}
+
+// Get 读取内容
+func (s *LocalStorage) Get(hash string) ([]byte, error) {
+	if err := validHash(hash); err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(s.path(hash))
+	if os.IsNotExist(err) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("读取文档失败：%w", err)
+	}
+	return data, nil
+}
+
+// Exists 内容是否已存储
+func (s *LocalStorage) Exists(hash string) (bool, error) {
+	if err := validHash(hash); err != nil {
+		return false, err
+	}
+	_, err := os.Stat(s.path(hash))
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func (s *LocalStorage) path(hash string) string {
+	return filepath.Join(s.dir, hash[:2], hash[2:4], hash)
+}
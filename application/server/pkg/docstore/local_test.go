@@ -0,0 +1,95 @@
+package docstore
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestHashContent(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+		want string
+	}{
+		{"空内容", []byte{}, "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"},
+		{"abc", []byte("abc"), "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := HashContent(tt.data); got != tt.want {
+				t.Errorf("HashContent() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidHash(t *testing.T) {
+	valid := HashContent([]byte("abc"))
+	tests := []struct {
+		name    string
+		hash    string
+		wantErr bool
+	}{
+		{"合法地址", valid, false},
+		{"长度不足", valid[:63], true},
+		{"非十六进制", strings.Repeat("g", 64), true},
+		{"路径穿越", "../" + valid[3:], true},
+		{"空地址", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := validHash(tt.hash); (err != nil) != tt.wantErr {
+				t.Errorf("validHash(%q) error = %v, wantErr %v", tt.hash, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLocalStorage(t *testing.T) {
+	s, err := NewLocalStorage(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewLocalStorage() error = %v", err)
+	}
+	data := []byte("质量证书 PDF")
+	hash := HashContent(data)
+
+	tests := []struct {
+		name    string
+		hash    string
+		data    []byte
+		wantErr bool
+	}{
+		{"按内容地址写入", hash, data, false},
+		{"同一内容重复写入", hash, data, false},
+		{"内容与地址不符", hash, []byte("被篡改的内容"), true},
+		{"非法地址", "../../etc/passwd", data, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := s.Put(tt.hash, tt.data); (err != nil) != tt.wantErr {
+				t.Errorf("Put() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	got, err := s.Get(hash)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if !bytes.Equal(got, data) {
+		t.Errorf("Get() = %q, want %q", got, data)
+	}
+	if exists, err := s.Exists(hash); err != nil || !exists {
+		t.Errorf("Exists() = %v, %v, want true, nil", exists, err)
+	}
+
+	missing := HashContent([]byte("不存在的内容"))
+	if _, err := s.Get(missing); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get(missing) error = %v, want ErrNotFound", err)
+	}
+	if exists, err := s.Exists(missing); err != nil || exists {
+		t.Errorf("Exists(missing) = %v, %v, want false, nil", exists, err)
+	}
+}
@@ -0,0 +1,174 @@
+package docstore
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// S3Options S3 兼容对象存储选项 (AWS S3、MinIO 等)
+type S3Options struct {
+	Endpoint  string // 服务地址, 如 http://minio:9000
+	Region    string // 区域, 默认 us-east-1
+	Bucket    string // 存储桶
+	Prefix    string // 对象键前缀
+	AccessKey string
+	SecretKey string
+}
+
+// S3Storage S3 兼容对象存储 (路径风格访问, AWS Signature V4 签名)
+type S3Storage struct {
+	opts   S3Options
+	client *http.Client
+}
+
+// NewS3Storage 创建 S3 兼容对象存储
+func NewS3Storage(opts S3Options) (*S3Storage, error) {
+	if opts.Endpoint == "" || opts.Bucket == "" {
+		return nil, fmt.Errorf("S3 存储须配置 endpoint 与 bucket")
+	}
+	if opts.Region == "" {
+		opts.Region = "us-east-1"
+	}
+	opts.Endpoint = strings.TrimRight(opts.Endpoint, "/")
+	return &S3Storage{opts: opts, client: &http.Client{Timeout: 60 * time.Second}}, nil
+}
+
+// Put 上传对象, 同一内容重复写入直接跳过
+func (s *S3Storage) Put(hash string, data []byte) error {
+	if err := validHash(hash); err != nil {
+		return err
+	}
+	if HashContent(data) != hash {
+		return fmt.Errorf("内容与地址 %s 不符", hash)
+	}
+	exists, err := s.Exists(hash)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return nil
+	}
+
+	resp, err := s.do(http.MethodPut, hash, data)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("上传对象失败：%s", readError(resp))
+	}
+	return nil
+}
+
+// Get 下载对象
+func (s *S3Storage) Get(hash string) ([]byte, error) {
+	if err := validHash(hash); err != nil {
+		return nil, err
+	}
+	resp, err := s.do(http.MethodGet, hash, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, ErrNotFound
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("下载对象失败：%s", readError(resp))
+	}
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("读取对象失败：%w", err)
+	}
+	return data, nil
+}
+
+// Exists 对象是否存在
+func (s *S3Storage) Exists(hash string) (bool, error) {
+	if err := validHash(hash); err != nil {
+		return false, err
+	}
+	resp, err := s.do(http.MethodHead, hash, nil)
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return true, nil
+	case http.StatusNotFound:
+		return false, nil
+	}
+	return false, fmt.Errorf("查询对象失败：%s", resp.Status)
+}
+
+// 发送带 SigV4 签名的请求, 负载哈希直接写入 x-amz-content-sha256
+func (s *S3Storage) do(method string, hash string, body []byte) (*http.Response, error) {
+	url := fmt.Sprintf("%s/%s/%s%s", s.opts.Endpoint, s.opts.Bucket, s.opts.Prefix, hash)
+	req, err := http.NewRequest(method, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("创建请求失败：%w", err)
+	}
+
+	payloadHash := HashContent(body)
+	now := time.Now().UTC()
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+	req.Header.Set("x-amz-date", amzDate)
+	req.Header.Set("x-amz-content-sha256", payloadHash)
+
+	signedHeaders := "host;x-amz-content-sha256;x-amz-date"
+	canonicalRequest := strings.Join([]string{
+		method,
+		req.URL.EscapedPath(),
+		"",
+		"host:" + req.URL.Host,
+		"x-amz-content-sha256:" + payloadHash,
+		"x-amz-date:" + amzDate,
+		"",
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+	scope := date + "/" + s.opts.Region + "/s3/aws4_request"
+	stringToSign := strings.Join([]string{
+		"AWS4-HMAC-SHA256",
+		amzDate,
+		scope,
+		HashContent([]byte(canonicalRequest)),
+	}, "\n")
+
+	signingKey := hmacSHA256([]byte("AWS4"+s.opts.SecretKey), date)
+	signingKey = hmacSHA256(signingKey, s.opts.Region)
+	signingKey = hmacSHA256(signingKey, "s3")
+	signingKey = hmacSHA256(signingKey, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(signingKey, stringToSign))
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s.opts.AccessKey, scope, signedHeaders, signature))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("访问对象存储失败：%w", err)
+	}
+	return resp, nil
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+func readError(resp *http.Response) string {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	if len(body) == 0 {
+		return resp.Status
+	}
+	return resp.Status + " " + string(body)
+}
@@ -0,0 +1,66 @@
+package docstore
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// ErrNotFound 存储中不存在该内容
+var ErrNotFound = errors.New("文档内容不存在")
+
+// Storage 内容寻址的链下文档存储, 以内容 SHA-256 (十六进制) 作为地址
+type Storage interface {
+	Put(hash string, data []byte) error
+	Get(hash string) ([]byte, error)
+	Exists(hash string) (bool, error)
+}
+
+// Options 存储后端选项
+type Options struct {
+	Backend string // local (默认) 或 s3
+	Dir     string // 本地存储目录
+	S3      S3Options
+}
+
+var (
+	storage     Storage
+	storageOnce sync.Once
+)
+
+// InitStorage 按配置初始化文档存储后端
+func InitStorage(opts Options) error {
+	var initErr error
+	storageOnce.Do(func() {
+		switch opts.Backend {
+		case "", "local":
+			storage, initErr = NewLocalStorage(opts.Dir)
+		case "s3":
+			storage, initErr = NewS3Storage(opts.S3)
+		default:
+			initErr = fmt.Errorf("不支持的文档存储后端：%s", opts.Backend)
+		}
+	})
+	return initErr
+}
+
+// GetStorage 获取文档存储实例
+func GetStorage() Storage {
+	return storage
+}
+
+// HashContent 计算内容地址 (SHA-256, 十六进制)
+func HashContent(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// 校验地址格式, 防止路径穿越
+func validHash(hash string) error {
+	if _, err := hex.DecodeString(hash); err != nil || len(hash) != sha256.Size*2 {
+		return fmt.Errorf("无效的内容地址：%s", hash)
+	}
+	return nil
+}
@@ -7,6 +7,7 @@ import (
 	"os"
 	"path"
 	"path/filepath"
+	"sort"
 	"time"
 
 	"github.com/hyperledger/fabric-gateway/pkg/client"
@@ -17,8 +18,14 @@ import (
 	"google.golang.org/grpc/status"
 )
 
+// 承运商/银行身份合约客户端名称前缀
+const (
+	CARRIER_IDENTITY_PREFIX = "carrier:"
+	BANK_IDENTITY_PREFIX    = "bank:"
+)
+
 var (
-	// 组织对应的合约客户端
+	// 组织 (及承运商/银行身份) 对应的合约客户端
 	contracts = make(map[string]*client.Contract)
 )
 
@@ -31,40 +38,10 @@ func InitFabric() error {
 
 	// 为每个组织创建合约客户端
 	for orgName, orgConfig := range config.GlobalConfig.Fabric.Organizations {
-		// 创建 gRPC 连接
-		clientConnection, err := newGrpcConnection(orgConfig)
-		if err != nil {
-			return fmt.Errorf("创建组织[%s]的gRPC连接失败：%v", orgName, err)
-		}
-
-		// 创建组织身份
-		id, err := newIdentity(orgConfig)
-		if err != nil {
-			return fmt.Errorf("创建组织[%s]身份失败：%v", orgName, err)
-		}
-
-		// 创建签名函数
-		sign, err := newSign(orgConfig)
-		if err != nil {
-			return fmt.Errorf("创建组织[%s]签名函数失败：%v", orgName, err)
-		}
-
-		// 创建 Gateway 连接
-		gw, err := client.Connect(
-			id,
-			client.WithSign(sign),
-			client.WithHash(hash.SHA256),
-			client.WithClientConnection(clientConnection),
-			client.WithEvaluateTimeout(5*time.Second),
-			client.WithEndorseTimeout(15*time.Second),
-			client.WithSubmitTimeout(5*time.Second),
-			client.WithCommitStatusTimeout(1*time.Minute),
-		)
+		network, err := connectNetwork(orgName, orgConfig)
 		if err != nil {
-			return fmt.Errorf("连接组织[%s]的Fabric网关失败：%v", orgName, err)
+			return err
 		}
-
-		network := gw.GetNetwork(config.GlobalConfig.Fabric.ChannelName)
 		contracts[orgName] = network.GetContract(config.GlobalConfig.Fabric.ChaincodeName)
 
 		// 添加网络到区块监听器
@@ -73,14 +50,102 @@ func InitFabric() error {
 		}
 	}
 
+	// 为每个承运商/银行身份创建合约客户端 (与 Org3 共用通道, 无需重复监听区块)
+	for prefix, identities := range map[string]map[string]config.OrganizationConfig{
+		CARRIER_IDENTITY_PREFIX: config.GlobalConfig.Fabric.Carriers,
+		BANK_IDENTITY_PREFIX:    config.GlobalConfig.Fabric.Banks,
+	} {
+		for id, identityConfig := range identities {
+			name := prefix + id
+			network, err := connectNetwork(name, identityConfig)
+			if err != nil {
+				return err
+			}
+			contracts[name] = network.GetContract(config.GlobalConfig.Fabric.ChaincodeName)
+		}
+	}
+
 	return nil
 }
 
+// connectNetwork 以指定身份连接 Fabric 网关并返回通道
+func connectNetwork(name string, orgConfig config.OrganizationConfig) (*client.Network, error) {
+	// 创建 gRPC 连接
+	clientConnection, err := newGrpcConnection(orgConfig)
+	if err != nil {
+		return nil, fmt.Errorf("创建组织[%s]的gRPC连接失败：%v", name, err)
+	}
+
+	// 创建组织身份
+	id, err := newIdentity(orgConfig)
+	if err != nil {
+		return nil, fmt.Errorf("创建组织[%s]身份失败：%v", name, err)
+	}
+
+	// 创建签名函数
+	sign, err := newSign(orgConfig)
+	if err != nil {
+		return nil, fmt.Errorf("创建组织[%s]签名函数失败：%v", name, err)
+	}
+
+	// 创建 Gateway 连接
+	gw, err := client.Connect(
+		id,
+		client.WithSign(sign),
+		client.WithHash(hash.SHA256),
+		client.WithClientConnection(clientConnection),
+		client.WithEvaluateTimeout(5*time.Second),
+		client.WithEndorseTimeout(15*time.Second),
+		client.WithSubmitTimeout(5*time.Second),
+		client.WithCommitStatusTimeout(1*time.Minute),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("连接组织[%s]的Fabric网关失败：%v", name, err)
+	}
+
+	return gw.GetNetwork(config.GlobalConfig.Fabric.ChannelName), nil
+}
+
+// CarrierIdentity 返回承运商身份对应的合约客户端名称;
+// carrierId 为空时使用默认承运商 (配置中按 ID 排序的第一个)
+func CarrierIdentity(carrierId string) (string, error) {
+	return resolveIdentity("承运商", CARRIER_IDENTITY_PREFIX, config.GlobalConfig.Fabric.Carriers, carrierId)
+}
+
+// BankIdentity 返回银行身份对应的合约客户端名称;
+// bankId 为空时使用默认银行 (配置中按 ID 排序的第一个)
+func BankIdentity(bankId string) (string, error) {
+	return resolveIdentity("银行", BANK_IDENTITY_PREFIX, config.GlobalConfig.Fabric.Banks, bankId)
+}
+
+func resolveIdentity(kind string, prefix string, identities map[string]config.OrganizationConfig, id string) (string, error) {
+	if id == "" {
+		ids := make([]string, 0, len(identities))
+		for configured := range identities {
+			ids = append(ids, configured)
+		}
+		if len(ids) == 0 {
+			return "", fmt.Errorf("未配置%s身份", kind)
+		}
+		sort.Strings(ids)
+		id = ids[0]
+	}
+	if _, ok := identities[id]; !ok {
+		return "", fmt.Errorf("未配置%s[%s]的身份", kind, id)
+	}
+	return prefix + id, nil
+}
+
 // GetContract 获取指定组织的合约客户端
 func GetContract(orgName string) *client.Contract {
 	return contracts[orgName]
 }
 
+// GetMSPID 获取指定组织的 MSP ID
+func GetMSPID(orgName string) string {
+	return config.GlobalConfig.Fabric.Organizations[orgName].MSPID
+}
+
 // ExtractErrorMessage 从错误中提取详细信息
 func ExtractErrorMessage(err error) string {
 	if err == nil {
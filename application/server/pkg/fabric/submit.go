@@ -0,0 +1,81 @@
+package fabric
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/hyperledger/fabric-gateway/pkg/client"
+)
+
+// SubmitOption 交易提交选项
+type SubmitOption func(*submitOptions)
+
+type submitOptions struct {
+	transient map[string][]byte
+	endorsers []string
+	timeout   time.Duration
+}
+
+// WithConfidential 将字段作为机密数据放入瞬态数据 (transient map)
+// 机密字段只发送给背书节点, 不会写入交易载荷, 排序节点与其他节点均不可见
+func WithConfidential(key string, value []byte) SubmitOption {
+	return func(o *submitOptions) {
+		if o.transient == nil {
+			o.transient = make(map[string][]byte)
+		}
+		o.transient[key] = value
+	}
+}
+
+// WithEndorsingOrgs 指定背书组织 (使用配置中的组织名, 如 org1)
+func WithEndorsingOrgs(orgNames ...string) SubmitOption {
+	return func(o *submitOptions) {
+		o.endorsers = append(o.endorsers, orgNames...)
+	}
+}
+
+// WithTimeout 设置单次调用的超时时间, 覆盖网关默认值
+func WithTimeout(timeout time.Duration) SubmitOption {
+	return func(o *submitOptions) {
+		o.timeout = timeout
+	}
+}
+
+// Submit 以指定组织身份提交交易
+func Submit(orgName string, name string, args []string, opts ...SubmitOption) ([]byte, error) {
+	contract := GetContract(orgName)
+	if contract == nil {
+		return nil, fmt.Errorf("组织[%s]的合约客户端未初始化", orgName)
+	}
+
+	options := &submitOptions{}
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	proposalOptions := []client.ProposalOption{client.WithArguments(args...)}
+	if len(options.transient) > 0 {
+		proposalOptions = append(proposalOptions, client.WithTransient(options.transient))
+	}
+	if len(options.endorsers) > 0 {
+		mspIDs := make([]string, 0, len(options.endorsers))
+		for _, endorser := range options.endorsers {
+			mspID := GetMSPID(endorser)
+			if mspID == "" {
+				return nil, fmt.Errorf("未找到组织[%s]的 MSP ID", endorser)
+			}
+			mspIDs = append(mspIDs, mspID)
+		}
+		proposalOptions = append(proposalOptions, client.WithEndorsingOrganizations(mspIDs...))
+	}
+
+	ctx := context.Background()
+	if options.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, options.timeout)
+		defer cancel()
+	}
+
+	return contract.SubmitWithContext(ctx, name, proposalOptions...)
+}
@@ -0,0 +1,42 @@
+package telemetry
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+)
+
+// HashReading 计算单条读数的叶子哈希 (SHA-256, 十六进制)
+func HashReading(reading Reading) string {
+	readingBytes, _ := json.Marshal(reading)
+	sum := sha256.Sum256(readingBytes)
+	return hex.EncodeToString(sum[:])
+}
+
+// MerkleRoot 计算一批读数的默克尔根 (奇数节点与自身配对)
+func MerkleRoot(readings []Reading) string {
+	if len(readings) == 0 {
+		return ""
+	}
+
+	level := make([][]byte, len(readings))
+	for i, reading := range readings {
+		readingBytes, _ := json.Marshal(reading)
+		sum := sha256.Sum256(readingBytes)
+		level[i] = sum[:]
+	}
+
+	for len(level) > 1 {
+		next := make([][]byte, 0, (len(level)+1)/2)
+		for i := 0; i < len(level); i += 2 {
+			right := level[i]
+			if i+1 < len(level) {
+				right = level[i+1]
+			}
+			sum := sha256.Sum256(append(append([]byte{}, level[i]...), right...))
+			next = append(next, sum[:])
+		}
+		level = next
+	}
+	return hex.EncodeToString(level[0])
+}
@@ -0,0 +1,73 @@
+package telemetry
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+	"time"
+)
+
+func TestMerkleRoot(t *testing.T) {
+	base := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	temperature := func(v float64) *float64 { return &v }
+	readings := []Reading{
+		{DeviceID: "D1", Timestamp: base, Temperature: temperature(4.5)},
+		{DeviceID: "D1", Timestamp: base.Add(time.Minute), Temperature: temperature(5)},
+		{DeviceID: "D2", Timestamp: base, Shock: temperature(1.2)},
+	}
+
+	leaf := func(r Reading) []byte {
+		b, _ := hex.DecodeString(HashReading(r))
+		return b
+	}
+	pair := func(left, right []byte) []byte {
+		sum := sha256.Sum256(append(append([]byte{}, left...), right...))
+		return sum[:]
+	}
+	l0, l1, l2 := leaf(readings[0]), leaf(readings[1]), leaf(readings[2])
+
+	tests := []struct {
+		name     string
+		readings []Reading
+		want     string
+	}{
+		{"空批次", nil, ""},
+		{"单条读数即为叶子哈希", readings[:1], hex.EncodeToString(l0)},
+		{"两条读数", readings[:2], hex.EncodeToString(pair(l0, l1))},
+		{"奇数节点与自身配对", readings, hex.EncodeToString(pair(pair(l0, l1), pair(l2, l2)))},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := MerkleRoot(tt.readings); got != tt.want {
+				t.Errorf("MerkleRoot() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMerkleRootDetectsTampering(t *testing.T) {
+	base := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	value := func(v float64) *float64 { return &v }
+	original := []Reading{
+		{DeviceID: "D1", Timestamp: base, Temperature: value(4.5)},
+		{DeviceID: "D1", Timestamp: base.Add(time.Minute), Temperature: value(5)},
+	}
+	root := MerkleRoot(original)
+
+	tests := []struct {
+		name     string
+		readings []Reading
+	}{
+		{"修改读数值", []Reading{original[0], {DeviceID: "D1", Timestamp: base.Add(time.Minute), Temperature: value(9)}}},
+		{"调换顺序", []Reading{original[1], original[0]}},
+		{"删除读数", original[:1]},
+		{"重复读数", []Reading{original[0], original[1], original[1]}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := MerkleRoot(tt.readings); got == root {
+				t.Errorf("MerkleRoot() 未检测到篡改: %s", got)
+			}
+		})
+	}
+}
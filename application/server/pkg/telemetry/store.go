@@ -0,0 +1,294 @@
+package telemetry
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+const (
+	_ReadingsBucket = "readings" // 按物流单分桶存储原始读数
+	_BatchesBucket  = "batches"  // 按物流单分桶存储已锚定批次
+	_CursorBucket   = "cursor"   // 每个物流单最后一条已锚定读数的序号
+	_CheckedBucket  = "checked"  // 每个物流单最后一条已完成超限检测的读数序号
+	_DedupBucket    = "dedup"    // 按物流单分桶记录 设备ID+读数时间 -> 序号, 保证重复上报不重复入库
+)
+
+// Reading 传感器读数 (字段顺序决定哈希, 勿随意调整)
+type Reading struct {
+	DeviceID    string    `json:"deviceId"`
+	Timestamp   time.Time `json:"timestamp"`
+	Temperature *float64  `json:"temperature,omitempty"`
+	Shock       *float64  `json:"shock,omitempty"`
+}
+
+// StoredReading 链下存储的读数
+type StoredReading struct {
+	Seq      uint64  `json:"seq"`
+	Reading  Reading `json:"reading"`
+	Hash     string  `json:"hash"`
+	Anchored bool    `json:"anchored"`
+}
+
+// Batch 已锚定的读数批次, 与链上 TelemetryAnchor 按序号一一对应
+type Batch struct {
+	AnchorSeq  int    `json:"anchorSeq"`
+	FromSeq    uint64 `json:"fromSeq"`
+	ToSeq      uint64 `json:"toSeq"`
+	MerkleRoot string `json:"merkleRoot"`
+}
+
+// Store 遥测读数本地存储
+type Store struct {
+	db *bolt.DB
+}
+
+var (
+	store     *Store
+	storeOnce sync.Once
+)
+
+// InitStore 初始化遥测本地存储
+func InitStore(dataDir string) error {
+	var initErr error
+	storeOnce.Do(func() {
+		if err := os.MkdirAll(dataDir, 0755); err != nil {
+			initErr = fmt.Errorf("创建数据目录失败：%w", err)
+			return
+		}
+
+		db, err := bolt.Open(filepath.Join(dataDir, "telemetry.db"), 0600, &bolt.Options{Timeout: 10 * time.Second})
+		if err != nil {
+			initErr = fmt.Errorf("打开数据库失败：%w", err)
+			return
+		}
+
+		if err := db.Update(func(tx *bolt.Tx) error {
+			for _, name := range []string{_ReadingsBucket, _BatchesBucket, _CursorBucket, _CheckedBucket, _DedupBucket} {
+				if _, err := tx.CreateBucketIfNotExists([]byte(name)); err != nil {
+					return fmt.Errorf("创建%s bucket失败: %w", name, err)
+				}
+			}
+			return nil
+		}); err != nil {
+			db.Close()
+			initErr = fmt.Errorf("初始化数据库失败：%w", err)
+			return
+		}
+
+		store = &Store{db: db}
+	})
+	return initErr
+}
+
+// GetStore 获取遥测存储实例
+func GetStore() *Store {
+	return store
+}
+
+// Append 追加一批读数, 返回新入库的存储记录; 同一设备同一读数时间的读数只入库一次, 重复上报直接跳过
+func (s *Store) Append(shipmentId string, readings []Reading) ([]StoredReading, error) {
+	stored := make([]StoredReading, 0, len(readings))
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		b, err := tx.Bucket([]byte(_ReadingsBucket)).CreateBucketIfNotExists([]byte(shipmentId))
+		if err != nil {
+			return err
+		}
+		dedup, err := tx.Bucket([]byte(_DedupBucket)).CreateBucketIfNotExists([]byte(shipmentId))
+		if err != nil {
+			return err
+		}
+		for _, reading := range readings {
+			key := readingKey(reading)
+			if dedup.Get(key) != nil {
+				continue
+			}
+			seq, err := b.NextSequence()
+			if err != nil {
+				return err
+			}
+			record := StoredReading{Seq: seq, Reading: reading, Hash: HashReading(reading)}
+			recordBytes, err := json.Marshal(record)
+			if err != nil {
+				return err
+			}
+			if err := b.Put(itob(seq), recordBytes); err != nil {
+				return err
+			}
+			if err := dedup.Put(key, itob(seq)); err != nil {
+				return err
+			}
+			stored = append(stored, record)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("保存遥测读数失败：%v", err)
+	}
+	return stored, nil
+}
+
+// List 查询物流单全部读数
+func (s *Store) List(shipmentId string) ([]StoredReading, error) {
+	return s.rangeReadings(shipmentId, 1, 0)
+}
+
+// Pending 查询尚未锚定的读数
+func (s *Store) Pending(shipmentId string) ([]StoredReading, error) {
+	return s.rangeReadings(shipmentId, s.cursor(shipmentId)+1, 0)
+}
+
+// PendingShipments 返回存在未锚定读数的物流单
+func (s *Store) PendingShipments() ([]string, error) {
+	return s.shipmentsAfter(_CursorBucket)
+}
+
+// Unchecked 查询尚未完成超限检测的读数
+func (s *Store) Unchecked(shipmentId string) ([]StoredReading, error) {
+	return s.rangeReadings(shipmentId, s.checked(shipmentId)+1, 0)
+}
+
+// UncheckedShipments 返回存在未完成超限检测读数的物流单
+func (s *Store) UncheckedShipments() ([]string, error) {
+	return s.shipmentsAfter(_CheckedBucket)
+}
+
+// MarkChecked 推进超限检测游标
+func (s *Store) MarkChecked(shipmentId string, seq uint64) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket([]byte(_CheckedBucket)).Put([]byte(shipmentId), itob(seq))
+	})
+}
+
+// 返回读数序号超过指定游标的物流单
+func (s *Store) shipmentsAfter(cursorBucket string) ([]string, error) {
+	shipmentIds := make([]string, 0)
+	err := s.db.View(func(tx *bolt.Tx) error {
+		cursors := tx.Bucket([]byte(cursorBucket))
+		return tx.Bucket([]byte(_ReadingsBucket)).ForEach(func(k, v []byte) error {
+			b := tx.Bucket([]byte(_ReadingsBucket)).Bucket(k)
+			if b == nil {
+				return nil
+			}
+			var anchored uint64
+			if data := cursors.Get(k); data != nil {
+				anchored = binary.BigEndian.Uint64(data)
+			}
+			if b.Sequence() > anchored {
+				shipmentIds = append(shipmentIds, string(k))
+			}
+			return nil
+		})
+	})
+	return shipmentIds, err
+}
+
+// MarkAnchored 记录已锚定批次并推进锚定游标
+func (s *Store) MarkAnchored(shipmentId string, batch Batch) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		b, err := tx.Bucket([]byte(_BatchesBucket)).CreateBucketIfNotExists([]byte(shipmentId))
+		if err != nil {
+			return err
+		}
+		batchBytes, err := json.Marshal(batch)
+		if err != nil {
+			return err
+		}
+		if err := b.Put(itob(uint64(batch.AnchorSeq)), batchBytes); err != nil {
+			return err
+		}
+		return tx.Bucket([]byte(_CursorBucket)).Put([]byte(shipmentId), itob(batch.ToSeq))
+	})
+}
+
+// Batches 查询物流单已锚定批次
+func (s *Store) Batches(shipmentId string) ([]Batch, error) {
+	batches := make([]Batch, 0)
+	err := s.db.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte(_BatchesBucket)).Bucket([]byte(shipmentId))
+		if b == nil {
+			return nil
+		}
+		return b.ForEach(func(k, v []byte) error {
+			var batch Batch
+			if err := json.Unmarshal(v, &batch); err != nil {
+				return err
+			}
+			batches = append(batches, batch)
+			return nil
+		})
+	})
+	return batches, err
+}
+
+// Range 查询序号区间 [fromSeq, toSeq] 内的读数
+func (s *Store) Range(shipmentId string, fromSeq, toSeq uint64) ([]StoredReading, error) {
+	return s.rangeReadings(shipmentId, fromSeq, toSeq)
+}
+
+func (s *Store) rangeReadings(shipmentId string, fromSeq, toSeq uint64) ([]StoredReading, error) {
+	anchored := s.cursor(shipmentId)
+	readings := make([]StoredReading, 0)
+	err := s.db.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte(_ReadingsBucket)).Bucket([]byte(shipmentId))
+		if b == nil {
+			return nil
+		}
+		c := b.Cursor()
+		for k, v := c.Seek(itob(fromSeq)); k != nil; k, v = c.Next() {
+			seq := binary.BigEndian.Uint64(k)
+			if toSeq > 0 && seq > toSeq {
+				break
+			}
+			var record StoredReading
+			if err := json.Unmarshal(v, &record); err != nil {
+				return err
+			}
+			record.Anchored = seq <= anchored
+			readings = append(readings, record)
+		}
+		return nil
+	})
+	return readings, err
+}
+
+func (s *Store) cursor(shipmentId string) uint64 {
+	return s.position(_CursorBucket, shipmentId)
+}
+
+func (s *Store) checked(shipmentId string) uint64 {
+	return s.position(_CheckedBucket, shipmentId)
+}
+
+func (s *Store) position(cursorBucket string, shipmentId string) uint64 {
+	var seq uint64
+	s.db.View(func(tx *bolt.Tx) error {
+		if data := tx.Bucket([]byte(cursorBucket)).Get([]byte(shipmentId)); data != nil {
+			seq = binary.BigEndian.Uint64(data)
+		}
+		return nil
+	})
+	return seq
+}
+
+// 读数去重键: 设备ID + 读数时间 (UTC, 纳秒精度)
+func readingKey(reading Reading) []byte {
+	return []byte(reading.DeviceID + "|" + reading.Timestamp.UTC().Format(time.RFC3339Nano))
+}
+
+// Close 关闭存储
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+func itob(v uint64) []byte {
+	b := make([]byte, 8)
+	binary.BigEndian.PutUint64(b, v)
+	return b
+}
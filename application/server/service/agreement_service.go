@@ -0,0 +1,113 @@
+package service
+
+import (
+	"application/pkg/fabric"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+type AgreementService struct{}
+
+// AgreementPart 框架协议零件 (单价仅通过瞬态数据上链)
+type AgreementPart struct {
+	PartNumber        string  `json:"partNumber"`
+	Name              string  `json:"name"`
+	CommittedQuantity int     `json:"committedQuantity"`
+	Price             float64 `json:"price"`
+}
+
+// CreateFrameworkAgreement 主机厂发起框架协议
+func (s *AgreementService) CreateFrameworkAgreement(id string, manufacturerId string, parts []AgreementPart, validFrom time.Time, validTo time.Time) error {
+	publicParts := make([]map[string]interface{}, 0, len(parts))
+	itemPrices := make([]float64, 0, len(parts))
+	for _, part := range parts {
+		publicParts = append(publicParts, map[string]interface{}{"partNumber": part.PartNumber, "name": part.Name, "committedQuantity": part.CommittedQuantity})
+		itemPrices = append(itemPrices, part.Price)
+	}
+	partsBytes, _ := json.Marshal(publicParts)
+
+	salt, err := newSalt()
+	if err != nil {
+		return err
+	}
+	priceBytes, _ := json.Marshal(map[string]interface{}{
+		"itemPrices": itemPrices,
+		"salt":       salt,
+	})
+
+	_, err = fabric.Submit(OEM_ORG, "CreateFrameworkAgreement",
+		[]string{id, manufacturerId, string(partsBytes), validFrom.Format(time.RFC3339), validTo.Format(time.RFC3339)},
+		fabric.WithConfidential("price", priceBytes),
+		orderEndorsers(),
+	)
+	if err != nil {
+		return fmt.Errorf("发起框架协议失败：%s", fabric.ExtractErrorMessage(err))
+	}
+	return nil
+}
+
+// AcceptFrameworkAgreement 零部件厂确认框架协议
+func (s *AgreementService) AcceptFrameworkAgreement(id string) error {
+	_, err := fabric.Submit(MANUFACTURER_ORG, "AcceptFrameworkAgreement", []string{id}, orderEndorsers())
+	if err != nil {
+		return fmt.Errorf("确认框架协议失败：%s", fabric.ExtractErrorMessage(err))
+	}
+	return nil
+}
+
+// TerminateFrameworkAgreement 主机厂终止框架协议
+func (s *AgreementService) TerminateFrameworkAgreement(id string) error {
+	_, err := fabric.Submit(OEM_ORG, "TerminateFrameworkAgreement", []string{id}, orderEndorsers())
+	if err != nil {
+		return fmt.Errorf("终止框架协议失败：%s", fabric.ExtractErrorMessage(err))
+	}
+	return nil
+}
+
+// CallOffOrder 在框架协议下下达调用订单
+func (s *AgreementService) CallOffOrder(agreementId string, orderId string, items []OrderItem) error {
+	publicItems := make([]map[string]interface{}, 0, len(items))
+	for _, item := range items {
+		publicItems = append(publicItems, map[string]interface{}{"partNumber": item.PartNumber, "name": item.Name, "quantity": item.Quantity})
+	}
+	itemsBytes, _ := json.Marshal(publicItems)
+
+	_, err := fabric.Submit(OEM_ORG, "CallOffOrder", []string{agreementId, orderId, string(itemsBytes)}, orderEndorsers())
+	if err != nil {
+		return fmt.Errorf("下达调用订单失败：%s", fabric.ExtractErrorMessage(err))
+	}
+	return nil
+}
+
+// QueryFrameworkAgreement 查询框架协议详情
+func (s *AgreementService) QueryFrameworkAgreement(orgName string, id string) (map[string]interface{}, error) {
+	contract := fabric.GetContract(orgName)
+	result, err := contract.EvaluateTransaction("QueryFrameworkAgreement", id)
+	if err != nil {
+		return nil, fmt.Errorf("查询框架协议失败：%s", fabric.ExtractErrorMessage(err))
+	}
+
+	var agreement map[string]interface{}
+	if err := json.Unmarshal(result, &agreement); err != nil {
+		return nil, fmt.Errorf("解析框架协议数据失败：%v", err)
+	}
+
+	return agreement, nil
+}
+
+// QueryAgreementUtilisation 查询框架协议执行情况
+func (s *AgreementService) QueryAgreementUtilisation(orgName string, id string) (map[string]interface{}, error) {
+	contract := fabric.GetContract(orgName)
+	result, err := contract.EvaluateTransaction("QueryAgreementUtilisation", id)
+	if err != nil {
+		return nil, fmt.Errorf("查询协议执行情况失败：%s", fabric.ExtractErrorMessage(err))
+	}
+
+	var utilisation map[string]interface{}
+	if err := json.Unmarshal(result, &utilisation); err != nil {
+		return nil, fmt.Errorf("解析协议执行情况失败：%v", err)
+	}
+
+	return utilisation, nil
+}
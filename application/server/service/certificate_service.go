@@ -0,0 +1,110 @@
+package service
+
+import (
+	"application/pkg/fabric"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+type CertificateService struct{}
+
+// Certificate 质量证书登记参数
+type Certificate struct {
+	ID           string    `json:"id"`
+	OrderID      string    `json:"orderId,omitempty"`
+	BatchNo      string    `json:"batchNo,omitempty"`
+	Type         string    `json:"type"`
+	DocHash      string    `json:"docHash"`
+	Issuer       string    `json:"issuer"`
+	ValidFrom    time.Time `json:"validFrom"`
+	ValidUntil   time.Time `json:"validUntil"`
+	LabID        string    `json:"labId,omitempty"`
+	LabSignature string    `json:"labSignature,omitempty"`
+}
+
+// RegisterLabKey 平台方登记第三方实验室公钥
+func (s *CertificateService) RegisterLabKey(labId string, name string, publicKey string) error {
+	_, err := fabric.Submit(PLATFORM_ORG, "RegisterLabKey", []string{labId, name, publicKey})
+	if err != nil {
+		return fmt.Errorf("登记实验室公钥失败：%s", fabric.ExtractErrorMessage(err))
+	}
+	return nil
+}
+
+// QueryLabKey 查询第三方实验室公钥
+func (s *CertificateService) QueryLabKey(orgName string, labId string) (map[string]interface{}, error) {
+	contract := fabric.GetContract(orgName)
+	result, err := contract.EvaluateTransaction("QueryLabKey", labId)
+	if err != nil {
+		return nil, fmt.Errorf("查询实验室公钥失败：%s", fabric.ExtractErrorMessage(err))
+	}
+
+	var labKey map[string]interface{}
+	if err := json.Unmarshal(result, &labKey); err != nil {
+		return nil, fmt.Errorf("解析实验室公钥失败：%v", err)
+	}
+
+	return labKey, nil
+}
+
+// AddCertificate 厂商为订单或生产批次登记质量证书
+func (s *CertificateService) AddCertificate(cert Certificate) error {
+	certBytes, _ := json.Marshal(cert)
+	_, err := fabric.Submit(MANUFACTURER_ORG, "AddCertificate", []string{string(certBytes)})
+	if err != nil {
+		return fmt.Errorf("登记质量证书失败：%s", fabric.ExtractErrorMessage(err))
+	}
+	return nil
+}
+
+// SetRequiredCertificates 主机厂设置签收前必须具备的证书类型
+func (s *CertificateService) SetRequiredCertificates(orderId string, types []string) error {
+	typesBytes, _ := json.Marshal(types)
+	_, err := fabric.Submit(OEM_ORG, "SetRequiredCertificates", []string{orderId, string(typesBytes)}, orderEndorsers())
+	if err != nil {
+		return fmt.Errorf("设置证书要求失败：%s", fabric.ExtractErrorMessage(err))
+	}
+	return nil
+}
+
+// QueryCertificate 查询质量证书
+func (s *CertificateService) QueryCertificate(orgName string, id string) (map[string]interface{}, error) {
+	contract := fabric.GetContract(orgName)
+	result, err := contract.EvaluateTransaction("QueryCertificate", id)
+	if err != nil {
+		return nil, fmt.Errorf("查询质量证书失败：%s", fabric.ExtractErrorMessage(err))
+	}
+
+	var cert map[string]interface{}
+	if err := json.Unmarshal(result, &cert); err != nil {
+		return nil, fmt.Errorf("解析质量证书失败：%v", err)
+	}
+
+	return cert, nil
+}
+
+// QueryOrderCertificates 查询订单的全部质量证书 (含订单内各批次)
+func (s *CertificateService) QueryOrderCertificates(orgName string, orderId string) ([]map[string]interface{}, error) {
+	return s.queryCertificates(orgName, "QueryOrderCertificates", orderId)
+}
+
+// QueryBatchCertificates 查询生产批次的质量证书
+func (s *CertificateService) QueryBatchCertificates(orgName string, batchNo string) ([]map[string]interface{}, error) {
+	return s.queryCertificates(orgName, "QueryBatchCertificates", batchNo)
+}
+
+func (s *CertificateService) queryCertificates(orgName string, function string, arg string) ([]map[string]interface{}, error) {
+	contract := fabric.GetContract(orgName)
+	result, err := contract.EvaluateTransaction(function, arg)
+	if err != nil {
+		return nil, fmt.Errorf("查询质量证书失败：%s", fabric.ExtractErrorMessage(err))
+	}
+
+	var certs []map[string]interface{}
+	if err := json.Unmarshal(result, &certs); err != nil {
+		return nil, fmt.Errorf("解析质量证书失败：%v", err)
+	}
+
+	return certs, nil
+}
@@ -0,0 +1,108 @@
+package service
+
+import (
+	"application/pkg/docstore"
+	"application/pkg/fabric"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+type DocumentService struct{}
+
+// ErrDocumentHashMismatch 链下内容与链上锚定哈希不一致
+var ErrDocumentHashMismatch = errors.New("文档哈希校验失败")
+
+// Document 文档元数据 (与链上锚定记录对应)
+type Document struct {
+	ID          string `json:"id"`
+	Hash        string `json:"hash"`
+	FileName    string `json:"fileName"`
+	ContentType string `json:"contentType"`
+	Size        int64  `json:"size"`
+	Category    string `json:"category"`
+	RefType     string `json:"refType"`
+	RefID       string `json:"refId"`
+}
+
+// UploadDocument 先由链码预校验上传权限与元数据, 再按内容寻址保存文件并将哈希与元数据锚定上链
+func (s *DocumentService) UploadDocument(orgName string, doc Document, data []byte) (*Document, error) {
+	doc.Hash = docstore.HashContent(data)
+	doc.Size = int64(len(data))
+	if doc.ID == "" {
+		suffix, err := newSalt()
+		if err != nil {
+			return nil, err
+		}
+		doc.ID = fmt.Sprintf("DOC-%s-%s", doc.Hash[:16], suffix[:8])
+	}
+
+	docBytes, _ := json.Marshal(doc)
+	if _, err := fabric.GetContract(orgName).EvaluateTransaction("CheckDocumentAnchor", string(docBytes)); err != nil {
+		return nil, fmt.Errorf("锚定文档失败：%s", fabric.ExtractErrorMessage(err))
+	}
+
+	if err := docstore.GetStorage().Put(doc.Hash, data); err != nil {
+		return nil, fmt.Errorf("保存文档失败：%v", err)
+	}
+
+	_, err := fabric.Submit(orgName, "AnchorDocument", []string{string(docBytes)})
+	if err != nil {
+		return nil, fmt.Errorf("锚定文档失败：%s", fabric.ExtractErrorMessage(err))
+	}
+	return &doc, nil
+}
+
+// DownloadDocument 读取链上锚定记录, 按锚定哈希取回内容并重新校验后返回
+func (s *DocumentService) DownloadDocument(orgName string, id string) (*Document, []byte, error) {
+	contract := fabric.GetContract(orgName)
+	result, err := contract.EvaluateTransaction("QueryDocument", id)
+	if err != nil {
+		return nil, nil, fmt.Errorf("查询文档失败：%s", fabric.ExtractErrorMessage(err))
+	}
+	var doc Document
+	if err := json.Unmarshal(result, &doc); err != nil {
+		return nil, nil, fmt.Errorf("解析文档失败：%v", err)
+	}
+
+	data, err := docstore.GetStorage().Get(doc.Hash)
+	if err != nil {
+		return nil, nil, fmt.Errorf("读取文档失败：%v", err)
+	}
+	if docstore.HashContent(data) != doc.Hash {
+		return nil, nil, fmt.Errorf("%w：链下内容与链上锚定哈希 %s 不一致", ErrDocumentHashMismatch, doc.Hash)
+	}
+	return &doc, data, nil
+}
+
+// QueryDocument 查询文档锚定记录
+func (s *DocumentService) QueryDocument(orgName string, id string) (map[string]interface{}, error) {
+	contract := fabric.GetContract(orgName)
+	result, err := contract.EvaluateTransaction("QueryDocument", id)
+	if err != nil {
+		return nil, fmt.Errorf("查询文档失败：%s", fabric.ExtractErrorMessage(err))
+	}
+
+	var doc map[string]interface{}
+	if err := json.Unmarshal(result, &doc); err != nil {
+		return nil, fmt.Errorf("解析文档失败：%v", err)
+	}
+
+	return doc, nil
+}
+
+// QueryDocuments 查询订单或物流单的全部文档
+func (s *DocumentService) QueryDocuments(orgName string, refType string, refId string) ([]map[string]interface{}, error) {
+	contract := fabric.GetContract(orgName)
+	result, err := contract.EvaluateTransaction("QueryDocuments", refType, refId)
+	if err != nil {
+		return nil, fmt.Errorf("查询文档列表失败：%s", fabric.ExtractErrorMessage(err))
+	}
+
+	var docs []map[string]interface{}
+	if err := json.Unmarshal(result, &docs); err != nil {
+		return nil, fmt.Errorf("解析文档列表失败：%v", err)
+	}
+
+	return docs, nil
+}
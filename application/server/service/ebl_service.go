@@ -0,0 +1,61 @@
+package service
+
+import (
+	"application/pkg/fabric"
+	"encoding/json"
+	"fmt"
+)
+
+type EBLService struct{}
+
+// IssueBillOfLading 承运商基于已生效运单签发电子提单
+func (s *EBLService) IssueBillOfLading(orgName string, id string, waybillId string) error {
+	_, err := fabric.Submit(orgName, "IssueBillOfLading", []string{id, waybillId})
+	if err != nil {
+		return fmt.Errorf("签发电子提单失败：%s", fabric.ExtractErrorMessage(err))
+	}
+	return nil
+}
+
+// TransferTitle 当前持有人背书转让提单
+func (s *EBLService) TransferTitle(orgName string, id string, newHolderId string, note string) error {
+	_, err := fabric.Submit(orgName, "TransferTitle", []string{id, newHolderId, note})
+	if err != nil {
+		return fmt.Errorf("转让提单失败：%s", fabric.ExtractErrorMessage(err))
+	}
+	return nil
+}
+
+// SurrenderBillOfLading 当前持有人交回提单
+func (s *EBLService) SurrenderBillOfLading(orgName string, id string) error {
+	_, err := fabric.Submit(orgName, "SurrenderBillOfLading", []string{id})
+	if err != nil {
+		return fmt.Errorf("交回提单失败：%s", fabric.ExtractErrorMessage(err))
+	}
+	return nil
+}
+
+// AcceptBillOfLadingSurrender 承运商确认持有人在货物到达目的地前交回的提单
+func (s *EBLService) AcceptBillOfLadingSurrender(orgName string, id string) error {
+	_, err := fabric.Submit(orgName, "AcceptBillOfLadingSurrender", []string{id})
+	if err != nil {
+		return fmt.Errorf("确认交回提单失败：%s", fabric.ExtractErrorMessage(err))
+	}
+	return nil
+}
+
+// QueryBillOfLading 查询电子提单
+func (s *EBLService) QueryBillOfLading(orgName string, id string) (map[string]interface{}, error) {
+	contract := fabric.GetContract(orgName)
+	result, err := contract.EvaluateTransaction("QueryBillOfLading", id)
+	if err != nil {
+		return nil, fmt.Errorf("查询电子提单失败：%s", fabric.ExtractErrorMessage(err))
+	}
+
+	var ebl map[string]interface{}
+	if err := json.Unmarshal(result, &ebl); err != nil {
+		return nil, fmt.Errorf("解析电子提单失败：%v", err)
+	}
+
+	return ebl, nil
+}
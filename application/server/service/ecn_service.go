@@ -0,0 +1,71 @@
+package service
+
+import (
+	"application/pkg/fabric"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+type ECNService struct{}
+
+// EngineeringChange 工程变更通知发布参数
+type EngineeringChange struct {
+	ID               string   `json:"id"`
+	PartNumbers      []string `json:"partNumbers"`
+	DrawingRevision  string   `json:"drawingRevision"`
+	Description      string   `json:"description"`
+	DocumentID       string   `json:"documentId,omitempty"`
+	AffectedOrderIDs []string `json:"affectedOrderIds"`
+}
+
+// IssueECN 主机厂发布工程变更通知
+func (s *ECNService) IssueECN(ecn EngineeringChange) error {
+	ecnBytes, _ := json.Marshal(ecn)
+	_, err := fabric.Submit(OEM_ORG, "IssueECN", []string{string(ecnBytes)}, orderEndorsers())
+	if err != nil {
+		return fmt.Errorf("发布工程变更失败：%s", fabric.ExtractErrorMessage(err))
+	}
+	return nil
+}
+
+// AcknowledgeECN 厂商确认工程变更并承诺生效日期
+func (s *ECNService) AcknowledgeECN(id string, manufacturerId string, effectivityDate time.Time, note string) error {
+	_, err := fabric.Submit(MANUFACTURER_ORG, "AcknowledgeECN", []string{id, manufacturerId, effectivityDate.Format(time.RFC3339), note}, orderEndorsers())
+	if err != nil {
+		return fmt.Errorf("确认工程变更失败：%s", fabric.ExtractErrorMessage(err))
+	}
+	return nil
+}
+
+// QueryECN 查询工程变更通知
+func (s *ECNService) QueryECN(orgName string, id string) (map[string]interface{}, error) {
+	contract := fabric.GetContract(orgName)
+	result, err := contract.EvaluateTransaction("QueryECN", id)
+	if err != nil {
+		return nil, fmt.Errorf("查询工程变更失败：%s", fabric.ExtractErrorMessage(err))
+	}
+
+	var ecn map[string]interface{}
+	if err := json.Unmarshal(result, &ecn); err != nil {
+		return nil, fmt.Errorf("解析工程变更失败：%v", err)
+	}
+
+	return ecn, nil
+}
+
+// QueryOrderECNs 查询影响订单的全部工程变更通知
+func (s *ECNService) QueryOrderECNs(orgName string, orderId string) ([]map[string]interface{}, error) {
+	contract := fabric.GetContract(orgName)
+	result, err := contract.EvaluateTransaction("QueryOrderECNs", orderId)
+	if err != nil {
+		return nil, fmt.Errorf("查询订单工程变更失败：%s", fabric.ExtractErrorMessage(err))
+	}
+
+	var ecns []map[string]interface{}
+	if err := json.Unmarshal(result, &ecns); err != nil {
+		return nil, fmt.Errorf("解析工程变更失败：%v", err)
+	}
+
+	return ecns, nil
+}
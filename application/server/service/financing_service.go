@@ -0,0 +1,141 @@
+package service
+
+import (
+	"application/pkg/fabric"
+	"encoding/json"
+	"fmt"
+	"strconv"
+)
+
+type FinancingService struct{}
+
+// CreateReceivable 厂商基于已匹配发票或已签收订单创建应收账款, 票面金额随机盐走瞬态数据
+func (s *FinancingService) CreateReceivable(id string, sourceType string, sourceId string) error {
+	salt, err := newSalt()
+	if err != nil {
+		return err
+	}
+	amountBytes, _ := json.Marshal(map[string]interface{}{"salt": salt})
+
+	_, err = fabric.Submit(MANUFACTURER_ORG, "CreateReceivable", []string{id, sourceType, sourceId},
+		fabric.WithConfidential("receivable", amountBytes),
+		orderEndorsers(),
+	)
+	if err != nil {
+		return fmt.Errorf("创建应收账款失败：%s", fabric.ExtractErrorMessage(err))
+	}
+	return nil
+}
+
+// ApplyFinancing 厂商以应收账款申请融资
+func (s *FinancingService) ApplyFinancing(id string, receivableId string, bankId string, requestAmount float64) error {
+	_, err := fabric.Submit(MANUFACTURER_ORG, "ApplyFinancing",
+		[]string{id, receivableId, bankId, strconv.FormatFloat(requestAmount, 'f', -1, 64)},
+		orderEndorsers(),
+	)
+	if err != nil {
+		return fmt.Errorf("申请融资失败：%s", fabric.ExtractErrorMessage(err))
+	}
+	return nil
+}
+
+// ApproveFinancing 银行审批通过
+func (s *FinancingService) ApproveFinancing(orgName string, id string) error {
+	_, err := fabric.Submit(orgName, "ApproveFinancing", []string{id})
+	if err != nil {
+		return fmt.Errorf("审批融资失败：%s", fabric.ExtractErrorMessage(err))
+	}
+	return nil
+}
+
+// RejectFinancing 银行拒绝融资申请
+func (s *FinancingService) RejectFinancing(orgName string, id string, reason string) error {
+	_, err := fabric.Submit(orgName, "RejectFinancing", []string{id, reason})
+	if err != nil {
+		return fmt.Errorf("拒绝融资失败：%s", fabric.ExtractErrorMessage(err))
+	}
+	return nil
+}
+
+// ConfirmRepayment 银行确认还款, 释放应收账款锁定
+func (s *FinancingService) ConfirmRepayment(orgName string, id string) error {
+	_, err := fabric.Submit(orgName, "ConfirmRepayment", []string{id})
+	if err != nil {
+		return fmt.Errorf("确认还款失败：%s", fabric.ExtractErrorMessage(err))
+	}
+	return nil
+}
+
+// QueryReceivable 查询应收账款
+func (s *FinancingService) QueryReceivable(orgName string, id string) (map[string]interface{}, error) {
+	contract := fabric.GetContract(orgName)
+	result, err := contract.EvaluateTransaction("QueryReceivable", id)
+	if err != nil {
+		return nil, fmt.Errorf("查询应收账款失败：%s", fabric.ExtractErrorMessage(err))
+	}
+
+	var receivable map[string]interface{}
+	if err := json.Unmarshal(result, &receivable); err != nil {
+		return nil, fmt.Errorf("解析应收账款失败：%v", err)
+	}
+
+	return receivable, nil
+}
+
+// QueryReceivableAmount 查询应收账款票面金额 (仅交易双方)
+func (s *FinancingService) QueryReceivableAmount(orgName string, id string) (map[string]interface{}, error) {
+	contract := fabric.GetContract(orgName)
+	result, err := contract.EvaluateTransaction("QueryReceivableAmount", id)
+	if err != nil {
+		return nil, fmt.Errorf("查询票面金额失败：%s", fabric.ExtractErrorMessage(err))
+	}
+
+	var amount map[string]interface{}
+	if err := json.Unmarshal(result, &amount); err != nil {
+		return nil, fmt.Errorf("解析票面金额失败：%v", err)
+	}
+
+	return amount, nil
+}
+
+// VerifyReceivableFaceValue 校验厂商披露的票面金额与随机盐是否与链上哈希一致
+func (s *FinancingService) VerifyReceivableFaceValue(orgName string, id string, faceValue float64, salt string) (bool, error) {
+	contract := fabric.GetContract(orgName)
+	result, err := contract.EvaluateTransaction("VerifyReceivableFaceValue", id, strconv.FormatFloat(faceValue, 'f', -1, 64), salt)
+	if err != nil {
+		return false, fmt.Errorf("校验票面金额失败：%s", fabric.ExtractErrorMessage(err))
+	}
+	return string(result) == "true", nil
+}
+
+// QueryFinancing 查询融资申请
+func (s *FinancingService) QueryFinancing(orgName string, id string) (map[string]interface{}, error) {
+	contract := fabric.GetContract(orgName)
+	result, err := contract.EvaluateTransaction("QueryFinancing", id)
+	if err != nil {
+		return nil, fmt.Errorf("查询融资申请失败：%s", fabric.ExtractErrorMessage(err))
+	}
+
+	var application map[string]interface{}
+	if err := json.Unmarshal(result, &application); err != nil {
+		return nil, fmt.Errorf("解析融资申请失败：%v", err)
+	}
+
+	return application, nil
+}
+
+// QueryBankFinancings 银行查询提交给自己的融资申请
+func (s *FinancingService) QueryBankFinancings(orgName string) ([]map[string]interface{}, error) {
+	contract := fabric.GetContract(orgName)
+	result, err := contract.EvaluateTransaction("QueryBankFinancings")
+	if err != nil {
+		return nil, fmt.Errorf("查询融资申请列表失败：%s", fabric.ExtractErrorMessage(err))
+	}
+
+	var applications []map[string]interface{}
+	if err := json.Unmarshal(result, &applications); err != nil {
+		return nil, fmt.Errorf("解析融资申请失败：%v", err)
+	}
+
+	return applications, nil
+}
@@ -0,0 +1,96 @@
+package service
+
+import (
+	"application/config"
+	"application/pkg/fabric"
+	"encoding/json"
+	"fmt"
+	"strconv"
+)
+
+type ForecastService struct{}
+
+// manufacturerMSPID 本服务所代表厂商的 MSP ID (预测私有集合按厂商划分)
+func manufacturerMSPID() string {
+	return config.GlobalConfig.Fabric.Organizations[MANUFACTURER_ORG].MSPID
+}
+
+// PublishForecast 主机厂发布零件 12 周滚动需求预测, 预测数据走瞬态数据写入厂商私有集合
+func (s *ForecastService) PublishForecast(manufacturerId string, partNumber string, startWeek string, demand []int) error {
+	forecastBytes, _ := json.Marshal(map[string]interface{}{
+		"startWeek": startWeek,
+		"demand":    demand,
+	})
+	_, err := fabric.Submit(OEM_ORG, "PublishForecast", []string{manufacturerId, partNumber},
+		fabric.WithConfidential("forecast", forecastBytes),
+		orderEndorsers(),
+	)
+	if err != nil {
+		return fmt.Errorf("发布需求预测失败：%s", fabric.ExtractErrorMessage(err))
+	}
+	return nil
+}
+
+// CommitCapacity 厂商按周承诺产能 (manufacturerId 为空时取本厂商)
+func (s *ForecastService) CommitCapacity(manufacturerId string, partNumber string, version int, committed []int) error {
+	if manufacturerId == "" {
+		manufacturerId = manufacturerMSPID()
+	}
+	commitmentBytes, _ := json.Marshal(map[string]interface{}{
+		"version":   version,
+		"committed": committed,
+	})
+	_, err := fabric.Submit(MANUFACTURER_ORG, "CommitForecastCapacity", []string{manufacturerId, partNumber},
+		fabric.WithConfidential("commitment", commitmentBytes),
+		orderEndorsers(),
+	)
+	if err != nil {
+		return fmt.Errorf("承诺产能失败：%s", fabric.ExtractErrorMessage(err))
+	}
+	return nil
+}
+
+// CreateOrderFromForecast 主机厂将已承诺的预测周转为订单, 单价与 CreateOrder 一样走瞬态数据
+func (s *ForecastService) CreateOrderFromForecast(id string, manufacturerId string, partNumber string, weekStart string, quantity int, price float64) error {
+	_, priceBytes, err := splitOrderItems([]OrderItem{{PartNumber: partNumber, Quantity: quantity, Price: price}})
+	if err != nil {
+		return err
+	}
+
+	_, err = fabric.Submit(OEM_ORG, "CreateOrderFromForecast", []string{id, manufacturerId, partNumber, weekStart, strconv.Itoa(quantity)},
+		fabric.WithConfidential("price", priceBytes),
+		orderEndorsers(),
+	)
+	if err != nil {
+		return fmt.Errorf("预测转订单失败：%s", fabric.ExtractErrorMessage(err))
+	}
+	return nil
+}
+
+// QueryForecast 查询零件需求预测 (厂商一侧 manufacturerId 为空时取本厂商)
+func (s *ForecastService) QueryForecast(orgName string, manufacturerId string, partNumber string) (map[string]interface{}, error) {
+	return s.evaluate(orgName, "QueryForecast", manufacturerId, partNumber)
+}
+
+// CompareForecast 对比预测需求与承诺产能
+func (s *ForecastService) CompareForecast(orgName string, manufacturerId string, partNumber string) (map[string]interface{}, error) {
+	return s.evaluate(orgName, "CompareForecast", manufacturerId, partNumber)
+}
+
+func (s *ForecastService) evaluate(orgName string, function string, manufacturerId string, partNumber string) (map[string]interface{}, error) {
+	if manufacturerId == "" {
+		manufacturerId = manufacturerMSPID()
+	}
+	contract := fabric.GetContract(orgName)
+	result, err := contract.EvaluateTransaction(function, manufacturerId, partNumber)
+	if err != nil {
+		return nil, fmt.Errorf("查询需求预测失败：%s", fabric.ExtractErrorMessage(err))
+	}
+
+	var forecast map[string]interface{}
+	if err := json.Unmarshal(result, &forecast); err != nil {
+		return nil, fmt.Errorf("解析需求预测失败：%v", err)
+	}
+
+	return forecast, nil
+}
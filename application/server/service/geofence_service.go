@@ -0,0 +1,90 @@
+package service
+
+import (
+	"application/pkg/fabric"
+	"encoding/json"
+	"fmt"
+)
+
+type GeofenceService struct{}
+
+// Geofence 地理围栏
+type Geofence struct {
+	ID           string  `json:"id"`
+	Name         string  `json:"name"`
+	Type         string  `json:"type"`
+	Latitude     float64 `json:"latitude"`
+	Longitude    float64 `json:"longitude"`
+	RadiusMeters float64 `json:"radiusMeters"`
+	AutoDeliver  bool    `json:"autoDeliver"`
+}
+
+// CreateGeofence 平台方登记地理围栏
+func (s *GeofenceService) CreateGeofence(fence Geofence) error {
+	fenceBytes, _ := json.Marshal(fence)
+	_, err := fabric.Submit(PLATFORM_ORG, "CreateGeofence", []string{string(fenceBytes)})
+	if err != nil {
+		return fmt.Errorf("登记地理围栏失败：%s", fabric.ExtractErrorMessage(err))
+	}
+	return nil
+}
+
+// UpdateGeofence 平台方修改地理围栏
+func (s *GeofenceService) UpdateGeofence(fence Geofence) error {
+	fenceBytes, _ := json.Marshal(fence)
+	_, err := fabric.Submit(PLATFORM_ORG, "UpdateGeofence", []string{string(fenceBytes)})
+	if err != nil {
+		return fmt.Errorf("修改地理围栏失败：%s", fabric.ExtractErrorMessage(err))
+	}
+	return nil
+}
+
+// DeleteGeofence 平台方删除地理围栏
+func (s *GeofenceService) DeleteGeofence(id string) error {
+	_, err := fabric.Submit(PLATFORM_ORG, "DeleteGeofence", []string{id})
+	if err != nil {
+		return fmt.Errorf("删除地理围栏失败：%s", fabric.ExtractErrorMessage(err))
+	}
+	return nil
+}
+
+// QueryGeofence 查询地理围栏
+func (s *GeofenceService) QueryGeofence(orgName string, id string) (map[string]interface{}, error) {
+	contract := fabric.GetContract(orgName)
+	result, err := contract.EvaluateTransaction("QueryGeofence", id)
+	if err != nil {
+		return nil, fmt.Errorf("查询地理围栏失败：%s", fabric.ExtractErrorMessage(err))
+	}
+
+	var fence map[string]interface{}
+	if err := json.Unmarshal(result, &fence); err != nil {
+		return nil, fmt.Errorf("解析地理围栏失败：%v", err)
+	}
+
+	return fence, nil
+}
+
+// QueryGeofenceList 查询全部地理围栏
+func (s *GeofenceService) QueryGeofenceList(orgName string) ([]map[string]interface{}, error) {
+	contract := fabric.GetContract(orgName)
+	result, err := contract.EvaluateTransaction("QueryGeofenceList")
+	if err != nil {
+		return nil, fmt.Errorf("查询地理围栏列表失败：%s", fabric.ExtractErrorMessage(err))
+	}
+
+	var fences []map[string]interface{}
+	if err := json.Unmarshal(result, &fences); err != nil {
+		return nil, fmt.Errorf("解析地理围栏失败：%v", err)
+	}
+
+	return fences, nil
+}
+
+// SetShipmentDestination 设置物流单目的地围栏
+func (s *GeofenceService) SetShipmentDestination(orgName string, shipmentId string, fenceId string) error {
+	_, err := fabric.Submit(orgName, "SetShipmentDestination", []string{shipmentId, fenceId})
+	if err != nil {
+		return fmt.Errorf("设置目的地失败：%s", fabric.ExtractErrorMessage(err))
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+package service
+
+import (
+	"application/pkg/fabric"
+	"encoding/json"
+	"fmt"
+)
+
+type InvoiceService struct{}
+
+// IssueInvoice 厂商开具发票, 开票单价与随机盐走瞬态数据写入私有集合
+func (s *InvoiceService) IssueInvoice(id string, orderId string, quantities []int, unitPrices []float64) error {
+	salt, err := newSalt()
+	if err != nil {
+		return err
+	}
+	quantitiesBytes, _ := json.Marshal(quantities)
+	amountBytes, _ := json.Marshal(map[string]interface{}{
+		"unitPrices": unitPrices,
+		"salt":       salt,
+	})
+
+	_, err = fabric.Submit(MANUFACTURER_ORG, "IssueInvoice",
+		[]string{id, orderId, string(quantitiesBytes)},
+		fabric.WithConfidential("invoice", amountBytes),
+		orderEndorsers(),
+	)
+	if err != nil {
+		return fmt.Errorf("开具发票失败：%s", fabric.ExtractErrorMessage(err))
+	}
+	return nil
+}
+
+// ApproveInvoice 主机厂批准发票付款
+func (s *InvoiceService) ApproveInvoice(id string) error {
+	_, err := fabric.Submit(OEM_ORG, "ApproveInvoice", []string{id}, orderEndorsers())
+	if err != nil {
+		return fmt.Errorf("批准发票失败：%s", fabric.ExtractErrorMessage(err))
+	}
+	return nil
+}
+
+// RejectInvoice 主机厂拒绝发票
+func (s *InvoiceService) RejectInvoice(id string, reason string) error {
+	_, err := fabric.Submit(OEM_ORG, "RejectInvoice", []string{id, reason}, orderEndorsers())
+	if err != nil {
+		return fmt.Errorf("拒绝发票失败：%s", fabric.ExtractErrorMessage(err))
+	}
+	return nil
+}
+
+// QueryInvoice 查询发票 (含三单匹配结果, 不含金额)
+func (s *InvoiceService) QueryInvoice(orgName string, id string) (map[string]interface{}, error) {
+	contract := fabric.GetContract(orgName)
+	result, err := contract.EvaluateTransaction("QueryInvoice", id)
+	if err != nil {
+		return nil, fmt.Errorf("查询发票失败：%s", fabric.ExtractErrorMessage(err))
+	}
+
+	var invoice map[string]interface{}
+	if err := json.Unmarshal(result, &invoice); err != nil {
+		return nil, fmt.Errorf("解析发票失败：%v", err)
+	}
+
+	return invoice, nil
+}
+
+// QueryInvoiceAmount 查询发票金额 (仅交易双方)
+func (s *InvoiceService) QueryInvoiceAmount(orgName string, id string) (map[string]interface{}, error) {
+	contract := fabric.GetContract(orgName)
+	result, err := contract.EvaluateTransaction("QueryInvoiceAmount", id)
+	if err != nil {
+		return nil, fmt.Errorf("查询发票金额失败：%s", fabric.ExtractErrorMessage(err))
+	}
+
+	var amount map[string]interface{}
+	if err := json.Unmarshal(result, &amount); err != nil {
+		return nil, fmt.Errorf("解析发票金额失败：%v", err)
+	}
+
+	return amount, nil
+}
@@ -0,0 +1,135 @@
+package service
+
+import (
+	"application/pkg/fabric"
+	"encoding/json"
+	"fmt"
+)
+
+type OnboardingService struct{}
+
+// OnboardingDocument 准入材料
+type OnboardingDocument struct {
+	Category   string `json:"category"`
+	Name       string `json:"name"`
+	Hash       string `json:"hash"`
+	DocumentID string `json:"documentId,omitempty"`
+}
+
+// OnboardingApplication 供应商准入申请
+type OnboardingApplication struct {
+	ID             string               `json:"id"`
+	ParticipantID  string               `json:"participantId"`
+	MSPID          string               `json:"mspId"`
+	CompanyName    string               `json:"companyName"`
+	RegistrationNo string               `json:"registrationNo"`
+	Address        string               `json:"address"`
+	ContactName    string               `json:"contactName"`
+	ContactEmail   string               `json:"contactEmail"`
+	Capabilities   []string             `json:"capabilities"`
+	Documents      []OnboardingDocument `json:"documents"`
+}
+
+// SubmitApplication 提交供应商准入申请 (主机厂推荐或平台方代录)
+func (s *OnboardingService) SubmitApplication(orgName string, app OnboardingApplication) error {
+	appBytes, _ := json.Marshal(app)
+	_, err := fabric.Submit(orgName, "SubmitOnboardingApplication", []string{string(appBytes)})
+	if err != nil {
+		return fmt.Errorf("提交准入申请失败：%s", fabric.ExtractErrorMessage(err))
+	}
+	return nil
+}
+
+// ResubmitApplication 补充材料后重新提交准入申请
+func (s *OnboardingService) ResubmitApplication(orgName string, id string, documents []OnboardingDocument, comment string) error {
+	documentsBytes, _ := json.Marshal(documents)
+	_, err := fabric.Submit(orgName, "ResubmitOnboardingApplication", []string{id, string(documentsBytes), comment})
+	if err != nil {
+		return fmt.Errorf("重新提交准入申请失败：%s", fabric.ExtractErrorMessage(err))
+	}
+	return nil
+}
+
+// StartReview 平台方开始审核
+func (s *OnboardingService) StartReview(id string) error {
+	_, err := fabric.Submit(PLATFORM_ORG, "StartOnboardingReview", []string{id})
+	if err != nil {
+		return fmt.Errorf("开始审核失败：%s", fabric.ExtractErrorMessage(err))
+	}
+	return nil
+}
+
+// RequestChanges 平台方要求补充材料
+func (s *OnboardingService) RequestChanges(id string, comment string) error {
+	_, err := fabric.Submit(PLATFORM_ORG, "RequestOnboardingChanges", []string{id, comment})
+	if err != nil {
+		return fmt.Errorf("要求补充材料失败：%s", fabric.ExtractErrorMessage(err))
+	}
+	return nil
+}
+
+// ApproveApplication 平台方批准准入申请并激活参与方
+func (s *OnboardingService) ApproveApplication(id string, comment string) error {
+	_, err := fabric.Submit(PLATFORM_ORG, "ApproveOnboardingApplication", []string{id, comment})
+	if err != nil {
+		return fmt.Errorf("批准准入申请失败：%s", fabric.ExtractErrorMessage(err))
+	}
+	return nil
+}
+
+// RejectApplication 平台方拒绝准入申请
+func (s *OnboardingService) RejectApplication(id string, reason string) error {
+	_, err := fabric.Submit(PLATFORM_ORG, "RejectOnboardingApplication", []string{id, reason})
+	if err != nil {
+		return fmt.Errorf("拒绝准入申请失败：%s", fabric.ExtractErrorMessage(err))
+	}
+	return nil
+}
+
+// QueryApplication 查询准入申请 (含审计记录)
+func (s *OnboardingService) QueryApplication(orgName string, id string) (map[string]interface{}, error) {
+	contract := fabric.GetContract(orgName)
+	result, err := contract.EvaluateTransaction("QueryOnboardingApplication", id)
+	if err != nil {
+		return nil, fmt.Errorf("查询准入申请失败：%s", fabric.ExtractErrorMessage(err))
+	}
+
+	var app map[string]interface{}
+	if err := json.Unmarshal(result, &app); err != nil {
+		return nil, fmt.Errorf("解析准入申请失败：%v", err)
+	}
+
+	return app, nil
+}
+
+// QueryApplications 按状态查询准入申请
+func (s *OnboardingService) QueryApplications(status string) ([]map[string]interface{}, error) {
+	contract := fabric.GetContract(PLATFORM_ORG)
+	result, err := contract.EvaluateTransaction("QueryOnboardingApplications", status)
+	if err != nil {
+		return nil, fmt.Errorf("查询准入申请列表失败：%s", fabric.ExtractErrorMessage(err))
+	}
+
+	var apps []map[string]interface{}
+	if err := json.Unmarshal(result, &apps); err != nil {
+		return nil, fmt.Errorf("解析准入申请列表失败：%v", err)
+	}
+
+	return apps, nil
+}
+
+// QueryParticipant 查询已准入参与方
+func (s *OnboardingService) QueryParticipant(orgName string, id string) (map[string]interface{}, error) {
+	contract := fabric.GetContract(orgName)
+	result, err := contract.EvaluateTransaction("QueryParticipant", id)
+	if err != nil {
+		return nil, fmt.Errorf("查询参与方失败：%s", fabric.ExtractErrorMessage(err))
+	}
+
+	var participant map[string]interface{}
+	if err := json.Unmarshal(result, &participant); err != nil {
+		return nil, fmt.Errorf("解析参与方失败：%v", err)
+	}
+
+	return participant, nil
+}
@@ -0,0 +1,79 @@
+package service
+
+import (
+	"application/pkg/fabric"
+	"encoding/json"
+	"fmt"
+)
+
+type PartService struct{}
+
+// Part 零件主数据
+type Part struct {
+	PartNumber            string   `json:"partNumber"`
+	Description           string   `json:"description"`
+	UnitOfMeasure         string   `json:"unitOfMeasure"`
+	DrawingRevision       string   `json:"drawingRevision"`
+	ApprovedManufacturers []string `json:"approvedManufacturers"`
+}
+
+// CreatePart 主机厂新增零件
+func (s *PartService) CreatePart(part Part) error {
+	partBytes, _ := json.Marshal(part)
+	_, err := fabric.Submit(OEM_ORG, "CreatePart", []string{string(partBytes)})
+	if err != nil {
+		return fmt.Errorf("新增零件失败：%s", fabric.ExtractErrorMessage(err))
+	}
+	return nil
+}
+
+// UpdatePart 主机厂修改零件
+func (s *PartService) UpdatePart(part Part) error {
+	partBytes, _ := json.Marshal(part)
+	_, err := fabric.Submit(OEM_ORG, "UpdatePart", []string{string(partBytes)})
+	if err != nil {
+		return fmt.Errorf("修改零件失败：%s", fabric.ExtractErrorMessage(err))
+	}
+	return nil
+}
+
+// ObsoletePart 主机厂停用零件
+func (s *PartService) ObsoletePart(partNumber string) error {
+	_, err := fabric.Submit(OEM_ORG, "ObsoletePart", []string{partNumber})
+	if err != nil {
+		return fmt.Errorf("停用零件失败：%s", fabric.ExtractErrorMessage(err))
+	}
+	return nil
+}
+
+// QueryPart 查询零件
+func (s *PartService) QueryPart(orgName string, partNumber string) (map[string]interface{}, error) {
+	contract := fabric.GetContract(orgName)
+	result, err := contract.EvaluateTransaction("QueryPart", partNumber)
+	if err != nil {
+		return nil, fmt.Errorf("查询零件失败：%s", fabric.ExtractErrorMessage(err))
+	}
+
+	var part map[string]interface{}
+	if err := json.Unmarshal(result, &part); err != nil {
+		return nil, fmt.Errorf("解析零件数据失败：%v", err)
+	}
+
+	return part, nil
+}
+
+// SearchParts 分页检索零件
+func (s *PartService) SearchParts(orgName string, keyword string, manufacturerId string, pageSize int32, bookmark string) (map[string]interface{}, error) {
+	contract := fabric.GetContract(orgName)
+	result, err := contract.EvaluateTransaction("SearchParts", keyword, manufacturerId, fmt.Sprintf("%d", pageSize), bookmark)
+	if err != nil {
+		return nil, fmt.Errorf("检索零件失败：%s", fabric.ExtractErrorMessage(err))
+	}
+
+	var queryResult map[string]interface{}
+	if err := json.Unmarshal(result, &queryResult); err != nil {
+		return nil, fmt.Errorf("解析查询结果失败：%v", err)
+	}
+
+	return queryResult, nil
+}
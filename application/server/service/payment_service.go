@@ -0,0 +1,82 @@
+package service
+
+import (
+	"application/pkg/fabric"
+	"encoding/json"
+	"fmt"
+)
+
+type PaymentService struct{}
+
+// PaymentMilestone 付款节点
+type PaymentMilestone struct {
+	Trigger string  `json:"trigger"`
+	Percent float64 `json:"percent"`
+}
+
+// SetPaymentSchedule 主机厂设置订单付款计划
+func (s *PaymentService) SetPaymentSchedule(orderId string, milestones []PaymentMilestone) error {
+	milestonesBytes, _ := json.Marshal(milestones)
+	_, err := fabric.Submit(OEM_ORG, "SetPaymentSchedule", []string{orderId, string(milestonesBytes)}, orderEndorsers())
+	if err != nil {
+		return fmt.Errorf("设置付款计划失败：%s", fabric.ExtractErrorMessage(err))
+	}
+	return nil
+}
+
+// RecordPayment 主机厂登记付款, 流水号与金额走瞬态数据写入私有集合
+func (s *PaymentService) RecordPayment(orderId string, reference string, amount float64) error {
+	paymentBytes, _ := json.Marshal(map[string]interface{}{
+		"reference": reference,
+		"amount":    amount,
+	})
+	_, err := fabric.Submit(OEM_ORG, "RecordPayment", []string{orderId},
+		fabric.WithConfidential("payment", paymentBytes),
+		orderEndorsers(),
+	)
+	if err != nil {
+		return fmt.Errorf("登记付款失败：%s", fabric.ExtractErrorMessage(err))
+	}
+	return nil
+}
+
+// ConfirmPaymentReceipt 厂商确认付款到账
+func (s *PaymentService) ConfirmPaymentReceipt(orderId string, reference string) error {
+	_, err := fabric.Submit(MANUFACTURER_ORG, "ConfirmPaymentReceipt", []string{orderId, reference}, orderEndorsers())
+	if err != nil {
+		return fmt.Errorf("确认到账失败：%s", fabric.ExtractErrorMessage(err))
+	}
+	return nil
+}
+
+// QueryOrderBalance 查询订单结算余额
+func (s *PaymentService) QueryOrderBalance(orgName string, orderId string) (map[string]interface{}, error) {
+	contract := fabric.GetContract(orgName)
+	result, err := contract.EvaluateTransaction("QueryOrderBalance", orderId)
+	if err != nil {
+		return nil, fmt.Errorf("查询结算余额失败：%s", fabric.ExtractErrorMessage(err))
+	}
+
+	var balance map[string]interface{}
+	if err := json.Unmarshal(result, &balance); err != nil {
+		return nil, fmt.Errorf("解析结算余额失败：%v", err)
+	}
+
+	return balance, nil
+}
+
+// QueryCounterpartyBalance 查询与交易对手的结算汇总
+func (s *PaymentService) QueryCounterpartyBalance(orgName string, counterpartyId string) (map[string]interface{}, error) {
+	contract := fabric.GetContract(orgName)
+	result, err := contract.EvaluateTransaction("QueryCounterpartyBalance", counterpartyId)
+	if err != nil {
+		return nil, fmt.Errorf("查询交易对手结算汇总失败：%s", fabric.ExtractErrorMessage(err))
+	}
+
+	var balance map[string]interface{}
+	if err := json.Unmarshal(result, &balance); err != nil {
+		return nil, fmt.Errorf("解析结算汇总失败：%v", err)
+	}
+
+	return balance, nil
+}
@@ -0,0 +1,148 @@
+package service
+
+import (
+	"application/pkg/fabric"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+type RFQService struct{}
+
+// RFQItem 询价零件规格
+type RFQItem struct {
+	PartNumber string `json:"partNumber"`
+	Name       string `json:"name"`
+	Quantity   int    `json:"quantity"`
+	Spec       string `json:"spec"`
+}
+
+// BidContent 投标内容, 字段顺序需与链码一致, 密封哈希按其 JSON 字节计算
+type BidContent struct {
+	RFQID          string    `json:"rfqId"`
+	ManufacturerID string    `json:"manufacturerId"`
+	ItemPrices     []float64 `json:"itemPrices"`
+	Salt           string    `json:"salt"`
+}
+
+// CreateRFQ 主机厂发布询价单
+func (s *RFQService) CreateRFQ(id string, title string, items []RFQItem, manufacturers []string, deadline time.Time) error {
+	itemsBytes, _ := json.Marshal(items)
+	manufacturersBytes, _ := json.Marshal(manufacturers)
+	_, err := fabric.Submit(OEM_ORG, "CreateRFQ", []string{id, title, string(itemsBytes), string(manufacturersBytes), deadline.Format(time.RFC3339)})
+	if err != nil {
+		return fmt.Errorf("发布询价单失败：%s", fabric.ExtractErrorMessage(err))
+	}
+	return nil
+}
+
+// SubmitBid 零部件厂提交密封标, 返回投标哈希与随机盐 (揭标时需原样提供)
+func (s *RFQService) SubmitBid(rfqId string, manufacturerId string, itemPrices []float64) (map[string]interface{}, error) {
+	salt, err := newSalt()
+	if err != nil {
+		return nil, err
+	}
+	content := BidContent{
+		RFQID:          rfqId,
+		ManufacturerID: manufacturerId,
+		ItemPrices:     itemPrices,
+		Salt:           salt,
+	}
+	contentBytes, _ := json.Marshal(content)
+	hash := sha256.Sum256(contentBytes)
+	bidHash := hex.EncodeToString(hash[:])
+
+	_, err = fabric.Submit(MANUFACTURER_ORG, "SubmitBid", []string{rfqId, manufacturerId, bidHash})
+	if err != nil {
+		return nil, fmt.Errorf("投标失败：%s", fabric.ExtractErrorMessage(err))
+	}
+	return map[string]interface{}{"bidHash": bidHash, "salt": content.Salt}, nil
+}
+
+// RevealBid 截止后揭标, 投标内容仅通过瞬态数据发送给交易双方节点
+func (s *RFQService) RevealBid(rfqId string, manufacturerId string, itemPrices []float64, salt string) error {
+	content := BidContent{
+		RFQID:          rfqId,
+		ManufacturerID: manufacturerId,
+		ItemPrices:     itemPrices,
+		Salt:           salt,
+	}
+	contentBytes, _ := json.Marshal(content)
+
+	_, err := fabric.Submit(MANUFACTURER_ORG, "RevealBid", []string{rfqId, manufacturerId},
+		fabric.WithConfidential("bid", contentBytes),
+		orderEndorsers(),
+	)
+	if err != nil {
+		return fmt.Errorf("揭标失败：%s", fabric.ExtractErrorMessage(err))
+	}
+	return nil
+}
+
+// AwardRFQ 主机厂定标并生成订单
+func (s *RFQService) AwardRFQ(rfqId string, manufacturerId string, orderId string) error {
+	_, err := fabric.Submit(OEM_ORG, "AwardRFQ", []string{rfqId, manufacturerId, orderId}, orderEndorsers())
+	if err != nil {
+		return fmt.Errorf("定标失败：%s", fabric.ExtractErrorMessage(err))
+	}
+	return nil
+}
+
+// CancelRFQ 主机厂取消询价
+func (s *RFQService) CancelRFQ(rfqId string) error {
+	_, err := fabric.Submit(OEM_ORG, "CancelRFQ", []string{rfqId})
+	if err != nil {
+		return fmt.Errorf("取消询价失败：%s", fabric.ExtractErrorMessage(err))
+	}
+	return nil
+}
+
+// QueryRFQ 查询询价单详情
+func (s *RFQService) QueryRFQ(orgName string, id string) (map[string]interface{}, error) {
+	contract := fabric.GetContract(orgName)
+	result, err := contract.EvaluateTransaction("QueryRFQ", id)
+	if err != nil {
+		return nil, fmt.Errorf("查询询价单失败：%s", fabric.ExtractErrorMessage(err))
+	}
+
+	var rfq map[string]interface{}
+	if err := json.Unmarshal(result, &rfq); err != nil {
+		return nil, fmt.Errorf("解析询价单数据失败：%v", err)
+	}
+
+	return rfq, nil
+}
+
+// QueryBids 查询询价单下的投标列表
+func (s *RFQService) QueryBids(orgName string, rfqId string) ([]map[string]interface{}, error) {
+	contract := fabric.GetContract(orgName)
+	result, err := contract.EvaluateTransaction("QueryBids", rfqId)
+	if err != nil {
+		return nil, fmt.Errorf("查询投标失败：%s", fabric.ExtractErrorMessage(err))
+	}
+
+	var bids []map[string]interface{}
+	if err := json.Unmarshal(result, &bids); err != nil {
+		return nil, fmt.Errorf("解析投标数据失败：%v", err)
+	}
+
+	return bids, nil
+}
+
+// QueryBidContent 查询已揭标的投标内容
+func (s *RFQService) QueryBidContent(orgName string, rfqId string, manufacturerId string) (map[string]interface{}, error) {
+	contract := fabric.GetContract(orgName)
+	result, err := contract.EvaluateTransaction("QueryBidContent", rfqId, manufacturerId)
+	if err != nil {
+		return nil, fmt.Errorf("查询投标内容失败：%s", fabric.ExtractErrorMessage(err))
+	}
+
+	var content map[string]interface{}
+	if err := json.Unmarshal(result, &content); err != nil {
+		return nil, fmt.Errorf("解析投标内容失败：%v", err)
+	}
+
+	return content, nil
+}
@@ -0,0 +1,111 @@
+package service
+
+import (
+	"application/pkg/fabric"
+	"encoding/json"
+	"fmt"
+)
+
+type RMAService struct{}
+
+// RMALine 退货行
+type RMALine struct {
+	LineIndex    int      `json:"lineIndex"`
+	Quantity     int      `json:"quantity"`
+	DefectReason string   `json:"defectReason"`
+	PhotoHashes  []string `json:"photoHashes"`
+}
+
+// OpenRMA 主机厂发起退货
+func (s *RMAService) OpenRMA(id string, orderId string, lines []RMALine) error {
+	linesBytes, _ := json.Marshal(lines)
+	_, err := fabric.Submit(OEM_ORG, "OpenRMA", []string{id, orderId, string(linesBytes)}, orderEndorsers())
+	if err != nil {
+		return fmt.Errorf("发起退货失败：%s", fabric.ExtractErrorMessage(err))
+	}
+	return nil
+}
+
+// ApproveRMA 厂商批准退货并确定处理方式
+func (s *RMAService) ApproveRMA(id string, resolution string) error {
+	_, err := fabric.Submit(MANUFACTURER_ORG, "ApproveRMA", []string{id, resolution}, orderEndorsers())
+	if err != nil {
+		return fmt.Errorf("批准退货失败：%s", fabric.ExtractErrorMessage(err))
+	}
+	return nil
+}
+
+// RejectRMA 厂商拒绝退货
+func (s *RMAService) RejectRMA(id string, reason string) error {
+	_, err := fabric.Submit(MANUFACTURER_ORG, "RejectRMA", []string{id, reason}, orderEndorsers())
+	if err != nil {
+		return fmt.Errorf("拒绝退货失败：%s", fabric.ExtractErrorMessage(err))
+	}
+	return nil
+}
+
+// PickupReturn 承运商取件承运退货
+func (s *RMAService) PickupReturn(orgName string, id string, shipmentId string) error {
+	_, err := fabric.Submit(orgName, "PickupReturn", []string{id, shipmentId}, orderEndorsers())
+	if err != nil {
+		return fmt.Errorf("退货取件失败：%s", fabric.ExtractErrorMessage(err))
+	}
+	return nil
+}
+
+// ConfirmReturnReceipt 厂商签收退货并出具处理结果
+func (s *RMAService) ConfirmReturnReceipt(id string) error {
+	_, err := fabric.Submit(MANUFACTURER_ORG, "ConfirmReturnReceipt", []string{id}, orderEndorsers())
+	if err != nil {
+		return fmt.Errorf("签收退货失败：%s", fabric.ExtractErrorMessage(err))
+	}
+	return nil
+}
+
+// QueryRMA 查询退货单
+func (s *RMAService) QueryRMA(orgName string, id string) (map[string]interface{}, error) {
+	contract := fabric.GetContract(orgName)
+	result, err := contract.EvaluateTransaction("QueryRMA", id)
+	if err != nil {
+		return nil, fmt.Errorf("查询退货单失败：%s", fabric.ExtractErrorMessage(err))
+	}
+
+	var rma map[string]interface{}
+	if err := json.Unmarshal(result, &rma); err != nil {
+		return nil, fmt.Errorf("解析退货单失败：%v", err)
+	}
+
+	return rma, nil
+}
+
+// QueryOrderRMAs 查询订单的全部退货单
+func (s *RMAService) QueryOrderRMAs(orgName string, orderId string) ([]map[string]interface{}, error) {
+	contract := fabric.GetContract(orgName)
+	result, err := contract.EvaluateTransaction("QueryOrderRMAs", orderId)
+	if err != nil {
+		return nil, fmt.Errorf("查询订单退货单失败：%s", fabric.ExtractErrorMessage(err))
+	}
+
+	var rmas []map[string]interface{}
+	if err := json.Unmarshal(result, &rmas); err != nil {
+		return nil, fmt.Errorf("解析退货单失败：%v", err)
+	}
+
+	return rmas, nil
+}
+
+// QueryCreditNote 查询退货单的贷项通知单
+func (s *RMAService) QueryCreditNote(orgName string, rmaId string) (map[string]interface{}, error) {
+	contract := fabric.GetContract(orgName)
+	result, err := contract.EvaluateTransaction("QueryCreditNote", rmaId)
+	if err != nil {
+		return nil, fmt.Errorf("查询贷项通知单失败：%s", fabric.ExtractErrorMessage(err))
+	}
+
+	var note map[string]interface{}
+	if err := json.Unmarshal(result, &note); err != nil {
+		return nil, fmt.Errorf("解析贷项通知单失败：%v", err)
+	}
+
+	return note, nil
+}
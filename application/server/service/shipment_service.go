@@ -0,0 +1,171 @@
+package service
+
+import (
+	"application/pkg/fabric"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+type ShipmentService struct{}
+
+// ShipmentLeg 物流运输段
+type ShipmentLeg struct {
+	CarrierID   string `json:"carrierId"`
+	Mode        string `json:"mode"`
+	Origin      string `json:"origin"`
+	Destination string `json:"destination"`
+}
+
+// PlanShipmentLegs 当前保管承运商规划多段运输
+func (s *ShipmentService) PlanShipmentLegs(orgName string, shipmentId string, legs []ShipmentLeg) error {
+	legsBytes, _ := json.Marshal(legs)
+	_, err := fabric.Submit(orgName, "PlanShipmentLegs", []string{shipmentId, string(legsBytes)})
+	if err != nil {
+		return fmt.Errorf("规划运输段失败：%s", fabric.ExtractErrorMessage(err))
+	}
+	return nil
+}
+
+// HandoverCustody 当前保管承运商发起交接
+func (s *ShipmentService) HandoverCustody(orgName string, shipmentId string, location string) error {
+	_, err := fabric.Submit(orgName, "HandoverCustody", []string{shipmentId, location})
+	if err != nil {
+		return fmt.Errorf("发起交接失败：%s", fabric.ExtractErrorMessage(err))
+	}
+	return nil
+}
+
+// AcceptCustody 接收承运商确认交接
+func (s *ShipmentService) AcceptCustody(orgName string, shipmentId string) error {
+	_, err := fabric.Submit(orgName, "AcceptCustody", []string{shipmentId})
+	if err != nil {
+		return fmt.Errorf("确认交接失败：%s", fabric.ExtractErrorMessage(err))
+	}
+	return nil
+}
+
+// RejectCustody 接收承运商拒绝交接
+func (s *ShipmentService) RejectCustody(orgName string, shipmentId string) error {
+	_, err := fabric.Submit(orgName, "RejectCustody", []string{shipmentId})
+	if err != nil {
+		return fmt.Errorf("拒绝交接失败：%s", fabric.ExtractErrorMessage(err))
+	}
+	return nil
+}
+
+// QueryCustodyAt 查询指定时刻的货物保管方
+func (s *ShipmentService) QueryCustodyAt(orgName string, shipmentId string, at time.Time) (map[string]interface{}, error) {
+	contract := fabric.GetContract(orgName)
+	result, err := contract.EvaluateTransaction("QueryCustodyAt", shipmentId, at.Format(time.RFC3339))
+	if err != nil {
+		return nil, fmt.Errorf("查询保管记录失败：%s", fabric.ExtractErrorMessage(err))
+	}
+
+	var record map[string]interface{}
+	if err := json.Unmarshal(result, &record); err != nil {
+		return nil, fmt.Errorf("解析保管记录失败：%v", err)
+	}
+
+	return record, nil
+}
+
+// Checkpoint 物流轨迹检查点
+type Checkpoint struct {
+	EventType string  `json:"eventType"`
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+	Place     string  `json:"place"`
+}
+
+// RecordCheckpoint 记录轨迹检查点 (进入目的地围栏可能自动更新订单状态, 需交易双方背书)
+func (s *ShipmentService) RecordCheckpoint(orgName string, shipmentId string, checkpoint Checkpoint) error {
+	_, err := fabric.Submit(orgName, "RecordCheckpoint", []string{
+		shipmentId,
+		checkpoint.EventType,
+		strconv.FormatFloat(checkpoint.Latitude, 'f', -1, 64),
+		strconv.FormatFloat(checkpoint.Longitude, 'f', -1, 64),
+		checkpoint.Place,
+	}, orderEndorsers())
+	if err != nil {
+		return fmt.Errorf("记录轨迹失败：%s", fabric.ExtractErrorMessage(err))
+	}
+	return nil
+}
+
+// QueryShipmentTrack 查询物流完整轨迹
+func (s *ShipmentService) QueryShipmentTrack(orgName string, shipmentId string) ([]map[string]interface{}, error) {
+	contract := fabric.GetContract(orgName)
+	result, err := contract.EvaluateTransaction("QueryShipmentTrack", shipmentId)
+	if err != nil {
+		return nil, fmt.Errorf("查询物流轨迹失败：%s", fabric.ExtractErrorMessage(err))
+	}
+
+	var track []map[string]interface{}
+	if err := json.Unmarshal(result, &track); err != nil {
+		return nil, fmt.Errorf("解析物流轨迹失败：%v", err)
+	}
+
+	return track, nil
+}
+
+// ShipmentException 物流异常报告
+type ShipmentException struct {
+	Type           string   `json:"type"`
+	Severity       string   `json:"severity"`
+	Description    string   `json:"description"`
+	PhotoHashes    []string `json:"photoHashes"`
+	ETAImpactHours int      `json:"etaImpactHours"`
+}
+
+// ReportException 保管承运商报告物流异常
+func (s *ShipmentService) ReportException(orgName string, shipmentId string, exception ShipmentException) error {
+	exceptionBytes, _ := json.Marshal(exception)
+	_, err := fabric.Submit(orgName, "ReportException", []string{shipmentId, string(exceptionBytes)})
+	if err != nil {
+		return fmt.Errorf("报告异常失败：%s", fabric.ExtractErrorMessage(err))
+	}
+	return nil
+}
+
+// ResolveException 采购方或平台方处理物流异常
+func (s *ShipmentService) ResolveException(orgName string, shipmentId string, seq int, resolution string, note string) error {
+	_, err := fabric.Submit(orgName, "ResolveException", []string{shipmentId, strconv.Itoa(seq), resolution, note})
+	if err != nil {
+		return fmt.Errorf("处理异常失败：%s", fabric.ExtractErrorMessage(err))
+	}
+	return nil
+}
+
+// QueryShipmentExceptions 查询物流单的异常报告
+func (s *ShipmentService) QueryShipmentExceptions(orgName string, shipmentId string) ([]map[string]interface{}, error) {
+	contract := fabric.GetContract(orgName)
+	result, err := contract.EvaluateTransaction("QueryShipmentExceptions", shipmentId)
+	if err != nil {
+		return nil, fmt.Errorf("查询异常报告失败：%s", fabric.ExtractErrorMessage(err))
+	}
+
+	var exceptions []map[string]interface{}
+	if err := json.Unmarshal(result, &exceptions); err != nil {
+		return nil, fmt.Errorf("解析异常报告失败：%v", err)
+	}
+
+	return exceptions, nil
+}
+
+// QueryOpenExceptions 查询待处理的物流异常
+func (s *ShipmentService) QueryOpenExceptions(orgName string) ([]map[string]interface{}, error) {
+	contract := fabric.GetContract(orgName)
+	result, err := contract.EvaluateTransaction("QueryOpenExceptions")
+	if err != nil {
+		return nil, fmt.Errorf("查询待处理异常失败：%s", fabric.ExtractErrorMessage(err))
+	}
+
+	var exceptions []map[string]interface{}
+	if err := json.Unmarshal(result, &exceptions); err != nil {
+		return nil, fmt.Errorf("解析异常报告失败：%v", err)
+	}
+
+	return exceptions, nil
+}
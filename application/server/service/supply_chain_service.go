@@ -2,8 +2,11 @@ package service
 
 import (
 	"application/pkg/fabric"
+	"crypto/rand"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"strconv"
 )
 
 type SupplyChainService struct{}
@@ -11,15 +14,69 @@ type SupplyChainService struct{}
 const (
 	OEM_ORG          = "org1"
 	MANUFACTURER_ORG = "org2"
-	CARRIER_ORG      = "org3"
+	CARRIER_ORG      = "org3" // 承运商查询; 承运商交易以 fabric.carriers 中携带 carrierId 属性的身份提交
 	PLATFORM_ORG     = "org3"
 )
 
+// OrderItem 订单零件明细 (单价仅通过瞬态数据上链)
+type OrderItem struct {
+	PartNumber string  `json:"partNumber"`
+	Name       string  `json:"name"`
+	Quantity   int     `json:"quantity"`
+	Price      float64 `json:"price"`
+}
+
+// orderEndorsers 订单键受状态背书策略约束，写订单的交易需由主机厂与零部件厂商节点共同背书
+func orderEndorsers() fabric.SubmitOption {
+	return fabric.WithEndorsingOrgs(OEM_ORG, MANUFACTURER_ORG)
+}
+
+// subOrderEndorsers 子订单由一级供应商独自背书 (二级供应商不在网络内, 主机厂不参与)
+func subOrderEndorsers() fabric.SubmitOption {
+	return fabric.WithEndorsingOrgs(MANUFACTURER_ORG)
+}
+
+// newSalt 生成随机盐, 防止对私有数据哈希进行穷举
+func newSalt() (string, error) {
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return "", fmt.Errorf("生成随机盐失败：%v", err)
+	}
+	return hex.EncodeToString(salt), nil
+}
+
+// splitOrderItems 拆分订单明细: 公开部分只包含零件名称与数量, 单价与随机盐走瞬态数据写入私有集合
+func splitOrderItems(items []OrderItem) ([]byte, []byte, error) {
+	publicItems := make([]map[string]interface{}, 0, len(items))
+	itemPrices := make([]float64, 0, len(items))
+	for _, item := range items {
+		publicItems = append(publicItems, map[string]interface{}{"partNumber": item.PartNumber, "name": item.Name, "quantity": item.Quantity})
+		itemPrices = append(itemPrices, item.Price)
+	}
+	itemsBytes, _ := json.Marshal(publicItems)
+
+	salt, err := newSalt()
+	if err != nil {
+		return nil, nil, err
+	}
+	priceBytes, _ := json.Marshal(map[string]interface{}{
+		"itemPrices": itemPrices,
+		"salt":       salt,
+	})
+	return itemsBytes, priceBytes, nil
+}
+
 // CreateOrder 主机厂创建订单
-func (s *SupplyChainService) CreateOrder(id string, manufacturerId string, items interface{}) error {
-	contract := fabric.GetContract(OEM_ORG)
-	itemsBytes, _ := json.Marshal(items)
-	_, err := contract.SubmitTransaction("CreateOrder", id, manufacturerId, string(itemsBytes))
+func (s *SupplyChainService) CreateOrder(id string, manufacturerId string, items []OrderItem) error {
+	itemsBytes, priceBytes, err := splitOrderItems(items)
+	if err != nil {
+		return err
+	}
+
+	_, err = fabric.Submit(OEM_ORG, "CreateOrder", []string{id, manufacturerId, string(itemsBytes)},
+		fabric.WithConfidential("price", priceBytes),
+		orderEndorsers(),
+	)
 	if err != nil {
 		return fmt.Errorf("创建订单失败：%s", fabric.ExtractErrorMessage(err))
 	}
@@ -28,8 +85,7 @@ func (s *SupplyChainService) CreateOrder(id string, manufacturerId string, items
 
 // AcceptOrder 零部件厂接受订单
 func (s *SupplyChainService) AcceptOrder(id string) error {
-	contract := fabric.GetContract(MANUFACTURER_ORG)
-	_, err := contract.SubmitTransaction("AcceptOrder", id)
+	_, err := fabric.Submit(MANUFACTURER_ORG, "AcceptOrder", []string{id}, orderEndorsers())
 	if err != nil {
 		return fmt.Errorf("接受订单失败：%s", fabric.ExtractErrorMessage(err))
 	}
@@ -38,8 +94,7 @@ func (s *SupplyChainService) AcceptOrder(id string) error {
 
 // UpdateProductionStatus 更新生产进度
 func (s *SupplyChainService) UpdateProductionStatus(id string, status string) error {
-	contract := fabric.GetContract(MANUFACTURER_ORG)
-	_, err := contract.SubmitTransaction("UpdateProductionStatus", id, status)
+	_, err := fabric.Submit(MANUFACTURER_ORG, "UpdateProductionStatus", []string{id, status}, orderEndorsers())
 	if err != nil {
 		return fmt.Errorf("更新生产进度失败：%s", fabric.ExtractErrorMessage(err))
 	}
@@ -47,35 +102,100 @@ func (s *SupplyChainService) UpdateProductionStatus(id string, status string) er
 }
 
 // PickupGoods 承运商取货
-func (s *SupplyChainService) PickupGoods(orderId string, shipmentId string) error {
-	contract := fabric.GetContract(CARRIER_ORG)
-	_, err := contract.SubmitTransaction("PickupGoods", orderId, shipmentId)
+func (s *SupplyChainService) PickupGoods(orgName string, orderId string, shipmentId string) error {
+	_, err := fabric.Submit(orgName, "PickupGoods", []string{orderId, shipmentId}, orderEndorsers())
 	if err != nil {
 		return fmt.Errorf("取货失败：%s", fabric.ExtractErrorMessage(err))
 	}
 	return nil
 }
 
-// UpdateLocation 更新物流位置
-func (s *SupplyChainService) UpdateLocation(shipmentId string, location string) error {
-	contract := fabric.GetContract(CARRIER_ORG)
-	_, err := contract.SubmitTransaction("UpdateLocation", shipmentId, location)
+// UpdateLocation 更新物流位置 (携带坐标时记录为轨迹检查点, 参与地理围栏判断)
+func (s *SupplyChainService) UpdateLocation(orgName string, shipmentId string, location string, latitude *float64, longitude *float64) error {
+	if latitude != nil && longitude != nil {
+		return (&ShipmentService{}).RecordCheckpoint(orgName, shipmentId, Checkpoint{
+			EventType: "POSITION",
+			Latitude:  *latitude,
+			Longitude: *longitude,
+			Place:     location,
+		})
+	}
+
+	_, err := fabric.Submit(orgName, "UpdateLocation", []string{shipmentId, location})
 	if err != nil {
 		return fmt.Errorf("更新物流位置失败：%s", fabric.ExtractErrorMessage(err))
 	}
 	return nil
 }
 
-// ConfirmReceipt 主机厂确认收货
-func (s *SupplyChainService) ConfirmReceipt(orderId string) error {
-	contract := fabric.GetContract(OEM_ORG)
-	_, err := contract.SubmitTransaction("ConfirmReceipt", orderId)
+// ConfirmReceipt 主机厂确认收货, receivedQuantities 为空时按订单数量全额收货
+func (s *SupplyChainService) ConfirmReceipt(orderId string, receivedQuantities []int) error {
+	var err error
+	if receivedQuantities == nil {
+		_, err = fabric.Submit(OEM_ORG, "ConfirmReceipt", []string{orderId}, orderEndorsers())
+	} else {
+		quantitiesBytes, _ := json.Marshal(receivedQuantities)
+		_, err = fabric.Submit(OEM_ORG, "ConfirmReceiptWithQuantities", []string{orderId, string(quantitiesBytes)}, orderEndorsers())
+	}
 	if err != nil {
 		return fmt.Errorf("确认收货失败：%s", fabric.ExtractErrorMessage(err))
 	}
 	return nil
 }
 
+// CreateSubOrder 一级供应商向二级供应商下达子订单
+func (s *SupplyChainService) CreateSubOrder(parentOrderId string, id string, supplierId string, items []OrderItem, blockParent bool) error {
+	itemsBytes, priceBytes, err := splitOrderItems(items)
+	if err != nil {
+		return err
+	}
+
+	// 价格先写入仅 Org2 可见的集合, 只由 Org2 背书, 瞬态数据不会发往其他组织
+	_, err = fabric.Submit(MANUFACTURER_ORG, "RecordSubOrderPrice",
+		[]string{id, string(itemsBytes)},
+		fabric.WithConfidential("price", priceBytes),
+		subOrderEndorsers(),
+	)
+	if err != nil {
+		return fmt.Errorf("登记子订单价格失败：%s", fabric.ExtractErrorMessage(err))
+	}
+
+	// 新建公开记录须满足链码级 MAJORITY 背书, 由平台方补足第二个背书组织, 其只接触价格哈希
+	_, err = fabric.Submit(MANUFACTURER_ORG, "CreateSubOrder",
+		[]string{parentOrderId, id, supplierId, string(itemsBytes), strconv.FormatBool(blockParent)},
+		fabric.WithEndorsingOrgs(MANUFACTURER_ORG, PLATFORM_ORG),
+	)
+	if err != nil {
+		return fmt.Errorf("下达子订单失败：%s", fabric.ExtractErrorMessage(err))
+	}
+	return nil
+}
+
+// ConfirmSubOrderReceipt 一级供应商签收子订单
+func (s *SupplyChainService) ConfirmSubOrderReceipt(orderId string) error {
+	_, err := fabric.Submit(MANUFACTURER_ORG, "ConfirmReceipt", []string{orderId}, subOrderEndorsers())
+	if err != nil {
+		return fmt.Errorf("签收子订单失败：%s", fabric.ExtractErrorMessage(err))
+	}
+	return nil
+}
+
+// QueryOrderTree 查询订单履约树
+func (s *SupplyChainService) QueryOrderTree(orgName string, id string) (map[string]interface{}, error) {
+	contract := fabric.GetContract(orgName)
+	result, err := contract.EvaluateTransaction("QueryOrderTree", id)
+	if err != nil {
+		return nil, fmt.Errorf("查询订单履约树失败：%s", fabric.ExtractErrorMessage(err))
+	}
+
+	var tree map[string]interface{}
+	if err := json.Unmarshal(result, &tree); err != nil {
+		return nil, fmt.Errorf("解析订单履约树失败：%v", err)
+	}
+
+	return tree, nil
+}
+
 // QueryOrder 查询订单详情
 func (s *SupplyChainService) QueryOrder(id string) (map[string]interface{}, error) {
 	contract := fabric.GetContract(OEM_ORG)
@@ -92,6 +212,38 @@ func (s *SupplyChainService) QueryOrder(id string) (map[string]interface{}, erro
 	return order, nil
 }
 
+// QueryOrderPrice 查询订单价格 (仅交易双方)
+func (s *SupplyChainService) QueryOrderPrice(orgName string, id string) (map[string]interface{}, error) {
+	contract := fabric.GetContract(orgName)
+	result, err := contract.EvaluateTransaction("QueryOrderPrice", id)
+	if err != nil {
+		return nil, fmt.Errorf("查询订单价格失败：%s", fabric.ExtractErrorMessage(err))
+	}
+
+	var price map[string]interface{}
+	if err := json.Unmarshal(result, &price); err != nil {
+		return nil, fmt.Errorf("解析订单价格失败：%v", err)
+	}
+
+	return price, nil
+}
+
+// VerifyOrderPrice 平台方校验订单价格哈希
+func (s *SupplyChainService) VerifyOrderPrice(id string) (bool, error) {
+	contract := fabric.GetContract(PLATFORM_ORG)
+	result, err := contract.EvaluateTransaction("VerifyOrderPrice", id)
+	if err != nil {
+		return false, fmt.Errorf("校验订单价格失败：%s", fabric.ExtractErrorMessage(err))
+	}
+
+	var matched bool
+	if err := json.Unmarshal(result, &matched); err != nil {
+		return false, fmt.Errorf("解析校验结果失败：%v", err)
+	}
+
+	return matched, nil
+}
+
 // QueryOrderList 分页查询订单列表
 func (s *SupplyChainService) QueryOrderList(pageSize int32, bookmark string) (map[string]interface{}, error) {
 	contract := fabric.GetContract(OEM_ORG)
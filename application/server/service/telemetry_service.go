@@ -0,0 +1,352 @@
+package service
+
+import (
+	"application/pkg/fabric"
+	"application/pkg/telemetry"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strconv"
+	"sync"
+	"time"
+)
+
+type TelemetryService struct{}
+
+// 锚定任务互斥, 保证链上锚定序号与本地批次一致
+var anchorMu sync.Mutex
+
+// 超限检测任务互斥, 保证检测游标单调推进
+var excursionMu sync.Mutex
+
+// 新读数入库后唤醒超限检测, 缓冲为 1, 检测进行中再次唤醒只保留一次
+var excursionWake = make(chan struct{}, 1)
+
+// SensorThresholds 传感器阈值
+type SensorThresholds struct {
+	MinTemperature *float64 `json:"minTemperature,omitempty"`
+	MaxTemperature *float64 `json:"maxTemperature,omitempty"`
+	MaxShock       *float64 `json:"maxShock,omitempty"`
+}
+
+// excursion 上链的超限记录
+type excursion struct {
+	DeviceID    string    `json:"deviceId"`
+	SensorType  string    `json:"sensorType"`
+	Value       float64   `json:"value"`
+	ReadingTime time.Time `json:"readingTime"`
+	ReadingHash string    `json:"readingHash"`
+}
+
+// BatchVerification 已锚定批次的校验结果
+type BatchVerification struct {
+	AnchorSeq    int    `json:"anchorSeq"`
+	MerkleRoot   string `json:"merkleRoot"`
+	ComputedRoot string `json:"computedRoot"`
+	ReadingCount int    `json:"readingCount"`
+	Valid        bool   `json:"valid"`
+}
+
+// SetSensorThresholds 交易双方设置物流单传感器阈值
+func (s *TelemetryService) SetSensorThresholds(orgName string, shipmentId string, thresholds SensorThresholds) error {
+	thresholdsBytes, _ := json.Marshal(thresholds)
+	_, err := fabric.Submit(orgName, "SetSensorThresholds", []string{shipmentId, string(thresholdsBytes)})
+	if err != nil {
+		return fmt.Errorf("设置传感器阈值失败：%s", fabric.ExtractErrorMessage(err))
+	}
+	return nil
+}
+
+// IngestReadings 接收一批传感器读数并写入链下存储, 返回新入库与重复跳过的数量
+// 超限检测不在请求路径上执行, 由后台任务按物流单汇总后批量上链
+func (s *TelemetryService) IngestReadings(shipmentId string, readings []telemetry.Reading) (int, int, error) {
+	if _, err := s.queryShipment(shipmentId); err != nil {
+		return 0, 0, err
+	}
+
+	stored, err := telemetry.GetStore().Append(shipmentId, readings)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	select {
+	case excursionWake <- struct{}{}:
+	default:
+	}
+	return len(stored), len(readings) - len(stored), nil
+}
+
+// DetectExcursions 检测所有物流单未检测的读数, 每个物流单的超限汇总为一笔交易上链后推进检测游标
+// 单个物流单的失败只记录日志并跳过, 链码按读数去重, 重试不会重复记录
+func (s *TelemetryService) DetectExcursions() error {
+	excursionMu.Lock()
+	defer excursionMu.Unlock()
+
+	store := telemetry.GetStore()
+	shipmentIds, err := store.UncheckedShipments()
+	if err != nil {
+		return fmt.Errorf("读取待检测物流单失败：%v", err)
+	}
+
+	for _, shipmentId := range shipmentIds {
+		unchecked, err := store.Unchecked(shipmentId)
+		if err != nil || len(unchecked) == 0 {
+			continue
+		}
+		// 单个物流单失败不影响其余物流单, 游标不推进, 下次检测重试
+		thresholds, err := s.queryThresholds(shipmentId)
+		if err != nil {
+			log.Printf("DetectExcursions %s Error: %v", shipmentId, err)
+			continue
+		}
+
+		excursions := make([]excursion, 0)
+		for _, record := range unchecked {
+			excursions = append(excursions, findExcursions(thresholds, record)...)
+		}
+		if len(excursions) > 0 {
+			excursionsBytes, _ := json.Marshal(excursions)
+			if _, err := fabric.Submit(PLATFORM_ORG, "RecordExcursions", []string{shipmentId, string(excursionsBytes)}); err != nil {
+				log.Printf("DetectExcursions %s Error: 记录超限失败：%s", shipmentId, fabric.ExtractErrorMessage(err))
+				continue
+			}
+		}
+		if err := store.MarkChecked(shipmentId, unchecked[len(unchecked)-1].Seq); err != nil {
+			log.Printf("DetectExcursions %s Error: 推进超限检测游标失败：%v", shipmentId, err)
+		}
+	}
+	return nil
+}
+
+// StartExcursionDetection 后台检测传感器超限: 新读数入库时唤醒, 并按固定间隔重试失败的检测
+func StartExcursionDetection(interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		s := &TelemetryService{}
+		for {
+			select {
+			case <-excursionWake:
+			case <-ticker.C:
+			}
+			if err := s.DetectExcursions(); err != nil {
+				log.Printf("DetectExcursions Error: %v", err)
+			}
+		}
+	}()
+}
+
+// AnchorPending 将所有物流单未锚定的读数计算默克尔根并上链
+func (s *TelemetryService) AnchorPending() error {
+	anchorMu.Lock()
+	defer anchorMu.Unlock()
+
+	store := telemetry.GetStore()
+	shipmentIds, err := store.PendingShipments()
+	if err != nil {
+		return fmt.Errorf("读取待锚定物流单失败：%v", err)
+	}
+
+	for _, shipmentId := range shipmentIds {
+		pending, err := store.Pending(shipmentId)
+		if err != nil || len(pending) == 0 {
+			continue
+		}
+		// 单个物流单锚定失败不阻塞其余物流单, 下个周期重试
+		if err := s.anchorBatch(shipmentId, pending); err != nil {
+			log.Printf("AnchorTelemetry %s Error: %v", shipmentId, err)
+		}
+	}
+	return nil
+}
+
+// anchorBatch 锚定一批读数, 链上按读数区间幂等, 本地记录失败后重试会返回同一批次序号
+func (s *TelemetryService) anchorBatch(shipmentId string, pending []telemetry.StoredReading) error {
+	readings := make([]telemetry.Reading, len(pending))
+	from, to := pending[0].Reading.Timestamp, pending[0].Reading.Timestamp
+	for i, record := range pending {
+		readings[i] = record.Reading
+		if record.Reading.Timestamp.Before(from) {
+			from = record.Reading.Timestamp
+		}
+		if record.Reading.Timestamp.After(to) {
+			to = record.Reading.Timestamp
+		}
+	}
+	root := telemetry.MerkleRoot(readings)
+
+	fromSeq, toSeq := pending[0].Seq, pending[len(pending)-1].Seq
+	result, err := fabric.Submit(PLATFORM_ORG, "AnchorTelemetry", []string{
+		shipmentId,
+		root,
+		strconv.Itoa(len(readings)),
+		strconv.FormatUint(fromSeq, 10),
+		strconv.FormatUint(toSeq, 10),
+		from.Format(time.RFC3339Nano),
+		to.Format(time.RFC3339Nano),
+	})
+	if err != nil {
+		return fmt.Errorf("锚定遥测数据失败：%s", fabric.ExtractErrorMessage(err))
+	}
+	var anchor struct {
+		Seq int `json:"seq"`
+	}
+	if err := json.Unmarshal(result, &anchor); err != nil {
+		return fmt.Errorf("解析遥测锚定记录失败：%v", err)
+	}
+
+	return telemetry.GetStore().MarkAnchored(shipmentId, telemetry.Batch{
+		AnchorSeq:  anchor.Seq,
+		FromSeq:    fromSeq,
+		ToSeq:      toSeq,
+		MerkleRoot: root,
+	})
+}
+
+// StartTelemetryAnchoring 按固定间隔锚定遥测数据
+func StartTelemetryAnchoring(interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		s := &TelemetryService{}
+		for range ticker.C {
+			if err := s.AnchorPending(); err != nil {
+				log.Printf("AnchorTelemetry Error: %v", err)
+			}
+		}
+	}()
+}
+
+// QueryReadings 查询链下存储的读数
+func (s *TelemetryService) QueryReadings(shipmentId string) ([]telemetry.StoredReading, error) {
+	readings, err := telemetry.GetStore().List(shipmentId)
+	if err != nil {
+		return nil, fmt.Errorf("查询遥测读数失败：%v", err)
+	}
+	return readings, nil
+}
+
+// QueryExcursions 查询传感器超限记录
+func (s *TelemetryService) QueryExcursions(orgName string, shipmentId string) ([]map[string]interface{}, error) {
+	contract := fabric.GetContract(orgName)
+	result, err := contract.EvaluateTransaction("QueryExcursions", shipmentId)
+	if err != nil {
+		return nil, fmt.Errorf("查询超限记录失败：%s", fabric.ExtractErrorMessage(err))
+	}
+
+	var excursions []map[string]interface{}
+	if err := json.Unmarshal(result, &excursions); err != nil {
+		return nil, fmt.Errorf("解析超限记录失败：%v", err)
+	}
+
+	return excursions, nil
+}
+
+// VerifyTelemetry 用链下读数重算默克尔根并与链上锚定记录比对
+func (s *TelemetryService) VerifyTelemetry(orgName string, shipmentId string) ([]BatchVerification, error) {
+	contract := fabric.GetContract(orgName)
+	result, err := contract.EvaluateTransaction("QueryTelemetryAnchors", shipmentId)
+	if err != nil {
+		return nil, fmt.Errorf("查询遥测锚定记录失败：%s", fabric.ExtractErrorMessage(err))
+	}
+
+	var anchors []struct {
+		Seq          int    `json:"seq"`
+		MerkleRoot   string `json:"merkleRoot"`
+		ReadingCount int    `json:"readingCount"`
+	}
+	if err := json.Unmarshal(result, &anchors); err != nil {
+		return nil, fmt.Errorf("解析遥测锚定记录失败：%v", err)
+	}
+
+	store := telemetry.GetStore()
+	batches, err := store.Batches(shipmentId)
+	if err != nil {
+		return nil, fmt.Errorf("读取本地批次失败：%v", err)
+	}
+	batchBySeq := make(map[int]telemetry.Batch, len(batches))
+	for _, batch := range batches {
+		batchBySeq[batch.AnchorSeq] = batch
+	}
+
+	verifications := make([]BatchVerification, 0, len(anchors))
+	for _, anchor := range anchors {
+		v := BatchVerification{
+			AnchorSeq:    anchor.Seq,
+			MerkleRoot:   anchor.MerkleRoot,
+			ReadingCount: anchor.ReadingCount,
+		}
+		if batch, ok := batchBySeq[anchor.Seq]; ok {
+			stored, err := store.Range(shipmentId, batch.FromSeq, batch.ToSeq)
+			if err != nil {
+				return nil, fmt.Errorf("读取遥测读数失败：%v", err)
+			}
+			readings := make([]telemetry.Reading, len(stored))
+			for i, record := range stored {
+				readings[i] = record.Reading
+			}
+			v.ComputedRoot = telemetry.MerkleRoot(readings)
+			v.Valid = v.ComputedRoot == anchor.MerkleRoot && len(readings) == anchor.ReadingCount
+		}
+		verifications = append(verifications, v)
+	}
+	return verifications, nil
+}
+
+type telemetryShipment struct {
+	SensorThresholds *SensorThresholds `json:"sensorThresholds"`
+}
+
+func (s *TelemetryService) queryShipment(shipmentId string) (*telemetryShipment, error) {
+	contract := fabric.GetContract(PLATFORM_ORG)
+	result, err := contract.EvaluateTransaction("QueryShipment", shipmentId)
+	if err != nil {
+		return nil, fmt.Errorf("查询物流失败：%s", fabric.ExtractErrorMessage(err))
+	}
+
+	var shipment telemetryShipment
+	if err := json.Unmarshal(result, &shipment); err != nil {
+		return nil, fmt.Errorf("解析物流数据失败：%v", err)
+	}
+	return &shipment, nil
+}
+
+func (s *TelemetryService) queryThresholds(shipmentId string) (*SensorThresholds, error) {
+	shipment, err := s.queryShipment(shipmentId)
+	if err != nil {
+		return nil, err
+	}
+	return shipment.SensorThresholds, nil
+}
+
+// 与链码 checkThreshold 保持一致的超限判断
+func findExcursions(thresholds *SensorThresholds, record telemetry.StoredReading) []excursion {
+	if thresholds == nil {
+		return nil
+	}
+
+	reading := record.Reading
+	newExcursion := func(sensorType string, value float64) excursion {
+		return excursion{
+			DeviceID:    reading.DeviceID,
+			SensorType:  sensorType,
+			Value:       value,
+			ReadingTime: reading.Timestamp,
+			ReadingHash: record.Hash,
+		}
+	}
+
+	excursions := make([]excursion, 0)
+	if t := reading.Temperature; t != nil {
+		if (thresholds.MinTemperature != nil && *t < *thresholds.MinTemperature) ||
+			(thresholds.MaxTemperature != nil && *t > *thresholds.MaxTemperature) {
+			excursions = append(excursions, newExcursion("TEMPERATURE", *t))
+		}
+	}
+	if shock := reading.Shock; shock != nil {
+		if thresholds.MaxShock != nil && *shock > *thresholds.MaxShock {
+			excursions = append(excursions, newExcursion("SHOCK", *shock))
+		}
+	}
+	return excursions
+}
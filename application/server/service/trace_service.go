@@ -0,0 +1,94 @@
+package service
+
+import (
+	"application/pkg/fabric"
+	"encoding/json"
+	"fmt"
+)
+
+type TraceService struct{}
+
+// ItemBatch 零件生产批次
+type ItemBatch struct {
+	BatchNo       string   `json:"batchNo"`
+	Quantity      int      `json:"quantity"`
+	SerialNumbers []string `json:"serialNumbers,omitempty"`
+}
+
+// CompleteProduction 零部件厂登记生产批次并完成生产
+func (s *TraceService) CompleteProduction(orderId string, batches [][]ItemBatch) error {
+	batchesBytes, _ := json.Marshal(batches)
+	_, err := fabric.Submit(MANUFACTURER_ORG, "CompleteProduction", []string{orderId, string(batchesBytes)}, orderEndorsers())
+	if err != nil {
+		return fmt.Errorf("登记生产批次失败：%s", fabric.ExtractErrorMessage(err))
+	}
+	return nil
+}
+
+// TraceBatch 追溯批次
+func (s *TraceService) TraceBatch(orgName string, batchNo string) (map[string]interface{}, error) {
+	contract := fabric.GetContract(orgName)
+	result, err := contract.EvaluateTransaction("TraceBatch", batchNo)
+	if err != nil {
+		return nil, fmt.Errorf("批次追溯失败：%s", fabric.ExtractErrorMessage(err))
+	}
+
+	var trace map[string]interface{}
+	if err := json.Unmarshal(result, &trace); err != nil {
+		return nil, fmt.Errorf("解析追溯结果失败：%v", err)
+	}
+
+	return trace, nil
+}
+
+// TraceSerial 按序列号追溯订单
+func (s *TraceService) TraceSerial(orgName string, serialNo string) ([]map[string]interface{}, error) {
+	contract := fabric.GetContract(orgName)
+	result, err := contract.EvaluateTransaction("TraceSerial", serialNo)
+	if err != nil {
+		return nil, fmt.Errorf("序列号追溯失败：%s", fabric.ExtractErrorMessage(err))
+	}
+
+	var orders []map[string]interface{}
+	if err := json.Unmarshal(result, &orders); err != nil {
+		return nil, fmt.Errorf("解析追溯结果失败：%v", err)
+	}
+
+	return orders, nil
+}
+
+// IssueRecall 平台方发布召回
+func (s *TraceService) IssueRecall(id string, batchNos []string, reason string) error {
+	batchNosBytes, _ := json.Marshal(batchNos)
+	// 召回需标记受影响订单, 订单键要求主机厂与零部件厂商背书
+	_, err := fabric.Submit(PLATFORM_ORG, "IssueRecall", []string{id, string(batchNosBytes), reason}, orderEndorsers())
+	if err != nil {
+		return fmt.Errorf("发布召回失败：%s", fabric.ExtractErrorMessage(err))
+	}
+	return nil
+}
+
+// CloseRecall 平台方关闭召回
+func (s *TraceService) CloseRecall(id string) error {
+	_, err := fabric.Submit(PLATFORM_ORG, "CloseRecall", []string{id})
+	if err != nil {
+		return fmt.Errorf("关闭召回失败：%s", fabric.ExtractErrorMessage(err))
+	}
+	return nil
+}
+
+// QueryRecall 查询召回单
+func (s *TraceService) QueryRecall(orgName string, id string) (map[string]interface{}, error) {
+	contract := fabric.GetContract(orgName)
+	result, err := contract.EvaluateTransaction("QueryRecall", id)
+	if err != nil {
+		return nil, fmt.Errorf("查询召回单失败：%s", fabric.ExtractErrorMessage(err))
+	}
+
+	var recall map[string]interface{}
+	if err := json.Unmarshal(result, &recall); err != nil {
+		return nil, fmt.Errorf("解析召回单数据失败：%v", err)
+	}
+
+	return recall, nil
+}
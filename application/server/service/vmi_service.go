@@ -0,0 +1,112 @@
+package service
+
+import (
+	"application/pkg/fabric"
+	"encoding/json"
+	"fmt"
+	"strconv"
+)
+
+type VMIService struct{}
+
+// CreateInventory 厂商在主机厂工厂开设寄售库存 (manufacturerId 为空时取本厂商)
+func (s *VMIService) CreateInventory(manufacturerId string, partNumber string, location string, minLevel int, maxLevel int) error {
+	if manufacturerId == "" {
+		manufacturerId = manufacturerMSPID()
+	}
+	_, err := fabric.Submit(MANUFACTURER_ORG, "CreateInventory", []string{manufacturerId, partNumber, location, strconv.Itoa(minLevel), strconv.Itoa(maxLevel)}, orderEndorsers())
+	if err != nil {
+		return fmt.Errorf("开设寄售库存失败：%s", fabric.ExtractErrorMessage(err))
+	}
+	return nil
+}
+
+// SetInventoryLevels 调整寄售库存最低/最高库存 (厂商一侧 ownerId 为空时取本厂商)
+func (s *VMIService) SetInventoryLevels(orgName string, partNumber string, location string, ownerId string, minLevel int, maxLevel int) error {
+	if ownerId == "" {
+		ownerId = manufacturerMSPID()
+	}
+	_, err := fabric.Submit(orgName, "SetInventoryLevels", []string{partNumber, location, ownerId, strconv.Itoa(minLevel), strconv.Itoa(maxLevel)}, orderEndorsers())
+	if err != nil {
+		return fmt.Errorf("调整库存水位失败：%s", fabric.ExtractErrorMessage(err))
+	}
+	return nil
+}
+
+// SetOrderConsignment 主机厂指定订单签收后入寄售库存
+func (s *VMIService) SetOrderConsignment(orderId string, location string) error {
+	_, err := fabric.Submit(OEM_ORG, "SetOrderConsignment", []string{orderId, location}, orderEndorsers())
+	if err != nil {
+		return fmt.Errorf("指定寄售入库失败：%s", fabric.ExtractErrorMessage(err))
+	}
+	return nil
+}
+
+// ReportConsumption 主机厂报告寄售库存消耗, 链码按来源订单自动开票 (发票金额写入私有数据, 须双方背书)
+func (s *VMIService) ReportConsumption(id string, partNumber string, location string, ownerId string, quantity int) error {
+	_, err := fabric.Submit(OEM_ORG, "ReportConsumption", []string{id, partNumber, location, ownerId, strconv.Itoa(quantity)}, orderEndorsers())
+	if err != nil {
+		return fmt.Errorf("报告消耗失败：%s", fabric.ExtractErrorMessage(err))
+	}
+	return nil
+}
+
+// QueryInventory 查询寄售库存 (厂商一侧 ownerId 为空时取本厂商)
+func (s *VMIService) QueryInventory(orgName string, partNumber string, location string, ownerId string) (map[string]interface{}, error) {
+	if ownerId == "" {
+		ownerId = manufacturerMSPID()
+	}
+	contract := fabric.GetContract(orgName)
+	result, err := contract.EvaluateTransaction("QueryInventory", partNumber, location, ownerId)
+	if err != nil {
+		return nil, fmt.Errorf("查询寄售库存失败：%s", fabric.ExtractErrorMessage(err))
+	}
+
+	var inventory map[string]interface{}
+	if err := json.Unmarshal(result, &inventory); err != nil {
+		return nil, fmt.Errorf("解析寄售库存失败：%v", err)
+	}
+
+	return inventory, nil
+}
+
+// QueryLocationInventory 查询某地点的全部寄售库存
+func (s *VMIService) QueryLocationInventory(orgName string, location string) ([]map[string]interface{}, error) {
+	return s.queryInventories(orgName, "QueryLocationInventory", location)
+}
+
+// QueryReplenishmentSuggestions 查询某地点的补货建议
+func (s *VMIService) QueryReplenishmentSuggestions(orgName string, location string) ([]map[string]interface{}, error) {
+	return s.queryInventories(orgName, "QueryReplenishmentSuggestions", location)
+}
+
+// QueryConsumption 查询消耗报告
+func (s *VMIService) QueryConsumption(orgName string, id string) (map[string]interface{}, error) {
+	contract := fabric.GetContract(orgName)
+	result, err := contract.EvaluateTransaction("QueryConsumption", id)
+	if err != nil {
+		return nil, fmt.Errorf("查询消耗报告失败：%s", fabric.ExtractErrorMessage(err))
+	}
+
+	var consumption map[string]interface{}
+	if err := json.Unmarshal(result, &consumption); err != nil {
+		return nil, fmt.Errorf("解析消耗报告失败：%v", err)
+	}
+
+	return consumption, nil
+}
+
+func (s *VMIService) queryInventories(orgName string, function string, location string) ([]map[string]interface{}, error) {
+	contract := fabric.GetContract(orgName)
+	result, err := contract.EvaluateTransaction(function, location)
+	if err != nil {
+		return nil, fmt.Errorf("查询寄售库存失败：%s", fabric.ExtractErrorMessage(err))
+	}
+
+	var inventories []map[string]interface{}
+	if err := json.Unmarshal(result, &inventories); err != nil {
+		return nil, fmt.Errorf("解析寄售库存失败：%v", err)
+	}
+
+	return inventories, nil
+}
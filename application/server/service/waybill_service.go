@@ -0,0 +1,116 @@
+package service
+
+import (
+	"application/pkg/fabric"
+	"encoding/json"
+	"fmt"
+)
+
+type WaybillService struct{}
+
+// Waybill 数字运单签发参数
+type Waybill struct {
+	ID           string  `json:"id"`
+	OrderID      string  `json:"orderId"`
+	CarrierID    string  `json:"carrierId"`
+	Receiver     string  `json:"receiver"`
+	CargoDetails string  `json:"cargoDetails"`
+	CargoValue   float64 `json:"cargoValue"`
+}
+
+// waybillEndorsers 运单键的状态背书策略要求核心企业与平台方共同背书
+func waybillEndorsers() fabric.SubmitOption {
+	return fabric.WithEndorsingOrgs(OEM_ORG, PLATFORM_ORG)
+}
+
+// CreateWaybill 核心企业签发数字运单
+func (s *WaybillService) CreateWaybill(waybill Waybill) error {
+	waybillBytes, _ := json.Marshal(waybill)
+	_, err := fabric.Submit(OEM_ORG, "CreateWaybill", []string{string(waybillBytes)}, waybillEndorsers())
+	if err != nil {
+		return fmt.Errorf("签发运单失败：%s", fabric.ExtractErrorMessage(err))
+	}
+	return nil
+}
+
+// ConfirmWaybill 承运方确认运单
+func (s *WaybillService) ConfirmWaybill(orgName string, id string) error {
+	_, err := fabric.Submit(orgName, "ConfirmWaybill", []string{id}, waybillEndorsers())
+	if err != nil {
+		return fmt.Errorf("确认运单失败：%s", fabric.ExtractErrorMessage(err))
+	}
+	return nil
+}
+
+// TransferWaybill 当前持有人转让运单 (签发电子提单前)
+func (s *WaybillService) TransferWaybill(orgName string, id string, newHolderId string) error {
+	_, err := fabric.Submit(orgName, "TransferWaybill", []string{id, newHolderId}, waybillEndorsers())
+	if err != nil {
+		return fmt.Errorf("转让运单失败：%s", fabric.ExtractErrorMessage(err))
+	}
+	return nil
+}
+
+// QueryWaybill 查询运单
+func (s *WaybillService) QueryWaybill(orgName string, id string) (map[string]interface{}, error) {
+	contract := fabric.GetContract(orgName)
+	result, err := contract.EvaluateTransaction("QueryWaybill", id)
+	if err != nil {
+		return nil, fmt.Errorf("查询运单失败：%s", fabric.ExtractErrorMessage(err))
+	}
+
+	var waybill map[string]interface{}
+	if err := json.Unmarshal(result, &waybill); err != nil {
+		return nil, fmt.Errorf("解析运单失败：%v", err)
+	}
+
+	return waybill, nil
+}
+
+// QueryWaybillList 分页查询运单列表
+func (s *WaybillService) QueryWaybillList(orgName string, pageSize int32, bookmark string, status string) (map[string]interface{}, error) {
+	contract := fabric.GetContract(orgName)
+	result, err := contract.EvaluateTransaction("QueryWaybillList", fmt.Sprintf("%d", pageSize), bookmark, status)
+	if err != nil {
+		return nil, fmt.Errorf("查询运单列表失败：%s", fabric.ExtractErrorMessage(err))
+	}
+
+	var queryResult map[string]interface{}
+	if err := json.Unmarshal(result, &queryResult); err != nil {
+		return nil, fmt.Errorf("解析查询结果失败：%v", err)
+	}
+
+	return queryResult, nil
+}
+
+// QueryOrderWaybills 查询订单关联的运单
+func (s *WaybillService) QueryOrderWaybills(orgName string, orderId string) ([]map[string]interface{}, error) {
+	contract := fabric.GetContract(orgName)
+	result, err := contract.EvaluateTransaction("QueryOrderWaybills", orderId)
+	if err != nil {
+		return nil, fmt.Errorf("查询订单运单失败：%s", fabric.ExtractErrorMessage(err))
+	}
+
+	var waybills []map[string]interface{}
+	if err := json.Unmarshal(result, &waybills); err != nil {
+		return nil, fmt.Errorf("解析运单失败：%v", err)
+	}
+
+	return waybills, nil
+}
+
+// VerifyWaybill 平台方校验运单内容哈希
+func (s *WaybillService) VerifyWaybill(id string) (bool, error) {
+	contract := fabric.GetContract(PLATFORM_ORG)
+	result, err := contract.EvaluateTransaction("VerifyWaybill", id)
+	if err != nil {
+		return false, fmt.Errorf("校验运单失败：%s", fabric.ExtractErrorMessage(err))
+	}
+
+	var valid bool
+	if err := json.Unmarshal(result, &valid); err != nil {
+		return false, fmt.Errorf("解析校验结果失败：%v", err)
+	}
+
+	return valid, nil
+}
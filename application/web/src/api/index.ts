@@ -1,5 +1,5 @@
 import request from '../utils/request';
-import type { Order, OrderItem, SupplyChainPageResult } from '../types';
+import type { Order, OrderItem, OrderPrice, SupplyChainPageResult } from '../types';
 
 export const supplyChainApi = {
   // 主机厂 (OEM)
@@ -27,6 +27,11 @@ export const supplyChainApi = {
   getOrder: (id: string) =>
     request.get<never, Order>(`/oem/order/${id}`),
 
+  getOrderPrice: (id: string, role: string) => {
+    const basePath = role === 'MANUFACTURER' ? '/manufacturer' : '/oem';
+    return request.get<never, OrderPrice>(`${basePath}/order/${id}/price`);
+  },
+
   getOrderList: (params: { pageSize: number; bookmark: string }, role: string) => {
     // 根据角色决定调用的基础路径
     let basePath = '/platform';
@@ -1,7 +1,10 @@
 export interface OrderItem {
+  partNumber?: string; // 零件号 (引用零件主数据)
   name: string;
+  unitOfMeasure?: string;
+  drawingRevision?: string;
   quantity: number;
-  price: number;
+  price?: number; // 仅下单时提交, 链上公开数据不含单价
 }
 
 export type OrderStatus = 'CREATED' | 'ACCEPTED' | 'PRODUCING' | 'PRODUCED' | 'READY' | 'SHIPPED' | 'DELIVERED' | 'RECEIVED';
@@ -12,18 +15,27 @@ export interface Order {
   manufacturerId: string;
   items: OrderItem[];
   status: OrderStatus;
-  totalPrice: number;
+  priceHash: string;
   shipmentId: string;
   createTime: string;
   updateTime: string;
 }
 
+// 订单价格 (私有数据, 仅主机厂与厂商可查询)
+export interface OrderPrice {
+  orderId: string;
+  itemPrices: number[];
+  totalPrice: number;
+}
+
+export type ShipmentStatus = 'IN_TRANSIT' | 'EXCEPTION' | 'DELIVERED' | 'LOST';
+
 export interface Shipment {
   id: string;
   orderId: string;
   carrierId: string;
   location: string;
-  status: string;
+  status: ShipmentStatus;
   updateTime: string;
 }
 
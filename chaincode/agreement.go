@@ -0,0 +1,407 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/v2/contractapi"
+)
+
+// 框架协议资产类型
+const (
+	FRAMEWORK_AGREEMENT = "AGREEMENT" // 年度框架协议
+)
+
+// AgreementStatus 框架协议状态
+type AgreementStatus string
+
+const (
+	AGREEMENT_PROPOSED   AgreementStatus = "PROPOSED"   // 主机厂已发起, 待厂商确认
+	AGREEMENT_ACTIVE     AgreementStatus = "ACTIVE"     // 已生效, 可下达调用订单
+	AGREEMENT_TERMINATED AgreementStatus = "TERMINATED" // 已终止
+)
+
+// FrameworkAgreement 框架协议 (单价存于私有集合, 公开部分仅保留哈希)
+type FrameworkAgreement struct {
+	ID             string          `json:"id"`             // 协议ID
+	ObjectType     string          `json:"objectType"`     // 资产类型 (AGREEMENT)
+	OEMID          string          `json:"oemId"`          // 主机厂组织 ID
+	ManufacturerID string          `json:"manufacturerId"` // 零部件厂商 ID
+	Parts          []AgreementPart `json:"parts"`          // 协议零件及承诺数量
+	ValidFrom      time.Time       `json:"validFrom"`      // 生效时间
+	ValidTo        time.Time       `json:"validTo"`        // 失效时间
+	Status         AgreementStatus `json:"status"`         // 当前状态
+	PriceHash      string          `json:"priceHash"`      // 私有价格数据哈希 (SHA-256)
+	OrderIDs       []string        `json:"orderIds"`       // 已下达的调用订单
+	CreateTime     time.Time       `json:"createTime"`     // 创建时间
+	UpdateTime     time.Time       `json:"updateTime"`     // 更新时间
+}
+
+// AgreementPart 协议零件
+type AgreementPart struct {
+	PartNumber        string `json:"partNumber,omitempty"` // 零件号
+	Name              string `json:"name"`                 // 零件名称
+	CommittedQuantity int    `json:"committedQuantity"`    // 承诺总量
+	ConsumedQuantity  int    `json:"consumedQuantity"`     // 已调用数量
+}
+
+// AgreementPrice 框架协议单价 (私有数据)
+type AgreementPrice struct {
+	AgreementID string    `json:"agreementId"` // 协议ID
+	ItemPrices  []float64 `json:"itemPrices"`  // 单价, 与 FrameworkAgreement.Parts 一一对应
+	Salt        string    `json:"salt"`        // 随机盐
+}
+
+// AgreementUtilisation 协议执行情况
+type AgreementUtilisation struct {
+	AgreementID string                     `json:"agreementId"` // 协议ID
+	Status      AgreementStatus            `json:"status"`      // 协议状态
+	ValidTo     time.Time                  `json:"validTo"`     // 失效时间
+	OrderCount  int                        `json:"orderCount"`  // 调用订单数
+	Parts       []AgreementPartUtilisation `json:"parts"`       // 各零件执行情况
+}
+
+// AgreementPartUtilisation 单个零件的执行情况
+type AgreementPartUtilisation struct {
+	Name              string  `json:"name"`              // 零件名称
+	CommittedQuantity int     `json:"committedQuantity"` // 承诺总量
+	ConsumedQuantity  int     `json:"consumedQuantity"`  // 已调用数量
+	RemainingQuantity int     `json:"remainingQuantity"` // 剩余数量
+	UtilisationRate   float64 `json:"utilisationRate"`   // 执行率 (0-1)
+}
+
+// CreateFrameworkAgreement 主机厂发起框架协议 (仅 Org1 可调用, 单价通过瞬态字段 price 传入)
+func (s *SmartContract) CreateFrameworkAgreement(ctx contractapi.TransactionContextInterface, id string, manufacturerId string, partsJson string, validFrom string, validTo string) error {
+	clientMSPID, err := s.getClientIdentityMSPID(ctx)
+	if err != nil {
+		return err
+	}
+	if clientMSPID != OEM_ORG_MSPID {
+		return fmt.Errorf("无权限: 仅限主机厂发起框架协议")
+	}
+
+	existing, err := ctx.GetStub().GetState(id)
+	if err != nil {
+		return fmt.Errorf("读取框架协议失败: %v", err)
+	}
+	if existing != nil {
+		return fmt.Errorf("框架协议 %s 已存在", id)
+	}
+	// 协议厂商须已完成准入, 确认与背书均按其映射的 MSP ID
+	mspID, err := s.resolveManufacturerMSPID(ctx, manufacturerId)
+	if err != nil {
+		return err
+	}
+
+	var parts []AgreementPart
+	if err := json.Unmarshal([]byte(partsJson), &parts); err != nil {
+		return fmt.Errorf("解析协议零件失败: %v", err)
+	}
+	if len(parts) == 0 {
+		return fmt.Errorf("协议零件不能为空")
+	}
+	seen := make(map[string]bool)
+	seenPartNumbers := make(map[string]bool)
+	for i := range parts {
+		if parts[i].CommittedQuantity <= 0 {
+			return fmt.Errorf("零件 %s 承诺数量必须大于 0", parts[i].Name)
+		}
+		if seen[parts[i].Name] {
+			return fmt.Errorf("零件 %s 重复", parts[i].Name)
+		}
+		seen[parts[i].Name] = true
+		parts[i].ConsumedQuantity = 0
+
+		// 协议零件须引用零件主数据, 调用订单沿用协议零件号
+		if parts[i].PartNumber == "" {
+			return fmt.Errorf("零件 %s 缺少零件号: 协议须引用零件主数据", parts[i].Name)
+		}
+		if seenPartNumbers[parts[i].PartNumber] {
+			return fmt.Errorf("零件号 %s 重复", parts[i].PartNumber)
+		}
+		seenPartNumbers[parts[i].PartNumber] = true
+		part, err := s.QueryPart(ctx, parts[i].PartNumber)
+		if err != nil {
+			return err
+		}
+		if part.Status != PART_ACTIVE {
+			return fmt.Errorf("零件 %s 已停用", parts[i].PartNumber)
+		}
+		if len(part.ApprovedManufacturers) > 0 && !containsString(part.ApprovedManufacturers, manufacturerId) {
+			return fmt.Errorf("厂商 %s 不是零件 %s 的合格供应商", manufacturerId, parts[i].PartNumber)
+		}
+	}
+
+	validFromTime, err := time.Parse(time.RFC3339, validFrom)
+	if err != nil {
+		return fmt.Errorf("解析生效时间失败: %v", err)
+	}
+	validToTime, err := time.Parse(time.RFC3339, validTo)
+	if err != nil {
+		return fmt.Errorf("解析失效时间失败: %v", err)
+	}
+	if !validToTime.After(validFromTime) {
+		return fmt.Errorf("失效时间必须晚于生效时间")
+	}
+
+	transientMap, err := ctx.GetStub().GetTransient()
+	if err != nil {
+		return fmt.Errorf("读取瞬态数据失败: %v", err)
+	}
+	priceJson, ok := transientMap[ORDER_PRICE_TRANSIENT]
+	if !ok {
+		return fmt.Errorf("缺少价格数据: 需通过瞬态字段 %s 传入", ORDER_PRICE_TRANSIENT)
+	}
+	var price AgreementPrice
+	if err := json.Unmarshal(priceJson, &price); err != nil {
+		return fmt.Errorf("解析价格数据失败: %v", err)
+	}
+	if len(price.ItemPrices) != len(parts) {
+		return fmt.Errorf("单价数量 %d 与零件数量 %d 不一致", len(price.ItemPrices), len(parts))
+	}
+	if price.Salt == "" {
+		return fmt.Errorf("价格数据缺少随机盐")
+	}
+	price.AgreementID = id
+
+	priceBytes, err := json.Marshal(price)
+	if err != nil {
+		return fmt.Errorf("序列化协议价格失败: %v", err)
+	}
+	if err := ctx.GetStub().PutPrivateData(ORDER_PRICE_COLLECTION, id, priceBytes); err != nil {
+		return fmt.Errorf("写入协议价格失败: %v", err)
+	}
+	priceHash := sha256.Sum256(priceBytes)
+
+	now, err := s.getTxTimestamp(ctx)
+	if err != nil {
+		return err
+	}
+
+	agreement := FrameworkAgreement{
+		ID:             id,
+		ObjectType:     FRAMEWORK_AGREEMENT,
+		OEMID:          clientMSPID,
+		ManufacturerID: manufacturerId,
+		Parts:          parts,
+		ValidFrom:      validFromTime,
+		ValidTo:        validToTime,
+		Status:         AGREEMENT_PROPOSED,
+		PriceHash:      hex.EncodeToString(priceHash[:]),
+		OrderIDs:       []string{},
+		CreateTime:     now,
+		UpdateTime:     now,
+	}
+	if err := s.putAgreement(ctx, &agreement); err != nil {
+		return err
+	}
+	return s.setEndorsementPolicy(ctx, id, clientMSPID, mspID)
+}
+
+// AcceptFrameworkAgreement 零部件厂确认框架协议 (仅协议厂商映射的 MSP 可调用)
+func (s *SmartContract) AcceptFrameworkAgreement(ctx contractapi.TransactionContextInterface, id string) error {
+	clientMSPID, err := s.getClientIdentityMSPID(ctx)
+	if err != nil {
+		return err
+	}
+
+	agreement, err := s.QueryFrameworkAgreement(ctx, id)
+	if err != nil {
+		return err
+	}
+	mspID, err := s.resolveManufacturerMSPID(ctx, agreement.ManufacturerID)
+	if err != nil {
+		return err
+	}
+	if clientMSPID != mspID {
+		return fmt.Errorf("无权限: 仅限协议厂商确认框架协议")
+	}
+	if agreement.Status != AGREEMENT_PROPOSED {
+		return fmt.Errorf("框架协议当前状态 %s 无法确认", agreement.Status)
+	}
+
+	now, err := s.getTxTimestamp(ctx)
+	if err != nil {
+		return err
+	}
+	agreement.Status = AGREEMENT_ACTIVE
+	agreement.UpdateTime = now
+	return s.putAgreement(ctx, agreement)
+}
+
+// TerminateFrameworkAgreement 主机厂终止框架协议 (仅 Org1 可调用)
+func (s *SmartContract) TerminateFrameworkAgreement(ctx contractapi.TransactionContextInterface, id string) error {
+	clientMSPID, err := s.getClientIdentityMSPID(ctx)
+	if err != nil {
+		return err
+	}
+	if clientMSPID != OEM_ORG_MSPID {
+		return fmt.Errorf("无权限: 仅限主机厂终止框架协议")
+	}
+
+	agreement, err := s.QueryFrameworkAgreement(ctx, id)
+	if err != nil {
+		return err
+	}
+	if agreement.Status == AGREEMENT_TERMINATED {
+		return fmt.Errorf("框架协议已终止")
+	}
+
+	now, err := s.getTxTimestamp(ctx)
+	if err != nil {
+		return err
+	}
+	agreement.Status = AGREEMENT_TERMINATED
+	agreement.UpdateTime = now
+	return s.putAgreement(ctx, agreement)
+}
+
+// CallOffOrder 在框架协议下下达调用订单 (仅 Org1 可调用)
+// 单价取自协议私有数据, 数量不得超过剩余承诺量
+func (s *SmartContract) CallOffOrder(ctx contractapi.TransactionContextInterface, agreementId string, orderId string, itemsJson string) error {
+	clientMSPID, err := s.getClientIdentityMSPID(ctx)
+	if err != nil {
+		return err
+	}
+	if clientMSPID != OEM_ORG_MSPID {
+		return fmt.Errorf("无权限: 仅限主机厂下达调用订单")
+	}
+
+	agreement, err := s.QueryFrameworkAgreement(ctx, agreementId)
+	if err != nil {
+		return err
+	}
+	if agreement.Status != AGREEMENT_ACTIVE {
+		return fmt.Errorf("框架协议当前状态 %s 无法下单", agreement.Status)
+	}
+
+	now, err := s.getTxTimestamp(ctx)
+	if err != nil {
+		return err
+	}
+	if now.Before(agreement.ValidFrom) || now.After(agreement.ValidTo) {
+		return fmt.Errorf("当前时间不在框架协议有效期内")
+	}
+
+	var items []OrderItem
+	if err := json.Unmarshal([]byte(itemsJson), &items); err != nil {
+		return fmt.Errorf("解析零件清单失败: %v", err)
+	}
+	if len(items) == 0 {
+		return fmt.Errorf("零件清单不能为空")
+	}
+
+	priceBytes, err := ctx.GetStub().GetPrivateData(ORDER_PRICE_COLLECTION, agreementId)
+	if err != nil {
+		return fmt.Errorf("读取协议价格失败: %v", err)
+	}
+	if priceBytes == nil {
+		return fmt.Errorf("框架协议 %s 价格不存在", agreementId)
+	}
+	var agreementPrice AgreementPrice
+	if err := json.Unmarshal(priceBytes, &agreementPrice); err != nil {
+		return fmt.Errorf("解析协议价格失败: %v", err)
+	}
+
+	itemPrices := make([]float64, 0, len(items))
+	for i := range items {
+		item := &items[i]
+		if item.Quantity <= 0 {
+			return fmt.Errorf("零件 %s 数量必须大于 0", item.Name)
+		}
+		index := -1
+		for j, part := range agreement.Parts {
+			if part.Name == item.Name || (item.PartNumber != "" && part.PartNumber == item.PartNumber) {
+				index = j
+				break
+			}
+		}
+		if index < 0 {
+			return fmt.Errorf("零件 %s 不在框架协议范围内", item.Name)
+		}
+		part := &agreement.Parts[index]
+		item.PartNumber = part.PartNumber
+		if remaining := part.CommittedQuantity - part.ConsumedQuantity; item.Quantity > remaining {
+			return fmt.Errorf("零件 %s 剩余可调用数量 %d, 本次需求 %d", item.Name, remaining, item.Quantity)
+		}
+		part.ConsumedQuantity += item.Quantity
+		itemPrices = append(itemPrices, agreementPrice.ItemPrices[index])
+	}
+	if err := requirePartNumbers(items); err != nil {
+		return err
+	}
+
+	// 以协议盐派生订单盐, 避免同价订单哈希相同
+	price, err := newOrderPrice(orderId, items, itemPrices, agreementPrice.Salt+":"+orderId)
+	if err != nil {
+		return err
+	}
+	order := &Order{
+		ID:             orderId,
+		OEMID:          clientMSPID,
+		ManufacturerID: agreement.ManufacturerID,
+		Items:          items,
+		AgreementID:    agreementId,
+	}
+	if err := s.createOrder(ctx, order, price); err != nil {
+		return err
+	}
+
+	agreement.OrderIDs = append(agreement.OrderIDs, orderId)
+	agreement.UpdateTime = now
+	return s.putAgreement(ctx, agreement)
+}
+
+// QueryFrameworkAgreement 查询框架协议详情
+func (s *SmartContract) QueryFrameworkAgreement(ctx contractapi.TransactionContextInterface, id string) (*FrameworkAgreement, error) {
+	agreementBytes, err := ctx.GetStub().GetState(id)
+	if err != nil {
+		return nil, fmt.Errorf("读取框架协议失败: %v", err)
+	}
+	if agreementBytes == nil {
+		return nil, fmt.Errorf("框架协议 %s 不存在", id)
+	}
+
+	var agreement FrameworkAgreement
+	if err := json.Unmarshal(agreementBytes, &agreement); err != nil || agreement.ObjectType != FRAMEWORK_AGREEMENT {
+		return nil, fmt.Errorf("框架协议 %s 不存在", id)
+	}
+	return &agreement, nil
+}
+
+// QueryAgreementUtilisation 查询框架协议执行情况
+func (s *SmartContract) QueryAgreementUtilisation(ctx contractapi.TransactionContextInterface, id string) (*AgreementUtilisation, error) {
+	agreement, err := s.QueryFrameworkAgreement(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	utilisation := &AgreementUtilisation{
+		AgreementID: agreement.ID,
+		Status:      agreement.Status,
+		ValidTo:     agreement.ValidTo,
+		OrderCount:  len(agreement.OrderIDs),
+		Parts:       make([]AgreementPartUtilisation, 0, len(agreement.Parts)),
+	}
+	for _, part := range agreement.Parts {
+		utilisation.Parts = append(utilisation.Parts, AgreementPartUtilisation{
+			Name:              part.Name,
+			CommittedQuantity: part.CommittedQuantity,
+			ConsumedQuantity:  part.ConsumedQuantity,
+			RemainingQuantity: part.CommittedQuantity - part.ConsumedQuantity,
+			UtilisationRate:   float64(part.ConsumedQuantity) / float64(part.CommittedQuantity),
+		})
+	}
+	return utilisation, nil
+}
+
+func (s *SmartContract) putAgreement(ctx contractapi.TransactionContextInterface, agreement *FrameworkAgreement) error {
+	agreementBytes, err := json.Marshal(agreement)
+	if err != nil {
+		return fmt.Errorf("序列化框架协议失败: %v", err)
+	}
+	return ctx.GetStub().PutState(agreement.ID, agreementBytes)
+}
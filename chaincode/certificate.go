@@ -0,0 +1,430 @@
+package main
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/v2/contractapi"
+)
+
+// 质量证书资产类型
+const (
+	CERTIFICATE = "CERTIFICATE" // 质量证书
+	LAB_KEY     = "LAB_KEY"     // 第三方实验室公钥 (复合键: LAB_KEY~labId)
+
+	CERT_ORDER_INDEX = "CERT_ORDER" // 订单证书索引 (复合键: CERT_ORDER~orderId~certificateId)
+	CERT_BATCH_INDEX = "CERT_BATCH" // 批次证书索引 (复合键: CERT_BATCH~batchNo~certificateId)
+)
+
+// CertificateType 证书类型 (常用类型如下, 也可由双方约定其他类型)
+type CertificateType string
+
+const (
+	CERT_CONFORMITY    CertificateType = "COC" // 产品合格证 (Certificate of Conformity)
+	CERT_MATERIAL_TEST CertificateType = "MTR" // 材料检测报告 (Material Test Report)
+)
+
+// Certificate 质量证书 (原件链下存储, 链上仅锚定文档哈希)
+type Certificate struct {
+	ID           string          `json:"id"`                     // 证书ID
+	ObjectType   string          `json:"objectType"`             // 资产类型 (CERTIFICATE)
+	OrderID      string          `json:"orderId,omitempty"`      // 关联订单ID
+	BatchNo      string          `json:"batchNo,omitempty"`      // 关联生产批次号
+	Type         CertificateType `json:"type"`                   // 证书类型
+	DocHash      string          `json:"docHash"`                // 证书文档 SHA-256
+	Issuer       string          `json:"issuer"`                 // 出具方
+	ValidFrom    time.Time       `json:"validFrom"`              // 生效时间
+	ValidUntil   time.Time       `json:"validUntil"`             // 失效时间
+	LabID        string          `json:"labId,omitempty"`        // 第三方实验室ID (可选)
+	LabSignature string          `json:"labSignature,omitempty"` // 实验室对文档哈希的签名 (Base64)
+	LabVerified  bool            `json:"labVerified"`            // 实验室签名已通过登记公钥验证
+	UploaderID   string          `json:"uploaderId"`             // 登记组织 ID (零部件厂商)
+	CreateTime   time.Time       `json:"createTime"`             // 登记时间
+}
+
+// LabKey 第三方实验室登记公钥 (平台方维护)
+type LabKey struct {
+	LabID        string    `json:"labId"`        // 实验室ID
+	ObjectType   string    `json:"objectType"`   // 资产类型 (LAB_KEY)
+	Name         string    `json:"name"`         // 实验室名称
+	PublicKey    string    `json:"publicKey"`    // PEM 格式公钥 (ECDSA / RSA / Ed25519)
+	RegisteredBy string    `json:"registeredBy"` // 登记组织 ID
+	UpdateTime   time.Time `json:"updateTime"`   // 更新时间
+}
+
+// RegisterLabKey 平台方登记或更新第三方实验室公钥 (仅平台方可调用)
+func (s *SmartContract) RegisterLabKey(ctx contractapi.TransactionContextInterface, labId string, name string, publicKeyPem string) error {
+	isPlatform, err := s.isPlatformIdentity(ctx)
+	if err != nil {
+		return err
+	}
+	if !isPlatform {
+		return fmt.Errorf("无权限: 仅限平台方登记实验室公钥")
+	}
+	if labId == "" {
+		return fmt.Errorf("实验室ID不能为空")
+	}
+	if _, err := parseLabPublicKey(publicKeyPem); err != nil {
+		return err
+	}
+
+	now, err := s.getTxTimestamp(ctx)
+	if err != nil {
+		return err
+	}
+	labKey := &LabKey{
+		LabID:        labId,
+		ObjectType:   LAB_KEY,
+		Name:         name,
+		PublicKey:    publicKeyPem,
+		RegisteredBy: PLATFORM_ORG_MSPID,
+		UpdateTime:   now,
+	}
+	labKeyBytes, err := json.Marshal(labKey)
+	if err != nil {
+		return fmt.Errorf("序列化实验室公钥失败: %v", err)
+	}
+	key, err := ctx.GetStub().CreateCompositeKey(LAB_KEY, []string{labId})
+	if err != nil {
+		return fmt.Errorf("创建实验室公钥键失败: %v", err)
+	}
+	return ctx.GetStub().PutState(key, labKeyBytes)
+}
+
+// QueryLabKey 查询第三方实验室登记公钥
+func (s *SmartContract) QueryLabKey(ctx contractapi.TransactionContextInterface, labId string) (*LabKey, error) {
+	key, err := ctx.GetStub().CreateCompositeKey(LAB_KEY, []string{labId})
+	if err != nil {
+		return nil, fmt.Errorf("创建实验室公钥键失败: %v", err)
+	}
+	labKeyBytes, err := ctx.GetStub().GetState(key)
+	if err != nil {
+		return nil, fmt.Errorf("读取实验室公钥失败: %v", err)
+	}
+	if labKeyBytes == nil {
+		return nil, fmt.Errorf("实验室 %s 未登记公钥", labId)
+	}
+
+	var labKey LabKey
+	if err := json.Unmarshal(labKeyBytes, &labKey); err != nil {
+		return nil, fmt.Errorf("解析实验室公钥失败: %v", err)
+	}
+	return &labKey, nil
+}
+
+// AddCertificate 零部件厂商为订单或生产批次登记质量证书 (仅 Org2 可调用)
+// 证书附带实验室签名时, 须通过该实验室登记公钥验证
+func (s *SmartContract) AddCertificate(ctx contractapi.TransactionContextInterface, certificateJson string) error {
+	clientMSPID, err := s.getClientIdentityMSPID(ctx)
+	if err != nil {
+		return err
+	}
+	if clientMSPID != MANUFACTURER_ORG_MSPID {
+		return fmt.Errorf("无权限: 仅限零部件厂商登记质量证书")
+	}
+
+	var cert Certificate
+	if err := json.Unmarshal([]byte(certificateJson), &cert); err != nil {
+		return fmt.Errorf("解析质量证书失败: %v", err)
+	}
+	if cert.ID == "" || cert.Type == "" || cert.Issuer == "" {
+		return fmt.Errorf("证书ID、类型与出具方不能为空")
+	}
+	if cert.OrderID == "" && cert.BatchNo == "" {
+		return fmt.Errorf("证书须关联订单或生产批次")
+	}
+	if _, err := hex.DecodeString(cert.DocHash); err != nil || len(cert.DocHash) != sha256.Size*2 {
+		return fmt.Errorf("文档哈希须为 64 位十六进制 SHA-256")
+	}
+	if !cert.ValidUntil.After(cert.ValidFrom) {
+		return fmt.Errorf("证书失效时间须晚于生效时间")
+	}
+
+	existing, err := ctx.GetStub().GetState(cert.ID)
+	if err != nil {
+		return fmt.Errorf("读取质量证书失败: %v", err)
+	}
+	if existing != nil {
+		return fmt.Errorf("质量证书 %s 已存在", cert.ID)
+	}
+
+	if cert.OrderID != "" {
+		order, err := s.QueryOrder(ctx, cert.OrderID)
+		if err != nil {
+			return err
+		}
+		if order.ManufacturerMSPID != clientMSPID {
+			return fmt.Errorf("无权限: 仅限订单供货方登记证书")
+		}
+	}
+	if cert.BatchNo != "" {
+		owned, err := s.isManufacturerBatch(ctx, cert.BatchNo, clientMSPID)
+		if err != nil {
+			return err
+		}
+		if !owned {
+			return fmt.Errorf("批次 %s 未在本厂商的订单中登记", cert.BatchNo)
+		}
+	}
+
+	cert.LabVerified = false
+	if cert.LabID != "" || cert.LabSignature != "" {
+		if err := s.verifyLabSignature(ctx, cert.LabID, cert.DocHash, cert.LabSignature); err != nil {
+			return err
+		}
+		cert.LabVerified = true
+	}
+
+	now, err := s.getTxTimestamp(ctx)
+	if err != nil {
+		return err
+	}
+	cert.ObjectType = CERTIFICATE
+	cert.UploaderID = clientMSPID
+	cert.CreateTime = now
+
+	certBytes, err := json.Marshal(cert)
+	if err != nil {
+		return fmt.Errorf("序列化质量证书失败: %v", err)
+	}
+	if err := ctx.GetStub().PutState(cert.ID, certBytes); err != nil {
+		return err
+	}
+	if cert.OrderID != "" {
+		if err := s.putIndex(ctx, CERT_ORDER_INDEX, cert.OrderID, cert.ID); err != nil {
+			return err
+		}
+	}
+	if cert.BatchNo != "" {
+		if err := s.putIndex(ctx, CERT_BATCH_INDEX, cert.BatchNo, cert.ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SetRequiredCertificates 采购方设置签收前必须具备的证书类型 (签收前可随时调整, 传空数组取消要求)
+func (s *SmartContract) SetRequiredCertificates(ctx contractapi.TransactionContextInterface, orderId string, typesJson string) error {
+	clientMSPID, err := s.getClientIdentityMSPID(ctx)
+	if err != nil {
+		return err
+	}
+
+	order, err := s.QueryOrder(ctx, orderId)
+	if err != nil {
+		return err
+	}
+	if clientMSPID != order.OEMID {
+		return fmt.Errorf("无权限: 仅限采购方设置证书要求")
+	}
+	if rank, ok := orderStatusRank[order.Status]; !ok || rank >= orderStatusRank[ORDER_RECEIVED] {
+		return fmt.Errorf("订单当前状态 %s 无法设置证书要求", order.Status)
+	}
+
+	var types []CertificateType
+	if err := json.Unmarshal([]byte(typesJson), &types); err != nil {
+		return fmt.Errorf("解析证书类型失败: %v", err)
+	}
+	seen := make(map[CertificateType]bool)
+	required := make([]CertificateType, 0, len(types))
+	for _, certType := range types {
+		if certType == "" {
+			return fmt.Errorf("证书类型不能为空")
+		}
+		if !seen[certType] {
+			seen[certType] = true
+			required = append(required, certType)
+		}
+	}
+
+	now, err := s.getTxTimestamp(ctx)
+	if err != nil {
+		return err
+	}
+	order.RequiredCertificates = required
+	order.UpdateTime = now
+	orderBytes, err := json.Marshal(order)
+	if err != nil {
+		return fmt.Errorf("序列化订单失败: %v", err)
+	}
+	return ctx.GetStub().PutState(orderId, orderBytes)
+}
+
+// QueryCertificate 查询质量证书
+func (s *SmartContract) QueryCertificate(ctx contractapi.TransactionContextInterface, id string) (*Certificate, error) {
+	certBytes, err := ctx.GetStub().GetState(id)
+	if err != nil {
+		return nil, fmt.Errorf("读取质量证书失败: %v", err)
+	}
+	if certBytes == nil {
+		return nil, fmt.Errorf("质量证书 %s 不存在", id)
+	}
+
+	var cert Certificate
+	if err := json.Unmarshal(certBytes, &cert); err != nil {
+		return nil, fmt.Errorf("解析质量证书失败: %v", err)
+	}
+	if cert.ObjectType != CERTIFICATE {
+		return nil, fmt.Errorf("质量证书 %s 不存在", id)
+	}
+	return &cert, nil
+}
+
+// QueryOrderCertificates 查询订单的全部质量证书 (含订单内各生产批次的证书)
+func (s *SmartContract) QueryOrderCertificates(ctx contractapi.TransactionContextInterface, orderId string) ([]*Certificate, error) {
+	order, err := s.QueryOrder(ctx, orderId)
+	if err != nil {
+		return nil, err
+	}
+	return s.collectOrderCertificates(ctx, order)
+}
+
+// QueryBatchCertificates 查询生产批次的质量证书
+func (s *SmartContract) QueryBatchCertificates(ctx contractapi.TransactionContextInterface, batchNo string) ([]*Certificate, error) {
+	return s.getIndexedCertificates(ctx, CERT_BATCH_INDEX, batchNo)
+}
+
+// 签收前校验订单要求的证书类型均已具备且在有效期内, 返回缺失的类型
+func (s *SmartContract) missingCertificates(ctx contractapi.TransactionContextInterface, order *Order, now time.Time) ([]CertificateType, error) {
+	if len(order.RequiredCertificates) == 0 {
+		return nil, nil
+	}
+	certs, err := s.collectOrderCertificates(ctx, order)
+	if err != nil {
+		return nil, err
+	}
+
+	present := make(map[CertificateType]bool)
+	for _, cert := range certs {
+		if !now.Before(cert.ValidFrom) && now.Before(cert.ValidUntil) {
+			present[cert.Type] = true
+		}
+	}
+	missing := make([]CertificateType, 0)
+	for _, certType := range order.RequiredCertificates {
+		if !present[certType] {
+			missing = append(missing, certType)
+		}
+	}
+	return missing, nil
+}
+
+func (s *SmartContract) collectOrderCertificates(ctx contractapi.TransactionContextInterface, order *Order) ([]*Certificate, error) {
+	certs, err := s.getIndexedCertificates(ctx, CERT_ORDER_INDEX, order.ID)
+	if err != nil {
+		return nil, err
+	}
+	seen := make(map[string]bool)
+	for _, cert := range certs {
+		seen[cert.ID] = true
+	}
+	for _, item := range order.Items {
+		for _, batch := range item.Batches {
+			batchCerts, err := s.getIndexedCertificates(ctx, CERT_BATCH_INDEX, batch.BatchNo)
+			if err != nil {
+				return nil, err
+			}
+			for _, cert := range batchCerts {
+				if !seen[cert.ID] {
+					seen[cert.ID] = true
+					certs = append(certs, cert)
+				}
+			}
+		}
+	}
+	return certs, nil
+}
+
+func (s *SmartContract) getIndexedCertificates(ctx contractapi.TransactionContextInterface, indexName string, attribute string) ([]*Certificate, error) {
+	ids, err := s.getIndexedIDs(ctx, indexName, attribute)
+	if err != nil {
+		return nil, err
+	}
+	certs := make([]*Certificate, 0, len(ids))
+	for _, id := range ids {
+		cert, err := s.QueryCertificate(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		certs = append(certs, cert)
+	}
+	return certs, nil
+}
+
+// 批次是否登记在该厂商 (按 MSP ID) 的订单中
+func (s *SmartContract) isManufacturerBatch(ctx contractapi.TransactionContextInterface, batchNo string, mspID string) (bool, error) {
+	orderIds, err := s.getIndexedIDs(ctx, BATCH_INDEX, batchNo)
+	if err != nil {
+		return false, err
+	}
+	for _, orderId := range orderIds {
+		order, err := s.QueryOrder(ctx, orderId)
+		if err != nil {
+			return false, err
+		}
+		if order.ManufacturerMSPID == mspID {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// 使用实验室登记公钥验证其对文档哈希 (32 字节摘要) 的签名
+func (s *SmartContract) verifyLabSignature(ctx contractapi.TransactionContextInterface, labId string, docHash string, signature string) error {
+	if labId == "" || signature == "" {
+		return fmt.Errorf("实验室ID与实验室签名须同时提供")
+	}
+	labKey, err := s.QueryLabKey(ctx, labId)
+	if err != nil {
+		return err
+	}
+	publicKey, err := parseLabPublicKey(labKey.PublicKey)
+	if err != nil {
+		return err
+	}
+	digest, _ := hex.DecodeString(docHash)
+	sig, err := base64.StdEncoding.DecodeString(signature)
+	if err != nil {
+		return fmt.Errorf("实验室签名须为 Base64 编码: %v", err)
+	}
+
+	valid := false
+	switch key := publicKey.(type) {
+	case *ecdsa.PublicKey:
+		valid = ecdsa.VerifyASN1(key, digest, sig)
+	case *rsa.PublicKey:
+		valid = rsa.VerifyPKCS1v15(key, crypto.SHA256, digest, sig) == nil
+	case ed25519.PublicKey:
+		valid = ed25519.Verify(key, digest, sig)
+	}
+	if !valid {
+		return fmt.Errorf("实验室 %s 签名验证失败", labId)
+	}
+	return nil
+}
+
+func parseLabPublicKey(publicKeyPem string) (interface{}, error) {
+	block, _ := pem.Decode([]byte(publicKeyPem))
+	if block == nil {
+		return nil, fmt.Errorf("实验室公钥须为 PEM 格式")
+	}
+	publicKey, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("解析实验室公钥失败: %v", err)
+	}
+	switch publicKey.(type) {
+	case *ecdsa.PublicKey, *rsa.PublicKey, ed25519.PublicKey:
+		return publicKey, nil
+	}
+	return nil, fmt.Errorf("不支持的实验室公钥类型, 仅支持 ECDSA / RSA / Ed25519")
+}
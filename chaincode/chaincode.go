@@ -1,12 +1,15 @@
 package main
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"log"
 	"time"
 
 	"github.com/hyperledger/fabric-chaincode-go/v2/pkg/cid"
+	"github.com/hyperledger/fabric-chaincode-go/v2/pkg/statebased"
 	"github.com/hyperledger/fabric-contract-api-go/v2/contractapi"
 )
 
@@ -21,6 +24,13 @@ const (
 	SHIPMENT = "SHIPMENT" // 物流信息
 )
 
+// 私有数据集合 (见 collections_config.json)
+const (
+	ORDER_PRICE_COLLECTION    = "collectionOrderPrice"    // 订单商务条款, 仅 Org1/Org2 可见
+	SUBORDER_PRICE_COLLECTION = "collectionSubOrderPrice" // 子订单商务条款, 仅 Org2 可见 (集合级背书策略仅需 Org2)
+	ORDER_PRICE_TRANSIENT     = "price"                   // 瞬态字段名, 携带 OrderPrice
+)
+
 // OrderStatus 订单状态
 type OrderStatus string
 
@@ -35,36 +45,90 @@ const (
 	ORDER_RECEIVED  OrderStatus = "RECEIVED"  // 已签收确认
 )
 
+// ShipmentStatus 物流状态
+type ShipmentStatus string
+
+const (
+	SHIPMENT_IN_TRANSIT ShipmentStatus = "IN_TRANSIT" // 运输中
+	SHIPMENT_EXCEPTION  ShipmentStatus = "EXCEPTION"  // 存在待处理异常
+	SHIPMENT_DELIVERED  ShipmentStatus = "DELIVERED"  // 已送达
+	SHIPMENT_LOST       ShipmentStatus = "LOST"       // 已确认灭失
+)
+
 // Order 订单信息
 type Order struct {
-	ID             string      `json:"id"`             // 订单ID
-	ObjectType     string      `json:"objectType"`     // 资产类型 (ORDER)
-	OEMID          string      `json:"oemId"`          // 主机厂组织 ID
-	ManufacturerID string      `json:"manufacturerId"` // 零部件厂商 ID
-	Items          []OrderItem `json:"items"`          // 零件清单
-	Status         OrderStatus `json:"status"`         // 当前状态
-	TotalPrice     float64     `json:"totalPrice"`     // 总价
-	ShipmentID     string      `json:"shipmentId"`     // 关联物流单ID
-	CreateTime     time.Time   `json:"createTime"`     // 创建时间
-	UpdateTime     time.Time   `json:"updateTime"`     // 更新时间
+	ID                string      `json:"id"`                // 订单ID
+	ObjectType        string      `json:"objectType"`        // 资产类型 (ORDER)
+	OEMID             string      `json:"oemId"`             // 采购方组织 ID (主机厂; 子订单为一级供应商)
+	ManufacturerID    string      `json:"manufacturerId"`    // 零部件厂商 ID
+	ManufacturerMSPID string      `json:"manufacturerMspId"` // 代表厂商在链上操作的组织 MSP ID (下单时按准入映射解析)
+	Items             []OrderItem `json:"items"`             // 零件清单
+	Status            OrderStatus `json:"status"`            // 当前状态
+	PriceHash         string      `json:"priceHash"`         // 私有价格数据哈希 (SHA-256)
+	ShipmentID        string      `json:"shipmentId"`        // 关联物流单ID
+	InvoiceID         string      `json:"invoiceId"`         // 当前有效发票ID
+	AgreementID       string      `json:"agreementId"`       // 框架协议ID (框架协议下单时)
+	RecallIDs         []string    `json:"recallIds"`         // 涉及的召回单
+	ParentOrderID     string      `json:"parentOrderId"`     // 父订单ID (二级供应商子订单)
+	BlockParent       bool        `json:"blockParent"`       // 子订单签收前父订单不可完成生产
+	CreateTime        time.Time   `json:"createTime"`        // 创建时间
+	UpdateTime        time.Time   `json:"updateTime"`        // 更新时间
+
+	PaymentMilestones    []PaymentMilestone `json:"paymentMilestones,omitempty"`    // 付款计划
+	RequiredCertificates []CertificateType  `json:"requiredCertificates,omitempty"` // 签收前须具备的证书类型
+	PendingECNIDs        []string           `json:"pendingEcnIds,omitempty"`        // 待厂商确认的工程变更 (非空即为标记)
+	ConsignmentLocation  string             `json:"consignmentLocation,omitempty"`  // 寄售入库地点 (签收后入寄售库存, 按消耗开票)
+	ConsignmentReceived  bool               `json:"consignmentReceived,omitempty"`  // 寄售库存已入库 (防止重复入库)
 }
 
 // OrderItem 零件明细
 type OrderItem struct {
-	Name     string  `json:"name"`     // 零件名称
-	Quantity int     `json:"quantity"` // 数量
-	Price    float64 `json:"price"`    // 单价
+	PartNumber       string      `json:"partNumber,omitempty"`       // 零件号 (引用零件主数据)
+	Name             string      `json:"name"`                       // 零件名称 (引用零件号时取主数据描述快照)
+	UnitOfMeasure    string      `json:"unitOfMeasure,omitempty"`    // 计量单位快照
+	DrawingRevision  string      `json:"drawingRevision,omitempty"`  // 下单时图纸版本快照
+	Quantity         int         `json:"quantity"`                   // 数量
+	ReceivedQuantity int         `json:"receivedQuantity,omitempty"` // 实收数量 (签收时登记)
+	Batches          []ItemBatch `json:"batches,omitempty"`          // 生产批次 (生产完成时登记)
+}
+
+// OrderPrice 订单商务条款 (私有数据)
+type OrderPrice struct {
+	OrderID    string    `json:"orderId"`    // 订单ID
+	ItemPrices []float64 `json:"itemPrices"` // 单价, 与 Order.Items 一一对应
+	TotalPrice float64   `json:"totalPrice"` // 总价
+	Salt       string    `json:"salt"`       // 随机盐, 防止对哈希进行价格穷举
 }
 
 // Shipment 物流信息
 type Shipment struct {
-	ID         string    `json:"id"`         // 物流单ID
-	ObjectType string    `json:"objectType"` // 资产类型 (SHIPMENT)
-	OrderID    string    `json:"orderId"`    // 关联订单ID
-	CarrierID  string    `json:"carrierId"`  // 承运商 ID
-	Location   string    `json:"location"`   // 当前位置
-	Status     string    `json:"status"`     // 运输状态
-	UpdateTime time.Time `json:"updateTime"` // 更新时间
+	ID         string         `json:"id"`         // 物流单ID
+	ObjectType string         `json:"objectType"` // 资产类型 (SHIPMENT)
+	OrderID    string         `json:"orderId"`    // 关联订单ID
+	CarrierID  string         `json:"carrierId"`  // 承运商 ID
+	Location   string         `json:"location"`   // 当前位置
+	Status     ShipmentStatus `json:"status"`     // 运输状态
+	UpdateTime time.Time      `json:"updateTime"` // 更新时间
+
+	Legs            []ShipmentLeg    `json:"legs,omitempty"`            // 多段运输
+	CurrentLeg      int              `json:"currentLeg"`                // 当前运输段序号
+	CustodianID     string           `json:"custodianId"`               // 当前实际保管货物的承运商
+	Custody         []CustodyRecord  `json:"custody"`                   // 保管记录
+	PendingHandover *CustodyHandover `json:"pendingHandover,omitempty"` // 待接收方确认的交接
+	CheckpointCount int              `json:"checkpointCount"`           // 轨迹检查点数量
+
+	ExceptionCount     int `json:"exceptionCount"`     // 异常报告总数
+	OpenExceptionCount int `json:"openExceptionCount"` // 待处理异常数
+	DelayHours         int `json:"delayHours"`         // 异常累计延误 (小时)
+
+	DestinationFenceID string   `json:"destinationFenceId,omitempty"` // 目的地地理围栏
+	InsideFenceIDs     []string `json:"insideFenceIds,omitempty"`     // 当前所在的地理围栏
+
+	SensorThresholds     *SensorThresholds `json:"sensorThresholds,omitempty"` // 传感器阈值
+	TelemetryAnchorCount int               `json:"telemetryAnchorCount"`       // 遥测锚定批次数
+	ExcursionCount       int               `json:"excursionCount"`             // 传感器超限次数
+
+	RMAID string `json:"rmaId,omitempty"` // 退货单ID (退货反向物流)
 }
 
 // QueryResponse 分页查询封装
@@ -82,6 +146,10 @@ const (
 	PLATFORM_ORG_MSPID     = "Org3MSP" // 平台方 & 承运商 (共用 Org3)
 )
 
+// MANUFACTURER_MSPIDS 可代表厂商操作的组织: 已加入通道、属于订单价格私有集合并可参与订单状态背书
+// (新组织须先完成上述网络配置再加入此列表, 准入审核据此校验参与方登记的 MSP ID)
+var MANUFACTURER_MSPIDS = []string{MANUFACTURER_ORG_MSPID}
+
 // 获取客户端身份 MSP ID
 func (s *SmartContract) getClientIdentityMSPID(ctx contractapi.TransactionContextInterface) (string, error) {
 	clientID, err := cid.New(ctx.GetStub())
@@ -91,6 +159,41 @@ func (s *SmartContract) getClientIdentityMSPID(ctx contractapi.TransactionContex
 	return clientID.GetMSPID()
 }
 
+// 判断调用方是否为平台方: Org3 身份且证书未携带承运商 carrierId 或银行 bankId 属性
+// (承运商与银行同属 Org3, 仅凭 MSP ID 无法区分平台方)
+func (s *SmartContract) isPlatformIdentity(ctx contractapi.TransactionContextInterface) (bool, error) {
+	clientID, err := cid.New(ctx.GetStub())
+	if err != nil {
+		return false, fmt.Errorf("获取客户端身份失败: %v", err)
+	}
+	mspID, err := clientID.GetMSPID()
+	if err != nil {
+		return false, err
+	}
+	if mspID != PLATFORM_ORG_MSPID {
+		return false, nil
+	}
+	for _, attribute := range []string{CARRIER_ID_ATTRIBUTE, BANK_ID_ATTRIBUTE} {
+		value, found, err := clientID.GetAttributeValue(attribute)
+		if err != nil {
+			return false, fmt.Errorf("读取证书属性失败: %v", err)
+		}
+		if found && value != "" {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// 获取客户端身份唯一标识 (证书主题与签发者), 用于将操作绑定到具体身份
+func (s *SmartContract) getClientIdentityID(ctx contractapi.TransactionContextInterface) (string, error) {
+	clientID, err := cid.New(ctx.GetStub())
+	if err != nil {
+		return "", fmt.Errorf("获取客户端身份失败: %v", err)
+	}
+	return clientID.GetID()
+}
+
 // 获取事务时间 (确定性时间)
 func (s *SmartContract) getTxTimestamp(ctx contractapi.TransactionContextInterface) (time.Time, error) {
 	txTimestamp, err := ctx.GetStub().GetTxTimestamp()
@@ -100,6 +203,71 @@ func (s *SmartContract) getTxTimestamp(ctx contractapi.TransactionContextInterfa
 	return time.Unix(txTimestamp.Seconds, int64(txTimestamp.Nanos)), nil
 }
 
+// 为订单等双边资产设置状态背书策略 (需主机厂与零部件厂商双方背书)
+func (s *SmartContract) setTradingPartnerEndorsementPolicy(ctx contractapi.TransactionContextInterface, key string) error {
+	return s.setEndorsementPolicy(ctx, key, OEM_ORG_MSPID, MANUFACTURER_ORG_MSPID)
+}
+
+// 设置状态背书策略, 此后对该键的修改须获得全部指定组织背书
+func (s *SmartContract) setEndorsementPolicy(ctx contractapi.TransactionContextInterface, key string, mspIDs ...string) error {
+	ep, err := statebased.NewStateEP(nil)
+	if err != nil {
+		return fmt.Errorf("创建背书策略失败: %v", err)
+	}
+	if err := ep.AddOrgs(statebased.RoleTypePeer, mspIDs...); err != nil {
+		return fmt.Errorf("添加背书组织失败: %v", err)
+	}
+	policy, err := ep.Policy()
+	if err != nil {
+		return fmt.Errorf("生成背书策略失败: %v", err)
+	}
+	if err := ctx.GetStub().SetStateValidationParameter(key, policy); err != nil {
+		return fmt.Errorf("设置状态背书策略失败: %v", err)
+	}
+	return nil
+}
+
+// 从瞬态数据中读取订单价格
+func (s *SmartContract) getOrderPriceFromTransient(ctx contractapi.TransactionContextInterface, orderId string, items []OrderItem) (*OrderPrice, error) {
+	transientMap, err := ctx.GetStub().GetTransient()
+	if err != nil {
+		return nil, fmt.Errorf("读取瞬态数据失败: %v", err)
+	}
+	priceJson, ok := transientMap[ORDER_PRICE_TRANSIENT]
+	if !ok {
+		return nil, fmt.Errorf("缺少价格数据: 需通过瞬态字段 %s 传入", ORDER_PRICE_TRANSIENT)
+	}
+
+	var price OrderPrice
+	if err := json.Unmarshal(priceJson, &price); err != nil {
+		return nil, fmt.Errorf("解析价格数据失败: %v", err)
+	}
+	return newOrderPrice(orderId, items, price.ItemPrices, price.Salt)
+}
+
+// 校验单价并按数量计算总价
+func newOrderPrice(orderId string, items []OrderItem, itemPrices []float64, salt string) (*OrderPrice, error) {
+	if len(itemPrices) != len(items) {
+		return nil, fmt.Errorf("单价数量 %d 与零件数量 %d 不一致", len(itemPrices), len(items))
+	}
+	if salt == "" {
+		return nil, fmt.Errorf("价格数据缺少随机盐")
+	}
+
+	price := &OrderPrice{
+		OrderID:    orderId,
+		ItemPrices: itemPrices,
+		Salt:       salt,
+	}
+	for i, item := range items {
+		if itemPrices[i] < 0 {
+			return nil, fmt.Errorf("零件 %s 单价不能为负数", item.Name)
+		}
+		price.TotalPrice += float64(item.Quantity) * itemPrices[i]
+	}
+	return price, nil
+}
+
 // InitLedger 链码初始化 (兼容脚本)
 func (s *SmartContract) InitLedger(ctx contractapi.TransactionContextInterface) error {
 	return nil
@@ -124,45 +292,100 @@ func (s *SmartContract) CreateOrder(ctx contractapi.TransactionContextInterface,
 	if err := json.Unmarshal([]byte(itemsJson), &items); err != nil {
 		return fmt.Errorf("解析零件清单失败: %v", err)
 	}
-
-	var totalPrice float64
-	for _, item := range items {
-		totalPrice += float64(item.Quantity) * item.Price
+	if err := requirePartNumbers(items); err != nil {
+		return err
 	}
 
-	now, err := s.getTxTimestamp(ctx)
+	price, err := s.getOrderPriceFromTransient(ctx, id, items)
 	if err != nil {
 		return err
 	}
 
-	order := Order{
+	order := &Order{
 		ID:             id,
-		ObjectType:     ORDER,
 		OEMID:          clientMSPID,
 		ManufacturerID: manufacturerId,
 		Items:          items,
-		Status:         ORDER_CREATED,
-		TotalPrice:     totalPrice,
-		CreateTime:     now,
-		UpdateTime:     now,
+	}
+	return s.createOrder(ctx, order, price)
+}
+
+// 写入新订单及其私有价格, 并设置订单状态背书策略
+func (s *SmartContract) createOrder(ctx contractapi.TransactionContextInterface, order *Order, price *OrderPrice) error {
+	priceBytes, err := json.Marshal(price)
+	if err != nil {
+		return fmt.Errorf("序列化订单价格失败: %v", err)
+	}
+	priceHash := sha256.Sum256(priceBytes)
+	if err := s.insertOrder(ctx, order, hex.EncodeToString(priceHash[:])); err != nil {
+		return err
+	}
+	if err := ctx.GetStub().PutPrivateData(orderPriceCollection(order), order.ID, priceBytes); err != nil {
+		return fmt.Errorf("写入订单价格失败: %v", err)
+	}
+	// 子订单的二级供应商不在网络内, 此后的修改仅需下单方背书
+	if order.ParentOrderID != "" {
+		return s.setEndorsementPolicy(ctx, order.ID, order.OEMID)
+	}
+	// 此后对该订单的任何修改都必须同时获得主机厂与零部件厂商背书
+	return s.setEndorsementPolicy(ctx, order.ID, order.OEMID, order.ManufacturerMSPID)
+}
+
+// 校验并写入新订单的公开部分 (价格哈希由调用方按所在私有集合计算)
+func (s *SmartContract) insertOrder(ctx contractapi.TransactionContextInterface, order *Order, priceHash string) error {
+	existing, err := ctx.GetStub().GetState(order.ID)
+	if err != nil {
+		return fmt.Errorf("读取订单失败: %v", err)
+	}
+	if existing != nil {
+		return fmt.Errorf("订单 %s 已存在", order.ID)
+	}
+
+	// 主机厂采购订单的供货方须已完成准入, 厂商侧操作按映射的 MSP ID 授权;
+	// 子订单的二级供应商不在网络内, 由下单的一级供应商代为操作
+	if order.ParentOrderID == "" {
+		mspID, err := s.resolveManufacturerMSPID(ctx, order.ManufacturerID)
+		if err != nil {
+			return err
+		}
+		order.ManufacturerMSPID = mspID
+	} else {
+		order.ManufacturerMSPID = order.OEMID
+	}
+	if err := s.snapshotParts(ctx, order); err != nil {
+		return err
+	}
+	for i := range order.Items {
+		order.Items[i].ReceivedQuantity = 0
 	}
 
+	now, err := s.getTxTimestamp(ctx)
+	if err != nil {
+		return err
+	}
+
+	order.ObjectType = ORDER
+	order.Status = ORDER_CREATED
+	order.PriceHash = priceHash
+	order.CreateTime = now
+	order.UpdateTime = now
+
 	orderBytes, err := json.Marshal(order)
 	if err != nil {
 		return fmt.Errorf("序列化订单失败: %v", err)
 	}
-	return ctx.GetStub().PutState(id, orderBytes)
+	if err := ctx.GetStub().PutState(order.ID, orderBytes); err != nil {
+		return fmt.Errorf("保存订单失败: %v", err)
+	}
+	return s.putCounterpartyIndex(ctx, order)
 }
 
-// AcceptOrder 零部件厂接受订单 (仅 Org2 可调用)
+// AcceptOrder 零部件厂接受订单 (仅订单厂商可调用)
 func (s *SmartContract) AcceptOrder(ctx contractapi.TransactionContextInterface, id string) error {
 	clientMSPID, err := s.getClientIdentityMSPID(ctx)
 	if err != nil {
 		return err
 	}
-	if clientMSPID != MANUFACTURER_ORG_MSPID {
-		return fmt.Errorf("无权限: 仅限零部件厂商接受订单")
-	}
 
 	orderBytes, err := ctx.GetStub().GetState(id)
 	if err != nil || orderBytes == nil {
@@ -171,6 +394,9 @@ func (s *SmartContract) AcceptOrder(ctx contractapi.TransactionContextInterface,
 
 	var order Order
 	json.Unmarshal(orderBytes, &order)
+	if clientMSPID != order.ManufacturerMSPID {
+		return fmt.Errorf("无权限: 仅限订单厂商接受订单")
+	}
 
 	if order.Status != ORDER_CREATED {
 		return fmt.Errorf("当前状态 %s 无法接受订单", order.Status)
@@ -182,20 +408,24 @@ func (s *SmartContract) AcceptOrder(ctx contractapi.TransactionContextInterface,
 	}
 	order.Status = ORDER_ACCEPTED
 	order.UpdateTime = now
+	triggerPaymentMilestones(&order, now)
 
 	newOrderBytes, _ := json.Marshal(order)
 	return ctx.GetStub().PutState(id, newOrderBytes)
 }
 
-// UpdateProductionStatus 更新生产状态 (仅 Org2 可调用)
+// 厂商可直接设置的生产状态及其唯一合法前置状态
+var productionStatusTransitions = map[OrderStatus]OrderStatus{
+	ORDER_PRODUCING: ORDER_ACCEPTED,
+	ORDER_READY:     ORDER_PRODUCED,
+}
+
+// UpdateProductionStatus 更新生产状态 (仅订单厂商可调用)
 func (s *SmartContract) UpdateProductionStatus(ctx contractapi.TransactionContextInterface, id string, status string) error {
 	clientMSPID, err := s.getClientIdentityMSPID(ctx)
 	if err != nil {
 		return err
 	}
-	if clientMSPID != MANUFACTURER_ORG_MSPID {
-		return fmt.Errorf("无权限")
-	}
 
 	if id == "" {
 		return fmt.Errorf("订单 ID 不能为空")
@@ -213,13 +443,26 @@ func (s *SmartContract) UpdateProductionStatus(ctx contractapi.TransactionContex
 	if err := json.Unmarshal(orderBytes, &order); err != nil {
 		return fmt.Errorf("解析订单失败: %v", err)
 	}
+	if clientMSPID != order.ManufacturerMSPID {
+		return fmt.Errorf("无权限: 仅限订单厂商更新生产状态")
+	}
+
+	// 厂商仅可推进 ACCEPTED -> PRODUCING 与 PRODUCED -> READY; 生产完成只能经 CompleteProduction 登记批次与序列号 (并受召回拦截)
+	target := OrderStatus(status)
+	if from, ok := productionStatusTransitions[target]; !ok || order.Status != from {
+		return fmt.Errorf("订单当前状态 %s 无法更新为 %s, 生产完成须通过 CompleteProduction 登记生产批次", order.Status, status)
+	}
 
 	now, err := s.getTxTimestamp(ctx)
 	if err != nil {
 		return err
 	}
-	order.Status = OrderStatus(status)
+	if err := s.checkChildOrdersComplete(ctx, &order, target); err != nil {
+		return err
+	}
+	order.Status = target
 	order.UpdateTime = now
+	triggerPaymentMilestones(&order, now)
 
 	newOrderBytes, err := json.Marshal(order)
 	if err != nil {
@@ -237,10 +480,26 @@ func (s *SmartContract) PickupGoods(ctx contractapi.TransactionContextInterface,
 	if clientMSPID != PLATFORM_ORG_MSPID {
 		return fmt.Errorf("无权限: 仅限承运商取货")
 	}
+	carrierID, err := s.getCarrierID(ctx)
+	if err != nil {
+		return err
+	}
 
-	orderBytes, _ := ctx.GetStub().GetState(orderId)
-	var order Order
-	json.Unmarshal(orderBytes, &order)
+	order, err := s.QueryOrder(ctx, orderId)
+	if err != nil {
+		return err
+	}
+	if order.Status != ORDER_READY {
+		return fmt.Errorf("订单当前状态 %s 无法取货, 须为待取货", order.Status)
+	}
+
+	existing, err := ctx.GetStub().GetState(shipmentId)
+	if err != nil {
+		return fmt.Errorf("读取物流单失败: %v", err)
+	}
+	if existing != nil {
+		return fmt.Errorf("物流单 %s 已存在", shipmentId)
+	}
 
 	now, err := s.getTxTimestamp(ctx)
 	if err != nil {
@@ -250,18 +509,34 @@ func (s *SmartContract) PickupGoods(ctx contractapi.TransactionContextInterface,
 	order.Status = ORDER_SHIPPED
 	order.ShipmentID = shipmentId
 	order.UpdateTime = now
+	triggerPaymentMilestones(order, now)
 
 	shipment := Shipment{
 		ID:         shipmentId,
 		ObjectType: SHIPMENT,
 		OrderID:    orderId,
-		CarrierID:  clientMSPID,
+		CarrierID:  carrierID,
 		Location:   "零部件仓库",
-		Status:     "运输中",
+		Status:     SHIPMENT_IN_TRANSIT,
 		UpdateTime: now,
+
+		CustodianID: carrierID,
+		Custody: []CustodyRecord{{
+			CarrierID: carrierID,
+			Location:  "零部件仓库",
+			FromTime:  now,
+		}},
+	}
+	if err := s.appendCheckpoint(ctx, &shipment, Checkpoint{
+		EventType: EVENT_DEPARTED,
+		Place:     shipment.Location,
+		CarrierID: carrierID,
+		Timestamp: now,
+	}); err != nil {
+		return err
 	}
-    
-	orderBytes, err = json.Marshal(order)
+
+	orderBytes, err := json.Marshal(order)
 	if err != nil {
 		return fmt.Errorf("序列化订单失败: %v", err)
 	}
@@ -290,10 +565,26 @@ func (s *SmartContract) UpdateLocation(ctx contractapi.TransactionContextInterfa
 	var shipment Shipment
 	json.Unmarshal(shipmentBytes, &shipment)
 
+	carrierID, err := s.getCarrierID(ctx)
+	if err != nil {
+		return err
+	}
+	if shipment.CustodianID != "" && carrierID != shipment.CustodianID {
+		return fmt.Errorf("无权限: 仅限当前保管承运商更新位置")
+	}
+
 	now, err := s.getTxTimestamp(ctx)
 	if err != nil {
 		return err
 	}
+	if err := s.appendCheckpoint(ctx, &shipment, Checkpoint{
+		EventType: EVENT_POSITION,
+		Place:     location,
+		CarrierID: carrierID,
+		Timestamp: now,
+	}); err != nil {
+		return err
+	}
 	shipment.Location = location
 	shipment.UpdateTime = now
 
@@ -304,26 +595,95 @@ func (s *SmartContract) UpdateLocation(ctx contractapi.TransactionContextInterfa
 	return ctx.GetStub().PutState(shipmentId, newShipmentBytes)
 }
 
-// ConfirmReceipt 主机厂签收 (仅 Org1 可调用)
+// ConfirmReceipt 采购方签收 (主机厂订单仅 Org1 可调用, 子订单由下单的一级供应商签收), 按订单数量全额收货
 func (s *SmartContract) ConfirmReceipt(ctx contractapi.TransactionContextInterface, orderId string) error {
+	return s.confirmReceipt(ctx, orderId, nil)
+}
+
+// ConfirmReceiptWithQuantities 采购方签收并登记每个零件的实收数量
+func (s *SmartContract) ConfirmReceiptWithQuantities(ctx contractapi.TransactionContextInterface, orderId string, quantitiesJson string) error {
+	var quantities []int
+	if err := json.Unmarshal([]byte(quantitiesJson), &quantities); err != nil {
+		return fmt.Errorf("解析实收数量失败: %v", err)
+	}
+	return s.confirmReceipt(ctx, orderId, quantities)
+}
+
+func (s *SmartContract) confirmReceipt(ctx contractapi.TransactionContextInterface, orderId string, quantities []int) error {
 	clientMSPID, err := s.getClientIdentityMSPID(ctx)
 	if err != nil {
 		return err
 	}
-	if clientMSPID != OEM_ORG_MSPID {
+
+	order, err := s.QueryOrder(ctx, orderId)
+	if err != nil {
+		return err
+	}
+	if clientMSPID != order.OEMID {
 		return fmt.Errorf("无权限")
 	}
+	// 主机厂订单须已发运或送达; 子订单的二级供应商不在网络内, 无物流状态, 未签收即可签收
+	if order.ParentOrderID == "" {
+		if order.Status != ORDER_SHIPPED && order.Status != ORDER_DELIVERED {
+			return fmt.Errorf("订单当前状态 %s 无法签收, 须已发运或已送达", order.Status)
+		}
+	} else if order.Status == ORDER_RECEIVED {
+		return fmt.Errorf("子订单 %s 已签收", orderId)
+	}
 
-	orderBytes, _ := ctx.GetStub().GetState(orderId)
-	var order Order
-	json.Unmarshal(orderBytes, &order)
+	if err := s.checkBillOfLadingForReceipt(ctx, order); err != nil {
+		return err
+	}
 
 	now, err := s.getTxTimestamp(ctx)
 	if err != nil {
 		return err
 	}
+	missing, err := s.missingCertificates(ctx, order, now)
+	if err != nil {
+		return err
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("订单 %s 缺少有效的质量证书: %v, 无法签收", orderId, missing)
+	}
+
+	if quantities != nil && len(quantities) != len(order.Items) {
+		return fmt.Errorf("实收数量 %d 与零件数量 %d 不一致", len(quantities), len(order.Items))
+	}
+	for i := range order.Items {
+		received := order.Items[i].Quantity
+		if quantities != nil {
+			received = quantities[i]
+		}
+		if received < 0 {
+			return fmt.Errorf("零件 %s 实收数量不能为负数", order.Items[i].Name)
+		}
+		order.Items[i].ReceivedQuantity = received
+	}
+
 	order.Status = ORDER_RECEIVED
 	order.UpdateTime = now
+	triggerPaymentMilestones(order, now)
+
+	if order.ConsignmentLocation != "" {
+		if err := s.receiveConsignment(ctx, order, now); err != nil {
+			return err
+		}
+	}
+
+	if order.ShipmentID != "" {
+		shipment, err := s.QueryShipment(ctx, order.ShipmentID)
+		if err != nil {
+			return err
+		}
+		if shipment.Status != SHIPMENT_LOST {
+			shipment.Status = SHIPMENT_DELIVERED
+			shipment.UpdateTime = now
+			if err := s.putShipment(ctx, shipment); err != nil {
+				return err
+			}
+		}
+	}
 
 	newOrderBytes, err := json.Marshal(order)
 	if err != nil {
@@ -356,6 +716,63 @@ func (s *SmartContract) QueryShipment(ctx contractapi.TransactionContextInterfac
 	return &shipment, nil
 }
 
+// QueryOrderPrice 查询订单价格 (仅 Org1/Org2 可调用)
+func (s *SmartContract) QueryOrderPrice(ctx contractapi.TransactionContextInterface, orderId string) (*OrderPrice, error) {
+	clientMSPID, err := s.getClientIdentityMSPID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if clientMSPID != OEM_ORG_MSPID && clientMSPID != MANUFACTURER_ORG_MSPID {
+		return nil, fmt.Errorf("无权限: 仅限交易双方查看价格")
+	}
+	order, err := s.QueryOrder(ctx, orderId)
+	if err != nil {
+		return nil, err
+	}
+	if order.ParentOrderID != "" && clientMSPID != order.OEMID {
+		return nil, fmt.Errorf("无权限: 子订单价格仅限下单的一级供应商查看")
+	}
+
+	priceBytes, err := ctx.GetStub().GetPrivateData(orderPriceCollection(order), orderId)
+	if err != nil {
+		return nil, fmt.Errorf("读取订单价格失败: %v", err)
+	}
+	if priceBytes == nil {
+		return nil, fmt.Errorf("订单 %s 价格不存在", orderId)
+	}
+
+	var price OrderPrice
+	if err := json.Unmarshal(priceBytes, &price); err != nil {
+		return nil, fmt.Errorf("解析订单价格失败: %v", err)
+	}
+	return &price, nil
+}
+
+// VerifyOrderPrice 校验私有价格数据与订单公开哈希是否一致 (任意组织可调用, 不暴露价格)
+func (s *SmartContract) VerifyOrderPrice(ctx contractapi.TransactionContextInterface, orderId string) (bool, error) {
+	order, err := s.QueryOrder(ctx, orderId)
+	if err != nil {
+		return false, err
+	}
+
+	priceHash, err := ctx.GetStub().GetPrivateDataHash(orderPriceCollection(order), orderId)
+	if err != nil {
+		return false, fmt.Errorf("读取价格哈希失败: %v", err)
+	}
+	if priceHash == nil {
+		return false, fmt.Errorf("订单 %s 价格不存在", orderId)
+	}
+	return hex.EncodeToString(priceHash) == order.PriceHash, nil
+}
+
+// 订单价格所在私有集合: 子订单价格仅一级供应商可见, 不进入主机厂可读的集合
+func orderPriceCollection(order *Order) string {
+	if order.ParentOrderID != "" {
+		return SUBORDER_PRICE_COLLECTION
+	}
+	return ORDER_PRICE_COLLECTION
+}
+
 // QueryOrderList 分页查询订单 (示例)
 func (s *SmartContract) QueryOrderList(ctx contractapi.TransactionContextInterface, pageSize int32, bookmark string) (*QueryResponse, error) {
 	// 简单的全量查询，实际应使用 CouchDB Selector
@@ -0,0 +1,227 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/v2/contractapi"
+)
+
+// 链下文档锚定资产类型
+const (
+	DOCUMENT           = "DOCUMENT"
+	DOCUMENT_REF_INDEX = "DOCUMENT_REF" // 关联对象文档索引 (复合键: DOCUMENT_REF~refType:refId~documentId)
+)
+
+// DocumentRefType 文档关联对象类型
+type DocumentRefType string
+
+const (
+	DOC_REF_ORDER    DocumentRefType = "ORDER"    // 订单
+	DOC_REF_SHIPMENT DocumentRefType = "SHIPMENT" // 物流单
+)
+
+// DocumentCategory 文档类别
+type DocumentCategory string
+
+const (
+	DOC_DRAWING DocumentCategory = "DRAWING" // 图纸
+	DOC_POD     DocumentCategory = "POD"     // 签收凭证 (Proof of Delivery)
+	DOC_PHOTO   DocumentCategory = "PHOTO"   // 照片
+	DOC_OTHER   DocumentCategory = "OTHER"   // 其他
+)
+
+// Document 链下文档锚定记录 (文件按内容寻址存储在链下, 链上仅保存哈希与元数据)
+type Document struct {
+	ID          string           `json:"id"`          // 文档ID
+	ObjectType  string           `json:"objectType"`  // 资产类型 (DOCUMENT)
+	Hash        string           `json:"hash"`        // 文件内容 SHA-256, 同时为链下存储地址
+	FileName    string           `json:"fileName"`    // 原始文件名
+	ContentType string           `json:"contentType"` // MIME 类型
+	Size        int64            `json:"size"`        // 文件大小 (字节)
+	Category    DocumentCategory `json:"category"`    // 文档类别
+	RefType     DocumentRefType  `json:"refType"`     // 关联对象类型
+	RefID       string           `json:"refId"`       // 关联对象ID
+	UploaderID  string           `json:"uploaderId"`  // 上传方
+	CreateTime  time.Time        `json:"createTime"`  // 锚定时间
+}
+
+// AnchorDocument 锚定链下文档的哈希与元数据, 仅关联订单的交易双方或物流单的承运商可调用
+func (s *SmartContract) AnchorDocument(ctx contractapi.TransactionContextInterface, documentJson string) error {
+	doc, err := s.prepareDocumentAnchor(ctx, documentJson)
+	if err != nil {
+		return err
+	}
+
+	now, err := s.getTxTimestamp(ctx)
+	if err != nil {
+		return err
+	}
+	doc.ObjectType = DOCUMENT
+	doc.CreateTime = now
+
+	docBytes, err := json.Marshal(doc)
+	if err != nil {
+		return fmt.Errorf("序列化文档失败: %v", err)
+	}
+	if err := ctx.GetStub().PutState(doc.ID, docBytes); err != nil {
+		return err
+	}
+	return s.putIndex(ctx, DOCUMENT_REF_INDEX, documentRefKey(doc.RefType, doc.RefID), doc.ID)
+}
+
+// CheckDocumentAnchor 预校验文档能否锚定 (仅查询), 应用在写入链下存储前调用, 避免未授权的内容落盘
+func (s *SmartContract) CheckDocumentAnchor(ctx contractapi.TransactionContextInterface, documentJson string) error {
+	_, err := s.prepareDocumentAnchor(ctx, documentJson)
+	return err
+}
+
+// QueryDocument 查询文档锚定记录 (仅关联订单的交易双方、物流单承运商与平台方可查询)
+func (s *SmartContract) QueryDocument(ctx contractapi.TransactionContextInterface, id string) (*Document, error) {
+	doc, err := s.getDocument(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.authorizeDocumentRead(ctx, doc.RefType, doc.RefID); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+// QueryDocuments 查询订单或物流单的全部文档
+func (s *SmartContract) QueryDocuments(ctx contractapi.TransactionContextInterface, refType string, refId string) ([]*Document, error) {
+	if err := s.authorizeDocumentRead(ctx, DocumentRefType(refType), refId); err != nil {
+		return nil, err
+	}
+	ids, err := s.getIndexedIDs(ctx, DOCUMENT_REF_INDEX, documentRefKey(DocumentRefType(refType), refId))
+	if err != nil {
+		return nil, err
+	}
+	docs := make([]*Document, 0, len(ids))
+	for _, id := range ids {
+		doc, err := s.getDocument(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		docs = append(docs, doc)
+	}
+	return docs, nil
+}
+
+// 校验锚定参数与调用方权限, 返回待写入的文档 (已填入上传方)
+func (s *SmartContract) prepareDocumentAnchor(ctx contractapi.TransactionContextInterface, documentJson string) (*Document, error) {
+	var doc Document
+	if err := json.Unmarshal([]byte(documentJson), &doc); err != nil {
+		return nil, fmt.Errorf("解析文档失败: %v", err)
+	}
+	if doc.ID == "" || doc.RefID == "" {
+		return nil, fmt.Errorf("文档ID与关联对象ID不能为空")
+	}
+	if _, err := hex.DecodeString(doc.Hash); err != nil || len(doc.Hash) != sha256.Size*2 {
+		return nil, fmt.Errorf("文档哈希须为 64 位十六进制 SHA-256")
+	}
+	if doc.Size <= 0 {
+		return nil, fmt.Errorf("文档大小必须大于 0")
+	}
+	switch doc.Category {
+	case DOC_DRAWING, DOC_POD, DOC_PHOTO, DOC_OTHER:
+	default:
+		return nil, fmt.Errorf("无效的文档类别: %s", doc.Category)
+	}
+
+	uploaderID, err := s.authorizeDocumentRef(ctx, doc.RefType, doc.RefID)
+	if err != nil {
+		return nil, err
+	}
+
+	existing, err := ctx.GetStub().GetState(doc.ID)
+	if err != nil {
+		return nil, fmt.Errorf("读取文档失败: %v", err)
+	}
+	if existing != nil {
+		return nil, fmt.Errorf("文档 %s 已存在", doc.ID)
+	}
+	doc.UploaderID = uploaderID
+	return &doc, nil
+}
+
+// 读取文档锚定记录 (不校验权限, 供链码内部引用)
+func (s *SmartContract) getDocument(ctx contractapi.TransactionContextInterface, id string) (*Document, error) {
+	docBytes, err := ctx.GetStub().GetState(id)
+	if err != nil {
+		return nil, fmt.Errorf("读取文档失败: %v", err)
+	}
+	if docBytes == nil {
+		return nil, fmt.Errorf("文档 %s 不存在", id)
+	}
+
+	var doc Document
+	if err := json.Unmarshal(docBytes, &doc); err != nil {
+		return nil, fmt.Errorf("解析文档失败: %v", err)
+	}
+	if doc.ObjectType != DOCUMENT {
+		return nil, fmt.Errorf("文档 %s 不存在", id)
+	}
+	return &doc, nil
+}
+
+// 文档可由平台方及有权为关联对象上传文档的参与方读取
+func (s *SmartContract) authorizeDocumentRead(ctx contractapi.TransactionContextInterface, refType DocumentRefType, refId string) error {
+	isPlatform, err := s.isPlatformIdentity(ctx)
+	if err != nil {
+		return err
+	}
+	if isPlatform {
+		return nil
+	}
+	_, err = s.authorizeDocumentRef(ctx, refType, refId)
+	return err
+}
+
+// 校验调用方为关联对象的参与方 (可上传与读取其文档), 返回上传方ID
+func (s *SmartContract) authorizeDocumentRef(ctx contractapi.TransactionContextInterface, refType DocumentRefType, refId string) (string, error) {
+	clientMSPID, err := s.getClientIdentityMSPID(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	var orderId string
+	switch refType {
+	case DOC_REF_ORDER:
+		orderId = refId
+	case DOC_REF_SHIPMENT:
+		shipment, err := s.QueryShipment(ctx, refId)
+		if err != nil {
+			return "", err
+		}
+		if clientMSPID == PLATFORM_ORG_MSPID {
+			carrierID, err := s.getCarrierID(ctx)
+			if err != nil {
+				return "", err
+			}
+			if carrierID != shipment.CarrierID && carrierID != shipment.CustodianID {
+				return "", fmt.Errorf("无权限: 仅限物流单承运商访问文档")
+			}
+			return carrierID, nil
+		}
+		orderId = shipment.OrderID
+	default:
+		return "", fmt.Errorf("无效的关联对象类型: %s", refType)
+	}
+
+	order, err := s.QueryOrder(ctx, orderId)
+	if err != nil {
+		return "", err
+	}
+	if clientMSPID != order.OEMID && clientMSPID != order.ManufacturerMSPID {
+		return "", fmt.Errorf("无权限: 仅限订单交易双方访问文档")
+	}
+	return clientMSPID, nil
+}
+
+func documentRefKey(refType DocumentRefType, refId string) string {
+	return string(refType) + ":" + refId
+}
@@ -0,0 +1,338 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/hyperledger/fabric-chaincode-go/v2/pkg/cid"
+	"github.com/hyperledger/fabric-contract-api-go/v2/contractapi"
+)
+
+// 电子提单资产类型
+const (
+	EBL       = "EBL"
+	EBL_ORDER = "EBL_ORDER" // 复合键: EBL_ORDER~orderId, 每个订单至多一份电子提单
+)
+
+// EBLStatus 电子提单状态
+type EBLStatus string
+
+const (
+	EBL_ISSUED            EBLStatus = "ISSUED"            // 已签发, 可背书转让
+	EBL_SURRENDER_PENDING EBLStatus = "SURRENDER_PENDING" // 货物未到达目的地时交回, 待承运商确认
+	EBL_SURRENDERED       EBLStatus = "SURRENDERED"       // 已交回承运商, 货物可交付
+)
+
+// TitleEndorsement 提单背书记录
+type TitleEndorsement struct {
+	FromHolderID string    `json:"fromHolderId"` // 背书人 (原持有人)
+	ToHolderID   string    `json:"toHolderId"`   // 被背书人 (新持有人)
+	Note         string    `json:"note"`         // 背书说明
+	Timestamp    time.Time `json:"timestamp"`    // 背书时间
+}
+
+// BillOfLading 可转让电子提单
+type BillOfLading struct {
+	ID            string             `json:"id"`                      // 提单ID
+	ObjectType    string             `json:"objectType"`              // 资产类型 (EBL)
+	WaybillID     string             `json:"waybillId"`               // 关联数字运单ID
+	OrderID       string             `json:"orderId"`                 // 关联订单ID
+	ShipmentID    string             `json:"shipmentId"`              // 关联物流单ID
+	CarrierID     string             `json:"carrierId"`               // 签发承运商
+	ShipperID     string             `json:"shipperId"`               // 托运人 (运单发货方)
+	HolderID      string             `json:"holderId"`                // 当前持有人
+	Status        EBLStatus          `json:"status"`                  // 当前状态
+	Endorsements  []TitleEndorsement `json:"endorsements"`            // 完整背书链
+	IssueTime     time.Time          `json:"issueTime"`               // 签发时间
+	SurrenderedBy string             `json:"surrenderedBy,omitempty"` // 交回人 (交回时的持有人, 提货方)
+	SurrenderTime *time.Time         `json:"surrenderTime,omitempty"` // 交回时间
+	UpdateTime    time.Time          `json:"updateTime"`              // 更新时间
+}
+
+// IssueBillOfLading 运单承运商基于已生效运单签发电子提单, 首个持有人为运单当前持有人
+func (s *SmartContract) IssueBillOfLading(ctx contractapi.TransactionContextInterface, id string, waybillId string) error {
+	carrierID, err := s.getCarrierID(ctx)
+	if err != nil {
+		return err
+	}
+
+	waybill, err := s.QueryWaybill(ctx, waybillId)
+	if err != nil {
+		return err
+	}
+	if waybill.CarrierID != carrierID {
+		return fmt.Errorf("无权限: 仅限运单承运方签发提单")
+	}
+	if waybill.Status != WAYBILL_ACTIVE {
+		return fmt.Errorf("运单当前状态 %s 无法签发提单, 须承运方确认生效", waybill.Status)
+	}
+
+	existing, err := ctx.GetStub().GetState(id)
+	if err != nil {
+		return fmt.Errorf("读取电子提单失败: %v", err)
+	}
+	if existing != nil {
+		return fmt.Errorf("电子提单 %s 已存在", id)
+	}
+	issued, err := s.getOrderBillOfLadingID(ctx, waybill.OrderID)
+	if err != nil {
+		return err
+	}
+	if issued != "" {
+		return fmt.Errorf("订单 %s 已签发电子提单 %s", waybill.OrderID, issued)
+	}
+	// 运单可能在取货前确认生效, 物流单ID以订单为准, 未取货时无法交回提单
+	order, err := s.QueryOrder(ctx, waybill.OrderID)
+	if err != nil {
+		return err
+	}
+	if order.ShipmentID == "" {
+		return fmt.Errorf("订单 %s 尚未取货, 无法签发提单", waybill.OrderID)
+	}
+
+	now, err := s.getTxTimestamp(ctx)
+	if err != nil {
+		return err
+	}
+	ebl := &BillOfLading{
+		ID:           id,
+		ObjectType:   EBL,
+		WaybillID:    waybillId,
+		OrderID:      waybill.OrderID,
+		ShipmentID:   order.ShipmentID,
+		CarrierID:    carrierID,
+		ShipperID:    waybill.Sender,
+		HolderID:     waybill.HolderID,
+		Status:       EBL_ISSUED,
+		Endorsements: []TitleEndorsement{},
+		IssueTime:    now,
+		UpdateTime:   now,
+	}
+	if err := s.putBillOfLading(ctx, ebl); err != nil {
+		return err
+	}
+
+	indexKey, err := ctx.GetStub().CreateCompositeKey(EBL_ORDER, []string{waybill.OrderID})
+	if err != nil {
+		return fmt.Errorf("创建提单索引失败: %v", err)
+	}
+	return ctx.GetStub().PutState(indexKey, []byte(id))
+}
+
+// TransferTitle 当前持有人将提单背书转让给新持有人 (融资锁定期间不可转让)
+func (s *SmartContract) TransferTitle(ctx contractapi.TransactionContextInterface, id string, newHolderId string, note string) error {
+	ebl, partyID, err := s.prepareHolderAction(ctx, id)
+	if err != nil {
+		return err
+	}
+	if newHolderId == "" || newHolderId == ebl.HolderID {
+		return fmt.Errorf("新持有人不能为空且不能与当前持有人相同")
+	}
+
+	now, err := s.getTxTimestamp(ctx)
+	if err != nil {
+		return err
+	}
+	ebl.Endorsements = append(ebl.Endorsements, TitleEndorsement{
+		FromHolderID: partyID,
+		ToHolderID:   newHolderId,
+		Note:         note,
+		Timestamp:    now,
+	})
+	ebl.HolderID = newHolderId
+	ebl.UpdateTime = now
+	return s.putBillOfLading(ctx, ebl)
+}
+
+// SurrenderBillOfLading 当前持有人向承运商交回提单以提货, 交回前订单不可签收
+// 物流单已进入目的地围栏或已送达时直接生效, 否则须签发承运商或当前保管承运商确认
+func (s *SmartContract) SurrenderBillOfLading(ctx contractapi.TransactionContextInterface, id string) error {
+	ebl, partyID, err := s.prepareHolderAction(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	shipment, err := s.QueryShipment(ctx, ebl.ShipmentID)
+	if err != nil {
+		return err
+	}
+	now, err := s.getTxTimestamp(ctx)
+	if err != nil {
+		return err
+	}
+	ebl.SurrenderedBy = partyID
+	ebl.Status = EBL_SURRENDER_PENDING
+	if isShipmentAtDestination(shipment) {
+		ebl.Status = EBL_SURRENDERED
+		ebl.SurrenderTime = &now
+	}
+	ebl.UpdateTime = now
+	return s.putBillOfLading(ctx, ebl)
+}
+
+// AcceptBillOfLadingSurrender 承运商在交付货物时确认持有人交回的提单 (仅签发承运商或当前保管承运商可调用)
+func (s *SmartContract) AcceptBillOfLadingSurrender(ctx contractapi.TransactionContextInterface, id string) error {
+	carrierID, err := s.getCarrierID(ctx)
+	if err != nil {
+		return err
+	}
+
+	ebl, err := s.QueryBillOfLading(ctx, id)
+	if err != nil {
+		return err
+	}
+	if ebl.Status != EBL_SURRENDER_PENDING {
+		return fmt.Errorf("电子提单当前状态 %s 无法确认交回", ebl.Status)
+	}
+	shipment, err := s.QueryShipment(ctx, ebl.ShipmentID)
+	if err != nil {
+		return err
+	}
+	if carrierID != ebl.CarrierID && carrierID != shipment.CustodianID {
+		return fmt.Errorf("无权限: 仅限签发承运商或当前保管承运商确认交回")
+	}
+
+	now, err := s.getTxTimestamp(ctx)
+	if err != nil {
+		return err
+	}
+	ebl.Status = EBL_SURRENDERED
+	ebl.SurrenderTime = &now
+	ebl.UpdateTime = now
+	return s.putBillOfLading(ctx, ebl)
+}
+
+// QueryBillOfLading 查询电子提单 (含完整背书链)
+func (s *SmartContract) QueryBillOfLading(ctx contractapi.TransactionContextInterface, id string) (*BillOfLading, error) {
+	eblBytes, err := ctx.GetStub().GetState(id)
+	if err != nil {
+		return nil, fmt.Errorf("读取电子提单失败: %v", err)
+	}
+	if eblBytes == nil {
+		return nil, fmt.Errorf("电子提单 %s 不存在", id)
+	}
+
+	var ebl BillOfLading
+	if err := json.Unmarshal(eblBytes, &ebl); err != nil {
+		return nil, fmt.Errorf("解析电子提单失败: %v", err)
+	}
+	if ebl.ObjectType != EBL {
+		return nil, fmt.Errorf("电子提单 %s 不存在", id)
+	}
+	return &ebl, nil
+}
+
+// 持有人操作前的公共校验: 调用方须为当前持有人, 提单未交回且不处于融资锁定
+func (s *SmartContract) prepareHolderAction(ctx contractapi.TransactionContextInterface, id string) (*BillOfLading, string, error) {
+	partyID, err := s.getPartyID(ctx)
+	if err != nil {
+		return nil, "", err
+	}
+
+	ebl, err := s.QueryBillOfLading(ctx, id)
+	if err != nil {
+		return nil, "", err
+	}
+	if ebl.HolderID != partyID {
+		return nil, "", fmt.Errorf("无权限: 仅限当前持有人 %s 操作提单", ebl.HolderID)
+	}
+	if ebl.Status != EBL_ISSUED {
+		return nil, "", fmt.Errorf("电子提单当前状态 %s 无法操作", ebl.Status)
+	}
+
+	locked, err := s.isOrderFinancingLocked(ctx, ebl.OrderID)
+	if err != nil {
+		return nil, "", err
+	}
+	if locked {
+		return nil, "", fmt.Errorf("订单 %s 的应收账款处于融资锁定中, 提单不可转让或交回", ebl.OrderID)
+	}
+	return ebl, partyID, nil
+}
+
+// 订单是否存在尚未交回的电子提单
+func (s *SmartContract) hasOutstandingBillOfLading(ctx contractapi.TransactionContextInterface, orderId string) (bool, error) {
+	eblId, err := s.getOrderBillOfLadingID(ctx, orderId)
+	if err != nil || eblId == "" {
+		return false, err
+	}
+	ebl, err := s.QueryBillOfLading(ctx, eblId)
+	if err != nil {
+		return false, err
+	}
+	return ebl.Status != EBL_SURRENDERED, nil
+}
+
+// 签收前校验电子提单: 须已交回, 且交回人为签收方 (提货权随提单转让, 仅最终交回的持有人可提货)
+func (s *SmartContract) checkBillOfLadingForReceipt(ctx contractapi.TransactionContextInterface, order *Order) error {
+	eblId, err := s.getOrderBillOfLadingID(ctx, order.ID)
+	if err != nil || eblId == "" {
+		return err
+	}
+	ebl, err := s.QueryBillOfLading(ctx, eblId)
+	if err != nil {
+		return err
+	}
+	if ebl.Status != EBL_SURRENDERED {
+		return fmt.Errorf("订单 %s 的电子提单尚未交回, 无法签收", order.ID)
+	}
+	if ebl.SurrenderedBy != order.OEMID {
+		return fmt.Errorf("订单 %s 的电子提单由 %s 交回, 仅交回提单的持有人可提货签收", order.ID, ebl.SurrenderedBy)
+	}
+	return nil
+}
+
+// 物流单是否已到达目的地: 已送达, 或当前位于目的地围栏内
+func isShipmentAtDestination(shipment *Shipment) bool {
+	if shipment.Status == SHIPMENT_DELIVERED {
+		return true
+	}
+	return shipment.DestinationFenceID != "" && containsString(shipment.InsideFenceIDs, shipment.DestinationFenceID)
+}
+
+func (s *SmartContract) getOrderBillOfLadingID(ctx contractapi.TransactionContextInterface, orderId string) (string, error) {
+	indexKey, err := ctx.GetStub().CreateCompositeKey(EBL_ORDER, []string{orderId})
+	if err != nil {
+		return "", fmt.Errorf("创建提单索引失败: %v", err)
+	}
+	eblId, err := ctx.GetStub().GetState(indexKey)
+	if err != nil {
+		return "", fmt.Errorf("读取提单索引失败: %v", err)
+	}
+	return string(eblId), nil
+}
+
+// 获取参与方ID: Org1/Org2 为 MSP ID; Org3 依次取证书属性 bankId、carrierId, 均未设置时为 MSP ID
+func (s *SmartContract) getPartyID(ctx contractapi.TransactionContextInterface) (string, error) {
+	clientID, err := cid.New(ctx.GetStub())
+	if err != nil {
+		return "", fmt.Errorf("获取客户端身份失败: %v", err)
+	}
+	mspID, err := clientID.GetMSPID()
+	if err != nil {
+		return "", err
+	}
+	if mspID != PLATFORM_ORG_MSPID {
+		return mspID, nil
+	}
+
+	for _, attribute := range []string{BANK_ID_ATTRIBUTE, CARRIER_ID_ATTRIBUTE} {
+		value, found, err := clientID.GetAttributeValue(attribute)
+		if err != nil {
+			return "", fmt.Errorf("读取证书属性失败: %v", err)
+		}
+		if found && value != "" {
+			return value, nil
+		}
+	}
+	return mspID, nil
+}
+
+func (s *SmartContract) putBillOfLading(ctx contractapi.TransactionContextInterface, ebl *BillOfLading) error {
+	eblBytes, err := json.Marshal(ebl)
+	if err != nil {
+		return fmt.Errorf("序列化电子提单失败: %v", err)
+	}
+	return ctx.GetStub().PutState(ebl.ID, eblBytes)
+}
@@ -0,0 +1,285 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/v2/contractapi"
+)
+
+// 工程变更通知资产类型
+const (
+	ECN       = "ECN"
+	ECN_ORDER = "ECN_ORDER" // 订单工程变更索引 (复合键: ECN_ORDER~orderId~ecnId)
+)
+
+// ECNStatus 工程变更通知状态
+type ECNStatus string
+
+const (
+	ECN_OPEN         ECNStatus = "OPEN"         // 已发布, 待受影响厂商确认
+	ECN_ACKNOWLEDGED ECNStatus = "ACKNOWLEDGED" // 受影响厂商均已确认
+)
+
+// ECNAcknowledgement 厂商对工程变更的确认记录
+type ECNAcknowledgement struct {
+	ManufacturerID  string    `json:"manufacturerId"`  // 确认厂商
+	EffectivityDate time.Time `json:"effectivityDate"` // 变更生效日期 (厂商承诺自该日起按新版本生产)
+	Note            string    `json:"note"`            // 确认说明
+	TxID            string    `json:"txId"`            // 确认交易ID (审计凭证)
+	Timestamp       time.Time `json:"timestamp"`       // 确认时间
+}
+
+// EngineeringChange 工程变更通知 (ECN)
+type EngineeringChange struct {
+	ID                    string               `json:"id"`                    // 变更通知ID
+	ObjectType            string               `json:"objectType"`            // 资产类型 (ECN)
+	IssuerID              string               `json:"issuerId"`              // 发布方 (主机厂)
+	PartNumbers           []string             `json:"partNumbers"`           // 变更零件号
+	DrawingRevision       string               `json:"drawingRevision"`       // 变更后图纸版本
+	Description           string               `json:"description"`           // 变更内容说明
+	DocumentID            string               `json:"documentId,omitempty"`  // 变更图纸文档ID (链下文档锚定记录)
+	AffectedOrderIDs      []string             `json:"affectedOrderIds"`      // 受影响的未完结订单
+	AffectedManufacturers []string             `json:"affectedManufacturers"` // 受影响厂商
+	Acknowledgements      []ECNAcknowledgement `json:"acknowledgements"`      // 厂商确认记录
+	Status                ECNStatus            `json:"status"`                // 当前状态
+	CreateTime            time.Time            `json:"createTime"`            // 发布时间
+	UpdateTime            time.Time            `json:"updateTime"`            // 更新时间
+}
+
+// IssueECN 主机厂发布工程变更通知, 受影响订单在厂商确认前标记为待确认变更 (仅 Org1 可调用)
+func (s *SmartContract) IssueECN(ctx contractapi.TransactionContextInterface, ecnJson string) error {
+	clientMSPID, err := s.getClientIdentityMSPID(ctx)
+	if err != nil {
+		return err
+	}
+	if clientMSPID != OEM_ORG_MSPID {
+		return fmt.Errorf("无权限: 仅限主机厂发布工程变更")
+	}
+
+	var ecn EngineeringChange
+	if err := json.Unmarshal([]byte(ecnJson), &ecn); err != nil {
+		return fmt.Errorf("解析工程变更失败: %v", err)
+	}
+	if ecn.ID == "" || ecn.Description == "" {
+		return fmt.Errorf("变更通知ID与变更内容不能为空")
+	}
+	if len(ecn.PartNumbers) == 0 || len(ecn.AffectedOrderIDs) == 0 {
+		return fmt.Errorf("变更零件与受影响订单不能为空")
+	}
+
+	existing, err := ctx.GetStub().GetState(ecn.ID)
+	if err != nil {
+		return fmt.Errorf("读取工程变更失败: %v", err)
+	}
+	if existing != nil {
+		return fmt.Errorf("工程变更 %s 已存在", ecn.ID)
+	}
+
+	parts := make(map[string]bool)
+	for _, partNumber := range ecn.PartNumbers {
+		if _, err := s.QueryPart(ctx, partNumber); err != nil {
+			return err
+		}
+		parts[partNumber] = true
+	}
+	if ecn.DocumentID != "" {
+		if _, err := s.getDocument(ctx, ecn.DocumentID); err != nil {
+			return err
+		}
+	}
+
+	now, err := s.getTxTimestamp(ctx)
+	if err != nil {
+		return err
+	}
+	manufacturers := make([]string, 0)
+	seenOrders := make(map[string]bool)
+	seenManufacturers := make(map[string]bool)
+	for _, orderId := range ecn.AffectedOrderIDs {
+		if seenOrders[orderId] {
+			return fmt.Errorf("受影响订单 %s 重复", orderId)
+		}
+		seenOrders[orderId] = true
+
+		order, err := s.QueryOrder(ctx, orderId)
+		if err != nil {
+			return err
+		}
+		if order.OEMID != clientMSPID {
+			return fmt.Errorf("无权限: 订单 %s 不属于本主机厂", orderId)
+		}
+		if rank, ok := orderStatusRank[order.Status]; !ok || rank >= orderStatusRank[ORDER_RECEIVED] {
+			return fmt.Errorf("订单 %s 当前状态 %s 已完结, 不受工程变更影响", orderId, order.Status)
+		}
+		affected := false
+		for _, item := range order.Items {
+			if parts[item.PartNumber] {
+				affected = true
+				break
+			}
+		}
+		if !affected {
+			return fmt.Errorf("订单 %s 不包含变更零件", orderId)
+		}
+
+		order.PendingECNIDs = append(order.PendingECNIDs, ecn.ID)
+		order.UpdateTime = now
+		orderBytes, err := json.Marshal(order)
+		if err != nil {
+			return fmt.Errorf("序列化订单失败: %v", err)
+		}
+		if err := ctx.GetStub().PutState(orderId, orderBytes); err != nil {
+			return err
+		}
+		if err := s.putIndex(ctx, ECN_ORDER, orderId, ecn.ID); err != nil {
+			return err
+		}
+
+		if !seenManufacturers[order.ManufacturerID] {
+			seenManufacturers[order.ManufacturerID] = true
+			manufacturers = append(manufacturers, order.ManufacturerID)
+		}
+	}
+
+	ecn.ObjectType = ECN
+	ecn.IssuerID = clientMSPID
+	ecn.AffectedManufacturers = manufacturers
+	ecn.Acknowledgements = []ECNAcknowledgement{}
+	ecn.Status = ECN_OPEN
+	ecn.CreateTime = now
+	ecn.UpdateTime = now
+	if err := s.putECN(ctx, &ecn); err != nil {
+		return err
+	}
+	return s.setTradingPartnerEndorsementPolicy(ctx, ecn.ID)
+}
+
+// AcknowledgeECN 受影响厂商确认工程变更并承诺生效日期, 同时清除其订单上的待确认标记
+// manufacturerId 为订单上的业务厂商ID, 调用方须为该厂商受影响订单映射的 MSP
+func (s *SmartContract) AcknowledgeECN(ctx contractapi.TransactionContextInterface, id string, manufacturerId string, effectivityDate string, note string) error {
+	clientMSPID, err := s.getClientIdentityMSPID(ctx)
+	if err != nil {
+		return err
+	}
+	if !containsString(MANUFACTURER_MSPIDS, clientMSPID) {
+		return fmt.Errorf("无权限: 仅限零部件厂商确认工程变更")
+	}
+
+	ecn, err := s.QueryECN(ctx, id)
+	if err != nil {
+		return err
+	}
+	if !containsString(ecn.AffectedManufacturers, manufacturerId) {
+		return fmt.Errorf("厂商 %s 不在工程变更 %s 的受影响范围内", manufacturerId, id)
+	}
+	for _, ack := range ecn.Acknowledgements {
+		if ack.ManufacturerID == manufacturerId {
+			return fmt.Errorf("厂商 %s 已确认工程变更 %s", manufacturerId, id)
+		}
+	}
+
+	orders := make([]*Order, 0)
+	for _, orderId := range ecn.AffectedOrderIDs {
+		order, err := s.QueryOrder(ctx, orderId)
+		if err != nil {
+			return err
+		}
+		if order.ManufacturerID != manufacturerId {
+			continue
+		}
+		if order.ManufacturerMSPID != clientMSPID {
+			return fmt.Errorf("无权限: 订单 %s 的厂商 %s 不属于调用方组织", orderId, manufacturerId)
+		}
+		orders = append(orders, order)
+	}
+
+	effectivity, err := time.Parse(time.RFC3339, effectivityDate)
+	if err != nil {
+		return fmt.Errorf("生效日期格式错误, 须为 RFC3339: %v", err)
+	}
+	if effectivity.Before(ecn.CreateTime) {
+		return fmt.Errorf("生效日期不能早于变更发布时间")
+	}
+
+	now, err := s.getTxTimestamp(ctx)
+	if err != nil {
+		return err
+	}
+	for _, order := range orders {
+		pending := make([]string, 0, len(order.PendingECNIDs))
+		for _, ecnId := range order.PendingECNIDs {
+			if ecnId != id {
+				pending = append(pending, ecnId)
+			}
+		}
+		order.PendingECNIDs = pending
+		order.UpdateTime = now
+		orderBytes, err := json.Marshal(order)
+		if err != nil {
+			return fmt.Errorf("序列化订单失败: %v", err)
+		}
+		if err := ctx.GetStub().PutState(order.ID, orderBytes); err != nil {
+			return err
+		}
+	}
+
+	ecn.Acknowledgements = append(ecn.Acknowledgements, ECNAcknowledgement{
+		ManufacturerID:  manufacturerId,
+		EffectivityDate: effectivity,
+		Note:            note,
+		TxID:            ctx.GetStub().GetTxID(),
+		Timestamp:       now,
+	})
+	if len(ecn.Acknowledgements) == len(ecn.AffectedManufacturers) {
+		ecn.Status = ECN_ACKNOWLEDGED
+	}
+	ecn.UpdateTime = now
+	return s.putECN(ctx, ecn)
+}
+
+// QueryECN 查询工程变更通知 (含厂商确认记录)
+func (s *SmartContract) QueryECN(ctx contractapi.TransactionContextInterface, id string) (*EngineeringChange, error) {
+	ecnBytes, err := ctx.GetStub().GetState(id)
+	if err != nil {
+		return nil, fmt.Errorf("读取工程变更失败: %v", err)
+	}
+	if ecnBytes == nil {
+		return nil, fmt.Errorf("工程变更 %s 不存在", id)
+	}
+
+	var ecn EngineeringChange
+	if err := json.Unmarshal(ecnBytes, &ecn); err != nil {
+		return nil, fmt.Errorf("解析工程变更失败: %v", err)
+	}
+	if ecn.ObjectType != ECN {
+		return nil, fmt.Errorf("工程变更 %s 不存在", id)
+	}
+	return &ecn, nil
+}
+
+// QueryOrderECNs 查询影响订单的全部工程变更通知
+func (s *SmartContract) QueryOrderECNs(ctx contractapi.TransactionContextInterface, orderId string) ([]*EngineeringChange, error) {
+	ids, err := s.getIndexedIDs(ctx, ECN_ORDER, orderId)
+	if err != nil {
+		return nil, err
+	}
+	ecns := make([]*EngineeringChange, 0, len(ids))
+	for _, id := range ids {
+		ecn, err := s.QueryECN(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		ecns = append(ecns, ecn)
+	}
+	return ecns, nil
+}
+
+func (s *SmartContract) putECN(ctx contractapi.TransactionContextInterface, ecn *EngineeringChange) error {
+	ecnBytes, err := json.Marshal(ecn)
+	if err != nil {
+		return fmt.Errorf("序列化工程变更失败: %v", err)
+	}
+	return ctx.GetStub().PutState(ecn.ID, ecnBytes)
+}
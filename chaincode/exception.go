@@ -0,0 +1,282 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/v2/contractapi"
+)
+
+// 物流异常报告 (复合键: EXCEPTION_REPORT~shipmentId~seq)
+const (
+	EXCEPTION_REPORT = "EXCEPTION_REPORT"
+)
+
+// ExceptionType 异常类型
+type ExceptionType string
+
+const (
+	EXCEPTION_DAMAGE ExceptionType = "DAMAGE" // 货损
+	EXCEPTION_DELAY  ExceptionType = "DELAY"  // 延误
+	EXCEPTION_LOSS   ExceptionType = "LOSS"   // 丢失
+	EXCEPTION_OTHER  ExceptionType = "OTHER"  // 其他
+)
+
+// ExceptionSeverity 严重程度
+type ExceptionSeverity string
+
+const (
+	SEVERITY_LOW      ExceptionSeverity = "LOW"
+	SEVERITY_MEDIUM   ExceptionSeverity = "MEDIUM"
+	SEVERITY_HIGH     ExceptionSeverity = "HIGH"
+	SEVERITY_CRITICAL ExceptionSeverity = "CRITICAL"
+)
+
+// ExceptionStatus 异常处理状态
+type ExceptionStatus string
+
+const (
+	EXCEPTION_OPEN     ExceptionStatus = "OPEN"     // 待处理
+	EXCEPTION_RESOLVED ExceptionStatus = "RESOLVED" // 已处理
+)
+
+// ResolutionAction 异常处理结论
+type ResolutionAction string
+
+const (
+	RESOLUTION_CONTINUE    ResolutionAction = "CONTINUE"    // 继续运输
+	RESOLUTION_CLAIM       ResolutionAction = "CLAIM"       // 发起索赔, 货物继续运输
+	RESOLUTION_WRITTEN_OFF ResolutionAction = "WRITTEN_OFF" // 确认灭失, 物流单终止
+)
+
+// ShipmentException 物流异常报告
+type ShipmentException struct {
+	ShipmentID     string            `json:"shipmentId"`               // 物流单ID
+	Seq            int               `json:"seq"`                      // 序号
+	OrderID        string            `json:"orderId"`                  // 关联订单ID
+	OEMID          string            `json:"oemId"`                    // 订单采购方
+	Type           ExceptionType     `json:"type"`                     // 异常类型
+	Severity       ExceptionSeverity `json:"severity"`                 // 严重程度
+	Description    string            `json:"description"`              // 描述
+	PhotoHashes    []string          `json:"photoHashes"`              // 现场照片哈希
+	ETAImpactHours int               `json:"etaImpactHours"`           // 预计到达时间延后 (小时)
+	ReportedBy     string            `json:"reportedBy"`               // 报告承运商
+	ReportTime     time.Time         `json:"reportTime"`               // 报告时间
+	Status         ExceptionStatus   `json:"status"`                   // 处理状态
+	Resolution     ResolutionAction  `json:"resolution,omitempty"`     // 处理结论
+	ResolutionNote string            `json:"resolutionNote,omitempty"` // 处理说明
+	ResolvedBy     string            `json:"resolvedBy,omitempty"`     // 处理方
+	ResolveTime    *time.Time        `json:"resolveTime,omitempty"`    // 处理时间
+}
+
+// ReportException 保管承运商报告物流异常 (仅 Org3 可调用)
+func (s *SmartContract) ReportException(ctx contractapi.TransactionContextInterface, shipmentId string, exceptionJson string) error {
+	carrierID, err := s.getCarrierID(ctx)
+	if err != nil {
+		return err
+	}
+
+	shipment, err := s.QueryShipment(ctx, shipmentId)
+	if err != nil {
+		return err
+	}
+	if shipment.CustodianID != "" && shipment.CustodianID != carrierID {
+		return fmt.Errorf("无权限: 仅限当前保管承运商报告异常")
+	}
+	if shipment.Status == SHIPMENT_DELIVERED || shipment.Status == SHIPMENT_LOST {
+		return fmt.Errorf("物流单当前状态 %s 无法报告异常", shipment.Status)
+	}
+
+	var exception ShipmentException
+	if err := json.Unmarshal([]byte(exceptionJson), &exception); err != nil {
+		return fmt.Errorf("解析异常报告失败: %v", err)
+	}
+	switch exception.Type {
+	case EXCEPTION_DAMAGE, EXCEPTION_DELAY, EXCEPTION_LOSS, EXCEPTION_OTHER:
+	default:
+		return fmt.Errorf("无效的异常类型: %s", exception.Type)
+	}
+	switch exception.Severity {
+	case SEVERITY_LOW, SEVERITY_MEDIUM, SEVERITY_HIGH, SEVERITY_CRITICAL:
+	default:
+		return fmt.Errorf("无效的严重程度: %s", exception.Severity)
+	}
+	if exception.Description == "" {
+		return fmt.Errorf("异常描述不能为空")
+	}
+	if exception.ETAImpactHours < 0 {
+		return fmt.Errorf("预计延误时长不能为负数")
+	}
+
+	order, err := s.QueryOrder(ctx, shipment.OrderID)
+	if err != nil {
+		return err
+	}
+
+	now, err := s.getTxTimestamp(ctx)
+	if err != nil {
+		return err
+	}
+	exception.ShipmentID = shipmentId
+	exception.Seq = shipment.ExceptionCount
+	exception.OrderID = shipment.OrderID
+	exception.OEMID = order.OEMID
+	exception.ReportedBy = carrierID
+	exception.ReportTime = now
+	exception.Status = EXCEPTION_OPEN
+	exception.Resolution = ""
+	exception.ResolutionNote = ""
+	exception.ResolvedBy = ""
+	exception.ResolveTime = nil
+	if exception.PhotoHashes == nil {
+		exception.PhotoHashes = []string{}
+	}
+	if err := s.putShipmentRecord(ctx, EXCEPTION_REPORT, shipmentId, exception.Seq, exception); err != nil {
+		return err
+	}
+
+	shipment.ExceptionCount++
+	shipment.OpenExceptionCount++
+	shipment.DelayHours += exception.ETAImpactHours
+	shipment.Status = SHIPMENT_EXCEPTION
+	shipment.UpdateTime = now
+	return s.putShipment(ctx, shipment)
+}
+
+// ResolveException 采购方或平台方处理物流异常, 确认灭失时物流单终止
+func (s *SmartContract) ResolveException(ctx contractapi.TransactionContextInterface, shipmentId string, seq int, resolution string, note string) error {
+	clientMSPID, err := s.getClientIdentityMSPID(ctx)
+	if err != nil {
+		return err
+	}
+
+	exception, err := s.getException(ctx, shipmentId, seq)
+	if err != nil {
+		return err
+	}
+	if clientMSPID != exception.OEMID {
+		isPlatform, err := s.isPlatformIdentity(ctx)
+		if err != nil {
+			return err
+		}
+		if !isPlatform {
+			return fmt.Errorf("无权限: 仅限采购方或平台方处理异常")
+		}
+	}
+	if exception.Status != EXCEPTION_OPEN {
+		return fmt.Errorf("异常 %s#%d 已处理", shipmentId, seq)
+	}
+
+	action := ResolutionAction(resolution)
+	switch action {
+	case RESOLUTION_CONTINUE, RESOLUTION_CLAIM, RESOLUTION_WRITTEN_OFF:
+	default:
+		return fmt.Errorf("无效的处理结论: %s", resolution)
+	}
+
+	shipment, err := s.QueryShipment(ctx, shipmentId)
+	if err != nil {
+		return err
+	}
+
+	now, err := s.getTxTimestamp(ctx)
+	if err != nil {
+		return err
+	}
+	exception.Status = EXCEPTION_RESOLVED
+	exception.Resolution = action
+	exception.ResolutionNote = note
+	exception.ResolvedBy = clientMSPID
+	exception.ResolveTime = &now
+	if err := s.putShipmentRecord(ctx, EXCEPTION_REPORT, shipmentId, seq, exception); err != nil {
+		return err
+	}
+
+	shipment.OpenExceptionCount--
+	switch {
+	case action == RESOLUTION_WRITTEN_OFF:
+		shipment.Status = SHIPMENT_LOST
+	case shipment.OpenExceptionCount == 0 && shipment.Status == SHIPMENT_EXCEPTION:
+		shipment.Status = SHIPMENT_IN_TRANSIT
+	}
+	shipment.UpdateTime = now
+	return s.putShipment(ctx, shipment)
+}
+
+// QueryShipmentExceptions 查询物流单的异常报告
+func (s *SmartContract) QueryShipmentExceptions(ctx contractapi.TransactionContextInterface, shipmentId string) ([]*ShipmentException, error) {
+	exceptions := make([]*ShipmentException, 0)
+	err := s.scanShipmentRecords(ctx, EXCEPTION_REPORT, shipmentId, func(value []byte) error {
+		var exception ShipmentException
+		if err := json.Unmarshal(value, &exception); err != nil {
+			return fmt.Errorf("解析异常报告失败: %v", err)
+		}
+		exceptions = append(exceptions, &exception)
+		return nil
+	})
+	return exceptions, err
+}
+
+// QueryOpenExceptions 查询待处理的物流异常 (主机厂仅可见自己订单的异常, 平台方可见全部)
+func (s *SmartContract) QueryOpenExceptions(ctx contractapi.TransactionContextInterface) ([]*ShipmentException, error) {
+	clientMSPID, err := s.getClientIdentityMSPID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if clientMSPID != OEM_ORG_MSPID {
+		isPlatform, err := s.isPlatformIdentity(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if !isPlatform {
+			return nil, fmt.Errorf("无权限: 仅限主机厂或平台方查看异常")
+		}
+	}
+
+	resultsIterator, err := ctx.GetStub().GetStateByPartialCompositeKey(EXCEPTION_REPORT, []string{})
+	if err != nil {
+		return nil, fmt.Errorf("读取异常报告失败: %v", err)
+	}
+	defer resultsIterator.Close()
+
+	exceptions := make([]*ShipmentException, 0)
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return nil, err
+		}
+		var exception ShipmentException
+		if err := json.Unmarshal(queryResponse.Value, &exception); err != nil {
+			return nil, fmt.Errorf("解析异常报告失败: %v", err)
+		}
+		if exception.Status != EXCEPTION_OPEN {
+			continue
+		}
+		if clientMSPID == OEM_ORG_MSPID && exception.OEMID != clientMSPID {
+			continue
+		}
+		exceptions = append(exceptions, &exception)
+	}
+	return exceptions, nil
+}
+
+func (s *SmartContract) getException(ctx contractapi.TransactionContextInterface, shipmentId string, seq int) (*ShipmentException, error) {
+	key, err := shipmentRecordKey(ctx, EXCEPTION_REPORT, shipmentId, seq)
+	if err != nil {
+		return nil, err
+	}
+	exceptionBytes, err := ctx.GetStub().GetState(key)
+	if err != nil {
+		return nil, fmt.Errorf("读取异常报告失败: %v", err)
+	}
+	if exceptionBytes == nil {
+		return nil, fmt.Errorf("异常 %s#%d 不存在", shipmentId, seq)
+	}
+
+	var exception ShipmentException
+	if err := json.Unmarshal(exceptionBytes, &exception); err != nil {
+		return nil, fmt.Errorf("解析异常报告失败: %v", err)
+	}
+	return &exception, nil
+}
@@ -0,0 +1,569 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/hyperledger/fabric-chaincode-go/v2/pkg/cid"
+	"github.com/hyperledger/fabric-contract-api-go/v2/contractapi"
+)
+
+// 供应链金融资产类型
+const (
+	RECEIVABLE       = "RECEIVABLE"       // 应收账款
+	RECEIVABLE_ORDER = "RECEIVABLE_ORDER" // 复合键: RECEIVABLE_ORDER~orderId, 每个订单至多一笔应收账款
+	FINANCING        = "FINANCING"        // 融资申请
+	FINANCING_BANK   = "FINANCING_BANK"   // 复合键: FINANCING_BANK~bankId~financingId
+)
+
+// 银行身份证书属性 (银行以 Org3 下独立注册的身份接入)
+const BANK_ID_ATTRIBUTE = "bankId"
+
+// 应收账款票面金额的瞬态字段名 (传入随机盐)
+const RECEIVABLE_TRANSIENT = "receivable"
+
+// ReceivableSource 应收账款来源
+type ReceivableSource string
+
+const (
+	SOURCE_INVOICE ReceivableSource = "INVOICE" // 三单匹配通过的发票
+	SOURCE_ORDER   ReceivableSource = "ORDER"   // 主机厂已签收的订单
+)
+
+// ReceivableStatus 应收账款状态
+type ReceivableStatus string
+
+const (
+	RECEIVABLE_OPEN     ReceivableStatus = "OPEN"     // 可融资
+	RECEIVABLE_FINANCED ReceivableStatus = "FINANCED" // 融资中
+	RECEIVABLE_REPAID   ReceivableStatus = "REPAID"   // 已还款结清
+)
+
+// FinancingStatus 融资申请状态
+type FinancingStatus string
+
+const (
+	FINANCING_PROPOSED FinancingStatus = "PROPOSED" // 待银行审批
+	FINANCING_APPROVED FinancingStatus = "APPROVED" // 已放款
+	FINANCING_REJECTED FinancingStatus = "REJECTED" // 已拒绝
+	FINANCING_REPAID   FinancingStatus = "REPAID"   // 已还款
+)
+
+// Receivable 应收账款
+type Receivable struct {
+	ID            string           `json:"id"`            // 应收账款ID
+	ObjectType    string           `json:"objectType"`    // 资产类型 (RECEIVABLE)
+	SourceType    ReceivableSource `json:"sourceType"`    // 来源类型
+	SourceID      string           `json:"sourceId"`      // 来源发票ID或订单ID
+	OrderID       string           `json:"orderId"`       // 关联订单ID
+	CreditorID    string           `json:"creditorId"`    // 债权人 (零部件厂商)
+	DebtorID      string           `json:"debtorId"`      // 债务人 (主机厂)
+	FaceValueHash string           `json:"faceValueHash"` // 票面金额私有数据哈希 (SHA-256), 金额仅存于交易双方私有集合
+	Status        ReceivableStatus `json:"status"`        // 当前状态
+	Locked        bool             `json:"locked"`        // 融资锁定, 锁定期间不可重复融资
+	FinancingID   string           `json:"financingId"`   // 持有锁的融资申请ID
+	CreateTime    time.Time        `json:"createTime"`    // 创建时间
+	UpdateTime    time.Time        `json:"updateTime"`    // 更新时间
+}
+
+// ReceivableAmount 应收账款票面金额 (私有数据)
+type ReceivableAmount struct {
+	ReceivableID string  `json:"receivableId"` // 应收账款ID
+	FaceValue    float64 `json:"faceValue"`    // 票面金额 (链码按私有发票金额或订单总价计算)
+	Salt         string  `json:"salt"`         // 随机盐, 防止对哈希进行金额穷举
+}
+
+// FinancingApplication 融资申请
+type FinancingApplication struct {
+	ID            string          `json:"id"`            // 融资申请ID
+	ObjectType    string          `json:"objectType"`    // 资产类型 (FINANCING)
+	ReceivableID  string          `json:"receivableId"`  // 关联应收账款ID
+	ApplicantID   string          `json:"applicantId"`   // 申请人 (零部件厂商)
+	BankID        string          `json:"bankId"`        // 目标银行
+	RequestAmount float64         `json:"requestAmount"` // 申请金额
+	Status        FinancingStatus `json:"status"`        // 当前状态
+	RejectReason  string          `json:"rejectReason"`  // 拒绝原因
+	CreateTime    time.Time       `json:"createTime"`    // 申请时间
+	UpdateTime    time.Time       `json:"updateTime"`    // 更新时间
+}
+
+// CreateReceivable 厂商基于已匹配发票或已签收订单创建应收账款 (仅订单映射的厂商 MSP 可调用, 随机盐通过瞬态字段 receivable 传入)
+func (s *SmartContract) CreateReceivable(ctx contractapi.TransactionContextInterface, id string, sourceType string, sourceId string) error {
+	clientMSPID, err := s.getClientIdentityMSPID(ctx)
+	if err != nil {
+		return err
+	}
+
+	existing, err := ctx.GetStub().GetState(id)
+	if err != nil {
+		return fmt.Errorf("读取应收账款失败: %v", err)
+	}
+	if existing != nil {
+		return fmt.Errorf("应收账款 %s 已存在", id)
+	}
+
+	var orderId string
+	var invoice *Invoice
+	switch ReceivableSource(sourceType) {
+	case SOURCE_INVOICE:
+		invoice, err = s.QueryInvoice(ctx, sourceId)
+		if err != nil {
+			return err
+		}
+		orderId = invoice.OrderID
+	case SOURCE_ORDER:
+		orderId = sourceId
+	default:
+		return fmt.Errorf("无效的应收账款来源: %s", sourceType)
+	}
+
+	order, err := s.QueryOrder(ctx, orderId)
+	if err != nil {
+		return err
+	}
+	// 子订单价格仅一级供应商与二级供应商可见, 不得形成应收账款
+	if order.ParentOrderID != "" {
+		return fmt.Errorf("订单 %s 为子订单, 无法形成应收账款", orderId)
+	}
+	if clientMSPID != order.ManufacturerMSPID {
+		return fmt.Errorf("无权限: 仅限订单厂商创建应收账款")
+	}
+
+	var faceValue float64
+	if invoice != nil {
+		if invoice.Status != INVOICE_MATCHED && invoice.Status != INVOICE_APPROVED {
+			return fmt.Errorf("发票当前状态 %s 无法形成应收账款, 须三单匹配通过", invoice.Status)
+		}
+		amount, err := s.QueryInvoiceAmount(ctx, sourceId)
+		if err != nil {
+			return err
+		}
+		faceValue = amount.TotalAmount
+	} else {
+		if order.Status != ORDER_RECEIVED {
+			return fmt.Errorf("订单当前状态 %s 无法形成应收账款, 须主机厂签收", order.Status)
+		}
+		price, err := s.QueryOrderPrice(ctx, sourceId)
+		if err != nil {
+			return err
+		}
+		faceValue = price.TotalPrice
+	}
+	if faceValue <= 0 {
+		return fmt.Errorf("应收账款金额必须大于 0")
+	}
+
+	indexKey, err := ctx.GetStub().CreateCompositeKey(RECEIVABLE_ORDER, []string{orderId})
+	if err != nil {
+		return fmt.Errorf("创建应收账款索引失败: %v", err)
+	}
+	indexed, err := ctx.GetStub().GetState(indexKey)
+	if err != nil {
+		return fmt.Errorf("读取应收账款索引失败: %v", err)
+	}
+	if indexed != nil {
+		return fmt.Errorf("订单 %s 已有应收账款 %s", orderId, string(indexed))
+	}
+
+	salt, err := getReceivableSaltFromTransient(ctx)
+	if err != nil {
+		return err
+	}
+	amountBytes, err := json.Marshal(&ReceivableAmount{ReceivableID: id, FaceValue: faceValue, Salt: salt})
+	if err != nil {
+		return fmt.Errorf("序列化票面金额失败: %v", err)
+	}
+	amountKey, err := ctx.GetStub().CreateCompositeKey(RECEIVABLE, []string{id})
+	if err != nil {
+		return fmt.Errorf("创建应收账款键失败: %v", err)
+	}
+	if err := ctx.GetStub().PutPrivateData(ORDER_PRICE_COLLECTION, amountKey, amountBytes); err != nil {
+		return fmt.Errorf("写入票面金额失败: %v", err)
+	}
+	amountHash := sha256.Sum256(amountBytes)
+
+	now, err := s.getTxTimestamp(ctx)
+	if err != nil {
+		return err
+	}
+	receivable := &Receivable{
+		ID:            id,
+		ObjectType:    RECEIVABLE,
+		SourceType:    ReceivableSource(sourceType),
+		SourceID:      sourceId,
+		OrderID:       orderId,
+		CreditorID:    order.ManufacturerID,
+		DebtorID:      order.OEMID,
+		FaceValueHash: hex.EncodeToString(amountHash[:]),
+		Status:        RECEIVABLE_OPEN,
+		CreateTime:    now,
+		UpdateTime:    now,
+	}
+	if err := s.putReceivable(ctx, receivable); err != nil {
+		return err
+	}
+	return ctx.GetStub().PutState(indexKey, []byte(id))
+}
+
+// ApplyFinancing 厂商以应收账款向银行申请融资, 申请期间锁定应收账款 (仅 Org2 可调用)
+func (s *SmartContract) ApplyFinancing(ctx contractapi.TransactionContextInterface, id string, receivableId string, bankId string, requestAmount float64) error {
+	clientMSPID, err := s.getClientIdentityMSPID(ctx)
+	if err != nil {
+		return err
+	}
+
+	receivable, err := s.QueryReceivable(ctx, receivableId)
+	if err != nil {
+		return err
+	}
+	// 债权人ID为订单的业务厂商标识, 按订单映射的 MSP ID 授权
+	order, err := s.QueryOrder(ctx, receivable.OrderID)
+	if err != nil {
+		return err
+	}
+	if clientMSPID != order.ManufacturerMSPID {
+		return fmt.Errorf("无权限: 仅限债权人申请融资")
+	}
+	if receivable.Locked {
+		return fmt.Errorf("应收账款 %s 已被融资申请 %s 锁定", receivableId, receivable.FinancingID)
+	}
+	if receivable.Status != RECEIVABLE_OPEN {
+		return fmt.Errorf("应收账款当前状态 %s 无法申请融资", receivable.Status)
+	}
+	if bankId == "" {
+		return fmt.Errorf("目标银行不能为空")
+	}
+	amount, err := s.QueryReceivableAmount(ctx, receivableId)
+	if err != nil {
+		return err
+	}
+	if requestAmount <= 0 || requestAmount > amount.FaceValue {
+		return fmt.Errorf("申请金额须大于 0 且不超过票面金额 %.2f", amount.FaceValue)
+	}
+
+	existing, err := ctx.GetStub().GetState(id)
+	if err != nil {
+		return fmt.Errorf("读取融资申请失败: %v", err)
+	}
+	if existing != nil {
+		return fmt.Errorf("融资申请 %s 已存在", id)
+	}
+
+	now, err := s.getTxTimestamp(ctx)
+	if err != nil {
+		return err
+	}
+	application := &FinancingApplication{
+		ID:            id,
+		ObjectType:    FINANCING,
+		ReceivableID:  receivableId,
+		ApplicantID:   clientMSPID,
+		BankID:        bankId,
+		RequestAmount: requestAmount,
+		Status:        FINANCING_PROPOSED,
+		CreateTime:    now,
+		UpdateTime:    now,
+	}
+	if err := s.putFinancing(ctx, application); err != nil {
+		return err
+	}
+	bankKey, err := ctx.GetStub().CreateCompositeKey(FINANCING_BANK, []string{bankId, id})
+	if err != nil {
+		return fmt.Errorf("创建融资索引失败: %v", err)
+	}
+	if err := ctx.GetStub().PutState(bankKey, []byte{0x00}); err != nil {
+		return err
+	}
+
+	receivable.Locked = true
+	receivable.FinancingID = id
+	receivable.UpdateTime = now
+	return s.putReceivable(ctx, receivable)
+}
+
+// ApproveFinancing 银行审批通过并放款, 应收账款保持锁定直至还款
+func (s *SmartContract) ApproveFinancing(ctx contractapi.TransactionContextInterface, id string) error {
+	application, receivable, now, err := s.prepareBankDecision(ctx, id, FINANCING_PROPOSED)
+	if err != nil {
+		return err
+	}
+
+	application.Status = FINANCING_APPROVED
+	application.UpdateTime = now
+	if err := s.putFinancing(ctx, application); err != nil {
+		return err
+	}
+
+	receivable.Status = RECEIVABLE_FINANCED
+	receivable.UpdateTime = now
+	return s.putReceivable(ctx, receivable)
+}
+
+// RejectFinancing 银行拒绝融资申请, 应收账款自动解锁
+func (s *SmartContract) RejectFinancing(ctx contractapi.TransactionContextInterface, id string, reason string) error {
+	application, receivable, now, err := s.prepareBankDecision(ctx, id, FINANCING_PROPOSED)
+	if err != nil {
+		return err
+	}
+
+	application.Status = FINANCING_REJECTED
+	application.RejectReason = reason
+	application.UpdateTime = now
+	if err := s.putFinancing(ctx, application); err != nil {
+		return err
+	}
+
+	receivable.Locked = false
+	receivable.FinancingID = ""
+	receivable.UpdateTime = now
+	return s.putReceivable(ctx, receivable)
+}
+
+// ConfirmRepayment 银行确认融资已还款, 释放应收账款锁定
+func (s *SmartContract) ConfirmRepayment(ctx contractapi.TransactionContextInterface, id string) error {
+	application, receivable, now, err := s.prepareBankDecision(ctx, id, FINANCING_APPROVED)
+	if err != nil {
+		return err
+	}
+
+	application.Status = FINANCING_REPAID
+	application.UpdateTime = now
+	if err := s.putFinancing(ctx, application); err != nil {
+		return err
+	}
+
+	receivable.Status = RECEIVABLE_REPAID
+	receivable.Locked = false
+	receivable.FinancingID = ""
+	receivable.UpdateTime = now
+	return s.putReceivable(ctx, receivable)
+}
+
+// QueryReceivable 查询应收账款
+func (s *SmartContract) QueryReceivable(ctx contractapi.TransactionContextInterface, id string) (*Receivable, error) {
+	receivableBytes, err := ctx.GetStub().GetState(id)
+	if err != nil {
+		return nil, fmt.Errorf("读取应收账款失败: %v", err)
+	}
+	if receivableBytes == nil {
+		return nil, fmt.Errorf("应收账款 %s 不存在", id)
+	}
+
+	var receivable Receivable
+	if err := json.Unmarshal(receivableBytes, &receivable); err != nil {
+		return nil, fmt.Errorf("解析应收账款失败: %v", err)
+	}
+	if receivable.ObjectType != RECEIVABLE {
+		return nil, fmt.Errorf("应收账款 %s 不存在", id)
+	}
+	return &receivable, nil
+}
+
+// QueryReceivableAmount 查询应收账款票面金额 (仅 Org1/Org2 可调用)
+func (s *SmartContract) QueryReceivableAmount(ctx contractapi.TransactionContextInterface, id string) (*ReceivableAmount, error) {
+	clientMSPID, err := s.getClientIdentityMSPID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if clientMSPID != OEM_ORG_MSPID && clientMSPID != MANUFACTURER_ORG_MSPID {
+		return nil, fmt.Errorf("无权限: 仅限交易双方查看票面金额")
+	}
+
+	amountKey, err := ctx.GetStub().CreateCompositeKey(RECEIVABLE, []string{id})
+	if err != nil {
+		return nil, fmt.Errorf("创建应收账款键失败: %v", err)
+	}
+	amountBytes, err := ctx.GetStub().GetPrivateData(ORDER_PRICE_COLLECTION, amountKey)
+	if err != nil {
+		return nil, fmt.Errorf("读取票面金额失败: %v", err)
+	}
+	if amountBytes == nil {
+		return nil, fmt.Errorf("应收账款 %s 票面金额不存在", id)
+	}
+
+	var amount ReceivableAmount
+	if err := json.Unmarshal(amountBytes, &amount); err != nil {
+		return nil, fmt.Errorf("解析票面金额失败: %v", err)
+	}
+	return &amount, nil
+}
+
+// VerifyReceivableFaceValue 校验厂商线下披露的票面金额与随机盐是否与链上哈希一致 (银行审批融资前调用)
+func (s *SmartContract) VerifyReceivableFaceValue(ctx contractapi.TransactionContextInterface, id string, faceValue float64, salt string) (bool, error) {
+	receivable, err := s.QueryReceivable(ctx, id)
+	if err != nil {
+		return false, err
+	}
+	amountBytes, err := json.Marshal(&ReceivableAmount{ReceivableID: id, FaceValue: faceValue, Salt: salt})
+	if err != nil {
+		return false, fmt.Errorf("序列化票面金额失败: %v", err)
+	}
+	amountHash := sha256.Sum256(amountBytes)
+	return hex.EncodeToString(amountHash[:]) == receivable.FaceValueHash, nil
+}
+
+// QueryFinancing 查询融资申请
+func (s *SmartContract) QueryFinancing(ctx contractapi.TransactionContextInterface, id string) (*FinancingApplication, error) {
+	applicationBytes, err := ctx.GetStub().GetState(id)
+	if err != nil {
+		return nil, fmt.Errorf("读取融资申请失败: %v", err)
+	}
+	if applicationBytes == nil {
+		return nil, fmt.Errorf("融资申请 %s 不存在", id)
+	}
+
+	var application FinancingApplication
+	if err := json.Unmarshal(applicationBytes, &application); err != nil {
+		return nil, fmt.Errorf("解析融资申请失败: %v", err)
+	}
+	if application.ObjectType != FINANCING {
+		return nil, fmt.Errorf("融资申请 %s 不存在", id)
+	}
+	return &application, nil
+}
+
+// QueryBankFinancings 银行查询提交给自己的融资申请
+func (s *SmartContract) QueryBankFinancings(ctx contractapi.TransactionContextInterface) ([]*FinancingApplication, error) {
+	bankID, err := s.getBankID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	resultsIterator, err := ctx.GetStub().GetStateByPartialCompositeKey(FINANCING_BANK, []string{bankID})
+	if err != nil {
+		return nil, fmt.Errorf("读取融资索引失败: %v", err)
+	}
+	defer resultsIterator.Close()
+
+	applications := make([]*FinancingApplication, 0)
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return nil, err
+		}
+		_, keyParts, err := ctx.GetStub().SplitCompositeKey(queryResponse.Key)
+		if err != nil {
+			return nil, fmt.Errorf("解析融资索引失败: %v", err)
+		}
+		application, err := s.QueryFinancing(ctx, keyParts[1])
+		if err != nil {
+			return nil, err
+		}
+		applications = append(applications, application)
+	}
+	return applications, nil
+}
+
+// 订单对应的应收账款是否处于融资锁定
+func (s *SmartContract) isOrderFinancingLocked(ctx contractapi.TransactionContextInterface, orderId string) (bool, error) {
+	indexKey, err := ctx.GetStub().CreateCompositeKey(RECEIVABLE_ORDER, []string{orderId})
+	if err != nil {
+		return false, fmt.Errorf("创建应收账款索引失败: %v", err)
+	}
+	receivableId, err := ctx.GetStub().GetState(indexKey)
+	if err != nil {
+		return false, fmt.Errorf("读取应收账款索引失败: %v", err)
+	}
+	if receivableId == nil {
+		return false, nil
+	}
+	receivable, err := s.QueryReceivable(ctx, string(receivableId))
+	if err != nil {
+		return false, err
+	}
+	return receivable.Locked, nil
+}
+
+// 银行审批前的公共校验: 调用方须为申请指定的银行, 申请须处于预期状态
+func (s *SmartContract) prepareBankDecision(ctx contractapi.TransactionContextInterface, id string, expected FinancingStatus) (*FinancingApplication, *Receivable, time.Time, error) {
+	bankID, err := s.getBankID(ctx)
+	if err != nil {
+		return nil, nil, time.Time{}, err
+	}
+
+	application, err := s.QueryFinancing(ctx, id)
+	if err != nil {
+		return nil, nil, time.Time{}, err
+	}
+	if application.BankID != bankID {
+		return nil, nil, time.Time{}, fmt.Errorf("无权限: 仅限目标银行 %s 处理该融资申请", application.BankID)
+	}
+	if application.Status != expected {
+		return nil, nil, time.Time{}, fmt.Errorf("融资申请当前状态 %s 无法执行该操作", application.Status)
+	}
+
+	receivable, err := s.QueryReceivable(ctx, application.ReceivableID)
+	if err != nil {
+		return nil, nil, time.Time{}, err
+	}
+
+	now, err := s.getTxTimestamp(ctx)
+	if err != nil {
+		return nil, nil, time.Time{}, err
+	}
+	return application, receivable, now, nil
+}
+
+// 获取银行ID: 银行以 Org3 身份接入, 证书属性 bankId 区分具体银行; 未携带该属性的 Org3 身份不是银行
+func (s *SmartContract) getBankID(ctx contractapi.TransactionContextInterface) (string, error) {
+	clientID, err := cid.New(ctx.GetStub())
+	if err != nil {
+		return "", fmt.Errorf("获取客户端身份失败: %v", err)
+	}
+	mspID, err := clientID.GetMSPID()
+	if err != nil {
+		return "", err
+	}
+	if mspID != PLATFORM_ORG_MSPID {
+		return "", fmt.Errorf("无权限: 仅限银行操作")
+	}
+
+	bankID, found, err := clientID.GetAttributeValue(BANK_ID_ATTRIBUTE)
+	if err != nil {
+		return "", fmt.Errorf("读取银行属性失败: %v", err)
+	}
+	if !found || bankID == "" {
+		return "", fmt.Errorf("无权限: 仅限银行操作, 调用方证书缺少 %s 属性", BANK_ID_ATTRIBUTE)
+	}
+	return bankID, nil
+}
+
+// 从瞬态数据中读取票面金额随机盐
+func getReceivableSaltFromTransient(ctx contractapi.TransactionContextInterface) (string, error) {
+	transientMap, err := ctx.GetStub().GetTransient()
+	if err != nil {
+		return "", fmt.Errorf("读取瞬态数据失败: %v", err)
+	}
+	amountJson, ok := transientMap[RECEIVABLE_TRANSIENT]
+	if !ok {
+		return "", fmt.Errorf("缺少票面金额随机盐: 需通过瞬态字段 %s 传入", RECEIVABLE_TRANSIENT)
+	}
+
+	var amount ReceivableAmount
+	if err := json.Unmarshal(amountJson, &amount); err != nil {
+		return "", fmt.Errorf("解析票面金额随机盐失败: %v", err)
+	}
+	if amount.Salt == "" {
+		return "", fmt.Errorf("票面金额缺少随机盐")
+	}
+	return amount.Salt, nil
+}
+
+func (s *SmartContract) putReceivable(ctx contractapi.TransactionContextInterface, receivable *Receivable) error {
+	receivableBytes, err := json.Marshal(receivable)
+	if err != nil {
+		return fmt.Errorf("序列化应收账款失败: %v", err)
+	}
+	return ctx.GetStub().PutState(receivable.ID, receivableBytes)
+}
+
+func (s *SmartContract) putFinancing(ctx contractapi.TransactionContextInterface, application *FinancingApplication) error {
+	applicationBytes, err := json.Marshal(application)
+	if err != nil {
+		return fmt.Errorf("序列化融资申请失败: %v", err)
+	}
+	return ctx.GetStub().PutState(application.ID, applicationBytes)
+}
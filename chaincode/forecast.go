@@ -0,0 +1,430 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/v2/contractapi"
+)
+
+// 需求预测 (私有数据, 按厂商准入时映射的 MSP 写入集合 collectionForecast<MSP ID>, 仅主机厂与该厂商可见)
+const (
+	FORECAST                      = "FORECAST" // 私有数据复合键: FORECAST~manufacturerId~partNumber
+	FORECAST_COLLECTION_PREFIX    = "collectionForecast"
+	FORECAST_TRANSIENT            = "forecast"   // 发布预测的瞬态字段
+	FORECAST_COMMITMENT_TRANSIENT = "commitment" // 产能承诺的瞬态字段
+	FORECAST_WEEKS                = 12           // 滚动预测周数
+	FORECAST_WEEK_LAYOUT          = "2006-01-02" // 周起始日 (周一) 格式
+)
+
+// 已在 collections_config.json 中配置需求预测集合的厂商 MSP, 新增厂商 MSP 须同时增加集合并升级链码
+var FORECAST_COLLECTION_MSPIDS = []string{MANUFACTURER_ORG_MSPID}
+
+// ForecastGapStatus 预测周缺口状态
+type ForecastGapStatus string
+
+const (
+	FORECAST_UNCOMMITTED ForecastGapStatus = "UNCOMMITTED" // 厂商尚未承诺
+	FORECAST_SHORTFALL   ForecastGapStatus = "SHORTFALL"   // 承诺产能低于需求
+	FORECAST_COVERED     ForecastGapStatus = "COVERED"     // 承诺产能满足需求
+)
+
+// ForecastWeek 单周预测与承诺
+type ForecastWeek struct {
+	WeekStart string   `json:"weekStart"`          // 周起始日 (周一)
+	Demand    int      `json:"demand"`             // 主机厂预测需求
+	Committed *int     `json:"committed"`          // 厂商承诺产能 (未承诺为空)
+	Ordered   int      `json:"ordered"`            // 已转为订单的数量
+	OrderIDs  []string `json:"orderIds,omitempty"` // 由该周承诺生成的订单
+}
+
+// Forecast 零件滚动需求预测 (私有数据)
+type Forecast struct {
+	PartNumber     string         `json:"partNumber"`           // 零件号
+	OEMID          string         `json:"oemId"`                // 发布方 (主机厂)
+	ManufacturerID string         `json:"manufacturerId"`       // 接收厂商
+	Version        int            `json:"version"`              // 预测版本 (每次滚动发布递增)
+	Weeks          []ForecastWeek `json:"weeks"`                // 12 周预测
+	PublishTime    time.Time      `json:"publishTime"`          // 发布时间
+	CommitVersion  int            `json:"commitVersion"`        // 厂商最近承诺所对应的预测版本
+	CommitTime     *time.Time     `json:"commitTime,omitempty"` // 最近承诺时间
+}
+
+// ForecastGap 单周预测缺口
+type ForecastGap struct {
+	WeekStart string            `json:"weekStart"` // 周起始日
+	Demand    int               `json:"demand"`    // 预测需求
+	Committed int               `json:"committed"` // 承诺产能
+	Gap       int               `json:"gap"`       // 缺口 (需求 - 承诺, 正数为产能不足)
+	Ordered   int               `json:"ordered"`   // 已下单数量
+	Status    ForecastGapStatus `json:"status"`    // 缺口状态
+}
+
+// ForecastComparison 预测与承诺对比
+type ForecastComparison struct {
+	PartNumber     string        `json:"partNumber"`     // 零件号
+	ManufacturerID string        `json:"manufacturerId"` // 厂商
+	Version        int           `json:"version"`        // 预测版本
+	CommitVersion  int           `json:"commitVersion"`  // 承诺对应的预测版本
+	TotalDemand    int           `json:"totalDemand"`    // 总需求
+	TotalCommitted int           `json:"totalCommitted"` // 总承诺
+	TotalShortfall int           `json:"totalShortfall"` // 产能不足合计
+	GapWeeks       int           `json:"gapWeeks"`       // 存在缺口或未承诺的周数
+	Weeks          []ForecastGap `json:"weeks"`          // 各周对比
+}
+
+// PublishForecast 主机厂向厂商发布零件 12 周滚动需求预测 (仅 Org1 可调用)
+// 瞬态字段 forecast: {"startWeek":"2026-10-19","demand":[...12 周]}, 重叠周的已承诺与已下单数量保留
+func (s *SmartContract) PublishForecast(ctx contractapi.TransactionContextInterface, manufacturerId string, partNumber string) error {
+	clientMSPID, err := s.getClientIdentityMSPID(ctx)
+	if err != nil {
+		return err
+	}
+	if clientMSPID != OEM_ORG_MSPID {
+		return fmt.Errorf("无权限: 仅限主机厂发布需求预测")
+	}
+
+	part, err := s.QueryPart(ctx, partNumber)
+	if err != nil {
+		return err
+	}
+	if part.Status != PART_ACTIVE {
+		return fmt.Errorf("零件 %s 已停用", partNumber)
+	}
+	if len(part.ApprovedManufacturers) > 0 && !containsString(part.ApprovedManufacturers, manufacturerId) {
+		return fmt.Errorf("厂商 %s 不是零件 %s 的合格供应商", manufacturerId, partNumber)
+	}
+	collection, err := s.forecastCollection(ctx, manufacturerId)
+	if err != nil {
+		return err
+	}
+
+	transientMap, err := ctx.GetStub().GetTransient()
+	if err != nil {
+		return fmt.Errorf("读取瞬态数据失败: %v", err)
+	}
+	forecastJson, ok := transientMap[FORECAST_TRANSIENT]
+	if !ok {
+		return fmt.Errorf("缺少预测数据: 需通过瞬态字段 %s 传入", FORECAST_TRANSIENT)
+	}
+	var input struct {
+		StartWeek string `json:"startWeek"`
+		Demand    []int  `json:"demand"`
+	}
+	if err := json.Unmarshal(forecastJson, &input); err != nil {
+		return fmt.Errorf("解析预测数据失败: %v", err)
+	}
+	if len(input.Demand) != FORECAST_WEEKS {
+		return fmt.Errorf("预测须覆盖 %d 周, 实际 %d 周", FORECAST_WEEKS, len(input.Demand))
+	}
+	start, err := time.Parse(FORECAST_WEEK_LAYOUT, input.StartWeek)
+	if err != nil {
+		return fmt.Errorf("预测起始周格式错误, 须为 %s: %v", FORECAST_WEEK_LAYOUT, err)
+	}
+	if start.Weekday() != time.Monday {
+		return fmt.Errorf("预测起始周 %s 须为周一", input.StartWeek)
+	}
+
+	previous, err := s.getForecast(ctx, collection, manufacturerId, partNumber)
+	if err != nil {
+		return err
+	}
+	carried := make(map[string]ForecastWeek)
+	version := 1
+	if previous != nil {
+		if input.StartWeek < previous.Weeks[0].WeekStart {
+			return fmt.Errorf("滚动预测起始周不能早于上一版本 %s", previous.Weeks[0].WeekStart)
+		}
+		for _, week := range previous.Weeks {
+			carried[week.WeekStart] = week
+		}
+		version = previous.Version + 1
+	}
+
+	weeks := make([]ForecastWeek, FORECAST_WEEKS)
+	for i, demand := range input.Demand {
+		if demand < 0 {
+			return fmt.Errorf("预测需求不能为负数")
+		}
+		weekStart := start.AddDate(0, 0, 7*i).Format(FORECAST_WEEK_LAYOUT)
+		week := ForecastWeek{WeekStart: weekStart}
+		if old, ok := carried[weekStart]; ok {
+			week = old
+		}
+		week.Demand = demand
+		weeks[i] = week
+	}
+
+	now, err := s.getTxTimestamp(ctx)
+	if err != nil {
+		return err
+	}
+	forecast := &Forecast{
+		PartNumber:     partNumber,
+		OEMID:          clientMSPID,
+		ManufacturerID: manufacturerId,
+		Version:        version,
+		Weeks:          weeks,
+		PublishTime:    now,
+	}
+	if previous != nil {
+		forecast.CommitVersion = previous.CommitVersion
+		forecast.CommitTime = previous.CommitTime
+	}
+	return s.putForecast(ctx, collection, forecast)
+}
+
+// CommitForecastCapacity 厂商针对当前预测版本逐周承诺产能 (仅预测接收厂商映射的 MSP 可调用)
+// 瞬态字段 commitment: {"version":1,"committed":[...12 周]}, 承诺不得低于该周已下单数量
+func (s *SmartContract) CommitForecastCapacity(ctx contractapi.TransactionContextInterface, manufacturerId string, partNumber string) error {
+	clientMSPID, err := s.getClientIdentityMSPID(ctx)
+	if err != nil {
+		return err
+	}
+	mspID, err := s.resolveManufacturerMSPID(ctx, manufacturerId)
+	if err != nil {
+		return err
+	}
+	if clientMSPID != mspID {
+		return fmt.Errorf("无权限: 仅限预测接收厂商承诺产能")
+	}
+	collection, err := s.forecastCollection(ctx, manufacturerId)
+	if err != nil {
+		return err
+	}
+
+	forecast, err := s.getForecast(ctx, collection, manufacturerId, partNumber)
+	if err != nil {
+		return err
+	}
+	if forecast == nil {
+		return fmt.Errorf("零件 %s 尚无需求预测", partNumber)
+	}
+
+	transientMap, err := ctx.GetStub().GetTransient()
+	if err != nil {
+		return fmt.Errorf("读取瞬态数据失败: %v", err)
+	}
+	commitmentJson, ok := transientMap[FORECAST_COMMITMENT_TRANSIENT]
+	if !ok {
+		return fmt.Errorf("缺少承诺数据: 需通过瞬态字段 %s 传入", FORECAST_COMMITMENT_TRANSIENT)
+	}
+	var input struct {
+		Version   int   `json:"version"`
+		Committed []int `json:"committed"`
+	}
+	if err := json.Unmarshal(commitmentJson, &input); err != nil {
+		return fmt.Errorf("解析承诺数据失败: %v", err)
+	}
+	if input.Version != forecast.Version {
+		return fmt.Errorf("预测已更新至版本 %d, 请基于最新版本承诺", forecast.Version)
+	}
+	if len(input.Committed) != len(forecast.Weeks) {
+		return fmt.Errorf("承诺周数 %d 与预测周数 %d 不一致", len(input.Committed), len(forecast.Weeks))
+	}
+
+	for i := range forecast.Weeks {
+		committed := input.Committed[i]
+		if committed < forecast.Weeks[i].Ordered {
+			return fmt.Errorf("第 %s 周承诺 %d 低于已下单数量 %d", forecast.Weeks[i].WeekStart, committed, forecast.Weeks[i].Ordered)
+		}
+		forecast.Weeks[i].Committed = &committed
+	}
+
+	now, err := s.getTxTimestamp(ctx)
+	if err != nil {
+		return err
+	}
+	forecast.CommitVersion = forecast.Version
+	forecast.CommitTime = &now
+	return s.putForecast(ctx, collection, forecast)
+}
+
+// QueryForecast 查询零件需求预测 (仅主机厂与该厂商可调用)
+func (s *SmartContract) QueryForecast(ctx contractapi.TransactionContextInterface, manufacturerId string, partNumber string) (*Forecast, error) {
+	collection, err := s.authorizeForecastRead(ctx, manufacturerId)
+	if err != nil {
+		return nil, err
+	}
+	forecast, err := s.getForecast(ctx, collection, manufacturerId, partNumber)
+	if err != nil {
+		return nil, err
+	}
+	if forecast == nil {
+		return nil, fmt.Errorf("零件 %s 尚无需求预测", partNumber)
+	}
+	return forecast, nil
+}
+
+// CompareForecast 对比预测需求与承诺产能, 标出未承诺与产能不足的周
+func (s *SmartContract) CompareForecast(ctx contractapi.TransactionContextInterface, manufacturerId string, partNumber string) (*ForecastComparison, error) {
+	forecast, err := s.QueryForecast(ctx, manufacturerId, partNumber)
+	if err != nil {
+		return nil, err
+	}
+
+	comparison := &ForecastComparison{
+		PartNumber:     forecast.PartNumber,
+		ManufacturerID: forecast.ManufacturerID,
+		Version:        forecast.Version,
+		CommitVersion:  forecast.CommitVersion,
+		Weeks:          make([]ForecastGap, 0, len(forecast.Weeks)),
+	}
+	for _, week := range forecast.Weeks {
+		gap := ForecastGap{
+			WeekStart: week.WeekStart,
+			Demand:    week.Demand,
+			Ordered:   week.Ordered,
+			Status:    FORECAST_UNCOMMITTED,
+		}
+		if week.Committed != nil {
+			gap.Committed = *week.Committed
+			gap.Status = FORECAST_COVERED
+			if gap.Committed < week.Demand {
+				gap.Status = FORECAST_SHORTFALL
+			}
+		}
+		gap.Gap = week.Demand - gap.Committed
+		if gap.Status != FORECAST_COVERED {
+			comparison.GapWeeks++
+		}
+		if gap.Gap > 0 {
+			comparison.TotalShortfall += gap.Gap
+		}
+		comparison.TotalDemand += week.Demand
+		comparison.TotalCommitted += gap.Committed
+		comparison.Weeks = append(comparison.Weeks, gap)
+	}
+	return comparison, nil
+}
+
+// CreateOrderFromForecast 主机厂将某周已承诺的预测数量转为订单 (仅 Org1 可调用, 单价同 CreateOrder 走瞬态字段 price)
+func (s *SmartContract) CreateOrderFromForecast(ctx contractapi.TransactionContextInterface, id string, manufacturerId string, partNumber string, weekStart string, quantity int) error {
+	clientMSPID, err := s.getClientIdentityMSPID(ctx)
+	if err != nil {
+		return err
+	}
+	if clientMSPID != OEM_ORG_MSPID {
+		return fmt.Errorf("无权限: 仅限主机厂创建订单")
+	}
+	if quantity <= 0 {
+		return fmt.Errorf("下单数量必须大于 0")
+	}
+
+	collection, err := s.forecastCollection(ctx, manufacturerId)
+	if err != nil {
+		return err
+	}
+	forecast, err := s.getForecast(ctx, collection, manufacturerId, partNumber)
+	if err != nil {
+		return err
+	}
+	if forecast == nil {
+		return fmt.Errorf("零件 %s 尚无需求预测", partNumber)
+	}
+	index := -1
+	for i := range forecast.Weeks {
+		if forecast.Weeks[i].WeekStart == weekStart {
+			index = i
+			break
+		}
+	}
+	if index < 0 {
+		return fmt.Errorf("预测中不存在第 %s 周", weekStart)
+	}
+	week := &forecast.Weeks[index]
+	if week.Committed == nil {
+		return fmt.Errorf("第 %s 周厂商尚未承诺产能", weekStart)
+	}
+	if available := *week.Committed - week.Ordered; quantity > available {
+		return fmt.Errorf("第 %s 周可下单数量 %d, 不足 %d", weekStart, available, quantity)
+	}
+
+	items := []OrderItem{{PartNumber: partNumber, Quantity: quantity}}
+	price, err := s.getOrderPriceFromTransient(ctx, id, items)
+	if err != nil {
+		return err
+	}
+	order := &Order{
+		ID:             id,
+		OEMID:          clientMSPID,
+		ManufacturerID: manufacturerId,
+		Items:          items,
+	}
+	if err := s.createOrder(ctx, order, price); err != nil {
+		return err
+	}
+
+	week.Ordered += quantity
+	week.OrderIDs = append(week.OrderIDs, id)
+	return s.putForecast(ctx, collection, forecast)
+}
+
+// 预测仅主机厂与接收厂商映射的 MSP 可读, 返回预测所在集合
+func (s *SmartContract) authorizeForecastRead(ctx contractapi.TransactionContextInterface, manufacturerId string) (string, error) {
+	clientMSPID, err := s.getClientIdentityMSPID(ctx)
+	if err != nil {
+		return "", err
+	}
+	mspID, err := s.resolveManufacturerMSPID(ctx, manufacturerId)
+	if err != nil {
+		return "", err
+	}
+	if clientMSPID != OEM_ORG_MSPID && clientMSPID != mspID {
+		return "", fmt.Errorf("无权限: 仅限主机厂与预测接收厂商查看")
+	}
+	return forecastCollectionForMSP(mspID)
+}
+
+// 按厂商准入时映射的 MSP 确定预测集合
+func (s *SmartContract) forecastCollection(ctx contractapi.TransactionContextInterface, manufacturerId string) (string, error) {
+	mspID, err := s.resolveManufacturerMSPID(ctx, manufacturerId)
+	if err != nil {
+		return "", err
+	}
+	return forecastCollectionForMSP(mspID)
+}
+
+// 仅已配置预测集合的 MSP 可接收需求预测
+func forecastCollectionForMSP(mspID string) (string, error) {
+	if !containsString(FORECAST_COLLECTION_MSPIDS, mspID) {
+		return "", fmt.Errorf("厂商 MSP %s 未配置需求预测私有集合 %s%s, 当前仅支持 %v", mspID, FORECAST_COLLECTION_PREFIX, mspID, FORECAST_COLLECTION_MSPIDS)
+	}
+	return FORECAST_COLLECTION_PREFIX + mspID, nil
+}
+
+func (s *SmartContract) getForecast(ctx contractapi.TransactionContextInterface, collection string, manufacturerId string, partNumber string) (*Forecast, error) {
+	key, err := ctx.GetStub().CreateCompositeKey(FORECAST, []string{manufacturerId, partNumber})
+	if err != nil {
+		return nil, fmt.Errorf("创建预测键失败: %v", err)
+	}
+	forecastBytes, err := ctx.GetStub().GetPrivateData(collection, key)
+	if err != nil {
+		return nil, fmt.Errorf("读取需求预测失败: %v", err)
+	}
+	if forecastBytes == nil {
+		return nil, nil
+	}
+
+	var forecast Forecast
+	if err := json.Unmarshal(forecastBytes, &forecast); err != nil {
+		return nil, fmt.Errorf("解析需求预测失败: %v", err)
+	}
+	return &forecast, nil
+}
+
+func (s *SmartContract) putForecast(ctx contractapi.TransactionContextInterface, collection string, forecast *Forecast) error {
+	key, err := ctx.GetStub().CreateCompositeKey(FORECAST, []string{forecast.ManufacturerID, forecast.PartNumber})
+	if err != nil {
+		return fmt.Errorf("创建预测键失败: %v", err)
+	}
+	forecastBytes, err := json.Marshal(forecast)
+	if err != nil {
+		return fmt.Errorf("序列化需求预测失败: %v", err)
+	}
+	if err := ctx.GetStub().PutPrivateData(collection, key, forecastBytes); err != nil {
+		return fmt.Errorf("写入需求预测失败: %v", err)
+	}
+	return nil
+}
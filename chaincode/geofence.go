@@ -0,0 +1,355 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/v2/contractapi"
+)
+
+// 地理围栏资产类型 (复合键: GEOFENCE~id)
+const (
+	GEOFENCE = "GEOFENCE"
+)
+
+// 地球平均半径 (米)
+const EARTH_RADIUS_METERS = 6371000.0
+
+// GeofenceType 围栏类型
+type GeofenceType string
+
+const (
+	FENCE_PLANT     GeofenceType = "PLANT"     // 主机厂工厂
+	FENCE_WAREHOUSE GeofenceType = "WAREHOUSE" // 仓库
+)
+
+// Geofence 地理围栏 (圆形, 平台方维护)
+type Geofence struct {
+	ID           string       `json:"id"`           // 围栏ID
+	ObjectType   string       `json:"objectType"`   // 资产类型 (GEOFENCE)
+	Name         string       `json:"name"`         // 名称
+	Type         GeofenceType `json:"type"`         // 围栏类型
+	Latitude     float64      `json:"latitude"`     // 中心纬度
+	Longitude    float64      `json:"longitude"`    // 中心经度
+	RadiusMeters float64      `json:"radiusMeters"` // 半径 (米)
+	AutoDeliver  bool         `json:"autoDeliver"`  // 作为目的地围栏时, 进入后自动将订单置为已送达
+	CreateTime   time.Time    `json:"createTime"`   // 创建时间
+	UpdateTime   time.Time    `json:"updateTime"`   // 更新时间
+}
+
+// CreateGeofence 平台方登记地理围栏 (仅平台方可调用)
+func (s *SmartContract) CreateGeofence(ctx contractapi.TransactionContextInterface, fenceJson string) error {
+	isPlatform, err := s.isPlatformIdentity(ctx)
+	if err != nil {
+		return err
+	}
+	if !isPlatform {
+		return fmt.Errorf("无权限: 仅限平台方维护地理围栏")
+	}
+
+	var fence Geofence
+	if err := json.Unmarshal([]byte(fenceJson), &fence); err != nil {
+		return fmt.Errorf("解析地理围栏失败: %v", err)
+	}
+	if err := validateGeofence(&fence); err != nil {
+		return err
+	}
+
+	existing, err := s.getGeofence(ctx, fence.ID)
+	if err != nil {
+		return err
+	}
+	if existing != nil {
+		return fmt.Errorf("地理围栏 %s 已存在", fence.ID)
+	}
+
+	now, err := s.getTxTimestamp(ctx)
+	if err != nil {
+		return err
+	}
+	fence.ObjectType = GEOFENCE
+	fence.CreateTime = now
+	fence.UpdateTime = now
+	return s.putGeofence(ctx, &fence)
+}
+
+// UpdateGeofence 平台方修改地理围栏 (仅平台方可调用)
+func (s *SmartContract) UpdateGeofence(ctx contractapi.TransactionContextInterface, fenceJson string) error {
+	isPlatform, err := s.isPlatformIdentity(ctx)
+	if err != nil {
+		return err
+	}
+	if !isPlatform {
+		return fmt.Errorf("无权限: 仅限平台方维护地理围栏")
+	}
+
+	var update Geofence
+	if err := json.Unmarshal([]byte(fenceJson), &update); err != nil {
+		return fmt.Errorf("解析地理围栏失败: %v", err)
+	}
+	if err := validateGeofence(&update); err != nil {
+		return err
+	}
+
+	fence, err := s.QueryGeofence(ctx, update.ID)
+	if err != nil {
+		return err
+	}
+
+	now, err := s.getTxTimestamp(ctx)
+	if err != nil {
+		return err
+	}
+	update.ObjectType = GEOFENCE
+	update.CreateTime = fence.CreateTime
+	update.UpdateTime = now
+	return s.putGeofence(ctx, &update)
+}
+
+// DeleteGeofence 平台方删除地理围栏 (仅平台方可调用)
+func (s *SmartContract) DeleteGeofence(ctx contractapi.TransactionContextInterface, id string) error {
+	isPlatform, err := s.isPlatformIdentity(ctx)
+	if err != nil {
+		return err
+	}
+	if !isPlatform {
+		return fmt.Errorf("无权限: 仅限平台方维护地理围栏")
+	}
+
+	if _, err := s.QueryGeofence(ctx, id); err != nil {
+		return err
+	}
+	key, err := ctx.GetStub().CreateCompositeKey(GEOFENCE, []string{id})
+	if err != nil {
+		return fmt.Errorf("创建地理围栏键失败: %v", err)
+	}
+	return ctx.GetStub().DelState(key)
+}
+
+// QueryGeofence 查询地理围栏
+func (s *SmartContract) QueryGeofence(ctx contractapi.TransactionContextInterface, id string) (*Geofence, error) {
+	fence, err := s.getGeofence(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if fence == nil {
+		return nil, fmt.Errorf("地理围栏 %s 不存在", id)
+	}
+	return fence, nil
+}
+
+// QueryGeofenceList 查询全部地理围栏
+func (s *SmartContract) QueryGeofenceList(ctx contractapi.TransactionContextInterface) ([]*Geofence, error) {
+	resultsIterator, err := ctx.GetStub().GetStateByPartialCompositeKey(GEOFENCE, []string{})
+	if err != nil {
+		return nil, fmt.Errorf("读取地理围栏失败: %v", err)
+	}
+	defer resultsIterator.Close()
+
+	fences := make([]*Geofence, 0)
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return nil, err
+		}
+		var fence Geofence
+		if err := json.Unmarshal(queryResponse.Value, &fence); err != nil {
+			return nil, fmt.Errorf("解析地理围栏失败: %v", err)
+		}
+		fences = append(fences, &fence)
+	}
+	return fences, nil
+}
+
+// SetShipmentDestination 设置物流单目的地围栏 (订单采购方或当前保管承运商可调用)
+func (s *SmartContract) SetShipmentDestination(ctx contractapi.TransactionContextInterface, shipmentId string, fenceId string) error {
+	clientMSPID, err := s.getClientIdentityMSPID(ctx)
+	if err != nil {
+		return err
+	}
+
+	shipment, err := s.QueryShipment(ctx, shipmentId)
+	if err != nil {
+		return err
+	}
+	order, err := s.QueryOrder(ctx, shipment.OrderID)
+	if err != nil {
+		return err
+	}
+	if clientMSPID != order.OEMID {
+		carrierID, err := s.getCarrierID(ctx)
+		if err != nil {
+			return err
+		}
+		if carrierID != shipment.CustodianID {
+			return fmt.Errorf("无权限: 仅限采购方或当前保管承运商设置目的地")
+		}
+	}
+
+	if _, err := s.QueryGeofence(ctx, fenceId); err != nil {
+		return err
+	}
+
+	now, err := s.getTxTimestamp(ctx)
+	if err != nil {
+		return err
+	}
+	shipment.DestinationFenceID = fenceId
+	shipment.UpdateTime = now
+	return s.putShipment(ctx, shipment)
+}
+
+// 根据最新坐标判断进出围栏, 自动追加到达/离开检查点; 进入目的地围栏时按配置将订单置为已送达
+func (s *SmartContract) evaluateGeofences(ctx contractapi.TransactionContextInterface, shipment *Shipment, latitude float64, longitude float64, carrierID string, now time.Time) error {
+	fences, err := s.QueryGeofenceList(ctx)
+	if err != nil {
+		return err
+	}
+
+	inside := make([]string, 0)
+	for _, fence := range fences {
+		if distanceMeters(latitude, longitude, fence.Latitude, fence.Longitude) > fence.RadiusMeters {
+			continue
+		}
+		inside = append(inside, fence.ID)
+		if containsString(shipment.InsideFenceIDs, fence.ID) {
+			continue
+		}
+
+		lat, lng := latitude, longitude
+		if err := s.appendCheckpoint(ctx, shipment, Checkpoint{
+			EventType: EVENT_ARRIVED,
+			Latitude:  &lat,
+			Longitude: &lng,
+			Place:     fence.Name,
+			FenceID:   fence.ID,
+			CarrierID: carrierID,
+			Timestamp: now,
+		}); err != nil {
+			return err
+		}
+		shipment.Location = fence.Name
+
+		if fence.ID == shipment.DestinationFenceID && fence.AutoDeliver {
+			// 电子提单未交回时不自动交付
+			outstanding, err := s.hasOutstandingBillOfLading(ctx, shipment.OrderID)
+			if err != nil {
+				return err
+			}
+			if outstanding {
+				continue
+			}
+			if err := s.markOrderDelivered(ctx, shipment.OrderID, now); err != nil {
+				return err
+			}
+			if shipment.Status == SHIPMENT_IN_TRANSIT {
+				shipment.Status = SHIPMENT_DELIVERED
+			}
+		}
+	}
+
+	for _, fenceID := range shipment.InsideFenceIDs {
+		if containsString(inside, fenceID) {
+			continue
+		}
+
+		place := fenceID
+		if fence, err := s.getGeofence(ctx, fenceID); err == nil && fence != nil {
+			place = fence.Name
+		}
+		lat, lng := latitude, longitude
+		if err := s.appendCheckpoint(ctx, shipment, Checkpoint{
+			EventType: EVENT_DEPARTED,
+			Latitude:  &lat,
+			Longitude: &lng,
+			Place:     place,
+			FenceID:   fenceID,
+			CarrierID: carrierID,
+			Timestamp: now,
+		}); err != nil {
+			return err
+		}
+	}
+
+	shipment.InsideFenceIDs = inside
+	return nil
+}
+
+func (s *SmartContract) markOrderDelivered(ctx contractapi.TransactionContextInterface, orderId string, now time.Time) error {
+	order, err := s.QueryOrder(ctx, orderId)
+	if err != nil {
+		return err
+	}
+	if order.Status != ORDER_SHIPPED {
+		return nil
+	}
+
+	order.Status = ORDER_DELIVERED
+	order.UpdateTime = now
+	triggerPaymentMilestones(order, now)
+	orderBytes, err := json.Marshal(order)
+	if err != nil {
+		return fmt.Errorf("序列化订单失败: %v", err)
+	}
+	return ctx.GetStub().PutState(orderId, orderBytes)
+}
+
+func validateGeofence(fence *Geofence) error {
+	if fence.ID == "" || fence.Name == "" {
+		return fmt.Errorf("围栏ID与名称不能为空")
+	}
+	if fence.Type != FENCE_PLANT && fence.Type != FENCE_WAREHOUSE {
+		return fmt.Errorf("无效的围栏类型: %s", fence.Type)
+	}
+	if fence.Latitude < -90 || fence.Latitude > 90 || fence.Longitude < -180 || fence.Longitude > 180 {
+		return fmt.Errorf("经纬度超出范围: %f, %f", fence.Latitude, fence.Longitude)
+	}
+	if fence.RadiusMeters <= 0 {
+		return fmt.Errorf("围栏半径必须大于 0")
+	}
+	return nil
+}
+
+// 两点间球面距离 (Haversine 公式, 单位米)
+func distanceMeters(lat1, lng1, lat2, lng2 float64) float64 {
+	toRad := func(deg float64) float64 { return deg * math.Pi / 180 }
+	dLat := toRad(lat2 - lat1)
+	dLng := toRad(lng2 - lng1)
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(toRad(lat1))*math.Cos(toRad(lat2))*math.Sin(dLng/2)*math.Sin(dLng/2)
+	return 2 * EARTH_RADIUS_METERS * math.Asin(math.Sqrt(a))
+}
+
+func (s *SmartContract) getGeofence(ctx contractapi.TransactionContextInterface, id string) (*Geofence, error) {
+	key, err := ctx.GetStub().CreateCompositeKey(GEOFENCE, []string{id})
+	if err != nil {
+		return nil, fmt.Errorf("创建地理围栏键失败: %v", err)
+	}
+	fenceBytes, err := ctx.GetStub().GetState(key)
+	if err != nil {
+		return nil, fmt.Errorf("读取地理围栏失败: %v", err)
+	}
+	if fenceBytes == nil {
+		return nil, nil
+	}
+
+	var fence Geofence
+	if err := json.Unmarshal(fenceBytes, &fence); err != nil {
+		return nil, fmt.Errorf("解析地理围栏失败: %v", err)
+	}
+	return &fence, nil
+}
+
+func (s *SmartContract) putGeofence(ctx contractapi.TransactionContextInterface, fence *Geofence) error {
+	key, err := ctx.GetStub().CreateCompositeKey(GEOFENCE, []string{fence.ID})
+	if err != nil {
+		return fmt.Errorf("创建地理围栏键失败: %v", err)
+	}
+	fenceBytes, err := json.Marshal(fence)
+	if err != nil {
+		return fmt.Errorf("序列化地理围栏失败: %v", err)
+	}
+	return ctx.GetStub().PutState(key, fenceBytes)
+}
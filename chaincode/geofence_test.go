@@ -0,0 +1,33 @@
+package main
+
+import (
+	"math"
+	"testing"
+)
+
+func TestDistanceMeters(t *testing.T) {
+	tests := []struct {
+		name                   string
+		lat1, lng1, lat2, lng2 float64
+		want                   float64
+		tolerance              float64
+	}{
+		{"同一点", 31.2304, 121.4737, 31.2304, 121.4737, 0, 1e-6},
+		{"赤道上经度相差 1 度", 0, 0, 0, 1, EARTH_RADIUS_METERS * math.Pi / 180, 1e-6},
+		{"同经线纬度相差 1 度", 10, 20, 11, 20, EARTH_RADIUS_METERS * math.Pi / 180, 1e-6},
+		{"南北极", 90, 0, -90, 0, EARTH_RADIUS_METERS * math.Pi, 1e-3},
+		{"跨越 180 度经线", 0, 179.5, 0, -179.5, EARTH_RADIUS_METERS * math.Pi / 180, 1e-3},
+		{"上海至北京约 1067 公里", 31.2304, 121.4737, 39.9042, 116.4074, 1067e3, 5e3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := distanceMeters(tt.lat1, tt.lng1, tt.lat2, tt.lng2)
+			if math.Abs(got-tt.want) > tt.tolerance {
+				t.Errorf("distanceMeters() = %f, want %f ± %f", got, tt.want, tt.tolerance)
+			}
+			if reverse := distanceMeters(tt.lat2, tt.lng2, tt.lat1, tt.lng1); math.Abs(reverse-got) > 1e-6 {
+				t.Errorf("distanceMeters() 不对称: %f != %f", reverse, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,358 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/v2/contractapi"
+)
+
+// 发票资产类型
+const (
+	INVOICE           = "INVOICE"
+	INVOICE_TRANSIENT = "invoice" // 瞬态字段名, 携带 InvoiceAmount
+)
+
+// 三单匹配容差 (数量与单价的相对偏差)
+const INVOICE_MATCH_TOLERANCE = 0.02
+
+// InvoiceStatus 发票状态
+type InvoiceStatus string
+
+const (
+	INVOICE_MATCHED   InvoiceStatus = "MATCHED"   // 三单匹配通过
+	INVOICE_EXCEPTION InvoiceStatus = "EXCEPTION" // 三单匹配存在差异
+	INVOICE_APPROVED  InvoiceStatus = "APPROVED"  // 主机厂已批准付款
+	INVOICE_REJECTED  InvoiceStatus = "REJECTED"  // 主机厂已拒绝
+)
+
+// Invoice 发票 (公开部分, 金额存于私有集合)
+type Invoice struct {
+	ID             string             `json:"id"`             // 发票ID
+	ObjectType     string             `json:"objectType"`     // 资产类型 (INVOICE)
+	OrderID        string             `json:"orderId"`        // 关联订单ID
+	OEMID          string             `json:"oemId"`          // 付款方
+	ManufacturerID string             `json:"manufacturerId"` // 开票厂商
+	Quantities     []int              `json:"quantities"`     // 开票数量, 与 Order.Items 一一对应
+	AmountHash     string             `json:"amountHash"`     // 私有金额数据哈希 (SHA-256)
+	Status         InvoiceStatus      `json:"status"`         // 当前状态
+	MatchResults   []InvoiceLineMatch `json:"matchResults"`   // 逐行匹配结果
+	RejectReason   string             `json:"rejectReason"`   // 拒绝原因
+	ConsumptionID  string             `json:"consumptionId"`  // 寄售消耗报告ID (按消耗自动开票时)
+	CreateTime     time.Time          `json:"createTime"`     // 开票时间
+	UpdateTime     time.Time          `json:"updateTime"`     // 更新时间
+}
+
+// InvoiceLineMatch 发票行三单匹配结果 (不含价格, 仅给出是否匹配)
+type InvoiceLineMatch struct {
+	PartNumber       string `json:"partNumber"`       // 零件号
+	OrderedQuantity  int    `json:"orderedQuantity"`  // 订单数量
+	ReceivedQuantity int    `json:"receivedQuantity"` // 实收数量
+	InvoicedQuantity int    `json:"invoicedQuantity"` // 开票数量
+	QuantityMatched  bool   `json:"quantityMatched"`  // 开票数量与实收数量在容差内
+	PriceMatched     bool   `json:"priceMatched"`     // 开票单价与订单单价在容差内
+}
+
+// InvoiceAmount 发票金额 (私有数据)
+type InvoiceAmount struct {
+	InvoiceID   string    `json:"invoiceId"`   // 发票ID
+	UnitPrices  []float64 `json:"unitPrices"`  // 开票单价, 与 Order.Items 一一对应
+	TotalAmount float64   `json:"totalAmount"` // 发票总额
+	Salt        string    `json:"salt"`        // 随机盐
+}
+
+// IssueInvoice 厂商对已签收订单开具发票并执行三单匹配 (仅订单映射的厂商 MSP 可调用, 单价通过瞬态字段 invoice 传入)
+func (s *SmartContract) IssueInvoice(ctx contractapi.TransactionContextInterface, id string, orderId string, quantitiesJson string) error {
+	clientMSPID, err := s.getClientIdentityMSPID(ctx)
+	if err != nil {
+		return err
+	}
+
+	existing, err := ctx.GetStub().GetState(id)
+	if err != nil {
+		return fmt.Errorf("读取发票失败: %v", err)
+	}
+	if existing != nil {
+		return fmt.Errorf("发票 %s 已存在", id)
+	}
+
+	order, err := s.QueryOrder(ctx, orderId)
+	if err != nil {
+		return err
+	}
+	if clientMSPID != order.ManufacturerMSPID {
+		return fmt.Errorf("无权限: 仅限订单厂商开具发票")
+	}
+	// 发票金额写入交易双方私有集合, 子订单由一级供应商在链下与二级供应商结算
+	if order.ParentOrderID != "" {
+		return fmt.Errorf("订单 %s 为子订单, 无法开具发票", orderId)
+	}
+	if order.Status != ORDER_RECEIVED {
+		return fmt.Errorf("订单当前状态 %s 无法开票, 须签收后开票", order.Status)
+	}
+	if order.ConsignmentLocation != "" {
+		return fmt.Errorf("订单 %s 为寄售订单, 按消耗自动开票", orderId)
+	}
+	if order.InvoiceID != "" {
+		invoice, err := s.QueryInvoice(ctx, order.InvoiceID)
+		if err != nil {
+			return err
+		}
+		if invoice.Status != INVOICE_REJECTED {
+			return fmt.Errorf("订单 %s 已有发票 %s", orderId, order.InvoiceID)
+		}
+	}
+
+	var quantities []int
+	if err := json.Unmarshal([]byte(quantitiesJson), &quantities); err != nil {
+		return fmt.Errorf("解析开票数量失败: %v", err)
+	}
+	if len(quantities) != len(order.Items) {
+		return fmt.Errorf("开票数量 %d 与零件数量 %d 不一致", len(quantities), len(order.Items))
+	}
+
+	amount, err := s.getInvoiceAmountFromTransient(ctx, id, quantities)
+	if err != nil {
+		return err
+	}
+	orderPrice, err := s.QueryOrderPrice(ctx, orderId)
+	if err != nil {
+		return err
+	}
+
+	results, matched := matchInvoice(order, orderPrice, quantities, amount.UnitPrices)
+	status := INVOICE_EXCEPTION
+	if matched {
+		status = INVOICE_MATCHED
+	}
+
+	amountKey, err := ctx.GetStub().CreateCompositeKey(INVOICE, []string{id})
+	if err != nil {
+		return fmt.Errorf("创建发票键失败: %v", err)
+	}
+	amountBytes, err := json.Marshal(amount)
+	if err != nil {
+		return fmt.Errorf("序列化发票金额失败: %v", err)
+	}
+	if err := ctx.GetStub().PutPrivateData(ORDER_PRICE_COLLECTION, amountKey, amountBytes); err != nil {
+		return fmt.Errorf("写入发票金额失败: %v", err)
+	}
+	amountHash := sha256.Sum256(amountBytes)
+
+	now, err := s.getTxTimestamp(ctx)
+	if err != nil {
+		return err
+	}
+	invoice := &Invoice{
+		ID:             id,
+		ObjectType:     INVOICE,
+		OrderID:        orderId,
+		OEMID:          order.OEMID,
+		ManufacturerID: order.ManufacturerID,
+		Quantities:     quantities,
+		AmountHash:     hex.EncodeToString(amountHash[:]),
+		Status:         status,
+		MatchResults:   results,
+		CreateTime:     now,
+		UpdateTime:     now,
+	}
+	if err := s.putInvoice(ctx, invoice); err != nil {
+		return err
+	}
+	if err := s.setEndorsementPolicy(ctx, id, order.OEMID, order.ManufacturerMSPID); err != nil {
+		return err
+	}
+
+	order.InvoiceID = id
+	order.UpdateTime = now
+	orderBytes, err := json.Marshal(order)
+	if err != nil {
+		return fmt.Errorf("序列化订单失败: %v", err)
+	}
+	return ctx.GetStub().PutState(orderId, orderBytes)
+}
+
+// ApproveInvoice 主机厂批准已匹配的发票付款
+func (s *SmartContract) ApproveInvoice(ctx contractapi.TransactionContextInterface, id string) error {
+	clientMSPID, err := s.getClientIdentityMSPID(ctx)
+	if err != nil {
+		return err
+	}
+
+	invoice, err := s.QueryInvoice(ctx, id)
+	if err != nil {
+		return err
+	}
+	if clientMSPID != invoice.OEMID {
+		return fmt.Errorf("无权限: 仅限付款方批准发票")
+	}
+	if invoice.Status != INVOICE_MATCHED {
+		return fmt.Errorf("发票当前状态 %s 无法批准, 须三单匹配通过", invoice.Status)
+	}
+
+	now, err := s.getTxTimestamp(ctx)
+	if err != nil {
+		return err
+	}
+	invoice.Status = INVOICE_APPROVED
+	invoice.UpdateTime = now
+	return s.putInvoice(ctx, invoice)
+}
+
+// RejectInvoice 主机厂拒绝发票, 厂商可对同一订单重新开票
+func (s *SmartContract) RejectInvoice(ctx contractapi.TransactionContextInterface, id string, reason string) error {
+	clientMSPID, err := s.getClientIdentityMSPID(ctx)
+	if err != nil {
+		return err
+	}
+
+	invoice, err := s.QueryInvoice(ctx, id)
+	if err != nil {
+		return err
+	}
+	if clientMSPID != invoice.OEMID {
+		return fmt.Errorf("无权限: 仅限付款方拒绝发票")
+	}
+	if invoice.Status != INVOICE_MATCHED && invoice.Status != INVOICE_EXCEPTION {
+		return fmt.Errorf("发票当前状态 %s 无法拒绝", invoice.Status)
+	}
+	locked, err := s.isOrderFinancingLocked(ctx, invoice.OrderID)
+	if err != nil {
+		return err
+	}
+	if locked {
+		return fmt.Errorf("订单 %s 的应收账款处于融资锁定中, 无法拒绝发票", invoice.OrderID)
+	}
+
+	now, err := s.getTxTimestamp(ctx)
+	if err != nil {
+		return err
+	}
+	invoice.Status = INVOICE_REJECTED
+	invoice.RejectReason = reason
+	invoice.UpdateTime = now
+	return s.putInvoice(ctx, invoice)
+}
+
+// QueryInvoice 查询发票
+func (s *SmartContract) QueryInvoice(ctx contractapi.TransactionContextInterface, id string) (*Invoice, error) {
+	invoiceBytes, err := ctx.GetStub().GetState(id)
+	if err != nil {
+		return nil, fmt.Errorf("读取发票失败: %v", err)
+	}
+	if invoiceBytes == nil {
+		return nil, fmt.Errorf("发票 %s 不存在", id)
+	}
+
+	var invoice Invoice
+	if err := json.Unmarshal(invoiceBytes, &invoice); err != nil {
+		return nil, fmt.Errorf("解析发票失败: %v", err)
+	}
+	if invoice.ObjectType != INVOICE {
+		return nil, fmt.Errorf("发票 %s 不存在", id)
+	}
+	return &invoice, nil
+}
+
+// QueryInvoiceAmount 查询发票金额 (仅 Org1/Org2 可调用)
+func (s *SmartContract) QueryInvoiceAmount(ctx contractapi.TransactionContextInterface, id string) (*InvoiceAmount, error) {
+	clientMSPID, err := s.getClientIdentityMSPID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if clientMSPID != OEM_ORG_MSPID && clientMSPID != MANUFACTURER_ORG_MSPID {
+		return nil, fmt.Errorf("无权限: 仅限交易双方查看发票金额")
+	}
+
+	amountKey, err := ctx.GetStub().CreateCompositeKey(INVOICE, []string{id})
+	if err != nil {
+		return nil, fmt.Errorf("创建发票键失败: %v", err)
+	}
+	amountBytes, err := ctx.GetStub().GetPrivateData(ORDER_PRICE_COLLECTION, amountKey)
+	if err != nil {
+		return nil, fmt.Errorf("读取发票金额失败: %v", err)
+	}
+	if amountBytes == nil {
+		return nil, fmt.Errorf("发票 %s 金额不存在", id)
+	}
+
+	var amount InvoiceAmount
+	if err := json.Unmarshal(amountBytes, &amount); err != nil {
+		return nil, fmt.Errorf("解析发票金额失败: %v", err)
+	}
+	return &amount, nil
+}
+
+// 三单匹配: 订单行 / 实收数量 / 发票行
+func matchInvoice(order *Order, orderPrice *OrderPrice, quantities []int, unitPrices []float64) ([]InvoiceLineMatch, bool) {
+	results := make([]InvoiceLineMatch, len(order.Items))
+	matched := true
+	for i, item := range order.Items {
+		result := InvoiceLineMatch{
+			PartNumber:       item.PartNumber,
+			OrderedQuantity:  item.Quantity,
+			ReceivedQuantity: item.ReceivedQuantity,
+			InvoicedQuantity: quantities[i],
+		}
+		result.QuantityMatched = quantities[i] <= item.Quantity &&
+			withinTolerance(float64(quantities[i]), float64(item.ReceivedQuantity))
+		result.PriceMatched = withinTolerance(unitPrices[i], orderPrice.ItemPrices[i])
+		if !result.QuantityMatched || !result.PriceMatched {
+			matched = false
+		}
+		results[i] = result
+	}
+	return results, matched
+}
+
+func withinTolerance(actual float64, expected float64) bool {
+	if expected == 0 {
+		return actual == 0
+	}
+	return math.Abs(actual-expected) <= math.Abs(expected)*INVOICE_MATCH_TOLERANCE
+}
+
+// 从瞬态数据中读取发票单价并计算总额
+func (s *SmartContract) getInvoiceAmountFromTransient(ctx contractapi.TransactionContextInterface, invoiceId string, quantities []int) (*InvoiceAmount, error) {
+	transientMap, err := ctx.GetStub().GetTransient()
+	if err != nil {
+		return nil, fmt.Errorf("读取瞬态数据失败: %v", err)
+	}
+	amountJson, ok := transientMap[INVOICE_TRANSIENT]
+	if !ok {
+		return nil, fmt.Errorf("缺少发票金额: 需通过瞬态字段 %s 传入", INVOICE_TRANSIENT)
+	}
+
+	var amount InvoiceAmount
+	if err := json.Unmarshal(amountJson, &amount); err != nil {
+		return nil, fmt.Errorf("解析发票金额失败: %v", err)
+	}
+	if len(amount.UnitPrices) != len(quantities) {
+		return nil, fmt.Errorf("开票单价数量 %d 与开票行数 %d 不一致", len(amount.UnitPrices), len(quantities))
+	}
+	if amount.Salt == "" {
+		return nil, fmt.Errorf("发票金额缺少随机盐")
+	}
+
+	amount.InvoiceID = invoiceId
+	amount.TotalAmount = 0
+	for i, price := range amount.UnitPrices {
+		if price < 0 || quantities[i] < 0 {
+			return nil, fmt.Errorf("第 %d 行开票单价与数量不能为负数", i+1)
+		}
+		amount.TotalAmount += float64(quantities[i]) * price
+	}
+	return &amount, nil
+}
+
+func (s *SmartContract) putInvoice(ctx contractapi.TransactionContextInterface, invoice *Invoice) error {
+	invoiceBytes, err := json.Marshal(invoice)
+	if err != nil {
+		return fmt.Errorf("序列化发票失败: %v", err)
+	}
+	return ctx.GetStub().PutState(invoice.ID, invoiceBytes)
+}
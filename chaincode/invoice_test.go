@@ -0,0 +1,123 @@
+package main
+
+import "testing"
+
+func TestWithinTolerance(t *testing.T) {
+	tests := []struct {
+		name     string
+		actual   float64
+		expected float64
+		want     bool
+	}{
+		{"相等", 100, 100, true},
+		{"上浮 2% 以内", 101.99, 100, true},
+		{"上浮恰好 2%", 102, 100, true},
+		{"上浮超过 2%", 102.01, 100, false},
+		{"下浮恰好 2%", 98, 100, true},
+		{"下浮超过 2%", 97.99, 100, false},
+		{"期望为 0 且实际为 0", 0, 0, true},
+		{"期望为 0 实际非 0", 0.01, 0, false},
+		{"负数期望按绝对值计算容差", -101, -100, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := withinTolerance(tt.actual, tt.expected); got != tt.want {
+				t.Errorf("withinTolerance(%v, %v) = %v, want %v", tt.actual, tt.expected, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMatchInvoice(t *testing.T) {
+	order := &Order{
+		Items: []OrderItem{
+			{PartNumber: "P-001", Quantity: 100, ReceivedQuantity: 100},
+			{PartNumber: "P-002", Quantity: 50, ReceivedQuantity: 48},
+		},
+	}
+	orderPrice := &OrderPrice{ItemPrices: []float64{10, 200}}
+
+	tests := []struct {
+		name          string
+		quantities    []int
+		unitPrices    []float64
+		wantMatched   bool
+		wantQuantity  []bool
+		wantPrice     []bool
+		wantInvoicedQ []int
+	}{
+		{
+			name:          "数量与单价全部一致",
+			quantities:    []int{100, 48},
+			unitPrices:    []float64{10, 200},
+			wantMatched:   true,
+			wantQuantity:  []bool{true, true},
+			wantPrice:     []bool{true, true},
+			wantInvoicedQ: []int{100, 48},
+		},
+		{
+			name:          "按订单数量而非实收数量开票, 短收行不匹配",
+			quantities:    []int{100, 50},
+			unitPrices:    []float64{10, 200},
+			wantMatched:   false,
+			wantQuantity:  []bool{true, false},
+			wantPrice:     []bool{true, true},
+			wantInvoicedQ: []int{100, 50},
+		},
+		{
+			name:          "开票数量超过订单数量",
+			quantities:    []int{101, 48},
+			unitPrices:    []float64{10, 200},
+			wantMatched:   false,
+			wantQuantity:  []bool{false, true},
+			wantPrice:     []bool{true, true},
+			wantInvoicedQ: []int{101, 48},
+		},
+		{
+			name:          "单价在容差内",
+			quantities:    []int{100, 48},
+			unitPrices:    []float64{10.2, 196},
+			wantMatched:   true,
+			wantQuantity:  []bool{true, true},
+			wantPrice:     []bool{true, true},
+			wantInvoicedQ: []int{100, 48},
+		},
+		{
+			name:          "单价超出容差",
+			quantities:    []int{100, 48},
+			unitPrices:    []float64{10, 205},
+			wantMatched:   false,
+			wantQuantity:  []bool{true, true},
+			wantPrice:     []bool{true, false},
+			wantInvoicedQ: []int{100, 48},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results, matched := matchInvoice(order, orderPrice, tt.quantities, tt.unitPrices)
+			if matched != tt.wantMatched {
+				t.Errorf("matched = %v, want %v", matched, tt.wantMatched)
+			}
+			if len(results) != len(order.Items) {
+				t.Fatalf("len(results) = %d, want %d", len(results), len(order.Items))
+			}
+			for i, result := range results {
+				if result.PartNumber != order.Items[i].PartNumber {
+					t.Errorf("line %d PartNumber = %s, want %s", i, result.PartNumber, order.Items[i].PartNumber)
+				}
+				if result.OrderedQuantity != order.Items[i].Quantity || result.ReceivedQuantity != order.Items[i].ReceivedQuantity {
+					t.Errorf("line %d ordered/received = %d/%d, want %d/%d", i, result.OrderedQuantity, result.ReceivedQuantity, order.Items[i].Quantity, order.Items[i].ReceivedQuantity)
+				}
+				if result.InvoicedQuantity != tt.wantInvoicedQ[i] {
+					t.Errorf("line %d InvoicedQuantity = %d, want %d", i, result.InvoicedQuantity, tt.wantInvoicedQ[i])
+				}
+				if result.QuantityMatched != tt.wantQuantity[i] {
+					t.Errorf("line %d QuantityMatched = %v, want %v", i, result.QuantityMatched, tt.wantQuantity[i])
+				}
+				if result.PriceMatched != tt.wantPrice[i] {
+					t.Errorf("line %d PriceMatched = %v, want %v", i, result.PriceMatched, tt.wantPrice[i])
+				}
+			}
+		})
+	}
+}
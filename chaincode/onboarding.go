@@ -0,0 +1,439 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/v2/contractapi"
+)
+
+// 供应商准入资产类型
+const (
+	ONBOARDING        = "ONBOARDING"        // 准入申请
+	ONBOARDING_STATUS = "ONBOARDING_STATUS" // 申请状态索引 (复合键: ONBOARDING_STATUS~status~applicationId)
+	PARTICIPANT       = "PARTICIPANT"       // 已准入参与方 (复合键: PARTICIPANT~participantId)
+)
+
+// OnboardingStatus 准入申请状态
+type OnboardingStatus string
+
+const (
+	ONBOARDING_SUBMITTED         OnboardingStatus = "SUBMITTED"         // 已提交, 待平台审核
+	ONBOARDING_UNDER_REVIEW      OnboardingStatus = "UNDER_REVIEW"      // 平台审核中
+	ONBOARDING_CHANGES_REQUESTED OnboardingStatus = "CHANGES_REQUESTED" // 平台要求补充材料
+	ONBOARDING_APPROVED          OnboardingStatus = "APPROVED"          // 已批准, 参与方已激活
+	ONBOARDING_REJECTED          OnboardingStatus = "REJECTED"          // 已拒绝
+)
+
+// OnboardingDocCategory 准入材料类别
+type OnboardingDocCategory string
+
+const (
+	ONBOARDING_DOC_KYC           OnboardingDocCategory = "KYC"           // 主体资质 (营业执照、法人身份等)
+	ONBOARDING_DOC_QUALIFICATION OnboardingDocCategory = "QUALIFICATION" // 质量/行业资质 (IATF 16949 等)
+)
+
+// ParticipantStatus 参与方状态
+type ParticipantStatus string
+
+const (
+	PARTICIPANT_ACTIVE ParticipantStatus = "ACTIVE" // 已激活, 可参与下单
+)
+
+// OnboardingDocument 准入材料 (原件链下存储, 链上仅保存哈希)
+type OnboardingDocument struct {
+	Category   OnboardingDocCategory `json:"category"`             // 材料类别
+	Name       string                `json:"name"`                 // 材料名称
+	Hash       string                `json:"hash"`                 // 文档 SHA-256
+	DocumentID string                `json:"documentId,omitempty"` // 链下文档锚定记录ID (可选)
+}
+
+// OnboardingAuditEntry 准入审计记录
+type OnboardingAuditEntry struct {
+	Action    string           `json:"action"`    // 操作
+	ActorID   string           `json:"actorId"`   // 操作方
+	Status    OnboardingStatus `json:"status"`    // 操作后状态
+	Comment   string           `json:"comment"`   // 说明
+	TxID      string           `json:"txId"`      // 交易ID
+	Timestamp time.Time        `json:"timestamp"` // 操作时间
+}
+
+// OnboardingApplication 供应商准入申请
+type OnboardingApplication struct {
+	ID             string                 `json:"id"`             // 申请ID
+	ObjectType     string                 `json:"objectType"`     // 资产类型 (ONBOARDING)
+	ParticipantID  string                 `json:"participantId"`  // 准入后的参与方ID (订单 manufacturerId 引用此ID)
+	MSPID          string                 `json:"mspId"`          // 参与方在链上操作所用的组织 MSP ID
+	CompanyName    string                 `json:"companyName"`    // 企业名称
+	RegistrationNo string                 `json:"registrationNo"` // 统一社会信用代码
+	Address        string                 `json:"address"`        // 注册地址
+	ContactName    string                 `json:"contactName"`    // 联系人
+	ContactEmail   string                 `json:"contactEmail"`   // 联系邮箱
+	Capabilities   []string               `json:"capabilities"`   // 供货能力 (零件类别等)
+	Documents      []OnboardingDocument   `json:"documents"`      // KYC 与资质材料
+	SubmitterID    string                 `json:"submitterId"`    // 提交方 (主机厂推荐或平台代录)
+	ReviewerID     string                 `json:"reviewerId"`     // 审核方
+	Status         OnboardingStatus       `json:"status"`         // 当前状态
+	AuditTrail     []OnboardingAuditEntry `json:"auditTrail"`     // 审计记录
+	CreateTime     time.Time              `json:"createTime"`     // 提交时间
+	UpdateTime     time.Time              `json:"updateTime"`     // 更新时间
+}
+
+// Participant 已准入参与方
+type Participant struct {
+	ID            string            `json:"id"`            // 参与方ID
+	ObjectType    string            `json:"objectType"`    // 资产类型 (PARTICIPANT)
+	CompanyName   string            `json:"companyName"`   // 企业名称
+	ApplicationID string            `json:"applicationId"` // 准入申请ID
+	Status        ParticipantStatus `json:"status"`        // 当前状态
+	MSPID         string            `json:"mspId"`         // 代表参与方操作的组织 MSP ID
+	ActivatedBy   string            `json:"activatedBy"`   // 激活方
+	ActivateTime  time.Time         `json:"activateTime"`  // 激活时间
+}
+
+// SubmitOnboardingApplication 提交供应商准入申请 (主机厂推荐或平台方代录)
+func (s *SmartContract) SubmitOnboardingApplication(ctx contractapi.TransactionContextInterface, applicationJson string) error {
+	clientMSPID, err := s.getClientIdentityMSPID(ctx)
+	if err != nil {
+		return err
+	}
+	if clientMSPID != OEM_ORG_MSPID {
+		isPlatform, err := s.isPlatformIdentity(ctx)
+		if err != nil {
+			return err
+		}
+		if !isPlatform {
+			return fmt.Errorf("无权限: 仅限主机厂或平台方提交准入申请")
+		}
+	}
+
+	var app OnboardingApplication
+	if err := json.Unmarshal([]byte(applicationJson), &app); err != nil {
+		return fmt.Errorf("解析准入申请失败: %v", err)
+	}
+	if app.ID == "" || app.ParticipantID == "" || app.CompanyName == "" || app.RegistrationNo == "" {
+		return fmt.Errorf("申请ID、参与方ID、企业名称与统一社会信用代码不能为空")
+	}
+	if err := validateOnboardingDocuments(app.Documents); err != nil {
+		return err
+	}
+	if err := validateManufacturerMSPID(app.MSPID); err != nil {
+		return err
+	}
+
+	existing, err := ctx.GetStub().GetState(app.ID)
+	if err != nil {
+		return fmt.Errorf("读取准入申请失败: %v", err)
+	}
+	if existing != nil {
+		return fmt.Errorf("准入申请 %s 已存在", app.ID)
+	}
+	participant, err := s.getParticipant(ctx, app.ParticipantID)
+	if err != nil {
+		return err
+	}
+	if participant != nil || app.ParticipantID == MANUFACTURER_ORG_MSPID {
+		return fmt.Errorf("参与方 %s 已准入", app.ParticipantID)
+	}
+
+	now, err := s.getTxTimestamp(ctx)
+	if err != nil {
+		return err
+	}
+	app.ObjectType = ONBOARDING
+	app.SubmitterID = clientMSPID
+	app.ReviewerID = ""
+	app.Status = ""
+	app.AuditTrail = []OnboardingAuditEntry{}
+	if app.Capabilities == nil {
+		app.Capabilities = []string{}
+	}
+	app.CreateTime = now
+	return s.moveOnboarding(ctx, &app, ONBOARDING_SUBMITTED, "SUBMIT", clientMSPID, "", now)
+}
+
+// ResubmitOnboardingApplication 提交方按平台要求补充材料后重新提交 (仅原提交方可调用)
+func (s *SmartContract) ResubmitOnboardingApplication(ctx contractapi.TransactionContextInterface, id string, documentsJson string, comment string) error {
+	clientMSPID, err := s.getClientIdentityMSPID(ctx)
+	if err != nil {
+		return err
+	}
+
+	app, err := s.QueryOnboardingApplication(ctx, id)
+	if err != nil {
+		return err
+	}
+	if clientMSPID != app.SubmitterID {
+		return fmt.Errorf("无权限: 仅限原提交方补充材料")
+	}
+	if app.Status != ONBOARDING_CHANGES_REQUESTED {
+		return fmt.Errorf("准入申请当前状态 %s 无法重新提交", app.Status)
+	}
+
+	var documents []OnboardingDocument
+	if err := json.Unmarshal([]byte(documentsJson), &documents); err != nil {
+		return fmt.Errorf("解析准入材料失败: %v", err)
+	}
+	if err := validateOnboardingDocuments(documents); err != nil {
+		return err
+	}
+
+	now, err := s.getTxTimestamp(ctx)
+	if err != nil {
+		return err
+	}
+	app.Documents = documents
+	return s.moveOnboarding(ctx, app, ONBOARDING_SUBMITTED, "RESUBMIT", clientMSPID, comment, now)
+}
+
+// StartOnboardingReview 平台方开始审核准入申请 (仅平台方可调用)
+func (s *SmartContract) StartOnboardingReview(ctx contractapi.TransactionContextInterface, id string) error {
+	app, reviewerID, now, err := s.prepareOnboardingReview(ctx, id, ONBOARDING_SUBMITTED)
+	if err != nil {
+		return err
+	}
+	app.ReviewerID = reviewerID
+	return s.moveOnboarding(ctx, app, ONBOARDING_UNDER_REVIEW, "START_REVIEW", reviewerID, "", now)
+}
+
+// RequestOnboardingChanges 平台方要求补充或更正材料 (仅平台方可调用)
+func (s *SmartContract) RequestOnboardingChanges(ctx contractapi.TransactionContextInterface, id string, comment string) error {
+	if comment == "" {
+		return fmt.Errorf("补充材料说明不能为空")
+	}
+	app, reviewerID, now, err := s.prepareOnboardingReview(ctx, id, ONBOARDING_UNDER_REVIEW)
+	if err != nil {
+		return err
+	}
+	return s.moveOnboarding(ctx, app, ONBOARDING_CHANGES_REQUESTED, "REQUEST_CHANGES", reviewerID, comment, now)
+}
+
+// ApproveOnboardingApplication 平台方批准准入申请并自动激活参与方, 激活后主机厂即可向其下单 (仅平台方可调用)
+func (s *SmartContract) ApproveOnboardingApplication(ctx contractapi.TransactionContextInterface, id string, comment string) error {
+	app, reviewerID, now, err := s.prepareOnboardingReview(ctx, id, ONBOARDING_UNDER_REVIEW)
+	if err != nil {
+		return err
+	}
+	existing, err := s.getParticipant(ctx, app.ParticipantID)
+	if err != nil {
+		return err
+	}
+	if existing != nil {
+		return fmt.Errorf("参与方 %s 已准入", app.ParticipantID)
+	}
+	if err := validateManufacturerMSPID(app.MSPID); err != nil {
+		return err
+	}
+
+	participant := &Participant{
+		ID:            app.ParticipantID,
+		ObjectType:    PARTICIPANT,
+		CompanyName:   app.CompanyName,
+		ApplicationID: app.ID,
+		Status:        PARTICIPANT_ACTIVE,
+		MSPID:         app.MSPID,
+		ActivatedBy:   reviewerID,
+		ActivateTime:  now,
+	}
+	participantBytes, err := json.Marshal(participant)
+	if err != nil {
+		return fmt.Errorf("序列化参与方失败: %v", err)
+	}
+	key, err := ctx.GetStub().CreateCompositeKey(PARTICIPANT, []string{participant.ID})
+	if err != nil {
+		return fmt.Errorf("创建参与方键失败: %v", err)
+	}
+	if err := ctx.GetStub().PutState(key, participantBytes); err != nil {
+		return err
+	}
+	return s.moveOnboarding(ctx, app, ONBOARDING_APPROVED, "APPROVE", reviewerID, comment, now)
+}
+
+// RejectOnboardingApplication 平台方拒绝准入申请 (仅平台方可调用)
+func (s *SmartContract) RejectOnboardingApplication(ctx contractapi.TransactionContextInterface, id string, reason string) error {
+	if reason == "" {
+		return fmt.Errorf("拒绝原因不能为空")
+	}
+	app, reviewerID, now, err := s.prepareOnboardingReview(ctx, id, ONBOARDING_UNDER_REVIEW)
+	if err != nil {
+		return err
+	}
+	return s.moveOnboarding(ctx, app, ONBOARDING_REJECTED, "REJECT", reviewerID, reason, now)
+}
+
+// QueryOnboardingApplication 查询准入申请 (含审计记录)
+func (s *SmartContract) QueryOnboardingApplication(ctx contractapi.TransactionContextInterface, id string) (*OnboardingApplication, error) {
+	appBytes, err := ctx.GetStub().GetState(id)
+	if err != nil {
+		return nil, fmt.Errorf("读取准入申请失败: %v", err)
+	}
+	if appBytes == nil {
+		return nil, fmt.Errorf("准入申请 %s 不存在", id)
+	}
+
+	var app OnboardingApplication
+	if err := json.Unmarshal(appBytes, &app); err != nil {
+		return nil, fmt.Errorf("解析准入申请失败: %v", err)
+	}
+	if app.ObjectType != ONBOARDING {
+		return nil, fmt.Errorf("准入申请 %s 不存在", id)
+	}
+	return &app, nil
+}
+
+// QueryOnboardingApplications 按状态查询准入申请 (平台审核队列)
+func (s *SmartContract) QueryOnboardingApplications(ctx contractapi.TransactionContextInterface, status string) ([]*OnboardingApplication, error) {
+	ids, err := s.getIndexedIDs(ctx, ONBOARDING_STATUS, status)
+	if err != nil {
+		return nil, err
+	}
+	apps := make([]*OnboardingApplication, 0, len(ids))
+	for _, id := range ids {
+		app, err := s.QueryOnboardingApplication(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		apps = append(apps, app)
+	}
+	return apps, nil
+}
+
+// QueryParticipant 查询已准入参与方
+func (s *SmartContract) QueryParticipant(ctx contractapi.TransactionContextInterface, id string) (*Participant, error) {
+	participant, err := s.getParticipant(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if participant == nil {
+		return nil, fmt.Errorf("参与方 %s 未准入", id)
+	}
+	return participant, nil
+}
+
+// 解析厂商在链上操作所用的 MSP ID: 网络初始成员 Org2 即其 MSP ID, 其余厂商须已准入, 按准入时登记的 MSP ID 映射
+func (s *SmartContract) resolveManufacturerMSPID(ctx contractapi.TransactionContextInterface, manufacturerId string) (string, error) {
+	if manufacturerId == MANUFACTURER_ORG_MSPID {
+		return MANUFACTURER_ORG_MSPID, nil
+	}
+	participant, err := s.getParticipant(ctx, manufacturerId)
+	if err != nil {
+		return "", err
+	}
+	if participant == nil || participant.Status != PARTICIPANT_ACTIVE {
+		return "", fmt.Errorf("厂商 %s 尚未完成准入", manufacturerId)
+	}
+	return participant.MSPID, nil
+}
+
+// 校验参与方登记的 MSP ID 已作为厂商接入网络
+func validateManufacturerMSPID(mspID string) error {
+	if mspID == "" {
+		return fmt.Errorf("参与方 MSP ID 不能为空")
+	}
+	if !containsString(MANUFACTURER_MSPIDS, mspID) {
+		return fmt.Errorf("MSP %s 尚未作为厂商接入网络: 须先加入通道并配置订单价格私有集合, 当前可用 %v", mspID, MANUFACTURER_MSPIDS)
+	}
+	return nil
+}
+
+// 平台审核操作前的公共校验
+func (s *SmartContract) prepareOnboardingReview(ctx contractapi.TransactionContextInterface, id string, expected OnboardingStatus) (*OnboardingApplication, string, time.Time, error) {
+	isPlatform, err := s.isPlatformIdentity(ctx)
+	if err != nil {
+		return nil, "", time.Time{}, err
+	}
+	if !isPlatform {
+		return nil, "", time.Time{}, fmt.Errorf("无权限: 仅限平台方审核准入申请")
+	}
+
+	app, err := s.QueryOnboardingApplication(ctx, id)
+	if err != nil {
+		return nil, "", time.Time{}, err
+	}
+	if app.Status != expected {
+		return nil, "", time.Time{}, fmt.Errorf("准入申请当前状态 %s 无法执行该操作", app.Status)
+	}
+
+	now, err := s.getTxTimestamp(ctx)
+	if err != nil {
+		return nil, "", time.Time{}, err
+	}
+	return app, PLATFORM_ORG_MSPID, now, nil
+}
+
+// 变更申请状态: 追加审计记录并维护状态索引
+func (s *SmartContract) moveOnboarding(ctx contractapi.TransactionContextInterface, app *OnboardingApplication, status OnboardingStatus, action string, actorID string, comment string, now time.Time) error {
+	if app.Status != "" {
+		oldKey, err := ctx.GetStub().CreateCompositeKey(ONBOARDING_STATUS, []string{string(app.Status), app.ID})
+		if err != nil {
+			return fmt.Errorf("创建索引键失败: %v", err)
+		}
+		if err := ctx.GetStub().DelState(oldKey); err != nil {
+			return fmt.Errorf("删除申请状态索引失败: %v", err)
+		}
+	}
+
+	app.Status = status
+	app.AuditTrail = append(app.AuditTrail, OnboardingAuditEntry{
+		Action:    action,
+		ActorID:   actorID,
+		Status:    status,
+		Comment:   comment,
+		TxID:      ctx.GetStub().GetTxID(),
+		Timestamp: now,
+	})
+	app.UpdateTime = now
+
+	appBytes, err := json.Marshal(app)
+	if err != nil {
+		return fmt.Errorf("序列化准入申请失败: %v", err)
+	}
+	if err := ctx.GetStub().PutState(app.ID, appBytes); err != nil {
+		return err
+	}
+	return s.putIndex(ctx, ONBOARDING_STATUS, string(status), app.ID)
+}
+
+func (s *SmartContract) getParticipant(ctx contractapi.TransactionContextInterface, id string) (*Participant, error) {
+	key, err := ctx.GetStub().CreateCompositeKey(PARTICIPANT, []string{id})
+	if err != nil {
+		return nil, fmt.Errorf("创建参与方键失败: %v", err)
+	}
+	participantBytes, err := ctx.GetStub().GetState(key)
+	if err != nil {
+		return nil, fmt.Errorf("读取参与方失败: %v", err)
+	}
+	if participantBytes == nil {
+		return nil, nil
+	}
+
+	var participant Participant
+	if err := json.Unmarshal(participantBytes, &participant); err != nil {
+		return nil, fmt.Errorf("解析参与方失败: %v", err)
+	}
+	return &participant, nil
+}
+
+// 准入材料须同时包含 KYC 与资质材料, 哈希为 SHA-256
+func validateOnboardingDocuments(documents []OnboardingDocument) error {
+	categories := make(map[OnboardingDocCategory]bool)
+	for _, doc := range documents {
+		if doc.Category != ONBOARDING_DOC_KYC && doc.Category != ONBOARDING_DOC_QUALIFICATION {
+			return fmt.Errorf("无效的准入材料类别: %s", doc.Category)
+		}
+		if doc.Name == "" {
+			return fmt.Errorf("准入材料名称不能为空")
+		}
+		if _, err := hex.DecodeString(doc.Hash); err != nil || len(doc.Hash) != sha256.Size*2 {
+			return fmt.Errorf("准入材料 %s 的哈希须为 64 位十六进制 SHA-256", doc.Name)
+		}
+		categories[doc.Category] = true
+	}
+	if !categories[ONBOARDING_DOC_KYC] || !categories[ONBOARDING_DOC_QUALIFICATION] {
+		return fmt.Errorf("准入材料须同时包含 KYC 与资质材料")
+	}
+	return nil
+}
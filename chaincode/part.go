@@ -0,0 +1,276 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/v2/contractapi"
+)
+
+// 零件主数据资产类型 (复合键: PART~partNumber)
+const (
+	PART = "PART"
+)
+
+// PartStatus 零件状态
+type PartStatus string
+
+const (
+	PART_ACTIVE   PartStatus = "ACTIVE"   // 可采购
+	PART_OBSOLETE PartStatus = "OBSOLETE" // 已停用
+)
+
+// Part 零件主数据 (主机厂维护)
+type Part struct {
+	PartNumber            string     `json:"partNumber"`            // 零件号
+	ObjectType            string     `json:"objectType"`            // 资产类型 (PART)
+	Description           string     `json:"description"`           // 零件描述
+	UnitOfMeasure         string     `json:"unitOfMeasure"`         // 计量单位
+	DrawingRevision       string     `json:"drawingRevision"`       // 图纸版本
+	ApprovedManufacturers []string   `json:"approvedManufacturers"` // 合格供应商
+	Status                PartStatus `json:"status"`                // 当前状态
+	CreateTime            time.Time  `json:"createTime"`            // 创建时间
+	UpdateTime            time.Time  `json:"updateTime"`            // 更新时间
+}
+
+// CreatePart 主机厂新增零件主数据 (仅 Org1 可调用)
+func (s *SmartContract) CreatePart(ctx contractapi.TransactionContextInterface, partJson string) error {
+	clientMSPID, err := s.getClientIdentityMSPID(ctx)
+	if err != nil {
+		return err
+	}
+	if clientMSPID != OEM_ORG_MSPID {
+		return fmt.Errorf("无权限: 仅限主机厂维护零件主数据")
+	}
+
+	var part Part
+	if err := json.Unmarshal([]byte(partJson), &part); err != nil {
+		return fmt.Errorf("解析零件主数据失败: %v", err)
+	}
+	if part.PartNumber == "" || part.Description == "" || part.UnitOfMeasure == "" {
+		return fmt.Errorf("零件号、描述与计量单位不能为空")
+	}
+
+	existing, err := s.getPart(ctx, part.PartNumber)
+	if err != nil {
+		return err
+	}
+	if existing != nil {
+		return fmt.Errorf("零件 %s 已存在", part.PartNumber)
+	}
+
+	now, err := s.getTxTimestamp(ctx)
+	if err != nil {
+		return err
+	}
+	part.ObjectType = PART
+	part.Status = PART_ACTIVE
+	part.CreateTime = now
+	part.UpdateTime = now
+	if part.ApprovedManufacturers == nil {
+		part.ApprovedManufacturers = []string{}
+	}
+	return s.putPart(ctx, &part)
+}
+
+// UpdatePart 主机厂修改零件主数据 (仅 Org1 可调用)
+func (s *SmartContract) UpdatePart(ctx contractapi.TransactionContextInterface, partJson string) error {
+	clientMSPID, err := s.getClientIdentityMSPID(ctx)
+	if err != nil {
+		return err
+	}
+	if clientMSPID != OEM_ORG_MSPID {
+		return fmt.Errorf("无权限: 仅限主机厂维护零件主数据")
+	}
+
+	var update Part
+	if err := json.Unmarshal([]byte(partJson), &update); err != nil {
+		return fmt.Errorf("解析零件主数据失败: %v", err)
+	}
+
+	part, err := s.QueryPart(ctx, update.PartNumber)
+	if err != nil {
+		return err
+	}
+
+	if update.Description != "" {
+		part.Description = update.Description
+	}
+	if update.UnitOfMeasure != "" {
+		part.UnitOfMeasure = update.UnitOfMeasure
+	}
+	if update.DrawingRevision != "" {
+		part.DrawingRevision = update.DrawingRevision
+	}
+	if update.ApprovedManufacturers != nil {
+		part.ApprovedManufacturers = update.ApprovedManufacturers
+	}
+
+	now, err := s.getTxTimestamp(ctx)
+	if err != nil {
+		return err
+	}
+	part.UpdateTime = now
+	return s.putPart(ctx, part)
+}
+
+// ObsoletePart 主机厂停用零件 (仅 Org1 可调用, 已有订单保留下单时快照)
+func (s *SmartContract) ObsoletePart(ctx contractapi.TransactionContextInterface, partNumber string) error {
+	clientMSPID, err := s.getClientIdentityMSPID(ctx)
+	if err != nil {
+		return err
+	}
+	if clientMSPID != OEM_ORG_MSPID {
+		return fmt.Errorf("无权限: 仅限主机厂维护零件主数据")
+	}
+
+	part, err := s.QueryPart(ctx, partNumber)
+	if err != nil {
+		return err
+	}
+
+	now, err := s.getTxTimestamp(ctx)
+	if err != nil {
+		return err
+	}
+	part.Status = PART_OBSOLETE
+	part.UpdateTime = now
+	return s.putPart(ctx, part)
+}
+
+// QueryPart 查询零件主数据
+func (s *SmartContract) QueryPart(ctx contractapi.TransactionContextInterface, partNumber string) (*Part, error) {
+	part, err := s.getPart(ctx, partNumber)
+	if err != nil {
+		return nil, err
+	}
+	if part == nil {
+		return nil, fmt.Errorf("零件 %s 不存在", partNumber)
+	}
+	return part, nil
+}
+
+// SearchParts 分页检索零件主数据 (关键字匹配零件号或描述, 可按合格供应商过滤)
+// 先过滤后分页: 书签为上一页最后一条匹配零件的键, 每页从其后继续扫描直至凑满 pageSize, 书签为空表示已无更多结果
+func (s *SmartContract) SearchParts(ctx contractapi.TransactionContextInterface, keyword string, manufacturerId string, pageSize int32, bookmark string) (*QueryResponse, error) {
+	if pageSize <= 0 {
+		return nil, fmt.Errorf("分页大小必须大于 0")
+	}
+	resultsIterator, err := ctx.GetStub().GetStateByPartialCompositeKey(PART, []string{})
+	if err != nil {
+		return nil, err
+	}
+	defer resultsIterator.Close()
+
+	keyword = strings.ToLower(keyword)
+	records := make([]interface{}, 0)
+	var fetched int32
+	lastKey := ""
+	nextBookmark := ""
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return nil, err
+		}
+		if bookmark != "" && queryResponse.Key <= bookmark {
+			continue
+		}
+		fetched++
+
+		var part Part
+		if err := json.Unmarshal(queryResponse.Value, &part); err != nil {
+			continue
+		}
+		if keyword != "" &&
+			!strings.Contains(strings.ToLower(part.PartNumber), keyword) &&
+			!strings.Contains(strings.ToLower(part.Description), keyword) {
+			continue
+		}
+		if manufacturerId != "" && !containsString(part.ApprovedManufacturers, manufacturerId) {
+			continue
+		}
+		if int32(len(records)) == pageSize {
+			nextBookmark = lastKey
+			break
+		}
+		records = append(records, part)
+		lastKey = queryResponse.Key
+	}
+
+	return &QueryResponse{
+		Records:             records,
+		RecordsCount:        int32(len(records)),
+		Bookmark:            nextBookmark,
+		FetchedRecordsCount: fetched,
+	}, nil
+}
+
+// 主机厂订单、定标订单与子订单的零件须引用零件主数据
+func requirePartNumbers(items []OrderItem) error {
+	for _, item := range items {
+		if item.PartNumber == "" {
+			return fmt.Errorf("零件 %s 缺少零件号: 订单须引用零件主数据", item.Name)
+		}
+	}
+	return nil
+}
+
+// 校验订单引用的零件号并写入主数据快照
+func (s *SmartContract) snapshotParts(ctx contractapi.TransactionContextInterface, order *Order) error {
+	for i := range order.Items {
+		item := &order.Items[i]
+		if item.PartNumber == "" {
+			continue
+		}
+
+		part, err := s.QueryPart(ctx, item.PartNumber)
+		if err != nil {
+			return err
+		}
+		if part.Status != PART_ACTIVE {
+			return fmt.Errorf("零件 %s 已停用", item.PartNumber)
+		}
+		if len(part.ApprovedManufacturers) > 0 && !containsString(part.ApprovedManufacturers, order.ManufacturerID) {
+			return fmt.Errorf("厂商 %s 不是零件 %s 的合格供应商", order.ManufacturerID, item.PartNumber)
+		}
+
+		item.Name = part.Description
+		item.UnitOfMeasure = part.UnitOfMeasure
+		item.DrawingRevision = part.DrawingRevision
+	}
+	return nil
+}
+
+func (s *SmartContract) getPart(ctx contractapi.TransactionContextInterface, partNumber string) (*Part, error) {
+	key, err := ctx.GetStub().CreateCompositeKey(PART, []string{partNumber})
+	if err != nil {
+		return nil, fmt.Errorf("创建零件键失败: %v", err)
+	}
+	partBytes, err := ctx.GetStub().GetState(key)
+	if err != nil {
+		return nil, fmt.Errorf("读取零件主数据失败: %v", err)
+	}
+	if partBytes == nil {
+		return nil, nil
+	}
+
+	var part Part
+	if err := json.Unmarshal(partBytes, &part); err != nil {
+		return nil, fmt.Errorf("解析零件主数据失败: %v", err)
+	}
+	return &part, nil
+}
+
+func (s *SmartContract) putPart(ctx contractapi.TransactionContextInterface, part *Part) error {
+	key, err := ctx.GetStub().CreateCompositeKey(PART, []string{part.PartNumber})
+	if err != nil {
+		return fmt.Errorf("创建零件键失败: %v", err)
+	}
+	partBytes, err := json.Marshal(part)
+	if err != nil {
+		return fmt.Errorf("序列化零件主数据失败: %v", err)
+	}
+	return ctx.GetStub().PutState(key, partBytes)
+}
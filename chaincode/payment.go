@@ -0,0 +1,433 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/v2/contractapi"
+)
+
+// 付款记录 (私有数据, 复合键: PAYMENT~orderId~reference)
+const (
+	PAYMENT           = "PAYMENT"
+	PAYMENT_TRANSIENT = "payment" // 瞬态字段名, 携带付款流水号与金额
+
+	ORDER_COUNTERPARTY_INDEX = "ORDER_COUNTERPARTY" // 交易对手订单索引 (复合键: ORDER_COUNTERPARTY~partyId:counterpartyId~orderId)
+)
+
+// PaymentStatus 付款状态
+type PaymentStatus string
+
+const (
+	PAYMENT_RECORDED  PaymentStatus = "RECORDED"  // 主机厂已登记付款
+	PAYMENT_CONFIRMED PaymentStatus = "CONFIRMED" // 厂商已确认到账
+)
+
+// PaymentMilestone 付款节点 (比例公开, 金额按私有总价计算)
+type PaymentMilestone struct {
+	Trigger OrderStatus `json:"trigger"`           // 触发节点的订单状态
+	Percent float64     `json:"percent"`           // 付款比例 (%)
+	Due     bool        `json:"due"`               // 是否已到期
+	DueTime *time.Time  `json:"dueTime,omitempty"` // 到期时间 (订单进入触发状态的时间)
+}
+
+// Payment 付款记录 (私有数据)
+type Payment struct {
+	OrderID     string        `json:"orderId"`               // 订单ID
+	Reference   string        `json:"reference"`             // 付款流水号
+	Amount      float64       `json:"amount"`                // 付款金额
+	PayerID     string        `json:"payerId"`               // 付款方
+	PayeeID     string        `json:"payeeId"`               // 收款方
+	Status      PaymentStatus `json:"status"`                // 状态
+	RecordTime  time.Time     `json:"recordTime"`            // 登记时间
+	ConfirmTime *time.Time    `json:"confirmTime,omitempty"` // 到账确认时间
+}
+
+// OrderBalance 订单结算余额
+type OrderBalance struct {
+	OrderID         string             `json:"orderId"`         // 订单ID
+	PayerID         string             `json:"payerId"`         // 付款方
+	PayeeID         string             `json:"payeeId"`         // 收款方
+	TotalPrice      float64            `json:"totalPrice"`      // 订单总价
+	DueAmount       float64            `json:"dueAmount"`       // 已到期应付
+	PaidAmount      float64            `json:"paidAmount"`      // 已登记付款
+	ConfirmedAmount float64            `json:"confirmedAmount"` // 厂商已确认到账
+	CreditAmount    float64            `json:"creditAmount"`    // 退货贷项通知单抵扣
+	Outstanding     float64            `json:"outstanding"`     // 未付余额 (总价 - 已付 - 贷项)
+	Overdue         float64            `json:"overdue"`         // 到期未付 (到期应付 - 已付 - 贷项)
+	Milestones      []PaymentMilestone `json:"milestones"`      // 付款节点
+	Payments        []*Payment         `json:"payments"`        // 付款记录
+}
+
+// CounterpartyBalance 与某交易对手的结算汇总
+type CounterpartyBalance struct {
+	PartyID         string          `json:"partyId"`         // 查询方
+	CounterpartyID  string          `json:"counterpartyId"`  // 交易对手
+	OrderCount      int             `json:"orderCount"`      // 订单数
+	TotalPrice      float64         `json:"totalPrice"`      // 订单总价合计
+	PaidAmount      float64         `json:"paidAmount"`      // 已付合计
+	ConfirmedAmount float64         `json:"confirmedAmount"` // 已确认到账合计
+	CreditAmount    float64         `json:"creditAmount"`    // 贷项抵扣合计
+	Outstanding     float64         `json:"outstanding"`     // 未付余额合计
+	Overdue         float64         `json:"overdue"`         // 到期未付合计
+	Orders          []*OrderBalance `json:"orders"`          // 各订单明细
+}
+
+// 订单状态在生命周期中的先后顺序, 用于判断付款节点是否已到达
+var orderStatusRank = map[OrderStatus]int{
+	ORDER_CREATED:   0,
+	ORDER_ACCEPTED:  1,
+	ORDER_PRODUCING: 2,
+	ORDER_PRODUCED:  3,
+	ORDER_READY:     4,
+	ORDER_SHIPPED:   5,
+	ORDER_DELIVERED: 6,
+	ORDER_RECEIVED:  7,
+}
+
+// SetPaymentSchedule 采购方在厂商接受订单前设置付款计划, 各节点比例合计须为 100%
+func (s *SmartContract) SetPaymentSchedule(ctx contractapi.TransactionContextInterface, orderId string, milestonesJson string) error {
+	clientMSPID, err := s.getClientIdentityMSPID(ctx)
+	if err != nil {
+		return err
+	}
+
+	order, err := s.QueryOrder(ctx, orderId)
+	if err != nil {
+		return err
+	}
+	if clientMSPID != order.OEMID {
+		return fmt.Errorf("无权限: 仅限采购方设置付款计划")
+	}
+	if order.Status != ORDER_CREATED {
+		return fmt.Errorf("订单当前状态 %s 无法设置付款计划, 须在厂商接受前设置", order.Status)
+	}
+
+	var milestones []PaymentMilestone
+	if err := json.Unmarshal([]byte(milestonesJson), &milestones); err != nil {
+		return fmt.Errorf("解析付款计划失败: %v", err)
+	}
+	if len(milestones) == 0 {
+		return fmt.Errorf("付款计划不能为空")
+	}
+	total := 0.0
+	for i := range milestones {
+		rank, ok := orderStatusRank[milestones[i].Trigger]
+		if !ok || rank == 0 {
+			return fmt.Errorf("无效的付款触发状态: %s", milestones[i].Trigger)
+		}
+		if milestones[i].Percent <= 0 {
+			return fmt.Errorf("付款比例必须大于 0")
+		}
+		milestones[i].Due = false
+		milestones[i].DueTime = nil
+		total += milestones[i].Percent
+	}
+	if math.Abs(total-100) > 1e-6 {
+		return fmt.Errorf("付款比例合计 %.2f%% 不等于 100%%", total)
+	}
+
+	now, err := s.getTxTimestamp(ctx)
+	if err != nil {
+		return err
+	}
+	order.PaymentMilestones = milestones
+	order.UpdateTime = now
+	orderBytes, err := json.Marshal(order)
+	if err != nil {
+		return fmt.Errorf("序列化订单失败: %v", err)
+	}
+	return ctx.GetStub().PutState(orderId, orderBytes)
+}
+
+// RecordPayment 采购方登记付款 (流水号与金额通过瞬态字段 payment 传入, 写入私有集合)
+func (s *SmartContract) RecordPayment(ctx contractapi.TransactionContextInterface, orderId string) error {
+	clientMSPID, err := s.getClientIdentityMSPID(ctx)
+	if err != nil {
+		return err
+	}
+
+	order, err := s.QueryOrder(ctx, orderId)
+	if err != nil {
+		return err
+	}
+	if clientMSPID != order.OEMID {
+		return fmt.Errorf("无权限: 仅限采购方登记付款")
+	}
+
+	transientMap, err := ctx.GetStub().GetTransient()
+	if err != nil {
+		return fmt.Errorf("读取瞬态数据失败: %v", err)
+	}
+	paymentJson, ok := transientMap[PAYMENT_TRANSIENT]
+	if !ok {
+		return fmt.Errorf("缺少付款数据: 需通过瞬态字段 %s 传入", PAYMENT_TRANSIENT)
+	}
+	var payment Payment
+	if err := json.Unmarshal(paymentJson, &payment); err != nil {
+		return fmt.Errorf("解析付款数据失败: %v", err)
+	}
+	if payment.Reference == "" {
+		return fmt.Errorf("付款流水号不能为空")
+	}
+	if payment.Amount <= 0 {
+		return fmt.Errorf("付款金额必须大于 0")
+	}
+
+	existing, err := s.getPayment(ctx, order, payment.Reference)
+	if err != nil {
+		return err
+	}
+	if existing != nil {
+		return fmt.Errorf("付款流水号 %s 已登记", payment.Reference)
+	}
+
+	balance, err := s.buildOrderBalance(ctx, order)
+	if err != nil {
+		return err
+	}
+	if payment.Amount > balance.Outstanding+1e-6 {
+		return fmt.Errorf("付款金额 %.2f 超过未付余额 %.2f", payment.Amount, balance.Outstanding)
+	}
+
+	now, err := s.getTxTimestamp(ctx)
+	if err != nil {
+		return err
+	}
+	payment.OrderID = orderId
+	payment.PayerID = order.OEMID
+	payment.PayeeID = order.ManufacturerID
+	payment.Status = PAYMENT_RECORDED
+	payment.RecordTime = now
+	payment.ConfirmTime = nil
+	return s.putPayment(ctx, order, &payment)
+}
+
+// ConfirmPaymentReceipt 收款厂商确认付款到账
+func (s *SmartContract) ConfirmPaymentReceipt(ctx contractapi.TransactionContextInterface, orderId string, reference string) error {
+	clientMSPID, err := s.getClientIdentityMSPID(ctx)
+	if err != nil {
+		return err
+	}
+
+	order, err := s.QueryOrder(ctx, orderId)
+	if err != nil {
+		return err
+	}
+	if clientMSPID != order.ManufacturerMSPID {
+		return fmt.Errorf("无权限: 仅限收款方确认到账")
+	}
+
+	payment, err := s.getPayment(ctx, order, reference)
+	if err != nil {
+		return err
+	}
+	if payment == nil {
+		return fmt.Errorf("付款记录 %s 不存在", reference)
+	}
+	if payment.Status != PAYMENT_RECORDED {
+		return fmt.Errorf("付款记录 %s 已确认到账", reference)
+	}
+
+	now, err := s.getTxTimestamp(ctx)
+	if err != nil {
+		return err
+	}
+	payment.Status = PAYMENT_CONFIRMED
+	payment.ConfirmTime = &now
+	return s.putPayment(ctx, order, payment)
+}
+
+// QueryOrderBalance 查询订单结算余额 (仅订单交易双方可调用)
+func (s *SmartContract) QueryOrderBalance(ctx contractapi.TransactionContextInterface, orderId string) (*OrderBalance, error) {
+	clientMSPID, err := s.getClientIdentityMSPID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	order, err := s.QueryOrder(ctx, orderId)
+	if err != nil {
+		return nil, err
+	}
+	if clientMSPID != order.OEMID && clientMSPID != order.ManufacturerMSPID {
+		return nil, fmt.Errorf("无权限: 仅限交易双方查看结算余额")
+	}
+	return s.buildOrderBalance(ctx, order)
+}
+
+// QueryCounterpartyBalance 汇总调用方与指定交易对手之间全部订单的结算余额
+func (s *SmartContract) QueryCounterpartyBalance(ctx contractapi.TransactionContextInterface, counterpartyId string) (*CounterpartyBalance, error) {
+	clientMSPID, err := s.getClientIdentityMSPID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if clientMSPID != OEM_ORG_MSPID && !containsString(MANUFACTURER_MSPIDS, clientMSPID) {
+		return nil, fmt.Errorf("无权限: 仅限交易双方查看结算余额")
+	}
+
+	orderIds, err := s.getIndexedIDs(ctx, ORDER_COUNTERPARTY_INDEX, counterpartyIndexKey(clientMSPID, counterpartyId))
+	if err != nil {
+		return nil, err
+	}
+
+	summary := &CounterpartyBalance{
+		PartyID:        clientMSPID,
+		CounterpartyID: counterpartyId,
+		Orders:         make([]*OrderBalance, 0),
+	}
+	for _, orderId := range orderIds {
+		order, err := s.QueryOrder(ctx, orderId)
+		if err != nil {
+			return nil, err
+		}
+
+		balance, err := s.buildOrderBalance(ctx, order)
+		if err != nil {
+			return nil, err
+		}
+		summary.OrderCount++
+		summary.TotalPrice += balance.TotalPrice
+		summary.PaidAmount += balance.PaidAmount
+		summary.ConfirmedAmount += balance.ConfirmedAmount
+		summary.CreditAmount += balance.CreditAmount
+		summary.Outstanding += balance.Outstanding
+		summary.Overdue += balance.Overdue
+		summary.Orders = append(summary.Orders, balance)
+	}
+	return summary, nil
+}
+
+// 交易对手索引属性: 主机厂侧以订单厂商ID为对手, 厂商侧以其 MSP ID 查询、主机厂为对手
+func counterpartyIndexKey(partyId string, counterpartyId string) string {
+	return partyId + ":" + counterpartyId
+}
+
+// 为订单写入交易双方的对手索引, 供汇总结算余额时按索引读取而非扫描全部状态
+func (s *SmartContract) putCounterpartyIndex(ctx contractapi.TransactionContextInterface, order *Order) error {
+	if err := s.putIndex(ctx, ORDER_COUNTERPARTY_INDEX, counterpartyIndexKey(order.OEMID, order.ManufacturerID), order.ID); err != nil {
+		return err
+	}
+	if order.ManufacturerMSPID == order.OEMID {
+		return nil
+	}
+	return s.putIndex(ctx, ORDER_COUNTERPARTY_INDEX, counterpartyIndexKey(order.ManufacturerMSPID, order.OEMID), order.ID)
+}
+
+// 订单进入新状态后, 将已到达的付款节点置为到期 (调用方负责写回订单)
+func triggerPaymentMilestones(order *Order, now time.Time) {
+	rank := orderStatusRank[order.Status]
+	for i := range order.PaymentMilestones {
+		milestone := &order.PaymentMilestones[i]
+		if milestone.Due || orderStatusRank[milestone.Trigger] > rank {
+			continue
+		}
+		dueTime := now
+		milestone.Due = true
+		milestone.DueTime = &dueTime
+	}
+}
+
+// 按订单私有总价与付款记录计算结算余额
+func (s *SmartContract) buildOrderBalance(ctx contractapi.TransactionContextInterface, order *Order) (*OrderBalance, error) {
+	price, err := s.QueryOrderPrice(ctx, order.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	balance := &OrderBalance{
+		OrderID:    order.ID,
+		PayerID:    order.OEMID,
+		PayeeID:    order.ManufacturerID,
+		TotalPrice: price.TotalPrice,
+		Milestones: order.PaymentMilestones,
+		Payments:   make([]*Payment, 0),
+	}
+	if balance.Milestones == nil {
+		balance.Milestones = []PaymentMilestone{}
+	}
+	for _, milestone := range order.PaymentMilestones {
+		if milestone.Due {
+			balance.DueAmount += price.TotalPrice * milestone.Percent / 100
+		}
+	}
+
+	resultsIterator, err := ctx.GetStub().GetPrivateDataByPartialCompositeKey(orderPriceCollection(order), PAYMENT, []string{order.ID})
+	if err != nil {
+		return nil, fmt.Errorf("读取付款记录失败: %v", err)
+	}
+	defer resultsIterator.Close()
+
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return nil, err
+		}
+		var payment Payment
+		if err := json.Unmarshal(queryResponse.Value, &payment); err != nil {
+			return nil, fmt.Errorf("解析付款记录失败: %v", err)
+		}
+		balance.PaidAmount += payment.Amount
+		if payment.Status == PAYMENT_CONFIRMED {
+			balance.ConfirmedAmount += payment.Amount
+		}
+		balance.Payments = append(balance.Payments, &payment)
+	}
+
+	creditIterator, err := ctx.GetStub().GetPrivateDataByPartialCompositeKey(orderPriceCollection(order), CREDIT_NOTE, []string{order.ID})
+	if err != nil {
+		return nil, fmt.Errorf("读取贷项通知单失败: %v", err)
+	}
+	defer creditIterator.Close()
+
+	for creditIterator.HasNext() {
+		queryResponse, err := creditIterator.Next()
+		if err != nil {
+			return nil, err
+		}
+		var note CreditNote
+		if err := json.Unmarshal(queryResponse.Value, &note); err != nil {
+			return nil, fmt.Errorf("解析贷项通知单失败: %v", err)
+		}
+		balance.CreditAmount += note.Amount
+	}
+
+	settled := balance.PaidAmount + balance.CreditAmount
+	balance.Outstanding = math.Max(balance.TotalPrice-settled, 0)
+	balance.Overdue = math.Max(balance.DueAmount-settled, 0)
+	return balance, nil
+}
+
+// 付款记录随订单价格存放: 子订单的付款只写入仅 Org2 可见的集合
+func (s *SmartContract) getPayment(ctx contractapi.TransactionContextInterface, order *Order, reference string) (*Payment, error) {
+	key, err := ctx.GetStub().CreateCompositeKey(PAYMENT, []string{order.ID, reference})
+	if err != nil {
+		return nil, fmt.Errorf("创建付款键失败: %v", err)
+	}
+	paymentBytes, err := ctx.GetStub().GetPrivateData(orderPriceCollection(order), key)
+	if err != nil {
+		return nil, fmt.Errorf("读取付款记录失败: %v", err)
+	}
+	if paymentBytes == nil {
+		return nil, nil
+	}
+
+	var payment Payment
+	if err := json.Unmarshal(paymentBytes, &payment); err != nil {
+		return nil, fmt.Errorf("解析付款记录失败: %v", err)
+	}
+	return &payment, nil
+}
+
+func (s *SmartContract) putPayment(ctx contractapi.TransactionContextInterface, order *Order, payment *Payment) error {
+	key, err := ctx.GetStub().CreateCompositeKey(PAYMENT, []string{payment.OrderID, payment.Reference})
+	if err != nil {
+		return fmt.Errorf("创建付款键失败: %v", err)
+	}
+	paymentBytes, err := json.Marshal(payment)
+	if err != nil {
+		return fmt.Errorf("序列化付款记录失败: %v", err)
+	}
+	return ctx.GetStub().PutPrivateData(orderPriceCollection(order), key, paymentBytes)
+}
@@ -0,0 +1,493 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/v2/contractapi"
+)
+
+// 询价招标资产类型
+const (
+	REQUEST_FOR_QUOTE = "RFQ" // 询价单
+	BID               = "BID" // 投标 (复合键: BID~rfqId~manufacturerId)
+
+	BID_TRANSIENT = "bid" // 瞬态字段名, 揭标时携带 BidContent
+)
+
+// RFQStatus 询价单状态
+type RFQStatus string
+
+const (
+	RFQ_OPEN      RFQStatus = "OPEN"      // 投标中 (截止前仅接受密封标)
+	RFQ_AWARDED   RFQStatus = "AWARDED"   // 已定标
+	RFQ_CANCELLED RFQStatus = "CANCELLED" // 已取消
+)
+
+// RFQ 询价单 (主机厂发布)
+type RFQ struct {
+	ID            string    `json:"id"`            // 询价单ID
+	ObjectType    string    `json:"objectType"`    // 资产类型 (RFQ)
+	OEMID         string    `json:"oemId"`         // 主机厂组织 ID
+	Title         string    `json:"title"`         // 标题
+	Items         []RFQItem `json:"items"`         // 询价零件
+	Manufacturers []string  `json:"manufacturers"` // 受邀合格厂商
+	Deadline      time.Time `json:"deadline"`      // 投标截止时间
+	Status        RFQStatus `json:"status"`        // 当前状态
+	WinnerID      string    `json:"winnerId"`      // 中标厂商
+	OrderID       string    `json:"orderId"`       // 定标后生成的订单ID
+	CreateTime    time.Time `json:"createTime"`    // 创建时间
+	UpdateTime    time.Time `json:"updateTime"`    // 更新时间
+}
+
+// RFQItem 询价零件规格
+type RFQItem struct {
+	PartNumber string `json:"partNumber"` // 零件号 (引用零件主数据)
+	Name       string `json:"name"`       // 零件名称
+	Quantity   int    `json:"quantity"`   // 数量
+	Spec       string `json:"spec"`       // 技术规格
+}
+
+// Bid 投标记录 (公开部分仅含密封哈希)
+type Bid struct {
+	RFQID          string    `json:"rfqId"`          // 询价单ID
+	ObjectType     string    `json:"objectType"`     // 资产类型 (BID)
+	ManufacturerID string    `json:"manufacturerId"` // 投标厂商
+	BidderID       string    `json:"bidderId"`       // 投标身份 (首次投标的客户端身份, 覆盖与揭标须同一身份)
+	BidHash        string    `json:"bidHash"`        // 投标内容 SHA-256
+	Revealed       bool      `json:"revealed"`       // 是否已揭标
+	SubmitTime     time.Time `json:"submitTime"`     // 投标时间
+	RevealTime     time.Time `json:"revealTime"`     // 揭标时间
+}
+
+// BidContent 投标内容 (揭标后存入私有集合)
+type BidContent struct {
+	RFQID          string    `json:"rfqId"`          // 询价单ID
+	ManufacturerID string    `json:"manufacturerId"` // 投标厂商
+	ItemPrices     []float64 `json:"itemPrices"`     // 单价, 与 RFQ.Items 一一对应
+	Salt           string    `json:"salt"`           // 随机盐
+}
+
+// CreateRFQ 主机厂发布询价单 (仅 Org1 可调用)
+func (s *SmartContract) CreateRFQ(ctx contractapi.TransactionContextInterface, id string, title string, itemsJson string, manufacturersJson string, deadline string) error {
+	clientMSPID, err := s.getClientIdentityMSPID(ctx)
+	if err != nil {
+		return err
+	}
+	if clientMSPID != OEM_ORG_MSPID {
+		return fmt.Errorf("无权限: 仅限主机厂发布询价")
+	}
+
+	existing, err := ctx.GetStub().GetState(id)
+	if err != nil {
+		return fmt.Errorf("读取询价单失败: %v", err)
+	}
+	if existing != nil {
+		return fmt.Errorf("询价单 %s 已存在", id)
+	}
+
+	var items []RFQItem
+	if err := json.Unmarshal([]byte(itemsJson), &items); err != nil {
+		return fmt.Errorf("解析询价零件失败: %v", err)
+	}
+	if len(items) == 0 {
+		return fmt.Errorf("询价零件不能为空")
+	}
+	for _, item := range items {
+		if item.PartNumber == "" {
+			return fmt.Errorf("询价零件 %s 缺少零件号: 定标后生成的订单须引用零件主数据", item.Name)
+		}
+		if _, err := s.QueryPart(ctx, item.PartNumber); err != nil {
+			return err
+		}
+	}
+
+	var manufacturers []string
+	if err := json.Unmarshal([]byte(manufacturersJson), &manufacturers); err != nil {
+		return fmt.Errorf("解析受邀厂商失败: %v", err)
+	}
+	if len(manufacturers) == 0 {
+		return fmt.Errorf("受邀厂商不能为空")
+	}
+
+	deadlineTime, err := time.Parse(time.RFC3339, deadline)
+	if err != nil {
+		return fmt.Errorf("解析截止时间失败: %v", err)
+	}
+
+	now, err := s.getTxTimestamp(ctx)
+	if err != nil {
+		return err
+	}
+	if !deadlineTime.After(now) {
+		return fmt.Errorf("截止时间必须晚于当前时间")
+	}
+
+	rfq := RFQ{
+		ID:            id,
+		ObjectType:    REQUEST_FOR_QUOTE,
+		OEMID:         clientMSPID,
+		Title:         title,
+		Items:         items,
+		Manufacturers: manufacturers,
+		Deadline:      deadlineTime,
+		Status:        RFQ_OPEN,
+		CreateTime:    now,
+		UpdateTime:    now,
+	}
+	return s.putRFQ(ctx, &rfq)
+}
+
+// SubmitBid 零部件厂提交密封标 (仅受邀厂商映射的 MSP 可调用, 截止前可由同一身份重复提交覆盖)
+func (s *SmartContract) SubmitBid(ctx contractapi.TransactionContextInterface, rfqId string, manufacturerId string, bidHash string) error {
+	clientMSPID, err := s.getClientIdentityMSPID(ctx)
+	if err != nil {
+		return err
+	}
+
+	if _, err := hex.DecodeString(bidHash); err != nil || len(bidHash) != sha256.Size*2 {
+		return fmt.Errorf("投标哈希格式错误")
+	}
+
+	rfq, err := s.QueryRFQ(ctx, rfqId)
+	if err != nil {
+		return err
+	}
+	if rfq.Status != RFQ_OPEN {
+		return fmt.Errorf("询价单当前状态 %s 不接受投标", rfq.Status)
+	}
+	if !containsString(rfq.Manufacturers, manufacturerId) {
+		return fmt.Errorf("厂商 %s 不在受邀名单中", manufacturerId)
+	}
+	mspID, err := s.resolveManufacturerMSPID(ctx, manufacturerId)
+	if err != nil {
+		return err
+	}
+	if clientMSPID != mspID {
+		return fmt.Errorf("无权限: 仅限厂商 %s 投标", manufacturerId)
+	}
+
+	now, err := s.getTxTimestamp(ctx)
+	if err != nil {
+		return err
+	}
+	if now.After(rfq.Deadline) {
+		return fmt.Errorf("投标已截止")
+	}
+
+	bidderID, err := s.getClientIdentityID(ctx)
+	if err != nil {
+		return err
+	}
+	if existing, err := s.getBid(ctx, rfqId, manufacturerId); err == nil && existing.BidderID != bidderID {
+		return fmt.Errorf("无权限: 厂商 %s 的投标已由其他身份提交", manufacturerId)
+	}
+
+	bid := Bid{
+		RFQID:          rfqId,
+		ObjectType:     BID,
+		ManufacturerID: manufacturerId,
+		BidderID:       bidderID,
+		BidHash:        bidHash,
+		SubmitTime:     now,
+	}
+	return s.putBid(ctx, &bid)
+}
+
+// RevealBid 截止后、定标前揭标 (仅原投标身份可调用, 投标内容通过瞬态字段 bid 传入)
+func (s *SmartContract) RevealBid(ctx contractapi.TransactionContextInterface, rfqId string, manufacturerId string) error {
+	rfq, err := s.QueryRFQ(ctx, rfqId)
+	if err != nil {
+		return err
+	}
+	if rfq.Status != RFQ_OPEN {
+		return fmt.Errorf("询价单当前状态 %s 不接受揭标", rfq.Status)
+	}
+	bid, err := s.getBid(ctx, rfqId, manufacturerId)
+	if err != nil {
+		return err
+	}
+	bidderID, err := s.getClientIdentityID(ctx)
+	if err != nil {
+		return err
+	}
+	if bid.BidderID != bidderID {
+		return fmt.Errorf("无权限: 仅限投标身份揭标")
+	}
+	if bid.Revealed {
+		return fmt.Errorf("该投标已揭标")
+	}
+
+	now, err := s.getTxTimestamp(ctx)
+	if err != nil {
+		return err
+	}
+	if !now.After(rfq.Deadline) {
+		return fmt.Errorf("投标截止前不可揭标")
+	}
+
+	transientMap, err := ctx.GetStub().GetTransient()
+	if err != nil {
+		return fmt.Errorf("读取瞬态数据失败: %v", err)
+	}
+	contentBytes, ok := transientMap[BID_TRANSIENT]
+	if !ok {
+		return fmt.Errorf("缺少投标内容: 需通过瞬态字段 %s 传入", BID_TRANSIENT)
+	}
+
+	// 密封哈希按原始字节计算, 与私有数据哈希一致
+	contentHash := sha256.Sum256(contentBytes)
+	if hex.EncodeToString(contentHash[:]) != bid.BidHash {
+		return fmt.Errorf("投标内容与密封哈希不一致")
+	}
+
+	var content BidContent
+	if err := json.Unmarshal(contentBytes, &content); err != nil {
+		return fmt.Errorf("解析投标内容失败: %v", err)
+	}
+	if content.RFQID != rfqId || content.ManufacturerID != manufacturerId {
+		return fmt.Errorf("投标内容与询价单或厂商不匹配")
+	}
+	if len(content.ItemPrices) != len(rfq.Items) {
+		return fmt.Errorf("单价数量 %d 与询价零件数量 %d 不一致", len(content.ItemPrices), len(rfq.Items))
+	}
+
+	bidKey, err := ctx.GetStub().CreateCompositeKey(BID, []string{rfqId, manufacturerId})
+	if err != nil {
+		return fmt.Errorf("创建投标键失败: %v", err)
+	}
+	if err := ctx.GetStub().PutPrivateData(ORDER_PRICE_COLLECTION, bidKey, contentBytes); err != nil {
+		return fmt.Errorf("写入投标内容失败: %v", err)
+	}
+
+	bid.Revealed = true
+	bid.RevealTime = now
+	return s.putBid(ctx, bid)
+}
+
+// AwardRFQ 主机厂定标并自动生成订单 (仅 Org1 可调用)
+func (s *SmartContract) AwardRFQ(ctx contractapi.TransactionContextInterface, rfqId string, manufacturerId string, orderId string) error {
+	clientMSPID, err := s.getClientIdentityMSPID(ctx)
+	if err != nil {
+		return err
+	}
+	if clientMSPID != OEM_ORG_MSPID {
+		return fmt.Errorf("无权限: 仅限主机厂定标")
+	}
+
+	rfq, err := s.QueryRFQ(ctx, rfqId)
+	if err != nil {
+		return err
+	}
+	if rfq.Status != RFQ_OPEN {
+		return fmt.Errorf("询价单当前状态 %s 无法定标", rfq.Status)
+	}
+
+	now, err := s.getTxTimestamp(ctx)
+	if err != nil {
+		return err
+	}
+	if !now.After(rfq.Deadline) {
+		return fmt.Errorf("投标截止前不可定标")
+	}
+
+	bid, err := s.getBid(ctx, rfqId, manufacturerId)
+	if err != nil {
+		return err
+	}
+	if !bid.Revealed {
+		return fmt.Errorf("厂商 %s 尚未揭标", manufacturerId)
+	}
+
+	bidKey, err := ctx.GetStub().CreateCompositeKey(BID, []string{rfqId, manufacturerId})
+	if err != nil {
+		return fmt.Errorf("创建投标键失败: %v", err)
+	}
+	contentBytes, err := ctx.GetStub().GetPrivateData(ORDER_PRICE_COLLECTION, bidKey)
+	if err != nil {
+		return fmt.Errorf("读取投标内容失败: %v", err)
+	}
+	if contentBytes == nil {
+		return fmt.Errorf("投标内容不存在")
+	}
+	var content BidContent
+	if err := json.Unmarshal(contentBytes, &content); err != nil {
+		return fmt.Errorf("解析投标内容失败: %v", err)
+	}
+
+	items := make([]OrderItem, 0, len(rfq.Items))
+	for _, item := range rfq.Items {
+		items = append(items, OrderItem{PartNumber: item.PartNumber, Name: item.Name, Quantity: item.Quantity})
+	}
+	if err := requirePartNumbers(items); err != nil {
+		return err
+	}
+	price, err := newOrderPrice(orderId, items, content.ItemPrices, content.Salt)
+	if err != nil {
+		return err
+	}
+
+	order := &Order{
+		ID:             orderId,
+		OEMID:          clientMSPID,
+		ManufacturerID: manufacturerId,
+		Items:          items,
+	}
+	if err := s.createOrder(ctx, order, price); err != nil {
+		return err
+	}
+
+	rfq.Status = RFQ_AWARDED
+	rfq.WinnerID = manufacturerId
+	rfq.OrderID = orderId
+	rfq.UpdateTime = now
+	return s.putRFQ(ctx, rfq)
+}
+
+// CancelRFQ 主机厂取消询价 (仅 Org1 可调用)
+func (s *SmartContract) CancelRFQ(ctx contractapi.TransactionContextInterface, rfqId string) error {
+	clientMSPID, err := s.getClientIdentityMSPID(ctx)
+	if err != nil {
+		return err
+	}
+	if clientMSPID != OEM_ORG_MSPID {
+		return fmt.Errorf("无权限: 仅限主机厂取消询价")
+	}
+
+	rfq, err := s.QueryRFQ(ctx, rfqId)
+	if err != nil {
+		return err
+	}
+	if rfq.Status != RFQ_OPEN {
+		return fmt.Errorf("询价单当前状态 %s 无法取消", rfq.Status)
+	}
+
+	now, err := s.getTxTimestamp(ctx)
+	if err != nil {
+		return err
+	}
+	rfq.Status = RFQ_CANCELLED
+	rfq.UpdateTime = now
+	return s.putRFQ(ctx, rfq)
+}
+
+// QueryRFQ 查询询价单详情
+func (s *SmartContract) QueryRFQ(ctx contractapi.TransactionContextInterface, id string) (*RFQ, error) {
+	rfqBytes, err := ctx.GetStub().GetState(id)
+	if err != nil {
+		return nil, fmt.Errorf("读取询价单失败: %v", err)
+	}
+	if rfqBytes == nil {
+		return nil, fmt.Errorf("询价单 %s 不存在", id)
+	}
+
+	var rfq RFQ
+	if err := json.Unmarshal(rfqBytes, &rfq); err != nil || rfq.ObjectType != REQUEST_FOR_QUOTE {
+		return nil, fmt.Errorf("询价单 %s 不存在", id)
+	}
+	return &rfq, nil
+}
+
+// QueryBids 查询询价单下的全部投标 (截止前仅可见密封哈希)
+func (s *SmartContract) QueryBids(ctx contractapi.TransactionContextInterface, rfqId string) ([]*Bid, error) {
+	resultsIterator, err := ctx.GetStub().GetStateByPartialCompositeKey(BID, []string{rfqId})
+	if err != nil {
+		return nil, fmt.Errorf("查询投标失败: %v", err)
+	}
+	defer resultsIterator.Close()
+
+	bids := make([]*Bid, 0)
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return nil, err
+		}
+		var bid Bid
+		if err := json.Unmarshal(queryResponse.Value, &bid); err != nil {
+			return nil, fmt.Errorf("解析投标失败: %v", err)
+		}
+		bids = append(bids, &bid)
+	}
+	return bids, nil
+}
+
+// QueryBidContent 查询已揭标的投标内容 (仅 Org1/Org2 可调用)
+func (s *SmartContract) QueryBidContent(ctx contractapi.TransactionContextInterface, rfqId string, manufacturerId string) (*BidContent, error) {
+	clientMSPID, err := s.getClientIdentityMSPID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if clientMSPID != OEM_ORG_MSPID && clientMSPID != MANUFACTURER_ORG_MSPID {
+		return nil, fmt.Errorf("无权限: 仅限交易双方查看投标内容")
+	}
+
+	bidKey, err := ctx.GetStub().CreateCompositeKey(BID, []string{rfqId, manufacturerId})
+	if err != nil {
+		return nil, fmt.Errorf("创建投标键失败: %v", err)
+	}
+	contentBytes, err := ctx.GetStub().GetPrivateData(ORDER_PRICE_COLLECTION, bidKey)
+	if err != nil {
+		return nil, fmt.Errorf("读取投标内容失败: %v", err)
+	}
+	if contentBytes == nil {
+		return nil, fmt.Errorf("投标内容不存在或尚未揭标")
+	}
+
+	var content BidContent
+	if err := json.Unmarshal(contentBytes, &content); err != nil {
+		return nil, fmt.Errorf("解析投标内容失败: %v", err)
+	}
+	return &content, nil
+}
+
+func (s *SmartContract) getBid(ctx contractapi.TransactionContextInterface, rfqId string, manufacturerId string) (*Bid, error) {
+	bidKey, err := ctx.GetStub().CreateCompositeKey(BID, []string{rfqId, manufacturerId})
+	if err != nil {
+		return nil, fmt.Errorf("创建投标键失败: %v", err)
+	}
+	bidBytes, err := ctx.GetStub().GetState(bidKey)
+	if err != nil {
+		return nil, fmt.Errorf("读取投标失败: %v", err)
+	}
+	if bidBytes == nil {
+		return nil, fmt.Errorf("厂商 %s 未对询价单 %s 投标", manufacturerId, rfqId)
+	}
+
+	var bid Bid
+	if err := json.Unmarshal(bidBytes, &bid); err != nil {
+		return nil, fmt.Errorf("解析投标失败: %v", err)
+	}
+	return &bid, nil
+}
+
+func (s *SmartContract) putBid(ctx contractapi.TransactionContextInterface, bid *Bid) error {
+	bidKey, err := ctx.GetStub().CreateCompositeKey(BID, []string{bid.RFQID, bid.ManufacturerID})
+	if err != nil {
+		return fmt.Errorf("创建投标键失败: %v", err)
+	}
+	bidBytes, err := json.Marshal(bid)
+	if err != nil {
+		return fmt.Errorf("序列化投标失败: %v", err)
+	}
+	return ctx.GetStub().PutState(bidKey, bidBytes)
+}
+
+func (s *SmartContract) putRFQ(ctx contractapi.TransactionContextInterface, rfq *RFQ) error {
+	rfqBytes, err := json.Marshal(rfq)
+	if err != nil {
+		return fmt.Errorf("序列化询价单失败: %v", err)
+	}
+	return ctx.GetStub().PutState(rfq.ID, rfqBytes)
+}
+
+func containsString(list []string, target string) bool {
+	for _, item := range list {
+		if item == target {
+			return true
+		}
+	}
+	return false
+}
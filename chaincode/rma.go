@@ -0,0 +1,513 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/v2/contractapi"
+)
+
+// 退货授权资产类型
+const (
+	RMA         = "RMA"
+	RMA_ORDER   = "RMA_ORDER"   // 复合键: RMA_ORDER~orderId~rmaId
+	CREDIT_NOTE = "CREDIT_NOTE" // 私有数据复合键: CREDIT_NOTE~orderId~rmaId
+)
+
+// RMAStatus 退货单状态
+type RMAStatus string
+
+const (
+	RMA_OPEN              RMAStatus = "OPEN"              // 主机厂已发起, 待厂商审批
+	RMA_APPROVED          RMAStatus = "APPROVED"          // 厂商已批准, 待承运商取件
+	RMA_REJECTED          RMAStatus = "REJECTED"          // 厂商已拒绝
+	RMA_RETURN_IN_TRANSIT RMAStatus = "RETURN_IN_TRANSIT" // 退货运输中
+	RMA_CLOSED            RMAStatus = "CLOSED"            // 厂商已收货并出具处理结果
+)
+
+// RMAResolution 退货处理方式
+type RMAResolution string
+
+const (
+	RMA_CREDIT_NOTE RMAResolution = "CREDIT_NOTE" // 开具贷项通知单
+	RMA_REPLACEMENT RMAResolution = "REPLACEMENT" // 补发替换订单
+)
+
+// RMALine 退货行
+type RMALine struct {
+	LineIndex    int      `json:"lineIndex"`    // 原订单零件行序号
+	PartNumber   string   `json:"partNumber"`   // 零件号
+	Quantity     int      `json:"quantity"`     // 退货数量
+	DefectReason string   `json:"defectReason"` // 缺陷原因
+	PhotoHashes  []string `json:"photoHashes"`  // 缺陷照片哈希
+}
+
+// ReturnAuthorization 退货授权 (RMA)
+type ReturnAuthorization struct {
+	ID                 string        `json:"id"`                 // 退货单ID
+	ObjectType         string        `json:"objectType"`         // 资产类型 (RMA)
+	OrderID            string        `json:"orderId"`            // 原订单ID
+	OEMID              string        `json:"oemId"`              // 退货方 (主机厂)
+	ManufacturerID     string        `json:"manufacturerId"`     // 原供货厂商
+	Lines              []RMALine     `json:"lines"`              // 退货明细
+	Status             RMAStatus     `json:"status"`             // 当前状态
+	Resolution         RMAResolution `json:"resolution"`         // 处理方式 (厂商批准时确定)
+	RejectReason       string        `json:"rejectReason"`       // 拒绝原因
+	ShipmentID         string        `json:"shipmentId"`         // 退货物流单ID
+	CreditNoteID       string        `json:"creditNoteId"`       // 贷项通知单ID (金额存于私有集合)
+	ReplacementOrderID string        `json:"replacementOrderId"` // 替换订单ID
+	CreateTime         time.Time     `json:"createTime"`         // 创建时间
+	UpdateTime         time.Time     `json:"updateTime"`         // 更新时间
+}
+
+// CreditNote 贷项通知单 (私有数据)
+type CreditNote struct {
+	ID         string    `json:"id"`         // 贷项通知单ID
+	RMAID      string    `json:"rmaId"`      // 退货单ID
+	OrderID    string    `json:"orderId"`    // 原订单ID
+	Amount     float64   `json:"amount"`     // 贷项金额 (退货数量 × 原订单单价)
+	CreateTime time.Time `json:"createTime"` // 开具时间
+}
+
+// OpenRMA 主机厂针对已签收订单的零件行发起退货 (退货数量累计不超过实收数量)
+func (s *SmartContract) OpenRMA(ctx contractapi.TransactionContextInterface, id string, orderId string, linesJson string) error {
+	clientMSPID, err := s.getClientIdentityMSPID(ctx)
+	if err != nil {
+		return err
+	}
+
+	order, err := s.QueryOrder(ctx, orderId)
+	if err != nil {
+		return err
+	}
+	if clientMSPID != order.OEMID {
+		return fmt.Errorf("无权限: 仅限订单采购方发起退货")
+	}
+	if order.Status != ORDER_RECEIVED {
+		return fmt.Errorf("订单当前状态 %s 无法退货, 须签收后发起", order.Status)
+	}
+	if order.ConsignmentLocation != "" {
+		return fmt.Errorf("订单 %s 为寄售订单, 未消耗的货物仍属厂商库存, 不适用退货流程", orderId)
+	}
+
+	existing, err := ctx.GetStub().GetState(id)
+	if err != nil {
+		return fmt.Errorf("读取退货单失败: %v", err)
+	}
+	if existing != nil {
+		return fmt.Errorf("退货单 %s 已存在", id)
+	}
+
+	var lines []RMALine
+	if err := json.Unmarshal([]byte(linesJson), &lines); err != nil {
+		return fmt.Errorf("解析退货明细失败: %v", err)
+	}
+	if len(lines) == 0 {
+		return fmt.Errorf("退货明细不能为空")
+	}
+
+	returned, err := s.returnedQuantities(ctx, order)
+	if err != nil {
+		return err
+	}
+	for i := range lines {
+		line := &lines[i]
+		if line.LineIndex < 0 || line.LineIndex >= len(order.Items) {
+			return fmt.Errorf("无效的零件行序号: %d", line.LineIndex)
+		}
+		if line.Quantity <= 0 {
+			return fmt.Errorf("退货数量必须大于 0")
+		}
+		if line.DefectReason == "" {
+			return fmt.Errorf("第 %d 行缺少缺陷原因", line.LineIndex+1)
+		}
+		item := order.Items[line.LineIndex]
+		returned[line.LineIndex] += line.Quantity
+		if returned[line.LineIndex] > item.ReceivedQuantity {
+			return fmt.Errorf("零件 %s 累计退货数量 %d 超过实收数量 %d", item.Name, returned[line.LineIndex], item.ReceivedQuantity)
+		}
+		line.PartNumber = item.PartNumber
+		if line.PhotoHashes == nil {
+			line.PhotoHashes = []string{}
+		}
+	}
+
+	now, err := s.getTxTimestamp(ctx)
+	if err != nil {
+		return err
+	}
+	rma := &ReturnAuthorization{
+		ID:             id,
+		ObjectType:     RMA,
+		OrderID:        orderId,
+		OEMID:          order.OEMID,
+		ManufacturerID: order.ManufacturerID,
+		Lines:          lines,
+		Status:         RMA_OPEN,
+		CreateTime:     now,
+		UpdateTime:     now,
+	}
+	if err := s.putRMA(ctx, rma); err != nil {
+		return err
+	}
+	if err := s.setTradingPartnerEndorsementPolicy(ctx, id); err != nil {
+		return err
+	}
+
+	indexKey, err := ctx.GetStub().CreateCompositeKey(RMA_ORDER, []string{orderId, id})
+	if err != nil {
+		return fmt.Errorf("创建退货单索引失败: %v", err)
+	}
+	return ctx.GetStub().PutState(indexKey, []byte{0x00})
+}
+
+// ApproveRMA 供货厂商批准退货并确定处理方式 (CREDIT_NOTE/REPLACEMENT)
+func (s *SmartContract) ApproveRMA(ctx contractapi.TransactionContextInterface, id string, resolution string) error {
+	rma, now, err := s.prepareManufacturerRMAAction(ctx, id, RMA_OPEN)
+	if err != nil {
+		return err
+	}
+
+	switch RMAResolution(resolution) {
+	case RMA_CREDIT_NOTE, RMA_REPLACEMENT:
+	default:
+		return fmt.Errorf("无效的处理方式: %s", resolution)
+	}
+
+	rma.Status = RMA_APPROVED
+	rma.Resolution = RMAResolution(resolution)
+	rma.UpdateTime = now
+	return s.putRMA(ctx, rma)
+}
+
+// RejectRMA 供货厂商拒绝退货
+func (s *SmartContract) RejectRMA(ctx contractapi.TransactionContextInterface, id string, reason string) error {
+	rma, now, err := s.prepareManufacturerRMAAction(ctx, id, RMA_OPEN)
+	if err != nil {
+		return err
+	}
+	if reason == "" {
+		return fmt.Errorf("拒绝原因不能为空")
+	}
+
+	rma.Status = RMA_REJECTED
+	rma.RejectReason = reason
+	rma.UpdateTime = now
+	return s.putRMA(ctx, rma)
+}
+
+// PickupReturn 承运商在主机厂取件, 以物流单承运退货 (仅 Org3 可调用)
+func (s *SmartContract) PickupReturn(ctx contractapi.TransactionContextInterface, id string, shipmentId string) error {
+	carrierID, err := s.getCarrierID(ctx)
+	if err != nil {
+		return err
+	}
+
+	rma, err := s.QueryRMA(ctx, id)
+	if err != nil {
+		return err
+	}
+	if rma.Status != RMA_APPROVED {
+		return fmt.Errorf("退货单当前状态 %s 无法取件, 须厂商批准", rma.Status)
+	}
+
+	existing, err := ctx.GetStub().GetState(shipmentId)
+	if err != nil {
+		return fmt.Errorf("读取物流单失败: %v", err)
+	}
+	if existing != nil {
+		return fmt.Errorf("物流单 %s 已存在", shipmentId)
+	}
+
+	now, err := s.getTxTimestamp(ctx)
+	if err != nil {
+		return err
+	}
+	shipment := Shipment{
+		ID:         shipmentId,
+		ObjectType: SHIPMENT,
+		OrderID:    rma.OrderID,
+		CarrierID:  carrierID,
+		Location:   "主机厂",
+		Status:     SHIPMENT_IN_TRANSIT,
+		UpdateTime: now,
+
+		CustodianID: carrierID,
+		Custody: []CustodyRecord{{
+			CarrierID: carrierID,
+			Location:  "主机厂",
+			FromTime:  now,
+		}},
+
+		RMAID: id,
+	}
+	if err := s.appendCheckpoint(ctx, &shipment, Checkpoint{
+		EventType: EVENT_DEPARTED,
+		Place:     shipment.Location,
+		CarrierID: carrierID,
+		Timestamp: now,
+	}); err != nil {
+		return err
+	}
+	if err := s.putShipment(ctx, &shipment); err != nil {
+		return err
+	}
+
+	rma.Status = RMA_RETURN_IN_TRANSIT
+	rma.ShipmentID = shipmentId
+	rma.UpdateTime = now
+	return s.putRMA(ctx, rma)
+}
+
+// ConfirmReturnReceipt 供货厂商签收退货, 按处理方式开具贷项通知单或生成替换订单
+func (s *SmartContract) ConfirmReturnReceipt(ctx contractapi.TransactionContextInterface, id string) error {
+	rma, now, err := s.prepareManufacturerRMAAction(ctx, id, RMA_RETURN_IN_TRANSIT)
+	if err != nil {
+		return err
+	}
+
+	shipment, err := s.QueryShipment(ctx, rma.ShipmentID)
+	if err != nil {
+		return err
+	}
+	if shipment.Status == SHIPMENT_LOST {
+		return fmt.Errorf("退货物流单 %s 已确认灭失, 无法签收", rma.ShipmentID)
+	}
+	shipment.Status = SHIPMENT_DELIVERED
+	shipment.UpdateTime = now
+	if err := s.putShipment(ctx, shipment); err != nil {
+		return err
+	}
+
+	order, err := s.QueryOrder(ctx, rma.OrderID)
+	if err != nil {
+		return err
+	}
+	switch rma.Resolution {
+	case RMA_CREDIT_NOTE:
+		if err := s.issueCreditNote(ctx, rma, order, now); err != nil {
+			return err
+		}
+	case RMA_REPLACEMENT:
+		if err := s.createReplacementOrder(ctx, rma, order); err != nil {
+			return err
+		}
+	}
+
+	rma.Status = RMA_CLOSED
+	rma.UpdateTime = now
+	return s.putRMA(ctx, rma)
+}
+
+// QueryRMA 查询退货单
+func (s *SmartContract) QueryRMA(ctx contractapi.TransactionContextInterface, id string) (*ReturnAuthorization, error) {
+	rmaBytes, err := ctx.GetStub().GetState(id)
+	if err != nil {
+		return nil, fmt.Errorf("读取退货单失败: %v", err)
+	}
+	if rmaBytes == nil {
+		return nil, fmt.Errorf("退货单 %s 不存在", id)
+	}
+
+	var rma ReturnAuthorization
+	if err := json.Unmarshal(rmaBytes, &rma); err != nil {
+		return nil, fmt.Errorf("解析退货单失败: %v", err)
+	}
+	if rma.ObjectType != RMA {
+		return nil, fmt.Errorf("退货单 %s 不存在", id)
+	}
+	return &rma, nil
+}
+
+// QueryOrderRMAs 查询订单的全部退货单
+func (s *SmartContract) QueryOrderRMAs(ctx contractapi.TransactionContextInterface, orderId string) ([]*ReturnAuthorization, error) {
+	resultsIterator, err := ctx.GetStub().GetStateByPartialCompositeKey(RMA_ORDER, []string{orderId})
+	if err != nil {
+		return nil, fmt.Errorf("读取退货单索引失败: %v", err)
+	}
+	defer resultsIterator.Close()
+
+	rmas := make([]*ReturnAuthorization, 0)
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return nil, err
+		}
+		_, keyParts, err := ctx.GetStub().SplitCompositeKey(queryResponse.Key)
+		if err != nil {
+			return nil, fmt.Errorf("解析退货单索引失败: %v", err)
+		}
+		rma, err := s.QueryRMA(ctx, keyParts[1])
+		if err != nil {
+			return nil, err
+		}
+		rmas = append(rmas, rma)
+	}
+	return rmas, nil
+}
+
+// QueryCreditNote 查询退货单的贷项通知单 (仅 Org1/Org2 可调用)
+func (s *SmartContract) QueryCreditNote(ctx contractapi.TransactionContextInterface, rmaId string) (*CreditNote, error) {
+	clientMSPID, err := s.getClientIdentityMSPID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if clientMSPID != OEM_ORG_MSPID && clientMSPID != MANUFACTURER_ORG_MSPID {
+		return nil, fmt.Errorf("无权限: 仅限交易双方查看贷项通知单")
+	}
+
+	rma, err := s.QueryRMA(ctx, rmaId)
+	if err != nil {
+		return nil, err
+	}
+	order, err := s.QueryOrder(ctx, rma.OrderID)
+	if err != nil {
+		return nil, err
+	}
+	if order.ParentOrderID != "" && clientMSPID != order.OEMID {
+		return nil, fmt.Errorf("无权限: 子订单贷项通知单仅限下单的一级供应商查看")
+	}
+	key, err := ctx.GetStub().CreateCompositeKey(CREDIT_NOTE, []string{rma.OrderID, rmaId})
+	if err != nil {
+		return nil, fmt.Errorf("创建贷项通知单键失败: %v", err)
+	}
+	noteBytes, err := ctx.GetStub().GetPrivateData(orderPriceCollection(order), key)
+	if err != nil {
+		return nil, fmt.Errorf("读取贷项通知单失败: %v", err)
+	}
+	if noteBytes == nil {
+		return nil, fmt.Errorf("退货单 %s 没有贷项通知单", rmaId)
+	}
+
+	var note CreditNote
+	if err := json.Unmarshal(noteBytes, &note); err != nil {
+		return nil, fmt.Errorf("解析贷项通知单失败: %v", err)
+	}
+	return &note, nil
+}
+
+// 按原订单单价计算退货金额, 以私有数据开具贷项通知单 (子订单写入仅 Org2 可见的集合)
+func (s *SmartContract) issueCreditNote(ctx contractapi.TransactionContextInterface, rma *ReturnAuthorization, order *Order, now time.Time) error {
+	price, err := s.QueryOrderPrice(ctx, rma.OrderID)
+	if err != nil {
+		return err
+	}
+
+	note := CreditNote{
+		ID:         rma.ID + "-CN",
+		RMAID:      rma.ID,
+		OrderID:    rma.OrderID,
+		CreateTime: now,
+	}
+	for _, line := range rma.Lines {
+		note.Amount += float64(line.Quantity) * price.ItemPrices[line.LineIndex]
+	}
+
+	key, err := ctx.GetStub().CreateCompositeKey(CREDIT_NOTE, []string{rma.OrderID, rma.ID})
+	if err != nil {
+		return fmt.Errorf("创建贷项通知单键失败: %v", err)
+	}
+	noteBytes, err := json.Marshal(note)
+	if err != nil {
+		return fmt.Errorf("序列化贷项通知单失败: %v", err)
+	}
+	if err := ctx.GetStub().PutPrivateData(orderPriceCollection(order), key, noteBytes); err != nil {
+		return fmt.Errorf("写入贷项通知单失败: %v", err)
+	}
+	rma.CreditNoteID = note.ID
+	return nil
+}
+
+// 以退货明细生成零价替换订单, 沿用订单生命周期
+func (s *SmartContract) createReplacementOrder(ctx contractapi.TransactionContextInterface, rma *ReturnAuthorization, order *Order) error {
+	items := make([]OrderItem, 0, len(rma.Lines))
+	for _, line := range rma.Lines {
+		original := order.Items[line.LineIndex]
+		items = append(items, OrderItem{
+			PartNumber: original.PartNumber,
+			Name:       original.Name,
+			Quantity:   line.Quantity,
+		})
+	}
+
+	// 替换订单不产生新的应付金额, 单价均为 0, 以交易ID作为盐
+	price, err := newOrderPrice(rma.ID+"-R", items, make([]float64, len(items)), ctx.GetStub().GetTxID())
+	if err != nil {
+		return err
+	}
+	// 子订单的替换订单仍挂在原父订单下, 价格写入子订单价格集合
+	replacement := &Order{
+		ID:             rma.ID + "-R",
+		OEMID:          rma.OEMID,
+		ManufacturerID: rma.ManufacturerID,
+		Items:          items,
+		ParentOrderID:  order.ParentOrderID,
+	}
+	if err := s.createOrder(ctx, replacement, price); err != nil {
+		return err
+	}
+	if replacement.ParentOrderID != "" {
+		if err := s.putIndex(ctx, SUBORDER_INDEX, replacement.ParentOrderID, replacement.ID); err != nil {
+			return err
+		}
+	}
+	rma.ReplacementOrderID = replacement.ID
+	return nil
+}
+
+// 订单各零件行已发起退货的数量 (不含被拒绝的退货单)
+func (s *SmartContract) returnedQuantities(ctx contractapi.TransactionContextInterface, order *Order) ([]int, error) {
+	rmas, err := s.QueryOrderRMAs(ctx, order.ID)
+	if err != nil {
+		return nil, err
+	}
+	returned := make([]int, len(order.Items))
+	for _, rma := range rmas {
+		if rma.Status == RMA_REJECTED {
+			continue
+		}
+		for _, line := range rma.Lines {
+			returned[line.LineIndex] += line.Quantity
+		}
+	}
+	return returned, nil
+}
+
+// 厂商操作退货单前的公共校验
+func (s *SmartContract) prepareManufacturerRMAAction(ctx contractapi.TransactionContextInterface, id string, expected RMAStatus) (*ReturnAuthorization, time.Time, error) {
+	clientMSPID, err := s.getClientIdentityMSPID(ctx)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+
+	rma, err := s.QueryRMA(ctx, id)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	// 退货单的厂商ID为业务标识, 按原订单映射的 MSP ID 授权
+	order, err := s.QueryOrder(ctx, rma.OrderID)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	if clientMSPID != order.ManufacturerMSPID {
+		return nil, time.Time{}, fmt.Errorf("无权限: 仅限供货厂商处理退货单")
+	}
+	if rma.Status != expected {
+		return nil, time.Time{}, fmt.Errorf("退货单当前状态 %s 无法执行该操作", rma.Status)
+	}
+
+	now, err := s.getTxTimestamp(ctx)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	return rma, now, nil
+}
+
+func (s *SmartContract) putRMA(ctx contractapi.TransactionContextInterface, rma *ReturnAuthorization) error {
+	rmaBytes, err := json.Marshal(rma)
+	if err != nil {
+		return fmt.Errorf("序列化退货单失败: %v", err)
+	}
+	return ctx.GetStub().PutState(rma.ID, rmaBytes)
+}
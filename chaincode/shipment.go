@@ -0,0 +1,284 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/hyperledger/fabric-chaincode-go/v2/pkg/cid"
+	"github.com/hyperledger/fabric-contract-api-go/v2/contractapi"
+)
+
+// 承运商身份证书属性 (Org3 下多个承运商通过 CA 注册属性区分, 承运商交易必须携带该属性)
+const CARRIER_ID_ATTRIBUTE = "carrierId"
+
+// TransportMode 运输方式
+type TransportMode string
+
+const (
+	MODE_ROAD TransportMode = "ROAD" // 公路
+	MODE_RAIL TransportMode = "RAIL" // 铁路
+	MODE_SEA  TransportMode = "SEA"  // 海运
+	MODE_AIR  TransportMode = "AIR"  // 空运
+)
+
+// LegStatus 运输段状态
+type LegStatus string
+
+const (
+	LEG_PLANNED    LegStatus = "PLANNED"    // 待承运
+	LEG_IN_TRANSIT LegStatus = "IN_TRANSIT" // 运输中
+	LEG_COMPLETED  LegStatus = "COMPLETED"  // 已完成
+)
+
+// ShipmentLeg 物流运输段
+type ShipmentLeg struct {
+	CarrierID   string        `json:"carrierId"`           // 承运商ID
+	Mode        TransportMode `json:"mode"`                // 运输方式
+	Origin      string        `json:"origin"`              // 起点
+	Destination string        `json:"destination"`         // 终点
+	Status      LegStatus     `json:"status"`              // 运输段状态
+	StartTime   *time.Time    `json:"startTime,omitempty"` // 开始承运时间
+	EndTime     *time.Time    `json:"endTime,omitempty"`   // 交接完成时间
+}
+
+// CustodyRecord 货物保管记录 (谁在何时段实际持有货物)
+type CustodyRecord struct {
+	CarrierID string     `json:"carrierId"`        // 保管承运商ID
+	LegIndex  int        `json:"legIndex"`         // 对应运输段序号
+	Location  string     `json:"location"`         // 接收地点
+	FromTime  time.Time  `json:"fromTime"`         // 接收时间
+	ToTime    *time.Time `json:"toTime,omitempty"` // 交出时间 (为空表示仍在保管)
+}
+
+// CustodyHandover 待确认的保管交接
+type CustodyHandover struct {
+	FromCarrierID string    `json:"fromCarrierId"` // 交出方
+	ToCarrierID   string    `json:"toCarrierId"`   // 接收方
+	LegIndex      int       `json:"legIndex"`      // 接收方承运的运输段序号
+	Location      string    `json:"location"`      // 交接地点
+	RequestTime   time.Time `json:"requestTime"`   // 发起时间
+}
+
+// PlanShipmentLegs 当前保管承运商规划多段运输 (仅 Org3 可调用, 首段须由当前保管方承运)
+func (s *SmartContract) PlanShipmentLegs(ctx contractapi.TransactionContextInterface, shipmentId string, legsJson string) error {
+	carrierID, err := s.getCarrierID(ctx)
+	if err != nil {
+		return err
+	}
+
+	shipment, err := s.QueryShipment(ctx, shipmentId)
+	if err != nil {
+		return err
+	}
+	if shipment.CustodianID != carrierID {
+		return fmt.Errorf("无权限: 仅限当前保管承运商规划运输段")
+	}
+	if len(shipment.Legs) > 0 {
+		return fmt.Errorf("物流单 %s 已规划运输段", shipmentId)
+	}
+
+	var legs []ShipmentLeg
+	if err := json.Unmarshal([]byte(legsJson), &legs); err != nil {
+		return fmt.Errorf("解析运输段失败: %v", err)
+	}
+	if len(legs) == 0 {
+		return fmt.Errorf("运输段不能为空")
+	}
+	for i, leg := range legs {
+		if leg.CarrierID == "" || leg.Origin == "" || leg.Destination == "" {
+			return fmt.Errorf("第 %d 段承运商、起点与终点不能为空", i+1)
+		}
+		switch leg.Mode {
+		case MODE_ROAD, MODE_RAIL, MODE_SEA, MODE_AIR:
+		default:
+			return fmt.Errorf("第 %d 段运输方式无效: %s", i+1, leg.Mode)
+		}
+	}
+	if legs[0].CarrierID != carrierID {
+		return fmt.Errorf("首段承运商须为当前保管承运商 %s", carrierID)
+	}
+
+	now, err := s.getTxTimestamp(ctx)
+	if err != nil {
+		return err
+	}
+	for i := range legs {
+		legs[i].Status = LEG_PLANNED
+		legs[i].StartTime = nil
+		legs[i].EndTime = nil
+	}
+	legs[0].Status = LEG_IN_TRANSIT
+	legs[0].StartTime = &now
+
+	shipment.Legs = legs
+	shipment.CurrentLeg = 0
+	shipment.UpdateTime = now
+	return s.putShipment(ctx, shipment)
+}
+
+// HandoverCustody 当前保管承运商发起向下一段承运商的交接 (接收方确认前保管责任不转移)
+func (s *SmartContract) HandoverCustody(ctx contractapi.TransactionContextInterface, shipmentId string, location string) error {
+	carrierID, err := s.getCarrierID(ctx)
+	if err != nil {
+		return err
+	}
+
+	shipment, err := s.QueryShipment(ctx, shipmentId)
+	if err != nil {
+		return err
+	}
+	if shipment.CustodianID != carrierID {
+		return fmt.Errorf("无权限: 仅限当前保管承运商发起交接")
+	}
+	next := shipment.CurrentLeg + 1
+	if next >= len(shipment.Legs) {
+		return fmt.Errorf("物流单 %s 没有后续运输段", shipmentId)
+	}
+
+	now, err := s.getTxTimestamp(ctx)
+	if err != nil {
+		return err
+	}
+	shipment.PendingHandover = &CustodyHandover{
+		FromCarrierID: carrierID,
+		ToCarrierID:   shipment.Legs[next].CarrierID,
+		LegIndex:      next,
+		Location:      location,
+		RequestTime:   now,
+	}
+	shipment.UpdateTime = now
+	return s.putShipment(ctx, shipment)
+}
+
+// AcceptCustody 接收承运商确认交接, 保管责任自确认时刻起转移
+func (s *SmartContract) AcceptCustody(ctx contractapi.TransactionContextInterface, shipmentId string) error {
+	carrierID, err := s.getCarrierID(ctx)
+	if err != nil {
+		return err
+	}
+
+	shipment, err := s.QueryShipment(ctx, shipmentId)
+	if err != nil {
+		return err
+	}
+	handover := shipment.PendingHandover
+	if handover == nil {
+		return fmt.Errorf("物流单 %s 没有待确认的交接", shipmentId)
+	}
+	if handover.ToCarrierID != carrierID {
+		return fmt.Errorf("无权限: 仅限接收承运商 %s 确认交接", handover.ToCarrierID)
+	}
+
+	now, err := s.getTxTimestamp(ctx)
+	if err != nil {
+		return err
+	}
+
+	current := &shipment.Legs[shipment.CurrentLeg]
+	current.Status = LEG_COMPLETED
+	current.EndTime = &now
+	next := &shipment.Legs[handover.LegIndex]
+	next.Status = LEG_IN_TRANSIT
+	next.StartTime = &now
+
+	if n := len(shipment.Custody); n > 0 {
+		shipment.Custody[n-1].ToTime = &now
+	}
+	shipment.Custody = append(shipment.Custody, CustodyRecord{
+		CarrierID: carrierID,
+		LegIndex:  handover.LegIndex,
+		Location:  handover.Location,
+		FromTime:  now,
+	})
+
+	shipment.CurrentLeg = handover.LegIndex
+	shipment.CustodianID = carrierID
+	shipment.CarrierID = carrierID
+	shipment.Location = handover.Location
+	shipment.PendingHandover = nil
+	shipment.UpdateTime = now
+	return s.putShipment(ctx, shipment)
+}
+
+// RejectCustody 接收承运商拒绝交接, 货物仍由交出方保管
+func (s *SmartContract) RejectCustody(ctx contractapi.TransactionContextInterface, shipmentId string) error {
+	carrierID, err := s.getCarrierID(ctx)
+	if err != nil {
+		return err
+	}
+
+	shipment, err := s.QueryShipment(ctx, shipmentId)
+	if err != nil {
+		return err
+	}
+	if shipment.PendingHandover == nil {
+		return fmt.Errorf("物流单 %s 没有待确认的交接", shipmentId)
+	}
+	if shipment.PendingHandover.ToCarrierID != carrierID {
+		return fmt.Errorf("无权限: 仅限接收承运商拒绝交接")
+	}
+
+	now, err := s.getTxTimestamp(ctx)
+	if err != nil {
+		return err
+	}
+	shipment.PendingHandover = nil
+	shipment.UpdateTime = now
+	return s.putShipment(ctx, shipment)
+}
+
+// QueryCustodyAt 查询指定时刻 (RFC3339) 货物的保管承运商, 用于货损责任认定
+func (s *SmartContract) QueryCustodyAt(ctx contractapi.TransactionContextInterface, shipmentId string, at string) (*CustodyRecord, error) {
+	t, err := time.Parse(time.RFC3339, at)
+	if err != nil {
+		return nil, fmt.Errorf("时间格式无效: %v", err)
+	}
+
+	shipment, err := s.QueryShipment(ctx, shipmentId)
+	if err != nil {
+		return nil, err
+	}
+	for i := range shipment.Custody {
+		record := &shipment.Custody[i]
+		if t.Before(record.FromTime) {
+			continue
+		}
+		if record.ToTime == nil || t.Before(*record.ToTime) {
+			return record, nil
+		}
+	}
+	return nil, fmt.Errorf("物流单 %s 在 %s 无保管记录", shipmentId, at)
+}
+
+// 获取调用方承运商ID (读取证书属性 carrierId); 未携带该属性的 Org3 身份不是承运商
+func (s *SmartContract) getCarrierID(ctx contractapi.TransactionContextInterface) (string, error) {
+	clientID, err := cid.New(ctx.GetStub())
+	if err != nil {
+		return "", fmt.Errorf("获取客户端身份失败: %v", err)
+	}
+	mspID, err := clientID.GetMSPID()
+	if err != nil {
+		return "", err
+	}
+	if mspID != PLATFORM_ORG_MSPID {
+		return "", fmt.Errorf("无权限: 仅限承运商操作")
+	}
+
+	carrierID, found, err := clientID.GetAttributeValue(CARRIER_ID_ATTRIBUTE)
+	if err != nil {
+		return "", fmt.Errorf("读取承运商属性失败: %v", err)
+	}
+	if !found || carrierID == "" {
+		return "", fmt.Errorf("无权限: 仅限承运商操作, 调用方证书缺少 %s 属性", CARRIER_ID_ATTRIBUTE)
+	}
+	return carrierID, nil
+}
+
+func (s *SmartContract) putShipment(ctx contractapi.TransactionContextInterface, shipment *Shipment) error {
+	shipmentBytes, err := json.Marshal(shipment)
+	if err != nil {
+		return fmt.Errorf("序列化物流单失败: %v", err)
+	}
+	return ctx.GetStub().PutState(shipment.ID, shipmentBytes)
+}
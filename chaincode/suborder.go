@@ -0,0 +1,178 @@
+package main
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+
+	"github.com/hyperledger/fabric-contract-api-go/v2/contractapi"
+)
+
+// 订单树最大深度, 防止异常数据导致无限递归
+const MAX_ORDER_TREE_DEPTH = 8
+
+// 子订单索引 (复合键: SUBORDER~parentOrderId~orderId)
+const SUBORDER_INDEX = "SUBORDER"
+
+// OrderTreeNode 订单物料履约树节点
+type OrderTreeNode struct {
+	Order     *Order           `json:"order"`     // 订单
+	Completed bool             `json:"completed"` // 本订单及全部子订单是否已签收
+	Children  []*OrderTreeNode `json:"children"`  // 子订单
+}
+
+// RecordSubOrderPrice 一级供应商登记子订单价格 (仅 Org2 可调用, 单价通过瞬态字段 price 传入)
+// 价格写入仅 Org2 可见的集合, 该交易只需 Org2 背书, 随后调用 CreateSubOrder 创建子订单
+func (s *SmartContract) RecordSubOrderPrice(ctx contractapi.TransactionContextInterface, id string, itemsJson string) error {
+	clientMSPID, err := s.getClientIdentityMSPID(ctx)
+	if err != nil {
+		return err
+	}
+	if clientMSPID != MANUFACTURER_ORG_MSPID {
+		return fmt.Errorf("无权限: 仅限零部件厂商登记子订单价格")
+	}
+
+	existing, err := ctx.GetStub().GetState(id)
+	if err != nil {
+		return fmt.Errorf("读取订单失败: %v", err)
+	}
+	if existing != nil {
+		return fmt.Errorf("订单 %s 已存在", id)
+	}
+
+	var items []OrderItem
+	if err := json.Unmarshal([]byte(itemsJson), &items); err != nil {
+		return fmt.Errorf("解析零件清单失败: %v", err)
+	}
+	price, err := s.getOrderPriceFromTransient(ctx, id, items)
+	if err != nil {
+		return err
+	}
+	priceBytes, err := json.Marshal(price)
+	if err != nil {
+		return fmt.Errorf("序列化订单价格失败: %v", err)
+	}
+	if err := ctx.GetStub().PutPrivateData(SUBORDER_PRICE_COLLECTION, id, priceBytes); err != nil {
+		return fmt.Errorf("写入子订单价格失败: %v", err)
+	}
+	return nil
+}
+
+// CreateSubOrder 一级供应商向二级供应商下达子订单 (仅 Org2 可调用, 须先经 RecordSubOrderPrice 登记价格)
+// 价格哈希取自仅 Org2 可见的集合, 其他背书节点只接触哈希; 子订单此后仅需 Org2 背书, 父订单不被修改
+// blockParent 为 true 时, 父订单在该子订单签收前不可进入生产完成
+func (s *SmartContract) CreateSubOrder(ctx contractapi.TransactionContextInterface, parentOrderId string, id string, supplierId string, itemsJson string, blockParent bool) error {
+	clientMSPID, err := s.getClientIdentityMSPID(ctx)
+	if err != nil {
+		return err
+	}
+	if clientMSPID != MANUFACTURER_ORG_MSPID {
+		return fmt.Errorf("无权限: 仅限零部件厂商下达子订单")
+	}
+
+	parent, err := s.QueryOrder(ctx, parentOrderId)
+	if err != nil {
+		return err
+	}
+	if parent.Status != ORDER_ACCEPTED && parent.Status != ORDER_PRODUCING {
+		return fmt.Errorf("父订单当前状态 %s 无法下达子订单", parent.Status)
+	}
+	if supplierId == parent.ManufacturerID {
+		return fmt.Errorf("子订单供应商不能为父订单厂商本身")
+	}
+
+	var items []OrderItem
+	if err := json.Unmarshal([]byte(itemsJson), &items); err != nil {
+		return fmt.Errorf("解析零件清单失败: %v", err)
+	}
+	if len(items) == 0 {
+		return fmt.Errorf("零件清单不能为空")
+	}
+	if err := requirePartNumbers(items); err != nil {
+		return err
+	}
+
+	priceHash, err := ctx.GetStub().GetPrivateDataHash(SUBORDER_PRICE_COLLECTION, id)
+	if err != nil {
+		return fmt.Errorf("读取子订单价格哈希失败: %v", err)
+	}
+	if priceHash == nil {
+		return fmt.Errorf("子订单 %s 尚未登记价格", id)
+	}
+
+	order := &Order{
+		ID:             id,
+		OEMID:          clientMSPID,
+		ManufacturerID: supplierId,
+		Items:          items,
+		ParentOrderID:  parentOrderId,
+		BlockParent:    blockParent,
+	}
+	if err := s.insertOrder(ctx, order, hex.EncodeToString(priceHash)); err != nil {
+		return err
+	}
+	if err := s.putIndex(ctx, SUBORDER_INDEX, parentOrderId, id); err != nil {
+		return err
+	}
+	// 二级供应商不在网络内, 子订单此后的修改仅需下单方背书
+	return s.setEndorsementPolicy(ctx, id, clientMSPID)
+}
+
+// QueryOrderTree 查询订单及其全部子订单的履约树
+func (s *SmartContract) QueryOrderTree(ctx contractapi.TransactionContextInterface, orderId string) (*OrderTreeNode, error) {
+	return s.buildOrderTree(ctx, orderId, 0)
+}
+
+func (s *SmartContract) buildOrderTree(ctx contractapi.TransactionContextInterface, orderId string, depth int) (*OrderTreeNode, error) {
+	if depth > MAX_ORDER_TREE_DEPTH {
+		return nil, fmt.Errorf("订单层级超过上限 %d", MAX_ORDER_TREE_DEPTH)
+	}
+
+	order, err := s.QueryOrder(ctx, orderId)
+	if err != nil {
+		return nil, err
+	}
+
+	childIds, err := s.getIndexedIDs(ctx, SUBORDER_INDEX, orderId)
+	if err != nil {
+		return nil, err
+	}
+	node := &OrderTreeNode{
+		Order:     order,
+		Completed: order.Status == ORDER_RECEIVED,
+		Children:  make([]*OrderTreeNode, 0, len(childIds)),
+	}
+	for _, childId := range childIds {
+		child, err := s.buildOrderTree(ctx, childId, depth+1)
+		if err != nil {
+			return nil, err
+		}
+		if !child.Completed {
+			node.Completed = false
+		}
+		node.Children = append(node.Children, child)
+	}
+	return node, nil
+}
+
+// 进入生产完成或待取货前, 设置了阻塞父订单的子订单须已签收
+func (s *SmartContract) checkChildOrdersComplete(ctx contractapi.TransactionContextInterface, order *Order, target OrderStatus) error {
+	if target != ORDER_PRODUCED && target != ORDER_READY {
+		return nil
+	}
+
+	childIds, err := s.getIndexedIDs(ctx, SUBORDER_INDEX, order.ID)
+	if err != nil {
+		return err
+	}
+	for _, childId := range childIds {
+		child, err := s.QueryOrder(ctx, childId)
+		if err != nil {
+			return err
+		}
+		if child.BlockParent && child.Status != ORDER_RECEIVED {
+			return fmt.Errorf("子订单 %s 尚未签收 (当前状态 %s), 父订单无法进入 %s", childId, child.Status, target)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,357 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/v2/contractapi"
+)
+
+// 传感器遥测上链数据 (原始读数存于链下, 链上只保存默克尔根与超限记录)
+const (
+	TELEMETRY_ANCHOR = "TELEMETRY_ANCHOR" // 复合键: TELEMETRY_ANCHOR~shipmentId~seq
+	EXCURSION        = "EXCURSION"        // 复合键: EXCURSION~shipmentId~seq
+
+	EXCURSION_READING_INDEX = "EXCURSION_READING" // 已记录超限的读数索引 (复合键: EXCURSION_READING~shipmentId~readingHash:sensorType)
+	TELEMETRY_ANCHOR_RANGE  = "TELEMETRY_RANGE"   // 已锚定的链下读数区间 (复合键: TELEMETRY_RANGE~shipmentId~fromSeq~toSeq, 值为批次序号)
+)
+
+// SensorType 传感器类型
+type SensorType string
+
+const (
+	SENSOR_TEMPERATURE SensorType = "TEMPERATURE" // 温度 (摄氏度)
+	SENSOR_SHOCK       SensorType = "SHOCK"       // 冲击 (g)
+)
+
+// SensorThresholds 物流单传感器阈值
+type SensorThresholds struct {
+	MinTemperature *float64 `json:"minTemperature,omitempty"` // 最低温度
+	MaxTemperature *float64 `json:"maxTemperature,omitempty"` // 最高温度
+	MaxShock       *float64 `json:"maxShock,omitempty"`       // 最大冲击
+}
+
+// TelemetryAnchor 一批遥测读数的默克尔根锚定记录
+type TelemetryAnchor struct {
+	ShipmentID   string    `json:"shipmentId"`   // 物流单ID
+	Seq          int       `json:"seq"`          // 批次序号
+	MerkleRoot   string    `json:"merkleRoot"`   // 默克尔根 (十六进制)
+	ReadingCount int       `json:"readingCount"` // 本批读数数量
+	FromSeq      uint64    `json:"fromSeq"`      // 链下存储首条读数序号
+	ToSeq        uint64    `json:"toSeq"`        // 链下存储末条读数序号
+	FromTime     time.Time `json:"fromTime"`     // 首条读数时间
+	ToTime       time.Time `json:"toTime"`       // 末条读数时间
+	AnchorTime   time.Time `json:"anchorTime"`   // 锚定时间
+}
+
+// Excursion 传感器超限记录
+type Excursion struct {
+	ShipmentID  string     `json:"shipmentId"`  // 物流单ID
+	Seq         int        `json:"seq"`         // 序号
+	DeviceID    string     `json:"deviceId"`    // 设备ID
+	SensorType  SensorType `json:"sensorType"`  // 传感器类型
+	Value       float64    `json:"value"`       // 读数
+	Threshold   float64    `json:"threshold"`   // 被突破的阈值
+	ReadingTime time.Time  `json:"readingTime"` // 读数时间
+	ReadingHash string     `json:"readingHash"` // 原始读数哈希, 可对照链下存储与默克尔根校验
+	RecordTime  time.Time  `json:"recordTime"`  // 上链时间
+}
+
+// SetSensorThresholds 交易双方设置物流单传感器阈值 (仅订单主机厂或厂商可调用)
+func (s *SmartContract) SetSensorThresholds(ctx contractapi.TransactionContextInterface, shipmentId string, thresholdsJson string) error {
+	clientMSPID, err := s.getClientIdentityMSPID(ctx)
+	if err != nil {
+		return err
+	}
+
+	shipment, err := s.QueryShipment(ctx, shipmentId)
+	if err != nil {
+		return err
+	}
+	order, err := s.QueryOrder(ctx, shipment.OrderID)
+	if err != nil {
+		return err
+	}
+	if clientMSPID != order.OEMID && clientMSPID != order.ManufacturerMSPID {
+		return fmt.Errorf("无权限: 仅限交易双方设置传感器阈值")
+	}
+
+	var thresholds SensorThresholds
+	if err := json.Unmarshal([]byte(thresholdsJson), &thresholds); err != nil {
+		return fmt.Errorf("解析传感器阈值失败: %v", err)
+	}
+	if thresholds.MinTemperature != nil && thresholds.MaxTemperature != nil &&
+		*thresholds.MinTemperature > *thresholds.MaxTemperature {
+		return fmt.Errorf("最低温度不能高于最高温度")
+	}
+	if thresholds.MaxShock != nil && *thresholds.MaxShock <= 0 {
+		return fmt.Errorf("最大冲击必须大于 0")
+	}
+
+	now, err := s.getTxTimestamp(ctx)
+	if err != nil {
+		return err
+	}
+	shipment.SensorThresholds = &thresholds
+	shipment.UpdateTime = now
+	return s.putShipment(ctx, shipment)
+}
+
+// AnchorTelemetry 锚定一批链下遥测读数的默克尔根并返回锚定记录 (仅平台方可调用)
+// 按链下读数区间幂等: 同一区间重复提交时返回已有记录, 根不一致时拒绝
+func (s *SmartContract) AnchorTelemetry(ctx contractapi.TransactionContextInterface, shipmentId string, merkleRoot string, readingCount int, fromSeq uint64, toSeq uint64, fromTime string, toTime string) (*TelemetryAnchor, error) {
+	isPlatform, err := s.isPlatformIdentity(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !isPlatform {
+		return nil, fmt.Errorf("无权限: 仅限平台方锚定遥测数据")
+	}
+	if merkleRoot == "" || readingCount <= 0 {
+		return nil, fmt.Errorf("默克尔根与读数数量不能为空")
+	}
+	if toSeq < fromSeq {
+		return nil, fmt.Errorf("读数区间无效: %d-%d", fromSeq, toSeq)
+	}
+
+	from, err := time.Parse(time.RFC3339Nano, fromTime)
+	if err != nil {
+		return nil, fmt.Errorf("时间格式无效: %v", err)
+	}
+	to, err := time.Parse(time.RFC3339Nano, toTime)
+	if err != nil {
+		return nil, fmt.Errorf("时间格式无效: %v", err)
+	}
+
+	shipment, err := s.QueryShipment(ctx, shipmentId)
+	if err != nil {
+		return nil, err
+	}
+
+	rangeKey, err := ctx.GetStub().CreateCompositeKey(TELEMETRY_ANCHOR_RANGE, []string{shipmentId, fmt.Sprintf("%020d", fromSeq), fmt.Sprintf("%020d", toSeq)})
+	if err != nil {
+		return nil, fmt.Errorf("创建索引键失败: %v", err)
+	}
+	anchoredSeq, err := ctx.GetStub().GetState(rangeKey)
+	if err != nil {
+		return nil, fmt.Errorf("读取锚定区间失败: %v", err)
+	}
+	if anchoredSeq != nil {
+		seq, err := strconv.Atoi(string(anchoredSeq))
+		if err != nil {
+			return nil, fmt.Errorf("解析锚定区间失败: %v", err)
+		}
+		existing, err := s.getTelemetryAnchor(ctx, shipmentId, seq)
+		if err != nil {
+			return nil, err
+		}
+		if existing.MerkleRoot != merkleRoot || existing.ReadingCount != readingCount {
+			return nil, fmt.Errorf("读数区间 %d-%d 已锚定为批次 %d, 默克尔根不一致", fromSeq, toSeq, seq)
+		}
+		return existing, nil
+	}
+
+	now, err := s.getTxTimestamp(ctx)
+	if err != nil {
+		return nil, err
+	}
+	anchor := &TelemetryAnchor{
+		ShipmentID:   shipmentId,
+		Seq:          shipment.TelemetryAnchorCount,
+		MerkleRoot:   merkleRoot,
+		ReadingCount: readingCount,
+		FromSeq:      fromSeq,
+		ToSeq:        toSeq,
+		FromTime:     from,
+		ToTime:       to,
+		AnchorTime:   now,
+	}
+	if err := s.putShipmentRecord(ctx, TELEMETRY_ANCHOR, shipmentId, anchor.Seq, anchor); err != nil {
+		return nil, err
+	}
+	if err := ctx.GetStub().PutState(rangeKey, []byte(strconv.Itoa(anchor.Seq))); err != nil {
+		return nil, fmt.Errorf("写入锚定区间失败: %v", err)
+	}
+
+	shipment.TelemetryAnchorCount++
+	shipment.UpdateTime = now
+	if err := s.putShipment(ctx, shipment); err != nil {
+		return nil, err
+	}
+	return anchor, nil
+}
+
+func (s *SmartContract) getTelemetryAnchor(ctx contractapi.TransactionContextInterface, shipmentId string, seq int) (*TelemetryAnchor, error) {
+	key, err := shipmentRecordKey(ctx, TELEMETRY_ANCHOR, shipmentId, seq)
+	if err != nil {
+		return nil, err
+	}
+	anchorBytes, err := ctx.GetStub().GetState(key)
+	if err != nil {
+		return nil, fmt.Errorf("读取遥测锚定记录失败: %v", err)
+	}
+	if anchorBytes == nil {
+		return nil, fmt.Errorf("物流单 %s 不存在遥测锚定批次 %d", shipmentId, seq)
+	}
+	var anchor TelemetryAnchor
+	if err := json.Unmarshal(anchorBytes, &anchor); err != nil {
+		return nil, fmt.Errorf("解析遥测锚定记录失败: %v", err)
+	}
+	return &anchor, nil
+}
+
+// RecordExcursions 批量记录传感器超限 (仅平台方可调用, 链码按物流单阈值复核)
+// 同一读数同一传感器的超限只记录一次, 应用重试或重复提交时跳过
+func (s *SmartContract) RecordExcursions(ctx contractapi.TransactionContextInterface, shipmentId string, excursionsJson string) error {
+	isPlatform, err := s.isPlatformIdentity(ctx)
+	if err != nil {
+		return err
+	}
+	if !isPlatform {
+		return fmt.Errorf("无权限: 仅限平台方记录超限")
+	}
+
+	var excursions []Excursion
+	if err := json.Unmarshal([]byte(excursionsJson), &excursions); err != nil {
+		return fmt.Errorf("解析超限记录失败: %v", err)
+	}
+	if len(excursions) == 0 {
+		return fmt.Errorf("超限记录不能为空")
+	}
+
+	shipment, err := s.QueryShipment(ctx, shipmentId)
+	if err != nil {
+		return err
+	}
+	now, err := s.getTxTimestamp(ctx)
+	if err != nil {
+		return err
+	}
+
+	recorded := make(map[string]bool)
+	for _, excursion := range excursions {
+		if excursion.ReadingHash == "" {
+			return fmt.Errorf("超限记录缺少原始读数哈希")
+		}
+		threshold, breached := checkThreshold(shipment.SensorThresholds, excursion.SensorType, excursion.Value)
+		if !breached {
+			return fmt.Errorf("读数 %v 未超出 %s 阈值", excursion.Value, excursion.SensorType)
+		}
+
+		readingKey := excursion.ReadingHash + ":" + string(excursion.SensorType)
+		if recorded[readingKey] {
+			continue
+		}
+		recorded[readingKey] = true
+		indexKey, err := ctx.GetStub().CreateCompositeKey(EXCURSION_READING_INDEX, []string{shipmentId, readingKey})
+		if err != nil {
+			return fmt.Errorf("创建索引键失败: %v", err)
+		}
+		existing, err := ctx.GetStub().GetState(indexKey)
+		if err != nil {
+			return fmt.Errorf("读取超限索引失败: %v", err)
+		}
+		if existing != nil {
+			continue
+		}
+
+		excursion.ShipmentID = shipmentId
+		excursion.Seq = shipment.ExcursionCount
+		excursion.Threshold = threshold
+		excursion.RecordTime = now
+		if err := s.putShipmentRecord(ctx, EXCURSION, shipmentId, excursion.Seq, excursion); err != nil {
+			return err
+		}
+		if err := s.putIndex(ctx, EXCURSION_READING_INDEX, shipmentId, readingKey); err != nil {
+			return err
+		}
+		shipment.ExcursionCount++
+	}
+
+	shipment.UpdateTime = now
+	return s.putShipment(ctx, shipment)
+}
+
+// QueryTelemetryAnchors 查询物流单的遥测锚定记录
+func (s *SmartContract) QueryTelemetryAnchors(ctx contractapi.TransactionContextInterface, shipmentId string) ([]*TelemetryAnchor, error) {
+	anchors := make([]*TelemetryAnchor, 0)
+	err := s.scanShipmentRecords(ctx, TELEMETRY_ANCHOR, shipmentId, func(value []byte) error {
+		var anchor TelemetryAnchor
+		if err := json.Unmarshal(value, &anchor); err != nil {
+			return fmt.Errorf("解析遥测锚定记录失败: %v", err)
+		}
+		anchors = append(anchors, &anchor)
+		return nil
+	})
+	return anchors, err
+}
+
+// QueryExcursions 查询物流单的传感器超限记录
+func (s *SmartContract) QueryExcursions(ctx contractapi.TransactionContextInterface, shipmentId string) ([]*Excursion, error) {
+	excursions := make([]*Excursion, 0)
+	err := s.scanShipmentRecords(ctx, EXCURSION, shipmentId, func(value []byte) error {
+		var excursion Excursion
+		if err := json.Unmarshal(value, &excursion); err != nil {
+			return fmt.Errorf("解析超限记录失败: %v", err)
+		}
+		excursions = append(excursions, &excursion)
+		return nil
+	})
+	return excursions, err
+}
+
+// 判断读数是否超出阈值, 返回被突破的阈值
+func checkThreshold(thresholds *SensorThresholds, sensorType SensorType, value float64) (float64, bool) {
+	if thresholds == nil {
+		return 0, false
+	}
+	switch sensorType {
+	case SENSOR_TEMPERATURE:
+		if thresholds.MinTemperature != nil && value < *thresholds.MinTemperature {
+			return *thresholds.MinTemperature, true
+		}
+		if thresholds.MaxTemperature != nil && value > *thresholds.MaxTemperature {
+			return *thresholds.MaxTemperature, true
+		}
+	case SENSOR_SHOCK:
+		if thresholds.MaxShock != nil && value > *thresholds.MaxShock {
+			return *thresholds.MaxShock, true
+		}
+	}
+	return 0, false
+}
+
+// 按 objectType~shipmentId~seq 写入物流单附属记录
+func (s *SmartContract) putShipmentRecord(ctx contractapi.TransactionContextInterface, objectType string, shipmentId string, seq int, record interface{}) error {
+	key, err := shipmentRecordKey(ctx, objectType, shipmentId, seq)
+	if err != nil {
+		return err
+	}
+	recordBytes, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("序列化记录失败: %v", err)
+	}
+	return ctx.GetStub().PutState(key, recordBytes)
+}
+
+// 按序号顺序遍历物流单附属记录
+func (s *SmartContract) scanShipmentRecords(ctx contractapi.TransactionContextInterface, objectType string, shipmentId string, handle func(value []byte) error) error {
+	resultsIterator, err := ctx.GetStub().GetStateByPartialCompositeKey(objectType, []string{shipmentId})
+	if err != nil {
+		return fmt.Errorf("读取记录失败: %v", err)
+	}
+	defer resultsIterator.Close()
+
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return err
+		}
+		if err := handle(queryResponse.Value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,392 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/v2/contractapi"
+)
+
+// 批次追溯与召回资产类型
+const (
+	RECALL = "RECALL" // 召回单
+
+	BATCH_INDEX        = "BATCH"        // 批次索引 (复合键: BATCH~batchNo~orderId)
+	SERIAL_INDEX       = "SERIAL"       // 序列号索引 (复合键: SERIAL~serialNo~orderId)
+	RECALL_BATCH_INDEX = "RECALL_BATCH" // 召回批次索引 (复合键: RECALL_BATCH~batchNo~recallId)
+)
+
+// RecallStatus 召回状态
+type RecallStatus string
+
+const (
+	RECALL_OPEN   RecallStatus = "OPEN"   // 召回中
+	RECALL_CLOSED RecallStatus = "CLOSED" // 已关闭
+)
+
+// ItemBatch 零件生产批次
+type ItemBatch struct {
+	BatchNo       string   `json:"batchNo"`                 // 批次号
+	Quantity      int      `json:"quantity"`                // 该批次数量
+	SerialNumbers []string `json:"serialNumbers,omitempty"` // 序列号
+}
+
+// Recall 召回单 (平台方发布)
+type Recall struct {
+	ID               string       `json:"id"`               // 召回单ID
+	ObjectType       string       `json:"objectType"`       // 资产类型 (RECALL)
+	IssuerID         string       `json:"issuerId"`         // 发布组织 ID
+	BatchNos         []string     `json:"batchNos"`         // 召回批次
+	Reason           string       `json:"reason"`           // 召回原因
+	AffectedOrderIDs []string     `json:"affectedOrderIds"` // 受影响订单
+	Status           RecallStatus `json:"status"`           // 当前状态
+	CreateTime       time.Time    `json:"createTime"`       // 发布时间
+	UpdateTime       time.Time    `json:"updateTime"`       // 更新时间
+}
+
+// BatchTrace 批次追溯结果
+type BatchTrace struct {
+	BatchNo  string            `json:"batchNo"`  // 批次号
+	Recalled bool              `json:"recalled"` // 是否处于召回中
+	Entries  []BatchTraceEntry `json:"entries"`  // 包含该批次的订单
+}
+
+// BatchTraceEntry 包含某批次的订单及物流
+type BatchTraceEntry struct {
+	OrderID        string      `json:"orderId"`            // 订单ID
+	ManufacturerID string      `json:"manufacturerId"`     // 零部件厂商 ID
+	ItemName       string      `json:"itemName"`           // 零件名称
+	Quantity       int         `json:"quantity"`           // 该批次数量
+	OrderStatus    OrderStatus `json:"orderStatus"`        // 订单状态
+	Shipment       *Shipment   `json:"shipment,omitempty"` // 关联物流单
+}
+
+// CompleteProduction 零部件厂登记生产批次并将订单置为生产完成 (仅订单厂商可调用)
+// batchesJson 为与 Order.Items 一一对应的批次列表
+func (s *SmartContract) CompleteProduction(ctx contractapi.TransactionContextInterface, orderId string, batchesJson string) error {
+	clientMSPID, err := s.getClientIdentityMSPID(ctx)
+	if err != nil {
+		return err
+	}
+
+	order, err := s.QueryOrder(ctx, orderId)
+	if err != nil {
+		return err
+	}
+	if clientMSPID != order.ManufacturerMSPID {
+		return fmt.Errorf("无权限: 仅限订单厂商登记生产批次")
+	}
+	if order.Status != ORDER_ACCEPTED && order.Status != ORDER_PRODUCING {
+		return fmt.Errorf("当前状态 %s 无法登记生产完成", order.Status)
+	}
+
+	var batches [][]ItemBatch
+	if err := json.Unmarshal([]byte(batchesJson), &batches); err != nil {
+		return fmt.Errorf("解析批次信息失败: %v", err)
+	}
+	if len(batches) != len(order.Items) {
+		return fmt.Errorf("批次列表数量 %d 与零件数量 %d 不一致", len(batches), len(order.Items))
+	}
+
+	for i := range order.Items {
+		item := &order.Items[i]
+		total := 0
+		for _, batch := range batches[i] {
+			if batch.BatchNo == "" {
+				return fmt.Errorf("零件 %s 批次号不能为空", item.Name)
+			}
+			if len(batch.SerialNumbers) > 0 && len(batch.SerialNumbers) != batch.Quantity {
+				return fmt.Errorf("批次 %s 序列号数量与批次数量不一致", batch.BatchNo)
+			}
+			recalled, err := s.isBatchRecalled(ctx, batch.BatchNo)
+			if err != nil {
+				return err
+			}
+			if recalled {
+				return fmt.Errorf("批次 %s 处于召回中, 不可交付", batch.BatchNo)
+			}
+			total += batch.Quantity
+
+			if err := s.putIndex(ctx, BATCH_INDEX, batch.BatchNo, orderId); err != nil {
+				return err
+			}
+			for _, serialNo := range batch.SerialNumbers {
+				if err := s.putIndex(ctx, SERIAL_INDEX, serialNo, orderId); err != nil {
+					return err
+				}
+			}
+		}
+		if total != item.Quantity {
+			return fmt.Errorf("零件 %s 批次数量合计 %d 与订单数量 %d 不一致", item.Name, total, item.Quantity)
+		}
+		item.Batches = batches[i]
+	}
+
+	if err := s.checkChildOrdersComplete(ctx, order, ORDER_PRODUCED); err != nil {
+		return err
+	}
+
+	now, err := s.getTxTimestamp(ctx)
+	if err != nil {
+		return err
+	}
+	order.Status = ORDER_PRODUCED
+	order.UpdateTime = now
+	triggerPaymentMilestones(order, now)
+
+	orderBytes, err := json.Marshal(order)
+	if err != nil {
+		return fmt.Errorf("序列化订单失败: %v", err)
+	}
+	return ctx.GetStub().PutState(orderId, orderBytes)
+}
+
+// TraceBatch 追溯包含指定批次的全部订单与物流单
+func (s *SmartContract) TraceBatch(ctx contractapi.TransactionContextInterface, batchNo string) (*BatchTrace, error) {
+	orderIds, err := s.getIndexedIDs(ctx, BATCH_INDEX, batchNo)
+	if err != nil {
+		return nil, err
+	}
+	recalled, err := s.isBatchRecalled(ctx, batchNo)
+	if err != nil {
+		return nil, err
+	}
+
+	trace := &BatchTrace{
+		BatchNo:  batchNo,
+		Recalled: recalled,
+		Entries:  make([]BatchTraceEntry, 0),
+	}
+	for _, orderId := range orderIds {
+		order, err := s.QueryOrder(ctx, orderId)
+		if err != nil {
+			return nil, err
+		}
+
+		var shipment *Shipment
+		if order.ShipmentID != "" {
+			if shipment, err = s.QueryShipment(ctx, order.ShipmentID); err != nil {
+				return nil, err
+			}
+		}
+
+		for _, item := range order.Items {
+			for _, batch := range item.Batches {
+				if batch.BatchNo != batchNo {
+					continue
+				}
+				trace.Entries = append(trace.Entries, BatchTraceEntry{
+					OrderID:        order.ID,
+					ManufacturerID: order.ManufacturerID,
+					ItemName:       item.Name,
+					Quantity:       batch.Quantity,
+					OrderStatus:    order.Status,
+					Shipment:       shipment,
+				})
+			}
+		}
+	}
+	return trace, nil
+}
+
+// TraceSerial 按序列号查询所属订单
+func (s *SmartContract) TraceSerial(ctx contractapi.TransactionContextInterface, serialNo string) ([]*Order, error) {
+	orderIds, err := s.getIndexedIDs(ctx, SERIAL_INDEX, serialNo)
+	if err != nil {
+		return nil, err
+	}
+
+	orders := make([]*Order, 0, len(orderIds))
+	for _, orderId := range orderIds {
+		order, err := s.QueryOrder(ctx, orderId)
+		if err != nil {
+			return nil, err
+		}
+		orders = append(orders, order)
+	}
+	return orders, nil
+}
+
+// IssueRecall 平台方发布召回并标记受影响订单 (仅平台方可调用)
+func (s *SmartContract) IssueRecall(ctx contractapi.TransactionContextInterface, id string, batchNosJson string, reason string) error {
+	isPlatform, err := s.isPlatformIdentity(ctx)
+	if err != nil {
+		return err
+	}
+	if !isPlatform {
+		return fmt.Errorf("无权限: 仅限平台方发布召回")
+	}
+
+	existing, err := ctx.GetStub().GetState(id)
+	if err != nil {
+		return fmt.Errorf("读取召回单失败: %v", err)
+	}
+	if existing != nil {
+		return fmt.Errorf("召回单 %s 已存在", id)
+	}
+
+	var batchNos []string
+	if err := json.Unmarshal([]byte(batchNosJson), &batchNos); err != nil {
+		return fmt.Errorf("解析召回批次失败: %v", err)
+	}
+	if len(batchNos) == 0 {
+		return fmt.Errorf("召回批次不能为空")
+	}
+
+	now, err := s.getTxTimestamp(ctx)
+	if err != nil {
+		return err
+	}
+
+	affected := make([]string, 0)
+	for _, batchNo := range batchNos {
+		if err := s.putIndex(ctx, RECALL_BATCH_INDEX, batchNo, id); err != nil {
+			return err
+		}
+		orderIds, err := s.getIndexedIDs(ctx, BATCH_INDEX, batchNo)
+		if err != nil {
+			return err
+		}
+		for _, orderId := range orderIds {
+			if !containsString(affected, orderId) {
+				affected = append(affected, orderId)
+			}
+		}
+	}
+
+	// 标记受影响订单 (订单受状态背书约束, 需主机厂与零部件厂商节点背书)
+	for _, orderId := range affected {
+		order, err := s.QueryOrder(ctx, orderId)
+		if err != nil {
+			return err
+		}
+		order.RecallIDs = append(order.RecallIDs, id)
+		order.UpdateTime = now
+		orderBytes, err := json.Marshal(order)
+		if err != nil {
+			return fmt.Errorf("序列化订单失败: %v", err)
+		}
+		if err := ctx.GetStub().PutState(orderId, orderBytes); err != nil {
+			return err
+		}
+	}
+
+	recall := Recall{
+		ID:               id,
+		ObjectType:       RECALL,
+		IssuerID:         PLATFORM_ORG_MSPID,
+		BatchNos:         batchNos,
+		Reason:           reason,
+		AffectedOrderIDs: affected,
+		Status:           RECALL_OPEN,
+		CreateTime:       now,
+		UpdateTime:       now,
+	}
+	recallBytes, err := json.Marshal(recall)
+	if err != nil {
+		return fmt.Errorf("序列化召回单失败: %v", err)
+	}
+	return ctx.GetStub().PutState(id, recallBytes)
+}
+
+// CloseRecall 平台方关闭召回 (仅平台方可调用)
+func (s *SmartContract) CloseRecall(ctx contractapi.TransactionContextInterface, id string) error {
+	isPlatform, err := s.isPlatformIdentity(ctx)
+	if err != nil {
+		return err
+	}
+	if !isPlatform {
+		return fmt.Errorf("无权限: 仅限平台方关闭召回")
+	}
+
+	recall, err := s.QueryRecall(ctx, id)
+	if err != nil {
+		return err
+	}
+	if recall.Status != RECALL_OPEN {
+		return fmt.Errorf("召回单已关闭")
+	}
+
+	for _, batchNo := range recall.BatchNos {
+		key, err := ctx.GetStub().CreateCompositeKey(RECALL_BATCH_INDEX, []string{batchNo, id})
+		if err != nil {
+			return fmt.Errorf("创建索引键失败: %v", err)
+		}
+		if err := ctx.GetStub().DelState(key); err != nil {
+			return fmt.Errorf("删除召回批次索引失败: %v", err)
+		}
+	}
+
+	now, err := s.getTxTimestamp(ctx)
+	if err != nil {
+		return err
+	}
+	recall.Status = RECALL_CLOSED
+	recall.UpdateTime = now
+
+	recallBytes, err := json.Marshal(recall)
+	if err != nil {
+		return fmt.Errorf("序列化召回单失败: %v", err)
+	}
+	return ctx.GetStub().PutState(id, recallBytes)
+}
+
+// QueryRecall 查询召回单详情
+func (s *SmartContract) QueryRecall(ctx contractapi.TransactionContextInterface, id string) (*Recall, error) {
+	recallBytes, err := ctx.GetStub().GetState(id)
+	if err != nil {
+		return nil, fmt.Errorf("读取召回单失败: %v", err)
+	}
+	if recallBytes == nil {
+		return nil, fmt.Errorf("召回单 %s 不存在", id)
+	}
+
+	var recall Recall
+	if err := json.Unmarshal(recallBytes, &recall); err != nil || recall.ObjectType != RECALL {
+		return nil, fmt.Errorf("召回单 %s 不存在", id)
+	}
+	return &recall, nil
+}
+
+func (s *SmartContract) isBatchRecalled(ctx contractapi.TransactionContextInterface, batchNo string) (bool, error) {
+	recallIds, err := s.getIndexedIDs(ctx, RECALL_BATCH_INDEX, batchNo)
+	if err != nil {
+		return false, err
+	}
+	return len(recallIds) > 0, nil
+}
+
+// 写入复合键索引 (值为占位字节)
+func (s *SmartContract) putIndex(ctx contractapi.TransactionContextInterface, indexName string, attribute string, id string) error {
+	key, err := ctx.GetStub().CreateCompositeKey(indexName, []string{attribute, id})
+	if err != nil {
+		return fmt.Errorf("创建索引键失败: %v", err)
+	}
+	return ctx.GetStub().PutState(key, []byte{0x00})
+}
+
+// 读取复合键索引下的全部 ID
+func (s *SmartContract) getIndexedIDs(ctx contractapi.TransactionContextInterface, indexName string, attribute string) ([]string, error) {
+	resultsIterator, err := ctx.GetStub().GetStateByPartialCompositeKey(indexName, []string{attribute})
+	if err != nil {
+		return nil, fmt.Errorf("查询索引失败: %v", err)
+	}
+	defer resultsIterator.Close()
+
+	ids := make([]string, 0)
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return nil, err
+		}
+		_, attributes, err := ctx.GetStub().SplitCompositeKey(queryResponse.Key)
+		if err != nil {
+			return nil, fmt.Errorf("解析索引键失败: %v", err)
+		}
+		if len(attributes) == 2 {
+			ids = append(ids, attributes[1])
+		}
+	}
+	return ids, nil
+}
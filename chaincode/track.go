@@ -0,0 +1,133 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/v2/contractapi"
+)
+
+// 物流轨迹检查点 (复合键: CHECKPOINT~shipmentId~seq, 只追加不修改)
+const (
+	CHECKPOINT = "CHECKPOINT"
+)
+
+// CheckpointEvent 检查点事件类型
+type CheckpointEvent string
+
+const (
+	EVENT_DEPARTED CheckpointEvent = "DEPARTED" // 出发
+	EVENT_ARRIVED  CheckpointEvent = "ARRIVED"  // 到达
+	EVENT_CUSTOMS  CheckpointEvent = "CUSTOMS"  // 报关/清关
+	EVENT_DELAY    CheckpointEvent = "DELAY"    // 延误
+	EVENT_POSITION CheckpointEvent = "POSITION" // 途中位置更新
+)
+
+// Checkpoint 物流轨迹检查点
+type Checkpoint struct {
+	ShipmentID string          `json:"shipmentId"`          // 物流单ID
+	Seq        int             `json:"seq"`                 // 序号
+	EventType  CheckpointEvent `json:"eventType"`           // 事件类型
+	Latitude   *float64        `json:"latitude,omitempty"`  // 纬度 (未上报坐标时为空)
+	Longitude  *float64        `json:"longitude,omitempty"` // 经度
+	Place      string          `json:"place"`               // 地点名称
+	FenceID    string          `json:"fenceId,omitempty"`   // 自动识别的地理围栏ID
+	CarrierID  string          `json:"carrierId"`           // 记录承运商
+	Timestamp  time.Time       `json:"timestamp"`           // 记录时间
+}
+
+// RecordCheckpoint 当前保管承运商记录轨迹检查点 (仅 Org3 可调用)
+func (s *SmartContract) RecordCheckpoint(ctx contractapi.TransactionContextInterface, shipmentId string, eventType string, latitude float64, longitude float64, place string) error {
+	carrierID, err := s.getCarrierID(ctx)
+	if err != nil {
+		return err
+	}
+
+	shipment, err := s.QueryShipment(ctx, shipmentId)
+	if err != nil {
+		return err
+	}
+	if shipment.CustodianID != "" && shipment.CustodianID != carrierID {
+		return fmt.Errorf("无权限: 仅限当前保管承运商记录轨迹")
+	}
+
+	event := CheckpointEvent(eventType)
+	switch event {
+	case EVENT_DEPARTED, EVENT_ARRIVED, EVENT_CUSTOMS, EVENT_DELAY, EVENT_POSITION:
+	default:
+		return fmt.Errorf("无效的事件类型: %s", eventType)
+	}
+	if latitude < -90 || latitude > 90 || longitude < -180 || longitude > 180 {
+		return fmt.Errorf("经纬度超出范围: %f, %f", latitude, longitude)
+	}
+
+	now, err := s.getTxTimestamp(ctx)
+	if err != nil {
+		return err
+	}
+	if err := s.appendCheckpoint(ctx, shipment, Checkpoint{
+		EventType: event,
+		Latitude:  &latitude,
+		Longitude: &longitude,
+		Place:     place,
+		CarrierID: carrierID,
+		Timestamp: now,
+	}); err != nil {
+		return err
+	}
+
+	if place != "" {
+		shipment.Location = place
+	}
+	if err := s.evaluateGeofences(ctx, shipment, latitude, longitude, carrierID, now); err != nil {
+		return err
+	}
+	shipment.UpdateTime = now
+	return s.putShipment(ctx, shipment)
+}
+
+// QueryShipmentTrack 查询物流单完整轨迹 (按记录顺序)
+func (s *SmartContract) QueryShipmentTrack(ctx contractapi.TransactionContextInterface, shipmentId string) ([]*Checkpoint, error) {
+	resultsIterator, err := ctx.GetStub().GetStateByPartialCompositeKey(CHECKPOINT, []string{shipmentId})
+	if err != nil {
+		return nil, fmt.Errorf("读取物流轨迹失败: %v", err)
+	}
+	defer resultsIterator.Close()
+
+	track := make([]*Checkpoint, 0)
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return nil, err
+		}
+		var checkpoint Checkpoint
+		if err := json.Unmarshal(queryResponse.Value, &checkpoint); err != nil {
+			return nil, fmt.Errorf("解析轨迹检查点失败: %v", err)
+		}
+		track = append(track, &checkpoint)
+	}
+	return track, nil
+}
+
+// 追加检查点并递增物流单的检查点计数 (调用方负责写回物流单)
+func (s *SmartContract) appendCheckpoint(ctx contractapi.TransactionContextInterface, shipment *Shipment, checkpoint Checkpoint) error {
+	checkpoint.ShipmentID = shipment.ID
+	checkpoint.Seq = shipment.CheckpointCount
+
+	if err := s.putShipmentRecord(ctx, CHECKPOINT, shipment.ID, checkpoint.Seq, checkpoint); err != nil {
+		return err
+	}
+
+	shipment.CheckpointCount++
+	return nil
+}
+
+// 物流单附属记录键 objectType~shipmentId~seq, 序号补零保证复合键按字典序即按记录顺序
+func shipmentRecordKey(ctx contractapi.TransactionContextInterface, objectType string, shipmentId string, seq int) (string, error) {
+	key, err := ctx.GetStub().CreateCompositeKey(objectType, []string{shipmentId, fmt.Sprintf("%08d", seq)})
+	if err != nil {
+		return "", fmt.Errorf("创建记录键失败: %v", err)
+	}
+	return key, nil
+}
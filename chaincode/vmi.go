@@ -0,0 +1,538 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/v2/contractapi"
+)
+
+// 供应商管理库存 (VMI) / 寄售库存资产类型
+const (
+	INVENTORY   = "INVENTORY"   // 寄售库存 (复合键: INVENTORY~location~partNumber~ownerId)
+	CONSUMPTION = "CONSUMPTION" // 消耗报告
+)
+
+// InventoryLot 寄售库存批次 (按入库先后排列, 消耗先进先出)
+type InventoryLot struct {
+	OrderID   string    `json:"orderId"`   // 来源订单
+	LineIndex int       `json:"lineIndex"` // 来源订单零件行序号
+	Remaining int       `json:"remaining"` // 剩余数量
+	InTime    time.Time `json:"inTime"`    // 入库时间
+}
+
+// ReplenishmentSuggestion 补货建议 (在库加在途低于最低库存时生成, 补至最高库存)
+type ReplenishmentSuggestion struct {
+	Quantity   int       `json:"quantity"`   // 建议补货数量
+	CreateTime time.Time `json:"createTime"` // 生成时间
+}
+
+// Inventory 寄售库存: 货权属于厂商, 实物存放于主机厂工厂
+type Inventory struct {
+	ObjectType    string                   `json:"objectType"`              // 资产类型 (INVENTORY)
+	PartNumber    string                   `json:"partNumber"`              // 零件号
+	Location      string                   `json:"location"`                // 存放地点 (主机厂工厂/库位)
+	OwnerID       string                   `json:"ownerId"`                 // 货权方 (厂商业务ID, 与订单 manufacturerId 一致)
+	OwnerMSPID    string                   `json:"ownerMspId"`              // 货权方映射的 MSP ID
+	HolderID      string                   `json:"holderId"`                // 保管方 (主机厂)
+	OnHand        int                      `json:"onHand"`                  // 在库数量
+	OnOrder       int                      `json:"onOrder"`                 // 在途数量 (已指定入该库存、尚未签收的订单数量)
+	MinLevel      int                      `json:"minLevel"`                // 最低库存
+	MaxLevel      int                      `json:"maxLevel"`                // 最高库存
+	Lots          []InventoryLot           `json:"lots"`                    // 在库批次
+	Replenishment *ReplenishmentSuggestion `json:"replenishment,omitempty"` // 当前补货建议
+	UpdateTime    time.Time                `json:"updateTime"`              // 更新时间
+}
+
+// ConsumptionAllocation 消耗分摊到来源订单行
+type ConsumptionAllocation struct {
+	OrderID   string `json:"orderId"`   // 来源订单
+	LineIndex int    `json:"lineIndex"` // 订单零件行序号
+	Quantity  int    `json:"quantity"`  // 消耗数量
+}
+
+// Consumption 主机厂消耗报告 (消耗即转移货权并按来源订单自动开票)
+type Consumption struct {
+	ID          string                  `json:"id"`          // 消耗报告ID
+	ObjectType  string                  `json:"objectType"`  // 资产类型 (CONSUMPTION)
+	PartNumber  string                  `json:"partNumber"`  // 零件号
+	Location    string                  `json:"location"`    // 存放地点
+	OwnerID     string                  `json:"ownerId"`     // 货权方 (厂商)
+	HolderID    string                  `json:"holderId"`    // 保管方 (主机厂)
+	Quantity    int                     `json:"quantity"`    // 消耗数量
+	Allocations []ConsumptionAllocation `json:"allocations"` // 先进先出分摊结果
+	InvoiceIDs  []string                `json:"invoiceIds"`  // 自动生成的发票
+	CreateTime  time.Time               `json:"createTime"`  // 报告时间
+}
+
+// CreateInventory 厂商在主机厂工厂开设寄售库存并设置最低/最高库存 (仅厂商映射的 MSP 可调用)
+// 库存按厂商业务ID登记, 与订单的 manufacturerId 对应
+func (s *SmartContract) CreateInventory(ctx contractapi.TransactionContextInterface, manufacturerId string, partNumber string, location string, minLevel int, maxLevel int) error {
+	clientMSPID, err := s.getClientIdentityMSPID(ctx)
+	if err != nil {
+		return err
+	}
+	mspID, err := s.resolveManufacturerMSPID(ctx, manufacturerId)
+	if err != nil {
+		return err
+	}
+	if clientMSPID != mspID {
+		return fmt.Errorf("无权限: 仅限厂商 %s 开设寄售库存", manufacturerId)
+	}
+	if location == "" {
+		return fmt.Errorf("存放地点不能为空")
+	}
+	if err := validateInventoryLevels(minLevel, maxLevel); err != nil {
+		return err
+	}
+
+	part, err := s.QueryPart(ctx, partNumber)
+	if err != nil {
+		return err
+	}
+	if len(part.ApprovedManufacturers) > 0 && !containsString(part.ApprovedManufacturers, manufacturerId) {
+		return fmt.Errorf("厂商 %s 不是零件 %s 的合格供应商", manufacturerId, partNumber)
+	}
+	existing, err := s.getInventory(ctx, location, partNumber, manufacturerId)
+	if err != nil {
+		return err
+	}
+	if existing != nil {
+		return fmt.Errorf("地点 %s 已存在零件 %s 的寄售库存", location, partNumber)
+	}
+
+	now, err := s.getTxTimestamp(ctx)
+	if err != nil {
+		return err
+	}
+	inventory := &Inventory{
+		ObjectType: INVENTORY,
+		PartNumber: partNumber,
+		Location:   location,
+		OwnerID:    manufacturerId,
+		OwnerMSPID: mspID,
+		HolderID:   OEM_ORG_MSPID,
+		MinLevel:   minLevel,
+		MaxLevel:   maxLevel,
+		Lots:       []InventoryLot{},
+	}
+	if err := s.putInventory(ctx, inventory, now); err != nil {
+		return err
+	}
+	key, err := inventoryKey(ctx, location, partNumber, manufacturerId)
+	if err != nil {
+		return err
+	}
+	return s.setEndorsementPolicy(ctx, key, OEM_ORG_MSPID, mspID)
+}
+
+// SetInventoryLevels 调整最低/最高库存 (货权方或保管方均可调用, 修改须双方背书)
+func (s *SmartContract) SetInventoryLevels(ctx contractapi.TransactionContextInterface, partNumber string, location string, ownerId string, minLevel int, maxLevel int) error {
+	clientMSPID, err := s.getClientIdentityMSPID(ctx)
+	if err != nil {
+		return err
+	}
+	if err := validateInventoryLevels(minLevel, maxLevel); err != nil {
+		return err
+	}
+
+	inventory, err := s.QueryInventory(ctx, partNumber, location, ownerId)
+	if err != nil {
+		return err
+	}
+	if clientMSPID != inventory.OwnerMSPID && clientMSPID != inventory.HolderID {
+		return fmt.Errorf("无权限: 仅限货权方或保管方调整库存水位")
+	}
+
+	now, err := s.getTxTimestamp(ctx)
+	if err != nil {
+		return err
+	}
+	inventory.MinLevel = minLevel
+	inventory.MaxLevel = maxLevel
+	return s.putInventory(ctx, inventory, now)
+}
+
+// SetOrderConsignment 主机厂指定订单签收后入寄售库存, 该订单不再整单开票, 改为按消耗开票 (签收前调用)
+func (s *SmartContract) SetOrderConsignment(ctx contractapi.TransactionContextInterface, orderId string, location string) error {
+	clientMSPID, err := s.getClientIdentityMSPID(ctx)
+	if err != nil {
+		return err
+	}
+
+	order, err := s.QueryOrder(ctx, orderId)
+	if err != nil {
+		return err
+	}
+	if clientMSPID != order.OEMID || clientMSPID != OEM_ORG_MSPID {
+		return fmt.Errorf("无权限: 仅限主机厂指定寄售入库")
+	}
+	if rank, ok := orderStatusRank[order.Status]; !ok || rank >= orderStatusRank[ORDER_RECEIVED] {
+		return fmt.Errorf("订单当前状态 %s 无法指定寄售入库, 须签收前指定", order.Status)
+	}
+	if order.ConsignmentLocation != "" {
+		return fmt.Errorf("订单 %s 已指定寄售地点 %s", orderId, order.ConsignmentLocation)
+	}
+
+	now, err := s.getTxTimestamp(ctx)
+	if err != nil {
+		return err
+	}
+	for _, item := range order.Items {
+		inventory, err := s.QueryInventory(ctx, item.PartNumber, location, order.ManufacturerID)
+		if err != nil {
+			return err
+		}
+		inventory.OnOrder += item.Quantity
+		if err := s.putInventory(ctx, inventory, now); err != nil {
+			return err
+		}
+	}
+
+	order.ConsignmentLocation = location
+	order.UpdateTime = now
+	orderBytes, err := json.Marshal(order)
+	if err != nil {
+		return fmt.Errorf("序列化订单失败: %v", err)
+	}
+	return ctx.GetStub().PutState(orderId, orderBytes)
+}
+
+// ReportConsumption 主机厂报告寄售库存消耗: 扣减库存并按来源订单单价自动开具已匹配的发票 (仅保管方可调用)
+func (s *SmartContract) ReportConsumption(ctx contractapi.TransactionContextInterface, id string, partNumber string, location string, ownerId string, quantity int) error {
+	clientMSPID, err := s.getClientIdentityMSPID(ctx)
+	if err != nil {
+		return err
+	}
+	if quantity <= 0 {
+		return fmt.Errorf("消耗数量必须大于 0")
+	}
+
+	inventory, err := s.QueryInventory(ctx, partNumber, location, ownerId)
+	if err != nil {
+		return err
+	}
+	if clientMSPID != inventory.HolderID {
+		return fmt.Errorf("无权限: 仅限保管方报告消耗")
+	}
+	if quantity > inventory.OnHand {
+		return fmt.Errorf("消耗数量 %d 超过在库数量 %d", quantity, inventory.OnHand)
+	}
+	existing, err := ctx.GetStub().GetState(id)
+	if err != nil {
+		return fmt.Errorf("读取消耗报告失败: %v", err)
+	}
+	if existing != nil {
+		return fmt.Errorf("消耗报告 %s 已存在", id)
+	}
+
+	// 先进先出扣减批次
+	allocations := make([]ConsumptionAllocation, 0)
+	remaining := quantity
+	lots := make([]InventoryLot, 0, len(inventory.Lots))
+	for _, lot := range inventory.Lots {
+		if remaining > 0 {
+			take := lot.Remaining
+			if take > remaining {
+				take = remaining
+			}
+			allocations = append(allocations, ConsumptionAllocation{OrderID: lot.OrderID, LineIndex: lot.LineIndex, Quantity: take})
+			lot.Remaining -= take
+			remaining -= take
+		}
+		if lot.Remaining > 0 {
+			lots = append(lots, lot)
+		}
+	}
+	if remaining > 0 {
+		return fmt.Errorf("寄售库存批次数量与在库数量不一致")
+	}
+
+	now, err := s.getTxTimestamp(ctx)
+	if err != nil {
+		return err
+	}
+	invoiceIds, err := s.invoiceConsumption(ctx, id, allocations, now)
+	if err != nil {
+		return err
+	}
+
+	inventory.Lots = lots
+	inventory.OnHand -= quantity
+	if err := s.putInventory(ctx, inventory, now); err != nil {
+		return err
+	}
+
+	consumption := &Consumption{
+		ID:          id,
+		ObjectType:  CONSUMPTION,
+		PartNumber:  partNumber,
+		Location:    location,
+		OwnerID:     inventory.OwnerID,
+		HolderID:    inventory.HolderID,
+		Quantity:    quantity,
+		Allocations: allocations,
+		InvoiceIDs:  invoiceIds,
+		CreateTime:  now,
+	}
+	consumptionBytes, err := json.Marshal(consumption)
+	if err != nil {
+		return fmt.Errorf("序列化消耗报告失败: %v", err)
+	}
+	if err := ctx.GetStub().PutState(id, consumptionBytes); err != nil {
+		return err
+	}
+	return s.setEndorsementPolicy(ctx, id, inventory.HolderID, inventory.OwnerMSPID)
+}
+
+// QueryInventory 查询寄售库存
+func (s *SmartContract) QueryInventory(ctx contractapi.TransactionContextInterface, partNumber string, location string, ownerId string) (*Inventory, error) {
+	inventory, err := s.getInventory(ctx, location, partNumber, ownerId)
+	if err != nil {
+		return nil, err
+	}
+	if inventory == nil {
+		return nil, fmt.Errorf("地点 %s 不存在厂商 %s 的零件 %s 寄售库存", location, ownerId, partNumber)
+	}
+	return inventory, nil
+}
+
+// QueryLocationInventory 查询某地点的全部寄售库存
+func (s *SmartContract) QueryLocationInventory(ctx contractapi.TransactionContextInterface, location string) ([]*Inventory, error) {
+	resultsIterator, err := ctx.GetStub().GetStateByPartialCompositeKey(INVENTORY, []string{location})
+	if err != nil {
+		return nil, fmt.Errorf("查询寄售库存失败: %v", err)
+	}
+	defer resultsIterator.Close()
+
+	inventories := make([]*Inventory, 0)
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return nil, err
+		}
+		var inventory Inventory
+		if err := json.Unmarshal(queryResponse.Value, &inventory); err != nil {
+			return nil, fmt.Errorf("解析寄售库存失败: %v", err)
+		}
+		inventories = append(inventories, &inventory)
+	}
+	return inventories, nil
+}
+
+// QueryReplenishmentSuggestions 查询某地点存在补货建议的寄售库存
+func (s *SmartContract) QueryReplenishmentSuggestions(ctx contractapi.TransactionContextInterface, location string) ([]*Inventory, error) {
+	inventories, err := s.QueryLocationInventory(ctx, location)
+	if err != nil {
+		return nil, err
+	}
+	suggestions := make([]*Inventory, 0)
+	for _, inventory := range inventories {
+		if inventory.Replenishment != nil {
+			suggestions = append(suggestions, inventory)
+		}
+	}
+	return suggestions, nil
+}
+
+// QueryConsumption 查询消耗报告
+func (s *SmartContract) QueryConsumption(ctx contractapi.TransactionContextInterface, id string) (*Consumption, error) {
+	consumptionBytes, err := ctx.GetStub().GetState(id)
+	if err != nil {
+		return nil, fmt.Errorf("读取消耗报告失败: %v", err)
+	}
+	if consumptionBytes == nil {
+		return nil, fmt.Errorf("消耗报告 %s 不存在", id)
+	}
+
+	var consumption Consumption
+	if err := json.Unmarshal(consumptionBytes, &consumption); err != nil {
+		return nil, fmt.Errorf("解析消耗报告失败: %v", err)
+	}
+	if consumption.ObjectType != CONSUMPTION {
+		return nil, fmt.Errorf("消耗报告 %s 不存在", id)
+	}
+	return &consumption, nil
+}
+
+// 寄售订单签收入库: 在途转在库, 按实收数量登记批次 (每个订单仅入库一次)
+func (s *SmartContract) receiveConsignment(ctx contractapi.TransactionContextInterface, order *Order, now time.Time) error {
+	if order.ConsignmentReceived {
+		return nil
+	}
+	for i, item := range order.Items {
+		inventory, err := s.QueryInventory(ctx, item.PartNumber, order.ConsignmentLocation, order.ManufacturerID)
+		if err != nil {
+			return err
+		}
+		inventory.OnOrder -= item.Quantity
+		if inventory.OnOrder < 0 {
+			inventory.OnOrder = 0
+		}
+		if item.ReceivedQuantity > 0 {
+			inventory.OnHand += item.ReceivedQuantity
+			inventory.Lots = append(inventory.Lots, InventoryLot{
+				OrderID:   order.ID,
+				LineIndex: i,
+				Remaining: item.ReceivedQuantity,
+				InTime:    now,
+			})
+		}
+		if err := s.putInventory(ctx, inventory, now); err != nil {
+			return err
+		}
+	}
+	order.ConsignmentReceived = true
+	return nil
+}
+
+// 按来源订单为消耗自动开票: 单价取订单私有单价, 每个来源订单一张发票, 直接视为匹配通过
+func (s *SmartContract) invoiceConsumption(ctx contractapi.TransactionContextInterface, consumptionId string, allocations []ConsumptionAllocation, now time.Time) ([]string, error) {
+	orderIds := make([]string, 0)
+	quantitiesByOrder := make(map[string][]int)
+	for _, allocation := range allocations {
+		quantities, ok := quantitiesByOrder[allocation.OrderID]
+		if !ok {
+			order, err := s.QueryOrder(ctx, allocation.OrderID)
+			if err != nil {
+				return nil, err
+			}
+			quantities = make([]int, len(order.Items))
+			orderIds = append(orderIds, allocation.OrderID)
+		}
+		quantities[allocation.LineIndex] += allocation.Quantity
+		quantitiesByOrder[allocation.OrderID] = quantities
+	}
+
+	invoiceIds := make([]string, 0, len(orderIds))
+	for _, orderId := range orderIds {
+		order, err := s.QueryOrder(ctx, orderId)
+		if err != nil {
+			return nil, err
+		}
+		orderPrice, err := s.QueryOrderPrice(ctx, orderId)
+		if err != nil {
+			return nil, err
+		}
+
+		invoiceId := consumptionId + "-" + orderId
+		quantities := quantitiesByOrder[orderId]
+		// 随机盐由订单私有盐派生, 避免公开可推导的盐被用于穷举金额
+		salt := sha256.Sum256([]byte(orderPrice.Salt + invoiceId))
+		amount := &InvoiceAmount{
+			InvoiceID:  invoiceId,
+			UnitPrices: orderPrice.ItemPrices,
+			Salt:       hex.EncodeToString(salt[:]),
+		}
+		results := make([]InvoiceLineMatch, len(order.Items))
+		for i, item := range order.Items {
+			amount.TotalAmount += float64(quantities[i]) * orderPrice.ItemPrices[i]
+			results[i] = InvoiceLineMatch{
+				PartNumber:       item.PartNumber,
+				OrderedQuantity:  item.Quantity,
+				ReceivedQuantity: item.ReceivedQuantity,
+				InvoicedQuantity: quantities[i],
+				QuantityMatched:  true,
+				PriceMatched:     true,
+			}
+		}
+
+		amountKey, err := ctx.GetStub().CreateCompositeKey(INVOICE, []string{invoiceId})
+		if err != nil {
+			return nil, fmt.Errorf("创建发票键失败: %v", err)
+		}
+		amountBytes, err := json.Marshal(amount)
+		if err != nil {
+			return nil, fmt.Errorf("序列化发票金额失败: %v", err)
+		}
+		if err := ctx.GetStub().PutPrivateData(ORDER_PRICE_COLLECTION, amountKey, amountBytes); err != nil {
+			return nil, fmt.Errorf("写入发票金额失败: %v", err)
+		}
+		amountHash := sha256.Sum256(amountBytes)
+
+		invoice := &Invoice{
+			ID:             invoiceId,
+			ObjectType:     INVOICE,
+			OrderID:        orderId,
+			OEMID:          order.OEMID,
+			ManufacturerID: order.ManufacturerID,
+			Quantities:     quantities,
+			AmountHash:     hex.EncodeToString(amountHash[:]),
+			Status:         INVOICE_MATCHED,
+			MatchResults:   results,
+			ConsumptionID:  consumptionId,
+			CreateTime:     now,
+			UpdateTime:     now,
+		}
+		if err := s.putInvoice(ctx, invoice); err != nil {
+			return nil, err
+		}
+		if err := s.setEndorsementPolicy(ctx, invoiceId, order.OEMID, order.ManufacturerMSPID); err != nil {
+			return nil, err
+		}
+		invoiceIds = append(invoiceIds, invoiceId)
+	}
+	return invoiceIds, nil
+}
+
+// 写入库存并刷新补货建议: 在库加在途不高于最低库存时建议补至最高库存
+func (s *SmartContract) putInventory(ctx contractapi.TransactionContextInterface, inventory *Inventory, now time.Time) error {
+	available := inventory.OnHand + inventory.OnOrder
+	if available <= inventory.MinLevel && inventory.MaxLevel > available {
+		quantity := inventory.MaxLevel - available
+		if inventory.Replenishment == nil || inventory.Replenishment.Quantity != quantity {
+			inventory.Replenishment = &ReplenishmentSuggestion{Quantity: quantity, CreateTime: now}
+		}
+	} else {
+		inventory.Replenishment = nil
+	}
+	inventory.UpdateTime = now
+
+	key, err := inventoryKey(ctx, inventory.Location, inventory.PartNumber, inventory.OwnerID)
+	if err != nil {
+		return err
+	}
+	inventoryBytes, err := json.Marshal(inventory)
+	if err != nil {
+		return fmt.Errorf("序列化寄售库存失败: %v", err)
+	}
+	return ctx.GetStub().PutState(key, inventoryBytes)
+}
+
+func (s *SmartContract) getInventory(ctx contractapi.TransactionContextInterface, location string, partNumber string, ownerId string) (*Inventory, error) {
+	key, err := inventoryKey(ctx, location, partNumber, ownerId)
+	if err != nil {
+		return nil, err
+	}
+	inventoryBytes, err := ctx.GetStub().GetState(key)
+	if err != nil {
+		return nil, fmt.Errorf("读取寄售库存失败: %v", err)
+	}
+	if inventoryBytes == nil {
+		return nil, nil
+	}
+
+	var inventory Inventory
+	if err := json.Unmarshal(inventoryBytes, &inventory); err != nil {
+		return nil, fmt.Errorf("解析寄售库存失败: %v", err)
+	}
+	return &inventory, nil
+}
+
+func inventoryKey(ctx contractapi.TransactionContextInterface, location string, partNumber string, ownerId string) (string, error) {
+	key, err := ctx.GetStub().CreateCompositeKey(INVENTORY, []string{location, partNumber, ownerId})
+	if err != nil {
+		return "", fmt.Errorf("创建寄售库存键失败: %v", err)
+	}
+	return key, nil
+}
+
+func validateInventoryLevels(minLevel int, maxLevel int) error {
+	if minLevel < 0 || maxLevel <= minLevel {
+		return fmt.Errorf("库存水位无效: 最低库存须不小于 0 且小于最高库存")
+	}
+	return nil
+}
@@ -0,0 +1,363 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/hyperledger/fabric-chaincode-go/v2/pkg/statebased"
+	"github.com/hyperledger/fabric-contract-api-go/v2/contractapi"
+)
+
+// 数字运单 (复合键: WAYBILL~status~id, 状态变更时删除旧键写入新键)
+const (
+	WAYBILL       = "WAYBILL"
+	WAYBILL_ORDER = "WAYBILL_ORDER" // 复合键: WAYBILL_ORDER~orderId~waybillId
+)
+
+// WaybillStatus 运单状态
+type WaybillStatus string
+
+const (
+	WAYBILL_PENDING WaybillStatus = "PENDING" // 待承运方确认
+	WAYBILL_ACTIVE  WaybillStatus = "ACTIVE"  // 已生效
+)
+
+// Waybill 数字运单
+type Waybill struct {
+	ID           string        `json:"id"`           // 运单唯一标识 (BillOfLadingID)
+	ObjectType   string        `json:"objectType"`   // 资产类型 (WAYBILL)
+	OrderID      string        `json:"orderId"`      // 关联订单ID
+	ShipmentID   string        `json:"shipmentId"`   // 关联物流单ID (订单已取货时)
+	Sender       string        `json:"sender"`       // 发货方 (核心企业)
+	CarrierID    string        `json:"carrierId"`    // 承运方标识
+	HolderID     string        `json:"holderId"`     // 当前持有人 (签发电子提单前可转让, 此后货权随提单转移)
+	Receiver     string        `json:"receiver"`     // 收货方信息
+	CargoDetails string        `json:"cargoDetails"` // 货物详情
+	CargoValue   float64       `json:"cargoValue"`   // 申报价值
+	Hash         string        `json:"hash"`         // 运单内容哈希 (SHA-256), 运单键由平台方参与背书
+	Status       WaybillStatus `json:"status"`       // 当前状态
+	CreateTime   time.Time     `json:"createTime"`   // 签发时间
+	UpdateTime   time.Time     `json:"updateTime"`   // 最后变更时间
+}
+
+// waybillContent 参与内容哈希的运单字段 (字段顺序固定, 保证哈希确定)
+type waybillContent struct {
+	ID           string  `json:"id"`
+	OrderID      string  `json:"orderId"`
+	Sender       string  `json:"sender"`
+	CarrierID    string  `json:"carrierId"`
+	Receiver     string  `json:"receiver"`
+	CargoDetails string  `json:"cargoDetails"`
+	CargoValue   float64 `json:"cargoValue"`
+}
+
+// CreateWaybill 核心企业签发数字运单 (仅 Org1 可调用)
+func (s *SmartContract) CreateWaybill(ctx contractapi.TransactionContextInterface, waybillJson string) error {
+	clientMSPID, err := s.getClientIdentityMSPID(ctx)
+	if err != nil {
+		return err
+	}
+	if clientMSPID != OEM_ORG_MSPID {
+		return fmt.Errorf("无权限: 仅限核心企业签发运单")
+	}
+
+	var waybill Waybill
+	if err := json.Unmarshal([]byte(waybillJson), &waybill); err != nil {
+		return fmt.Errorf("解析运单失败: %v", err)
+	}
+	if waybill.ID == "" || waybill.CarrierID == "" || waybill.Receiver == "" || waybill.CargoDetails == "" {
+		return fmt.Errorf("运单ID、承运方、收货方与货物详情不能为空")
+	}
+	if waybill.CargoValue < 0 {
+		return fmt.Errorf("申报价值不能为负数")
+	}
+
+	existing, err := s.findWaybill(ctx, waybill.ID)
+	if err != nil {
+		return err
+	}
+	if existing != nil {
+		return fmt.Errorf("运单 %s 已存在", waybill.ID)
+	}
+
+	order, err := s.QueryOrder(ctx, waybill.OrderID)
+	if err != nil {
+		return err
+	}
+	if order.OEMID != clientMSPID {
+		return fmt.Errorf("无权限: 仅限订单采购方签发运单")
+	}
+
+	now, err := s.getTxTimestamp(ctx)
+	if err != nil {
+		return err
+	}
+	waybill.ObjectType = WAYBILL
+	waybill.ShipmentID = order.ShipmentID
+	waybill.Sender = clientMSPID
+	waybill.HolderID = clientMSPID
+	waybill.Status = WAYBILL_PENDING
+	waybill.CreateTime = now
+	waybill.UpdateTime = now
+	waybill.Hash, err = computeWaybillHash(&waybill)
+	if err != nil {
+		return err
+	}
+	if err := s.putWaybill(ctx, &waybill); err != nil {
+		return err
+	}
+
+	indexKey, err := ctx.GetStub().CreateCompositeKey(WAYBILL_ORDER, []string{waybill.OrderID, waybill.ID})
+	if err != nil {
+		return fmt.Errorf("创建运单索引失败: %v", err)
+	}
+	return ctx.GetStub().PutState(indexKey, []byte{0x00})
+}
+
+// ConfirmWaybill 承运方确认运单, 运单生效 (仅运单指定的承运商可调用)
+func (s *SmartContract) ConfirmWaybill(ctx contractapi.TransactionContextInterface, id string) error {
+	carrierID, err := s.getCarrierID(ctx)
+	if err != nil {
+		return err
+	}
+
+	waybill, err := s.QueryWaybill(ctx, id)
+	if err != nil {
+		return err
+	}
+	if waybill.Status != WAYBILL_PENDING {
+		return fmt.Errorf("运单当前状态 %s 无法确认", waybill.Status)
+	}
+	if waybill.CarrierID != carrierID {
+		return fmt.Errorf("无权限: 运单承运方为 %s, 当前调用者为 %s", waybill.CarrierID, carrierID)
+	}
+
+	if waybill.ShipmentID == "" {
+		order, err := s.QueryOrder(ctx, waybill.OrderID)
+		if err != nil {
+			return err
+		}
+		waybill.ShipmentID = order.ShipmentID
+	}
+
+	now, err := s.getTxTimestamp(ctx)
+	if err != nil {
+		return err
+	}
+	return s.moveWaybill(ctx, waybill, WAYBILL_ACTIVE, now)
+}
+
+// TransferWaybill 当前持有人将已生效运单转让给新持有人 (订单已签发电子提单后不可转让, 货权改由提单背书转移)
+func (s *SmartContract) TransferWaybill(ctx contractapi.TransactionContextInterface, id string, newHolderId string) error {
+	partyID, err := s.getPartyID(ctx)
+	if err != nil {
+		return err
+	}
+
+	waybill, err := s.QueryWaybill(ctx, id)
+	if err != nil {
+		return err
+	}
+	if waybill.HolderID != partyID {
+		return fmt.Errorf("无权限: 仅限当前持有人 %s 转让运单", waybill.HolderID)
+	}
+	if waybill.Status != WAYBILL_ACTIVE {
+		return fmt.Errorf("运单当前状态 %s 无法转让, 须承运方确认生效", waybill.Status)
+	}
+	if newHolderId == "" || newHolderId == waybill.HolderID {
+		return fmt.Errorf("新持有人不能为空且不能与当前持有人相同")
+	}
+	eblId, err := s.getOrderBillOfLadingID(ctx, waybill.OrderID)
+	if err != nil {
+		return err
+	}
+	if eblId != "" {
+		return fmt.Errorf("订单 %s 已签发电子提单 %s, 须通过提单背书转让", waybill.OrderID, eblId)
+	}
+
+	now, err := s.getTxTimestamp(ctx)
+	if err != nil {
+		return err
+	}
+	waybill.HolderID = newHolderId
+	waybill.UpdateTime = now
+	return s.putWaybill(ctx, waybill)
+}
+
+// QueryWaybill 查询运单 (遍历各状态前缀)
+func (s *SmartContract) QueryWaybill(ctx contractapi.TransactionContextInterface, id string) (*Waybill, error) {
+	waybill, err := s.findWaybill(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if waybill == nil {
+		return nil, fmt.Errorf("运单 %s 不存在", id)
+	}
+	return waybill, nil
+}
+
+// QueryWaybillList 分页查询运单列表, status 为空时返回全部状态
+func (s *SmartContract) QueryWaybillList(ctx contractapi.TransactionContextInterface, pageSize int32, bookmark string, status string) (*QueryResponse, error) {
+	attributes := []string{}
+	if status != "" {
+		attributes = append(attributes, status)
+	}
+	resultsIterator, responseMetadata, err := ctx.GetStub().GetStateByPartialCompositeKeyWithPagination(WAYBILL, attributes, pageSize, bookmark)
+	if err != nil {
+		return nil, fmt.Errorf("读取运单失败: %v", err)
+	}
+	defer resultsIterator.Close()
+
+	records := make([]interface{}, 0)
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return nil, err
+		}
+		var waybill Waybill
+		if err := json.Unmarshal(queryResponse.Value, &waybill); err != nil {
+			return nil, fmt.Errorf("解析运单失败: %v", err)
+		}
+		records = append(records, waybill)
+	}
+
+	return &QueryResponse{
+		Records:             records,
+		RecordsCount:        int32(len(records)),
+		Bookmark:            responseMetadata.Bookmark,
+		FetchedRecordsCount: responseMetadata.FetchedRecordsCount,
+	}, nil
+}
+
+// QueryOrderWaybills 查询订单关联的运单
+func (s *SmartContract) QueryOrderWaybills(ctx contractapi.TransactionContextInterface, orderId string) ([]*Waybill, error) {
+	resultsIterator, err := ctx.GetStub().GetStateByPartialCompositeKey(WAYBILL_ORDER, []string{orderId})
+	if err != nil {
+		return nil, fmt.Errorf("读取运单索引失败: %v", err)
+	}
+	defer resultsIterator.Close()
+
+	waybills := make([]*Waybill, 0)
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return nil, err
+		}
+		_, keyParts, err := ctx.GetStub().SplitCompositeKey(queryResponse.Key)
+		if err != nil {
+			return nil, fmt.Errorf("解析运单索引失败: %v", err)
+		}
+		waybill, err := s.QueryWaybill(ctx, keyParts[1])
+		if err != nil {
+			return nil, err
+		}
+		waybills = append(waybills, waybill)
+	}
+	return waybills, nil
+}
+
+// VerifyWaybill 按运单当前内容重算哈希, 校验是否与签发时一致
+func (s *SmartContract) VerifyWaybill(ctx contractapi.TransactionContextInterface, id string) (bool, error) {
+	waybill, err := s.QueryWaybill(ctx, id)
+	if err != nil {
+		return false, err
+	}
+	hash, err := computeWaybillHash(waybill)
+	if err != nil {
+		return false, err
+	}
+	return hash == waybill.Hash, nil
+}
+
+func computeWaybillHash(waybill *Waybill) (string, error) {
+	contentBytes, err := json.Marshal(waybillContent{
+		ID:           waybill.ID,
+		OrderID:      waybill.OrderID,
+		Sender:       waybill.Sender,
+		CarrierID:    waybill.CarrierID,
+		Receiver:     waybill.Receiver,
+		CargoDetails: waybill.CargoDetails,
+		CargoValue:   waybill.CargoValue,
+	})
+	if err != nil {
+		return "", fmt.Errorf("序列化运单内容失败: %v", err)
+	}
+	hash := sha256.Sum256(contentBytes)
+	return hex.EncodeToString(hash[:]), nil
+}
+
+// 在各状态前缀下查找运单, 不存在时返回 nil
+func (s *SmartContract) findWaybill(ctx contractapi.TransactionContextInterface, id string) (*Waybill, error) {
+	for _, status := range waybillStatuses {
+		key, err := ctx.GetStub().CreateCompositeKey(WAYBILL, []string{string(status), id})
+		if err != nil {
+			return nil, fmt.Errorf("创建运单键失败: %v", err)
+		}
+		waybillBytes, err := ctx.GetStub().GetState(key)
+		if err != nil {
+			return nil, fmt.Errorf("读取运单失败: %v", err)
+		}
+		if waybillBytes == nil {
+			continue
+		}
+
+		var waybill Waybill
+		if err := json.Unmarshal(waybillBytes, &waybill); err != nil {
+			return nil, fmt.Errorf("解析运单失败: %v", err)
+		}
+		return &waybill, nil
+	}
+	return nil, nil
+}
+
+// 运单的全部状态前缀
+var waybillStatuses = []WaybillStatus{WAYBILL_PENDING, WAYBILL_ACTIVE}
+
+// 变更运单状态: 删除旧状态键, 写入新状态键
+func (s *SmartContract) moveWaybill(ctx contractapi.TransactionContextInterface, waybill *Waybill, status WaybillStatus, now time.Time) error {
+	oldKey, err := ctx.GetStub().CreateCompositeKey(WAYBILL, []string{string(waybill.Status), waybill.ID})
+	if err != nil {
+		return fmt.Errorf("创建运单键失败: %v", err)
+	}
+	if err := ctx.GetStub().DelState(oldKey); err != nil {
+		return fmt.Errorf("删除运单旧状态失败: %v", err)
+	}
+
+	waybill.Status = status
+	waybill.UpdateTime = now
+	return s.putWaybill(ctx, waybill)
+}
+
+// 写入运单并设置状态背书策略 (需核心企业与平台方背书, 平台方对运单内容哈希背书)
+func (s *SmartContract) putWaybill(ctx contractapi.TransactionContextInterface, waybill *Waybill) error {
+	key, err := ctx.GetStub().CreateCompositeKey(WAYBILL, []string{string(waybill.Status), waybill.ID})
+	if err != nil {
+		return fmt.Errorf("创建运单键失败: %v", err)
+	}
+	waybillBytes, err := json.Marshal(waybill)
+	if err != nil {
+		return fmt.Errorf("序列化运单失败: %v", err)
+	}
+	if err := ctx.GetStub().PutState(key, waybillBytes); err != nil {
+		return err
+	}
+
+	ep, err := statebased.NewStateEP(nil)
+	if err != nil {
+		return fmt.Errorf("创建背书策略失败: %v", err)
+	}
+	if err := ep.AddOrgs(statebased.RoleTypePeer, OEM_ORG_MSPID, PLATFORM_ORG_MSPID); err != nil {
+		return fmt.Errorf("添加背书组织失败: %v", err)
+	}
+	policy, err := ep.Policy()
+	if err != nil {
+		return fmt.Errorf("生成背书策略失败: %v", err)
+	}
+	if err := ctx.GetStub().SetStateValidationParameter(key, policy); err != nil {
+		return fmt.Errorf("设置状态背书策略失败: %v", err)
+	}
+	return nil
+}
@@ -38,6 +38,7 @@ check_command() {
 log_info "检查必要的依赖..."
 check_command docker
 check_command docker-compose
+check_command go
 
 echo -e "\n${GREEN}================================${NC}"
 echo -e "${GREEN}   Fabric-Realty 一键安装脚本${NC}"
@@ -65,6 +66,16 @@ else
     log_info "跳过镜像加速，后续将直接从 Docker Hub 下载镜像..."
 fi
 
+# 编译链码 (二进制不纳入版本库, 在此本地构建以提前发现编译错误)
+log_info "开始编译链码..."
+cd chaincode
+if ! go build -o chaincode .; then
+    log_error "链码编译失败！"
+    exit 1
+fi
+log_success "链码编译完成"
+cd ..
+
 # 部署区块链网络
 log_info "开始部署区块链网络..."
 cd network
@@ -182,6 +182,17 @@ Version="1.0.0"
 Sequence="1"
 CHAINCODE_PATH="/opt/gopath/src/chaincode"
 CHAINCODE_PACKAGE="${CHAINCODE_PATH}/chaincode_${Version}.tar.gz"
+COLLECTIONS_CONFIG="${CHAINCODE_PATH}/collections_config.json"
+
+# Org3 CA 配置 (承运商/银行身份通过证书属性区分)
+CA_ORG3_CONTAINER="ca.${ORG3_DOMAIN}"
+CA_ORG3_CMD="docker exec ${CA_ORG3_CONTAINER} sh -c"
+CA_ORG3_URL="localhost:7054"
+CA_ORG3_USERS_PATH="/etc/hyperledger/org3/users"
+# 承运商身份: 名称:carrierId (与 application/server 配置 fabric.carriers 对应)
+CARRIER_IDENTITIES=("carrier1:CARRIER001" "carrier2:CARRIER002")
+# 银行身份: 名称:bankId (与 application/server 配置 fabric.banks 对应)
+BANK_IDENTITIES=("bank1:BANK001" "bank2:BANK002")
 
 # Order 配置
 ORDERER1_ADDRESS="orderer1.${DOMAIN}:7050"
@@ -221,6 +232,27 @@ CORE_PEER_TLS_CERT_FILE=\${ORG${org}_PEER${peer}_TLS_CERT_FILE} \\
 CORE_PEER_TLS_KEY_FILE=\${ORG${org}_PEER${peer}_TLS_KEY_FILE}\""
 }
 
+# 通过 Org3 CA 注册并签发携带证书属性的身份, MSP 写入 crypto-config 的 users 目录
+enroll_org3_identity() {
+    local name=$1   # 身份名称
+    local attr=$2   # 证书属性, 如 carrierId=CARRIER001
+    local msp_dir="${CA_ORG3_USERS_PATH}/${name}@${ORG3_DOMAIN}/msp"
+
+    $CA_ORG3_CMD "fabric-ca-client register --caname ca-org3 -u http://${CA_ORG3_URL} --id.name ${name} --id.secret ${name}pw --id.type client --id.attrs '${attr}:ecert'"
+    $CA_ORG3_CMD "fabric-ca-client enroll --caname ca-org3 -u http://${name}:${name}pw@${CA_ORG3_URL} --mspdir ${msp_dir}"
+}
+
+# 签发所有承运商与银行身份
+enroll_org3_identities() {
+    $CA_ORG3_CMD "fabric-ca-client enroll --caname ca-org3 -u http://admin:adminpw@${CA_ORG3_URL}"
+    for identity in "${CARRIER_IDENTITIES[@]}"; do
+        enroll_org3_identity "${identity%%:*}" "carrierId=${identity##*:}"
+    done
+    for identity in "${BANK_IDENTITIES[@]}"; do
+        enroll_org3_identity "${identity%%:*}" "bankId=${identity##*:}"
+    done
+}
+
 # 生成所有节点配置
 for org in 1 2 3; do
     for peer in 0 1; do
@@ -287,6 +319,9 @@ main() {
     execute_with_timer "启动节点" "docker-compose up -d"
     wait_for_completion "等待节点启动（${NETWORK_STARTUP_WAIT}秒）" $NETWORK_STARTUP_WAIT
 
+    # 签发承运商与银行身份
+    execute_with_timer "签发承运商与银行身份" "enroll_org3_identities"
+
     # 创建通道
     show_progress 9 "创建通道" $start_time
     execute_with_timer "创建通道" "$CLI_CMD \"$Org1Peer0Cli peer channel create --outputBlock ${CONFIG_PATH}/$ChannelName.block -o $ORDERER1_ADDRESS -c $ChannelName -f ${CONFIG_PATH}/$ChannelName.tx --tls --cafile $ORDERER_CA\""
@@ -322,13 +357,13 @@ main() {
     # 批准链码
     show_progress 14 "批准链码" $start_time
     PackageID=$($CLI_CMD "$Org1Peer0Cli peer lifecycle chaincode calculatepackageid ${CHAINCODE_PACKAGE}")
-    execute_with_timer "Org1批准链码" "$CLI_CMD \"$Org1Peer0Cli peer lifecycle chaincode approveformyorg -o $ORDERER1_ADDRESS --channelID $ChannelName --name $ChainCodeName --version $Version --package-id $PackageID --sequence $Sequence --tls --cafile $ORDERER_CA\""
-    execute_with_timer "Org2批准链码" "$CLI_CMD \"$Org2Peer0Cli peer lifecycle chaincode approveformyorg -o $ORDERER1_ADDRESS --channelID $ChannelName --name $ChainCodeName --version $Version --package-id $PackageID --sequence $Sequence --tls --cafile $ORDERER_CA\""
-    execute_with_timer "Org3批准链码" "$CLI_CMD \"$Org3Peer0Cli peer lifecycle chaincode approveformyorg -o $ORDERER1_ADDRESS --channelID $ChannelName --name $ChainCodeName --version $Version --package-id $PackageID --sequence $Sequence --tls --cafile $ORDERER_CA\""
+    execute_with_timer "Org1批准链码" "$CLI_CMD \"$Org1Peer0Cli peer lifecycle chaincode approveformyorg -o $ORDERER1_ADDRESS --channelID $ChannelName --name $ChainCodeName --version $Version --package-id $PackageID --sequence $Sequence --collections-config $COLLECTIONS_CONFIG --tls --cafile $ORDERER_CA\""
+    execute_with_timer "Org2批准链码" "$CLI_CMD \"$Org2Peer0Cli peer lifecycle chaincode approveformyorg -o $ORDERER1_ADDRESS --channelID $ChannelName --name $ChainCodeName --version $Version --package-id $PackageID --sequence $Sequence --collections-config $COLLECTIONS_CONFIG --tls --cafile $ORDERER_CA\""
+    execute_with_timer "Org3批准链码" "$CLI_CMD \"$Org3Peer0Cli peer lifecycle chaincode approveformyorg -o $ORDERER1_ADDRESS --channelID $ChannelName --name $ChainCodeName --version $Version --package-id $PackageID --sequence $Sequence --collections-config $COLLECTIONS_CONFIG --tls --cafile $ORDERER_CA\""
 
     # 提交链码
     show_progress 15 "提交链码" $start_time
-    execute_with_timer "提交链码定义" "$CLI_CMD \"$Org1Peer0Cli peer lifecycle chaincode commit -o $ORDERER1_ADDRESS --channelID $ChannelName --name $ChainCodeName --version $Version --sequence $Sequence --tls --cafile $ORDERER_CA --peerAddresses $ORG1_PEER0_ADDRESS --tlsRootCertFiles $ORG1_PEER0_TLS_ROOTCERT_FILE --peerAddresses $ORG2_PEER0_ADDRESS --tlsRootCertFiles $ORG2_PEER0_TLS_ROOTCERT_FILE --peerAddresses $ORG3_PEER0_ADDRESS --tlsRootCertFiles $ORG3_PEER0_TLS_ROOTCERT_FILE\""
+    execute_with_timer "提交链码定义" "$CLI_CMD \"$Org1Peer0Cli peer lifecycle chaincode commit -o $ORDERER1_ADDRESS --channelID $ChannelName --name $ChainCodeName --version $Version --sequence $Sequence --collections-config $COLLECTIONS_CONFIG --tls --cafile $ORDERER_CA --peerAddresses $ORG1_PEER0_ADDRESS --tlsRootCertFiles $ORG1_PEER0_TLS_ROOTCERT_FILE --peerAddresses $ORG2_PEER0_ADDRESS --tlsRootCertFiles $ORG2_PEER0_TLS_ROOTCERT_FILE --peerAddresses $ORG3_PEER0_ADDRESS --tlsRootCertFiles $ORG3_PEER0_TLS_ROOTCERT_FILE\""
 
     # 初始化并验证
     show_progress 16 "初始化并验证" $start_time